@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/layout"
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// batchConcurrency caps the layout scripts running at once
+const batchConcurrency = 4
+
+// batchEntry is a session to create from a batch file line
+type batchEntry struct {
+	name   string
+	dir    string
+	layout string
+	env    []string
+
+	exists bool  // Already running, left alone
+	err    error // Creating the session or applying its layout failed
+}
+
+// runBatch creates the sessions listed in a batch file ("-" reads stdin), one
+// "name<TAB>dir<TAB>template" per line. An empty dir or template falls back to
+// --dir / --template, then to the current directory / configured layout. The
+// whole file is checked before anything is created; layouts run concurrently.
+func runBatch(path, template, dir string, values map[string]string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	defaults := batchEntry{dir: dir, layout: cfg.Layout}
+	if template != "" {
+		if !layout.Exists(cfg.LayoutDir, template) {
+			return fmt.Errorf("layout %q not found (no %s)", template, layout.Path(cfg.LayoutDir, template))
+		}
+		defaults.layout = template
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+	entries, err := parseBatch(r, cfg, defaults, values)
+	if err != nil {
+		return err
+	}
+
+	// Sessions are created one by one, their layouts concurrently
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, batchConcurrency)
+	for i := range entries {
+		e := &entries[i]
+		if e.exists = tmux.SessionExists(e.name); e.exists {
+			continue
+		}
+		if err := tmux.CreateSession(e.name, e.dir, cfg.SessionCommand(e.layout)); err != nil {
+			e.err = fmt.Errorf("failed to create session: %w", err)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			if err := layout.Apply(cfg.LayoutDir, e.layout, e.name, e.dir, e.env); err != nil {
+				e.err = fmt.Errorf("layout failed: %w", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	created, skipped, failed := 0, 0, 0
+	for _, e := range entries {
+		switch {
+		case e.exists:
+			skipped++
+			fmt.Printf("  - %s: already exists\n", e.name)
+		case e.err != nil:
+			failed++
+			fmt.Printf("  ✗ %s: %v\n", e.name, e.err)
+		default:
+			created++
+			rememberSession(cfg, e.name, e.dir, e.layout)
+			logEvent(cfg, state.EventCreate, e.name)
+			fmt.Printf("  ✓ %s: %s\n", e.name, e.dir)
+		}
+	}
+	fmt.Printf("Created %d of %d sessions (%d skipped, %d failed)\n", created, len(entries), skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d sessions failed", failed)
+	}
+	return nil
+}
+
+// parseBatch reads and checks the lines of a batch file. Blank lines and
+// lines starting with # are skipped. All problems are reported together, with
+// their line numbers.
+func parseBatch(r io.Reader, cfg config.Config, defaults batchEntry, values map[string]string) ([]batchEntry, error) {
+	var entries []batchEntry
+	var errs []error
+	seen := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) > 3 {
+			errs = append(errs, fmt.Errorf("line %d: expected name<TAB>dir<TAB>template, got %d fields", n, len(fields)))
+			continue
+		}
+		fields = append(fields, "", "")
+		e := batchEntry{
+			name:   strings.TrimSpace(fields[0]),
+			dir:    strings.TrimSpace(fields[1]),
+			layout: strings.TrimSpace(fields[2]),
+		}
+		if e.dir == "" {
+			e.dir = defaults.dir
+		}
+		if e.layout == "" {
+			e.layout = defaults.layout
+		}
+
+		if err := checkBatchEntry(&e, cfg, values); err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", n, err))
+			continue
+		}
+		if first, ok := seen[e.name]; ok {
+			errs = append(errs, fmt.Errorf("line %d: %q is already listed on line %d", n, e.name, first))
+			continue
+		}
+		seen[e.name] = n
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no sessions listed")
+	}
+	return entries, nil
+}
+
+// checkBatchEntry validates a batch entry's name, resolves its directory and
+// builds its layout's environment
+func checkBatchEntry(e *batchEntry, cfg config.Config, values map[string]string) error {
+	if e.name == "" {
+		return fmt.Errorf("missing session name")
+	}
+	// tmux would silently rename the session, so the layout couldn't target it
+	if strings.ContainsAny(e.name, ".:") {
+		return fmt.Errorf("session name %q can't contain \".\" or \":\"", e.name)
+	}
+
+	dir, err := resolveDir(e.dir)
+	if err != nil {
+		return err
+	}
+	e.dir = dir
+
+	// Like tsm new, a missing configured layout just means no layout
+	if e.layout != cfg.Layout && !layout.Exists(cfg.LayoutDir, e.layout) {
+		return fmt.Errorf("layout %q not found (no %s)", e.layout, layout.Path(cfg.LayoutDir, e.layout))
+	}
+	e.env, err = layout.Env(layout.Vars(cfg.LayoutDir, e.layout), values)
+	return err
+}
+
+// resolveDir resolves a session's working directory: ~ and relative paths
+// are expanded, empty means the current directory
+func resolveDir(dir string) (string, error) {
+	var err error
+	switch {
+	case dir == "":
+		if dir, err = os.Getwd(); err != nil {
+			return "", err
+		}
+	case dir == "~" || strings.HasPrefix(dir, "~/"):
+		dir = filepath.Join(os.Getenv("HOME"), dir[1:])
+	}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", dir)
+	}
+	return dir, nil
+}
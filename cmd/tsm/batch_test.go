@@ -0,0 +1,123 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nikbrunner/tsm/internal/config"
+)
+
+// batchConfig returns a config whose layout dir holds a "web" layout
+// declaring PORT (no default) and a "plain" one without variables
+func batchConfig(t *testing.T) config.Config {
+	t.Helper()
+	dir := t.TempDir()
+	for name, script := range map[string]string{
+		"web":   "# tsm-var: PORT Dev server port\n",
+		"plain": "tmux rename-window main\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name+".sh"), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return config.Config{LayoutDir: dir, Layout: "plain"}
+}
+
+func TestParseBatch(t *testing.T) {
+	cfg := batchConfig(t)
+	dir := t.TempDir()
+	defaults := batchEntry{dir: dir, layout: cfg.Layout}
+
+	tests := []struct {
+		name    string
+		input   string
+		values  map[string]string
+		want    []batchEntry // name, dir and layout
+		wantErr string
+	}{
+		{
+			name:  "defaults fill empty fields",
+			input: "api\nweb\t" + dir + "\n\n# a comment\ndocs\t\tplain\n",
+			want: []batchEntry{
+				{name: "api", dir: dir, layout: "plain"},
+				{name: "web", dir: dir, layout: "plain"},
+				{name: "docs", dir: dir, layout: "plain"},
+			},
+		},
+		{
+			name:   "layout variables from --var",
+			input:  "api\t\tweb\n",
+			values: map[string]string{"PORT": "3000"},
+			want:   []batchEntry{{name: "api", dir: dir, layout: "web"}},
+		},
+		{name: "too many fields", input: "api\t.\tplain\textra\n", wantErr: "line 1: expected name<TAB>dir<TAB>template, got 4 fields"},
+		{name: "duplicate names", input: "api\nweb\napi\n", wantErr: `line 3: "api" is already listed on line 1`},
+		{name: "missing name", input: "\t" + dir + "\n", wantErr: "line 1: missing session name"},
+		{name: "name tmux would rename", input: "my.app\n", wantErr: `line 1: session name "my.app" can't contain`},
+		{name: "bad dir", input: "api\t" + filepath.Join(dir, "nope") + "\n", wantErr: "line 1: " + filepath.Join(dir, "nope") + " is not a directory"},
+		{name: "unknown layout", input: "api\t\tmissing\n", wantErr: `line 1: layout "missing" not found`},
+		{name: "required variable", input: "api\t\tweb\n", wantErr: "line 1: PORT is required"},
+		{name: "every problem reported", input: "a.b\nc\td\te\tf\n", wantErr: "can't contain \".\" or \":\"\nline 2: expected"},
+		{name: "nothing listed", input: "# only a comment\n\n", wantErr: "no sessions listed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := parseBatch(strings.NewReader(tt.input), cfg, defaults, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseBatch() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseBatch() error = %v", err)
+			}
+			same := func(a, b batchEntry) bool { return a.name == b.name && a.dir == b.dir && a.layout == b.layout }
+			if !slices.EqualFunc(entries, tt.want, same) {
+				t.Errorf("parseBatch() = %+v, want %+v", entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckBatchEntry(t *testing.T) {
+	cfg := batchConfig(t)
+	cfg.Layout = "gone"
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+
+	tests := []struct {
+		name    string
+		entry   batchEntry
+		values  map[string]string
+		wantDir string
+		wantEnv []string
+		wantErr bool
+	}{
+		{name: "home dir", entry: batchEntry{name: "api", dir: "~", layout: "plain"}, wantDir: dir},
+		{name: "layout env", entry: batchEntry{name: "api", dir: dir, layout: "web"}, values: map[string]string{"PORT": "8080"}, wantDir: dir, wantEnv: []string{"PORT=8080"}},
+		{name: "missing configured layout means none", entry: batchEntry{name: "api", dir: dir, layout: "gone"}, wantDir: dir},
+		{name: "undeclared variable", entry: batchEntry{name: "api", dir: dir, layout: "plain"}, values: map[string]string{"PORT": "8080"}, wantErr: true},
+		{name: "colon in name", entry: batchEntry{name: "api:1", dir: dir}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.entry
+			err := checkBatchEntry(&e, cfg, tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkBatchEntry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if e.dir != tt.wantDir || !slices.Equal(e.env, tt.wantEnv) {
+				t.Errorf("dir = %q, env = %v, want %q, %v", e.dir, e.env, tt.wantDir, tt.wantEnv)
+			}
+		})
+	}
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/claude"
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/configform"
+	"github.com/nikbrunner/tsm/internal/layout"
+	"github.com/nikbrunner/tsm/internal/projects"
+	"github.com/nikbrunner/tsm/internal/selfupdate"
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/tmux"
+	"github.com/nikbrunner/tsm/internal/version"
+)
+
+// command is a tsm subcommand
+type command struct {
+	name        string
+	args        string // Argument synopsis shown in usage
+	description string
+	run         func(args []string) error
+
+	// completesSessions marks commands whose argument is a session name
+	completesSessions bool
+
+	// mutates marks commands refused by --read-only (or read_only)
+	mutates bool
+}
+
+// commands lists all subcommands in the order they appear in usage and completion.
+// Populated in init() because completion refers back to this list.
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "init", description: "Create a config file with commented defaults", run: runInit},
+		{name: "config", args: "edit", description: "Edit the main options in a form", run: runConfig},
+		{name: "list", args: "[--names]", description: "List sessions", run: runList},
+		{name: "switch", args: "<session>", description: "Switch to a session", run: runSwitch, completesSessions: true},
+		{name: "kill", args: "<session>", description: "Kill a session", run: runKill, completesSessions: true, mutates: true},
+		{name: "new", args: "[flags] <session>", description: "Create a session with a layout (--batch <file> for many)", run: runNew, mutates: true},
+		{name: "grep", args: "[--switch] <text>", description: "Search session names, tags, paths and notes", run: runGrep},
+		{name: "go", args: "<session>", description: "Switch to a session, creating it if needed", run: runGo, completesSessions: true, mutates: true},
+		{name: "save", description: "Save a snapshot of every session", run: runSave, mutates: true},
+		{name: "diff", description: "Compare the live sessions to the saved snapshot", run: runDiff},
+		{name: "restore", description: "Recreate the saved sessions that aren't running", run: runRestore, mutates: true},
+		{name: "projects", args: "refresh", description: "Rescan the project directories picked from with C-p", run: runProjects},
+		{name: "watch", args: "[--interval 2s]", description: "Mirror Claude states into the @claude_status session option", run: runWatch, mutates: true},
+		{name: "stats", args: "[--weeks 4]", description: "Show where the time went, from the metrics_log events", run: runStats},
+		{name: "clean", description: "Remove Claude statuses, tags and notes of closed sessions", run: runClean, mutates: true},
+		{name: "completion", args: "bash|zsh|fish", description: "Print a shell completion script", run: runCompletion},
+		{name: "version", description: "Print the tsm version", run: runVersion},
+		{name: "self-update", args: "[--check]", description: "Install the latest release binary", run: runSelfUpdate, mutates: true},
+	}
+}
+
+// findCommand returns the subcommand with the given name, or nil
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// usage returns the usage text listing all subcommands
+func usage() string {
+	var b strings.Builder
+	b.WriteString("Usage: tsm [flags] [command]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(&b, "  %-28s %s\n", strings.TrimSpace(c.name+" "+c.args), c.description)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// commandNames returns the names of all subcommands
+func commandNames() []string {
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.name
+	}
+	return names
+}
+
+func runInit(args []string) error {
+	if err := config.Init(); err != nil {
+		return err
+	}
+	fmt.Printf("Created config file at %s\n", config.Path())
+	return nil
+}
+
+func runConfig(args []string) error {
+	if len(args) != 1 || args[0] != "edit" {
+		return fmt.Errorf("usage: tsm config edit")
+	}
+
+	cfg, err := config.LoadFile()
+	if err != nil {
+		return err
+	}
+
+	// Start from the commented template so its documentation is kept
+	if _, err := os.Stat(config.Path()); os.IsNotExist(err) {
+		if err := config.Init(); err != nil {
+			return err
+		}
+	}
+
+	result, err := tea.NewProgram(configform.New(config.Path(), cfg), tea.WithAltScreen()).Run()
+	if err != nil {
+		return err
+	}
+	if form, ok := result.(configform.Model); ok && form.Saved() {
+		fmt.Printf("Saved %s\n", config.Path())
+	}
+	return nil
+}
+
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	namesOnly := fs.Bool("names", false, "print session names only")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	sessions, err := tmux.ListSessions("")
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	current := ""
+	if os.Getenv("TMUX") != "" {
+		current, _ = tmux.CurrentSession()
+	}
+
+	for _, s := range sessions {
+		switch {
+		case *namesOnly:
+			fmt.Println(s.Name)
+		case s.Name == current:
+			fmt.Printf("* %s\n", s.Name)
+		default:
+			fmt.Printf("  %s\n", s.Name)
+		}
+	}
+	return nil
+}
+
+func runSwitch(args []string) error {
+	name, err := sessionArg("switch", args)
+	if err != nil {
+		return err
+	}
+	if err := requireTmux(); err != nil {
+		return err
+	}
+	if !tmux.SessionExists(name) {
+		return fmt.Errorf("session %q not found", name)
+	}
+	return switchClient(name)
+}
+
+func runKill(args []string) error {
+	name, err := sessionArg("kill", args)
+	if err != nil {
+		return err
+	}
+	if !tmux.SessionExists(name) {
+		return fmt.Errorf("session %q not found", name)
+	}
+	if err := tmux.KillSessionSafe(name); err != nil {
+		return fmt.Errorf("failed to kill session: %w", err)
+	}
+	if cfg, err := config.Load(); err == nil {
+		logEvent(cfg, state.EventKill, name)
+	}
+	fmt.Printf("Killed \"%s\"\n", name)
+	return nil
+}
+
+func runGo(args []string) error {
+	name, err := sessionArg("go", args)
+	if err != nil {
+		return err
+	}
+	if err := requireTmux(); err != nil {
+		return err
+	}
+
+	if !tmux.SessionExists(name) {
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		cfg, cfgErr := config.Load()
+		command := ""
+		if cfgErr == nil {
+			command = cfg.DefaultCommand
+		}
+		if err := tmux.CreateSession(name, dir, command); err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
+		}
+		if cfgErr == nil {
+			logEvent(cfg, state.EventCreate, name)
+		}
+	}
+	return switchClient(name)
+}
+
+func runNew(args []string) error {
+	fs := flag.NewFlagSet("new", flag.ContinueOnError)
+	template := fs.String("template", "", "layout to apply (default: the configured layout)")
+	dir := fs.String("dir", "", "working directory (default: the current directory)")
+	switchTo := fs.Bool("switch", false, "switch to the session once it is set up")
+	batch := fs.String("batch", "", "create the sessions listed in a file (- for stdin), one name<TAB>dir<TAB>template per line")
+	values := make(map[string]string)
+	fs.Func("var", "layout variable as NAME=value (repeatable)", func(s string) error {
+		name, value, ok := strings.Cut(s, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("expected NAME=value, got %q", s)
+		}
+		values[name] = value
+		return nil
+	})
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *batch != "" {
+		if fs.NArg() > 0 || *switchTo {
+			return fmt.Errorf("usage: tsm new --batch <file> [--template name] [--dir dir] [--var NAME=value]")
+		}
+		return runBatch(*batch, *template, *dir, values)
+	}
+	name, err := sessionArg("new", fs.Args())
+	if err != nil {
+		return err
+	}
+	// tmux would silently rename the session, so the layout couldn't target it
+	if strings.ContainsAny(name, ".:") {
+		return fmt.Errorf("session names can't contain \".\" or \":\"")
+	}
+	if tmux.SessionExists(name) {
+		return fmt.Errorf("session %q already exists", name)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	layoutName := cfg.Layout
+	if *template != "" {
+		if !layout.Exists(cfg.LayoutDir, *template) {
+			return fmt.Errorf("layout %q not found (no %s)", *template, layout.Path(cfg.LayoutDir, *template))
+		}
+		layoutName = *template
+	}
+	env, err := layout.Env(layout.Vars(cfg.LayoutDir, layoutName), values)
+	if err != nil {
+		return err
+	}
+
+	workingDir, err := resolveDir(*dir)
+	if err != nil {
+		return err
+	}
+
+	if err := tmux.CreateSession(name, workingDir, cfg.SessionCommand(layoutName)); err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	if err := layout.Apply(cfg.LayoutDir, layoutName, name, workingDir, env); err != nil {
+		return fmt.Errorf("created %q but its layout failed: %w", name, err)
+	}
+	rememberSession(cfg, name, workingDir, layoutName)
+	logEvent(cfg, state.EventCreate, name)
+
+	fmt.Printf("Created \"%s\" in %s\n", name, workingDir)
+	if *switchTo {
+		if err := requireTmux(); err != nil {
+			return err
+		}
+		return switchClient(name)
+	}
+	return nil
+}
+
+// rememberSession records a new session's directory and layout, so the picker
+// can recreate it once it is gone
+func rememberSession(cfg config.Config, name, dir, layoutName string) {
+	_ = state.UpdateHistory(cfg.StateDir, func(h *state.History) error {
+		h.Touch(name, dir, time.Now())
+		h.SetLayout(name, layoutName)
+		return nil
+	})
+}
+
+// switchClient switches to a session and records it in the switch history
+// the picker goes back and forward through
+func switchClient(name string) error {
+	from, _ := tmux.CurrentSession()
+	if err := tmux.SwitchClient(name); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	_ = state.UpdateSwitchHistory(cfg.StateDir, func(h *state.SwitchHistory) error {
+		h.Visit(from, name)
+		return nil
+	})
+	logEvent(cfg, state.EventSwitch, name)
+	return nil
+}
+
+// logEvent adds a switch, create or kill to the metrics log read by tsm stats
+// when metrics_log is on
+func logEvent(cfg config.Config, action, name string) {
+	if cfg.MetricsLog {
+		_ = state.AppendEvent(cfg.StateDir, state.Event{Time: time.Now(), Action: action, Session: name})
+	}
+}
+
+// runClean removes what the picker cleans up when it opens: the Claude status
+// files of sessions that are gone, and the tags and notes of sessions closed
+// for longer than state_ttl
+func runClean(args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	sessions, err := tmux.ListSessions("")
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	live := make([]string, len(sessions))
+	for i, s := range sessions {
+		live[i] = s.Name
+	}
+	// Sessions on the other servers are live too
+	others, err := tmux.SessionNames(cfg.Sockets()...)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	live = append(live, others...)
+
+	fmt.Printf("Removed %d Claude status files\n", claude.CleanupStale(cfg.CacheDir, live))
+	if cfg.StateBackend != config.BackendFile || cfg.StateTTL == 0 {
+		return nil
+	}
+	pruned, err := state.PruneClosed(cfg.StateDir, live, cfg.StateTTL, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, name := range pruned {
+		fmt.Printf("  %s\n", name)
+	}
+	fmt.Printf("Removed the tags and notes of %d closed sessions (state_ttl = %s)\n", len(pruned), cfg.StateTTL)
+	return nil
+}
+
+func runProjects(args []string) error {
+	if len(args) != 1 || args[0] != "refresh" {
+		return fmt.Errorf("usage: tsm projects refresh")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	dirs := projects.Scan(cfg.ProjectDirs, cfg.ProjectDepth)
+	cache := state.ProjectCache{Roots: cfg.ProjectDirs, Depth: cfg.ProjectDepth, Dirs: dirs, ScannedAt: time.Now()}
+	if err := state.SaveProjectCache(cfg.StateDir, cache); err != nil {
+		return err
+	}
+	fmt.Printf("Cached %d project directories\n", len(dirs))
+	return nil
+}
+
+func runVersion(args []string) error {
+	fmt.Printf("tsm %s\n", version.Get())
+	return nil
+}
+
+func runSelfUpdate(args []string) error {
+	fs := flag.NewFlagSet("self-update", flag.ContinueOnError)
+	check := fs.Bool("check", false, "only report whether an update is available")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	current := version.Get().Version
+	updater := selfupdate.New()
+	release, err := updater.Latest()
+	if err != nil {
+		return err
+	}
+	if release.Tag == current {
+		fmt.Printf("tsm %s is up to date\n", current)
+		return nil
+	}
+	if *check {
+		fmt.Printf("Update available: %s -> %s\n", current, release.Tag)
+		return nil
+	}
+
+	path, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return err
+	}
+	if err := updater.Update(release, path); err != nil {
+		return err
+	}
+	fmt.Printf("Updated tsm %s -> %s (%s)\n", current, release.Tag, path)
+	return nil
+}
+
+// sessionArg extracts the single session name argument of a subcommand
+func sessionArg(cmd string, args []string) (string, error) {
+	if len(args) != 1 || args[0] == "" {
+		return "", fmt.Errorf("usage: tsm %s <session>", cmd)
+	}
+	return args[0], nil
+}
+
+// requireTmux returns an error when not running inside a tmux client
+func requireTmux() error {
+	if os.Getenv("TMUX") == "" {
+		return fmt.Errorf("tsm must be run from within tmux")
+	}
+	return nil
+}
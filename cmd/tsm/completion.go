@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func runCompletion(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: tsm completion bash|zsh|fish")
+	}
+
+	var script string
+	switch args[0] {
+	case "bash":
+		script = bashCompletion()
+	case "zsh":
+		script = zshCompletion()
+	case "fish":
+		script = fishCompletion()
+	default:
+		return fmt.Errorf("unsupported shell %q (supported: bash, zsh, fish)", args[0])
+	}
+
+	fmt.Print(script)
+	return nil
+}
+
+// sessionCommandNames returns the subcommands that take a session name argument
+func sessionCommandNames() []string {
+	var names []string
+	for _, c := range commands {
+		if c.completesSessions {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
+
+// bashCompletion returns the bash completion script.
+// Install: tsm completion bash > ~/.local/share/bash-completion/completions/tsm
+func bashCompletion() string {
+	return fmt.Sprintf(`# bash completion for tsm
+_tsm() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+
+    if [[ $COMP_CWORD -eq 1 ]]; then
+        COMPREPLY=($(compgen -W "%s" -- "$cur"))
+        return
+    fi
+
+    case "${COMP_WORDS[1]}" in
+        %s)
+            [[ $COMP_CWORD -eq 2 ]] && COMPREPLY=($(compgen -W "$(tsm list --names 2>/dev/null)" -- "$cur"))
+            ;;
+        completion)
+            [[ $COMP_CWORD -eq 2 ]] && COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur"))
+            ;;
+        config)
+            [[ $COMP_CWORD -eq 2 ]] && COMPREPLY=($(compgen -W "edit" -- "$cur"))
+            ;;
+        projects)
+            [[ $COMP_CWORD -eq 2 ]] && COMPREPLY=($(compgen -W "refresh" -- "$cur"))
+            ;;
+        list)
+            COMPREPLY=($(compgen -W "--names" -- "$cur"))
+            ;;
+        new)
+            COMPREPLY=($(compgen -W "--template --dir --var --switch --batch" -- "$cur"))
+            ;;
+        grep)
+            COMPREPLY=($(compgen -W "--switch" -- "$cur"))
+            ;;
+        watch)
+            COMPREPLY=($(compgen -W "--interval" -- "$cur"))
+            ;;
+        stats)
+            COMPREPLY=($(compgen -W "--weeks" -- "$cur"))
+            ;;
+        self-update)
+            COMPREPLY=($(compgen -W "--check" -- "$cur"))
+            ;;
+    esac
+}
+complete -F _tsm tsm
+`, strings.Join(commandNames(), " "), strings.Join(sessionCommandNames(), "|"))
+}
+
+// zshCompletion returns the zsh completion script.
+// Install: tsm completion zsh > "${fpath[1]}/_tsm"
+func zshCompletion() string {
+	var described strings.Builder
+	for _, c := range commands {
+		// _describe splits the items at the first colon not escaped
+		fmt.Fprintf(&described, "        %s\n", zshQuote(strings.ReplaceAll(c.name, ":", `\:`)+":"+c.description))
+	}
+
+	return fmt.Sprintf(`#compdef tsm
+# zsh completion for tsm
+_tsm() {
+    local -a subcommands
+    subcommands=(
+%s    )
+
+    if (( CURRENT == 2 )); then
+        _describe 'command' subcommands
+        return
+    fi
+
+    case "${words[2]}" in
+        %s)
+            (( CURRENT == 3 )) && compadd -- ${(f)"$(tsm list --names 2>/dev/null)"}
+            ;;
+        completion)
+            (( CURRENT == 3 )) && compadd bash zsh fish
+            ;;
+        config)
+            (( CURRENT == 3 )) && compadd edit
+            ;;
+        projects)
+            (( CURRENT == 3 )) && compadd refresh
+            ;;
+        list)
+            compadd -- --names
+            ;;
+        new)
+            compadd -- --template --dir --var --switch --batch
+            ;;
+        grep)
+            compadd -- --switch
+            ;;
+        watch)
+            compadd -- --interval
+            ;;
+        stats)
+            compadd -- --weeks
+            ;;
+        self-update)
+            compadd -- --check
+            ;;
+    esac
+}
+compdef _tsm tsm
+`, described.String(), strings.Join(sessionCommandNames(), "|"))
+}
+
+// fishCompletion returns the fish completion script.
+// Install: tsm completion fish > ~/.config/fish/completions/tsm.fish
+func fishCompletion() string {
+	var b strings.Builder
+	b.WriteString("# fish completion for tsm\n")
+	b.WriteString("complete -c tsm -f\n")
+	for _, c := range commands {
+		fmt.Fprintf(&b, "complete -c tsm -n '__fish_use_subcommand' -a %s -d %s\n", c.name, fishQuote(c.description))
+	}
+	fmt.Fprintf(&b, "complete -c tsm -n '__fish_seen_subcommand_from %s' -a '(tsm list --names 2>/dev/null)'\n",
+		strings.Join(sessionCommandNames(), " "))
+	b.WriteString("complete -c tsm -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'\n")
+	b.WriteString("complete -c tsm -n '__fish_seen_subcommand_from config' -a 'edit'\n")
+	b.WriteString("complete -c tsm -n '__fish_seen_subcommand_from projects' -a 'refresh'\n")
+	b.WriteString("complete -c tsm -n '__fish_seen_subcommand_from list' -l names -d 'Print session names only'\n")
+	b.WriteString("complete -c tsm -n '__fish_seen_subcommand_from new' -l template -r -d 'Layout to apply'\n")
+	b.WriteString("complete -c tsm -n '__fish_seen_subcommand_from new' -l dir -r -d 'Working directory'\n")
+	b.WriteString("complete -c tsm -n '__fish_seen_subcommand_from new' -l var -r -d 'Layout variable NAME=value'\n")
+	b.WriteString("complete -c tsm -n '__fish_seen_subcommand_from new' -l switch -d 'Switch to the new session'\n")
+	b.WriteString("complete -c tsm -n '__fish_seen_subcommand_from new' -l batch -r -F -d 'File listing the sessions to create'\n")
+	b.WriteString("complete -c tsm -n '__fish_seen_subcommand_from grep' -l switch -d 'Switch to the best match'\n")
+	b.WriteString("complete -c tsm -n '__fish_seen_subcommand_from watch' -l interval -x -d 'How often the status files are read'\n")
+	b.WriteString("complete -c tsm -n '__fish_seen_subcommand_from stats' -l weeks -x -d 'Number of weeks to sum up'\n")
+	b.WriteString("complete -c tsm -n '__fish_seen_subcommand_from self-update' -l check -d 'Only check for an update'\n")
+	return b.String()
+}
+
+// zshQuote single-quotes a word for zsh, where nothing escapes inside single
+// quotes: a quote closes them, is escaped and reopens them
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote single-quotes a word for fish, which takes \' and \\ inside
+// single quotes
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// shellWords splits a line into words the way a shell reads single quotes
+// and backslashes. escapesInQuotes lists what a backslash escapes inside
+// single quotes (fish: \' and \\, zsh: nothing). ok is false when a quote
+// is left open.
+func shellWords(line, escapesInQuotes string) (words []string, ok bool) {
+	var word strings.Builder
+	inWord, quoted := false, false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quoted && c == '\\' && i+1 < len(line) && strings.IndexByte(escapesInQuotes, line[i+1]) >= 0:
+			i++
+			word.WriteByte(line[i])
+		case c == '\'':
+			quoted, inWord = !quoted, true
+		case quoted:
+			word.WriteByte(c)
+		case c == '\\' && i+1 < len(line):
+			i++
+			word.WriteByte(line[i])
+			inWord = true
+		case c == ' ':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, !quoted
+}
+
+func TestCompletionQuoting(t *testing.T) {
+	orig := commands
+	t.Cleanup(func() { commands = orig })
+	commands = append(commands, command{name: "odd:name", description: `it's a "test": with \ backslash`})
+
+	for _, c := range commands {
+		zshItem := strings.ReplaceAll(c.name, ":", `\:`) + ":" + c.description
+		found := false
+		for _, line := range strings.Split(zshCompletion(), "\n") {
+			words, ok := shellWords(line, "")
+			if !ok {
+				t.Fatalf("zsh line %q leaves a quote open", line)
+			}
+			if len(words) == 1 && words[0] == zshItem {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("zsh completion lacks %q", zshItem)
+		}
+
+		found = false
+		for _, line := range strings.Split(fishCompletion(), "\n") {
+			words, ok := shellWords(line, `\'`)
+			if !ok {
+				t.Fatalf("fish line %q leaves a quote open", line)
+			}
+			if len(words) == 9 && words[6] == c.name && words[7] == "-d" && words[8] == c.description {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("fish completion lacks %s described as %q", c.name, c.description)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// runGrep searches the sessions' names, tags, paths and notes, printing one
+// line per match with the session to switch to, best session first
+func runGrep(args []string) error {
+	fs := flag.NewFlagSet("grep", flag.ContinueOnError)
+	switchTo := fs.Bool("switch", false, "switch to the best match")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	text := strings.Join(fs.Args(), " ")
+	if text == "" {
+		return fmt.Errorf("usage: tsm grep [--switch] <text>")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	sessions, err := tmux.ListSessions("")
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	store := state.NewStore(cfg.StateBackend, cfg.StateDir)
+	tags, _ := store.LoadTags()
+	entries := make([]state.SearchEntry, len(sessions))
+	for i, s := range sessions {
+		notes, _ := store.ReadNotes(s.Name)
+		entries[i] = state.SearchEntry{Session: s.Name, Path: s.Path, Tags: tags[s.Name], Notes: notes}
+	}
+
+	results := state.Search(entries, text)
+	if len(results) == 0 {
+		return fmt.Errorf("no session matches %q", text)
+	}
+
+	if *switchTo {
+		if err := requireTmux(); err != nil {
+			return err
+		}
+		return switchClient(results[0].Session)
+	}
+
+	width := 0
+	for _, r := range results {
+		width = max(width, len(r.Session))
+	}
+	for _, r := range results {
+		for _, match := range r.Matches {
+			line := match.Text
+			switch match.Field {
+			case state.FieldName:
+				line = ""
+			case state.FieldPath:
+				line = homePath(line)
+			}
+			fmt.Println(strings.TrimRight(fmt.Sprintf("%-*s  %-4s  %s", width, r.Session, match.Field, line), " "))
+		}
+	}
+	return nil
+}
+
+// homePath shortens a path below $HOME to start with ~
+func homePath(path string) string {
+	home := os.Getenv("HOME")
+	if home != "" && (path == home || strings.HasPrefix(path, home+"/")) {
+		return "~" + strings.TrimPrefix(path, home)
+	}
+	return path
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/mattn/go-isatty"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/layout"
+	"github.com/nikbrunner/tsm/internal/model"
+	"github.com/nikbrunner/tsm/internal/projects"
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// launcherSize caps the entries the first-run list offers
+const launcherSize = 9
+
+// launcherRecent caps the closed sessions offered before the projects
+const launcherRecent = 3
+
+// launchEntry is a way to start the first sessions of an empty tmux server
+type launchEntry struct {
+	label string
+
+	// start creates the sessions in the background and returns the one to
+	// attach to, asking on in for what it needs
+	start func(in *bufio.Reader) (string, error)
+}
+
+// offerLauncher lists ways to start when the tmux server has no sessions:
+// the saved snapshot, recently closed sessions and project directories with
+// their layouts, or a plain new session. The choice is started and attached
+// to, replacing tsm. Without a terminal to ask on, it only prints the command;
+// in read-only mode it creates nothing either and only prints the command.
+func offerLauncher(readOnly bool) {
+	cfg, err := config.Load()
+	if readOnly || err == nil && cfg.ReadOnly {
+		fmt.Println("Run `tmux new-session`, then open tsm from inside tmux (read-only mode starts no sessions).")
+		return
+	}
+	if err != nil || !isatty.IsTerminal(os.Stdin.Fd()) {
+		offerTmux("No tmux server is running. Start one?", "new-session")
+		return
+	}
+	entries := launcherEntries(cfg)
+	if len(entries) == 1 {
+		offerTmux("No tmux server is running. Start one?", "new-session")
+		return
+	}
+
+	fmt.Println("No tmux session is running. Start with:")
+	for i, e := range entries {
+		fmt.Printf("  %d) %s\n", i+1, e.label)
+	}
+	fmt.Printf("Choice [1-%d, enter for 1, q quits]: ", len(entries))
+	in := bufio.NewReader(os.Stdin)
+	answer, _ := in.ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	if answer == "q" {
+		return
+	}
+	choice := 1
+	if answer != "" {
+		choice, err = strconv.Atoi(answer)
+		if err != nil || choice < 1 || choice > len(entries) {
+			fmt.Printf("Error: no entry %q\n", answer)
+			return
+		}
+	}
+
+	session, err := entries[choice-1].start(in)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	path, err := exec.LookPath("tmux")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	args := []string{"tmux", "attach", "-t", session}
+	if session == "" {
+		args = []string{"tmux", "new-session"}
+	}
+	if err := syscall.Exec(path, args, os.Environ()); err != nil {
+		fmt.Printf("Error: failed to run %s: %v\n", strings.Join(args, " "), err)
+	}
+}
+
+// launcherEntries returns the first-run choices, the saved snapshot first and
+// a plain new session last
+func launcherEntries(cfg config.Config) []launchEntry {
+	var entries []launchEntry
+
+	if snapshot, err := state.LoadSnapshot(cfg.StateDir); err == nil && len(snapshot.Sessions) > 0 {
+		names := make([]string, len(snapshot.Sessions))
+		for i, s := range snapshot.Sessions {
+			names[i] = s.Name
+		}
+		entries = append(entries, launchEntry{
+			label: fmt.Sprintf("Restore the snapshot saved %s (%s)", snapshot.SavedAt.Format("Jan 2 15:04"), strings.Join(names, ", ")),
+			start: func(*bufio.Reader) (string, error) { return restoreSnapshot(cfg, snapshot) },
+		})
+	}
+
+	// Closed sessions come back with the layout they were created with,
+	// project directories with the configured one
+	seen := make(map[string]bool)
+	history, _ := state.LoadHistory(cfg.StateDir)
+	for _, e := range history.Recent(nil, launcherRecent) {
+		if e.Path == "" || !isDir(e.Path) {
+			continue
+		}
+		seen[e.Path] = true
+		label := fmt.Sprintf("%s  %s", e.Name, homePath(e.Path))
+		if e.Layout != "" {
+			label += fmt.Sprintf(" (layout %s)", e.Layout)
+		}
+		entries = append(entries, launchEntry{label: label, start: startSession(cfg, e.Name, e.Path, e.Layout)})
+	}
+
+	cache, _ := state.LoadProjectCache(cfg.StateDir)
+	dirs := cache.Dirs
+	if !cache.Matches(cfg.ProjectDirs, cfg.ProjectDepth) {
+		dirs = projects.Scan(cfg.ProjectDirs, cfg.ProjectDepth)
+	}
+	for _, dir := range dirs {
+		if len(entries) == launcherSize-1 {
+			break
+		}
+		if seen[dir] {
+			continue
+		}
+		name := model.ProjectSessionName(cfg, dir)
+		entries = append(entries, launchEntry{label: homePath(dir), start: startSession(cfg, name, dir, cfg.Layout)})
+	}
+
+	return append(entries, launchEntry{
+		label: "New session here (tmux new-session)",
+		start: func(*bufio.Reader) (string, error) { return "", nil },
+	})
+}
+
+// restoreSnapshot recreates every saved session and returns the one active
+// last when the snapshot was taken
+func restoreSnapshot(cfg config.Config, snapshot state.Snapshot) (string, error) {
+	first := ""
+	for _, session := range snapshot.Sessions {
+		windows := state.RestorableWindows(session.Windows, cfg.DefaultSessionDir)
+		if err := tmux.RestoreSession(session.Name, windows); err != nil {
+			return first, fmt.Errorf("failed to restore %q: %w", session.Name, err)
+		}
+		if first == "" {
+			first = session.Name
+		}
+	}
+	return first, nil
+}
+
+// startSession returns a launcher start creating a session in dir with a
+// layout, asking for the layout's variables first
+func startSession(cfg config.Config, name, dir, layoutName string) func(*bufio.Reader) (string, error) {
+	return func(in *bufio.Reader) (string, error) {
+		vars := layout.Vars(cfg.LayoutDir, layoutName)
+		env, err := layout.Env(vars, askLayoutVars(in, vars))
+		if err != nil {
+			return "", fmt.Errorf("layout %s: %w", layoutName, err)
+		}
+		if err := tmux.CreateSession(name, dir, cfg.SessionCommand(layoutName)); err != nil {
+			return "", fmt.Errorf("failed to create session: %w", err)
+		}
+		if err := layout.Apply(cfg.LayoutDir, layoutName, name, dir, env); err != nil {
+			return name, fmt.Errorf("created %q but its layout failed: %w", name, err)
+		}
+		rememberSession(cfg, name, dir, layoutName)
+		logEvent(cfg, state.EventCreate, name)
+		return name, nil
+	}
+}
+
+// askLayoutVars reads a value for each layout variable from in, an empty
+// answer keeping the default
+func askLayoutVars(in *bufio.Reader, vars []layout.Var) map[string]string {
+	values := make(map[string]string, len(vars))
+	for _, v := range vars {
+		if v.Default != "" {
+			fmt.Printf("%s [%s]: ", v.Prompt, v.Default)
+		} else {
+			fmt.Printf("%s: ", v.Prompt)
+		}
+		answer, _ := in.ReadString('\n')
+		if answer = strings.TrimSpace(answer); answer != "" {
+			values[v.Name] = answer
+		}
+	}
+	return values
+}
+
+// isDir reports whether path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/nikbrunner/tsm/internal/config"
 	"github.com/nikbrunner/tsm/internal/model"
+	"github.com/nikbrunner/tsm/internal/profile"
 	"github.com/nikbrunner/tsm/internal/tmux"
+	"github.com/nikbrunner/tsm/internal/ui"
 )
 
 func main() {
@@ -18,36 +22,81 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Global flags (before any subcommand)
+	plain := flag.Bool("plain", false, "plain output without icons, colors or box drawing")
+	ascii := flag.Bool("ascii", false, "ASCII icons, for terminals without a nerd font")
+	readOnly := flag.Bool("read-only", false, "disable killing, merging and creating sessions")
+	expand := flag.String("expand", "", "open with this session's windows expanded (may be the current session)")
+	expandAll := flag.Bool("expand-all", false, "open with every session's windows expanded")
+	tree := flag.Bool("tree", false, "choose-tree style view: every session expanded, windows and panes drawn as a tree")
+	profileFlag := flag.Bool("profile", false, "print timing of tmux calls, loads and first render on exit")
+	flag.Usage = func() {
+		fmt.Println(usage())
+		fmt.Println("\nFlags:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// Profiling: report to stderr with --profile, or append to $TSM_DEBUG_LOG
+	// (stderr is lost when tsm runs in a tmux popup)
+	if *profileFlag || os.Getenv("TSM_DEBUG_LOG") != "" {
+		profile.Enable()
+	}
+
 	// Handle subcommands
-	if len(os.Args) > 1 {
-		switch os.Args[1] {
-		case "init":
-			if err := config.Init(); err != nil {
-				fmt.Printf("Error: %v\n", err)
-				os.Exit(1)
-			}
-			fmt.Printf("Created config file at %s\n", config.Path())
-			return
-		default:
-			fmt.Printf("Unknown command: %s\n", os.Args[1])
-			fmt.Println("Usage: tsm [init]")
+	if args := flag.Args(); len(args) > 0 {
+		cmd := findCommand(args[0])
+		if cmd == nil {
+			fmt.Printf("Unknown command: %s\n", args[0])
+			fmt.Println(usage())
+			os.Exit(1)
+		}
+		// Subcommands load the config themselves, but read_only and
+		// tmux_timeout hold for all of them
+		if cfg, err := config.Load(); err == nil {
+			tmux.SetTimeout(cfg.TmuxTimeout)
+			*readOnly = *readOnly || cfg.ReadOnly
+		}
+		if *readOnly && cmd.mutates {
+			fmt.Printf("Error: %s is disabled in read-only mode\n", cmd.name)
 			os.Exit(1)
 		}
+		if err := cmd.run(args[1:]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		reportProfile(*profileFlag)
+		return
 	}
 
-	// Check if running inside tmux
-	if os.Getenv("TMUX") == "" {
-		fmt.Println("Error: tsm must be run from within tmux")
+	// Check that tmux is installed, running and tsm runs inside it
+	if !selfCheck(*readOnly) {
 		os.Exit(1)
 	}
 
 	// Load configuration
+	endLoadConfig := profile.Start("load config")
 	cfg, err := config.Load()
+	endLoadConfig()
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		os.Exit(1)
 	}
 
+	tmux.SetTimeout(cfg.TmuxTimeout)
+
+	if *readOnly {
+		cfg.ReadOnly = true
+	}
+
+	if *plain || cfg.Plain {
+		ui.SetPlain()
+	}
+	if err := setIcons(cfg, *ascii); err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Get current session to exclude from list
 	currentSession, err := tmux.CurrentSession()
 	if err != nil {
@@ -57,10 +106,49 @@ func main() {
 
 	// Initialize and run the TUI
 	m := model.New(currentSession, cfg)
+	m.ExpandOnStart(*expand, *expandAll)
+	if *tree {
+		m.TreeView()
+	}
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
+	reportProfile(*profileFlag)
+}
+
+// setIcons picks the icon set: ascii with --ascii (ignoring the [icons]
+// overrides, which may well be nerd font glyphs), else icon_set, with auto
+// resolved from the terminal and locale
+func setIcons(cfg config.Config, ascii bool) error {
+	if ascii {
+		return ui.SetIcons(ui.IconsASCII, nil)
+	}
+	set := cfg.IconSet
+	if set == ui.IconsAuto {
+		set = ui.DetectIconSet(os.Getenv)
+	}
+	return ui.SetIcons(set, cfg.Icons)
+}
+
+// reportProfile writes recorded timing spans to stderr and/or the debug log
+func reportProfile(toStderr bool) {
+	if !profile.Enabled() {
+		return
+	}
+	if toStderr {
+		profile.Report(os.Stderr)
+	}
+	if path := os.Getenv("TSM_DEBUG_LOG"); path != "" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing debug log: %v\n", err)
+			return
+		}
+		defer func() { _ = f.Close() }()
+		_, _ = fmt.Fprintf(f, "--- %s\n", time.Now().Format(time.RFC3339))
+		profile.Report(f)
+	}
 }
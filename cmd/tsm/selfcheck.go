@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/mattn/go-isatty"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// selfCheck makes sure the picker can talk to tmux before the TUI starts.
+// When it can't, it prints what is wrong and how to fix it, and outside tmux
+// offers to attach to the running server or, without sessions, to start from
+// the first-run list (see offerLauncher). It returns false when tsm should
+// exit.
+func selfCheck(readOnly bool) bool {
+	if !tmux.Installed() {
+		fmt.Println("Error: tmux is not installed (not found in $PATH)")
+		fmt.Println("Install it with your package manager, e.g. `brew install tmux` or `apt install tmux`.")
+		return false
+	}
+
+	running := tmux.ServerRunning()
+	if os.Getenv("TMUX") != "" {
+		if running {
+			return true
+		}
+		fmt.Println("Error: the tmux server in $TMUX isn't responding (was it killed?)")
+		fmt.Println("Open a new terminal and start tmux again with `tmux new`.")
+		return false
+	}
+
+	fmt.Println("tsm must be run from within tmux.")
+	if sessions, err := tmux.ListSessions(""); running && err == nil && len(sessions) > 0 {
+		offerTmux("A tmux server is running. Attach to it?", "attach")
+	} else {
+		offerLauncher(readOnly)
+	}
+	return false
+}
+
+// offerTmux asks whether to run a tmux command and replaces tsm with it.
+// Without a terminal to ask on, it only prints the command.
+func offerTmux(question string, args ...string) {
+	command := "tmux " + strings.Join(args, " ")
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		fmt.Printf("Run `%s`, then open tsm from inside tmux.\n", command)
+		return
+	}
+
+	fmt.Printf("%s (runs `%s`) [Y/n] ", question, command)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "" && answer != "y" && answer != "yes" {
+		return
+	}
+
+	path, err := exec.LookPath("tmux")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if err := syscall.Exec(path, append([]string{"tmux"}, args...), os.Environ()); err != nil {
+		fmt.Printf("Error: failed to run %s: %v\n", command, err)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+func runSave(args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	sessions, err := state.CaptureSessions()
+	if err != nil {
+		return fmt.Errorf("failed to read sessions: %w", err)
+	}
+
+	snapshot := state.Snapshot{SavedAt: time.Now(), Sessions: sessions}
+	if err := state.SaveSnapshot(cfg.StateDir, snapshot); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	fmt.Printf("Saved %d sessions\n", len(sessions))
+	return nil
+}
+
+func runDiff(args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	snapshot, diffs, err := diffSnapshot(cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Compared to the snapshot saved %s:\n", snapshot.SavedAt.Format("2006-01-02 15:04"))
+	if len(diffs) == 0 {
+		fmt.Println("  Live sessions match the snapshot")
+	}
+	for _, d := range diffs {
+		fmt.Println(d)
+	}
+	return nil
+}
+
+func runRestore(args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	snapshot, diffs, err := diffSnapshot(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Running sessions are left alone, only missing ones are recreated
+	missing := make(map[string]bool)
+	for _, d := range diffs {
+		if d.Kind == state.DiffMissing {
+			missing[d.Name] = true
+		}
+	}
+	if len(missing) == 0 {
+		fmt.Println("Every saved session is running")
+		return nil
+	}
+
+	for _, session := range snapshot.Sessions {
+		if !missing[session.Name] {
+			continue
+		}
+		windows := state.RestorableWindows(session.Windows, cfg.DefaultSessionDir)
+		if err := tmux.RestoreSession(session.Name, windows); err != nil {
+			return fmt.Errorf("failed to restore %q: %w", session.Name, err)
+		}
+		fmt.Printf("Restored \"%s\"\n", session.Name)
+	}
+	return nil
+}
+
+// diffSnapshot compares the live sessions to the saved snapshot
+func diffSnapshot(cfg config.Config) (state.Snapshot, []state.SessionDiff, error) {
+	snapshot, err := state.LoadSnapshot(cfg.StateDir)
+	if err != nil {
+		return snapshot, nil, err
+	}
+	if snapshot.SavedAt.IsZero() {
+		return snapshot, nil, fmt.Errorf("no snapshot saved yet (tsm save)")
+	}
+	live, err := state.CaptureSessions()
+	if err != nil {
+		return snapshot, nil, fmt.Errorf("failed to read sessions: %w", err)
+	}
+	return snapshot, snapshot.Diff(live), nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/state"
+)
+
+// statsBarWidth is the width of the bar of the session used most
+const statsBarWidth = 20
+
+// statsTopPerWeek is how many sessions each week lists
+const statsTopPerWeek = 3
+
+// runStats shows the time spent per session over the last weeks and the
+// sessions used most each week, from the events logged with metrics_log
+func runStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	weeks := fs.Int("weeks", 4, "number of weeks to sum up, the current one included")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || *weeks < 1 {
+		return fmt.Errorf("usage: tsm stats [--weeks 4]")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	events, err := state.LoadEvents(cfg.StateDir)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		if !cfg.MetricsLog {
+			return fmt.Errorf("no events logged yet, set metrics_log = true in %s", config.Path())
+		}
+		fmt.Println("No events logged yet")
+		return nil
+	}
+
+	now := time.Now()
+	week := startOfWeek(now)
+	from := week.AddDate(0, 0, -7*(*weeks-1))
+
+	fmt.Printf("Time per session since %s:\n", from.Format("Mon Jan 2"))
+	usages := state.Usages(events, from, now)
+	var total time.Duration
+	for _, u := range usages {
+		total += u.Time
+	}
+	if total == 0 {
+		fmt.Println("  nothing logged")
+	}
+	for _, u := range usages {
+		if u.Time == 0 {
+			continue
+		}
+		bar := strings.Repeat("█", max(int(u.Time*statsBarWidth/usages[0].Time), 1))
+		switches := "switches"
+		if u.Switches == 1 {
+			switches = "switch"
+		}
+		fmt.Printf("  %-20s %7s %4.0f%%  %s%s %d %s\n", u.Session, formatDuration(u.Time),
+			float64(u.Time)*100/float64(total), bar, strings.Repeat(" ", statsBarWidth-len([]rune(bar))), u.Switches, switches)
+	}
+
+	fmt.Println("\nMost used per week:")
+	for start := week; !start.Before(from); start = start.AddDate(0, 0, -7) {
+		var top []string
+		for _, u := range state.Usages(events, start, start.AddDate(0, 0, 7)) {
+			if u.Time == 0 || len(top) == statsTopPerWeek {
+				break
+			}
+			top = append(top, fmt.Sprintf("%s %s", u.Session, formatDuration(u.Time)))
+		}
+		if len(top) == 0 {
+			top = []string{"-"}
+		}
+		fmt.Printf("  %s  %s\n", start.Format("Jan 02"), strings.Join(top, " · "))
+	}
+	return nil
+}
+
+// startOfWeek returns midnight of the Monday starting t's week
+func startOfWeek(t time.Time) time.Time {
+	days := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-days, 0, 0, 0, 0, t.Location())
+}
+
+// formatDuration formats a duration in hours and minutes, e.g. "3h05m"
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
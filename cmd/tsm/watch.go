@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nikbrunner/tsm/internal/claude"
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// runWatch mirrors the Claude state of every session into the @claude_status
+// session option until the tmux server exits, so the tmux status line can
+// show it too. Meant to run in the background from tmux.conf:
+//
+//	run-shell -b "tsm watch"
+func runWatch(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	interval := fs.Duration("interval", 2*time.Second, "how often the status files are read")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || *interval <= 0 {
+		return fmt.Errorf("usage: tsm watch [--interval 2s]")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		if !syncClaudeOptions(cfg.CacheDir) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			// Don't leave states behind that nothing updates anymore
+			clearClaudeOptions()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// syncClaudeOptions sets @claude_status to each session's Claude state and
+// unsets it where the state cleared (Claude exited or went stale). Returns
+// false once the tmux server is gone.
+func syncClaudeOptions(cacheDir string) bool {
+	sessions, err := tmux.ListSessions("")
+	if err != nil {
+		return tmux.ServerRunning()
+	}
+	mirrored, err := tmux.ListSessionOption(claude.Option)
+	if err != nil {
+		return true
+	}
+
+	names := make([]string, len(sessions))
+	for i, s := range sessions {
+		names[i] = s.Name
+	}
+	for name, state := range claude.OptionChanges(mirrored, claude.GetStatuses(names, cacheDir)) {
+		_ = tmux.SetSessionOption(name, claude.Option, state)
+	}
+	return true
+}
+
+// clearClaudeOptions unsets @claude_status on every session
+func clearClaudeOptions() {
+	mirrored, _ := tmux.ListSessionOption(claude.Option)
+	for name := range mirrored {
+		_ = tmux.SetSessionOption(name, claude.Option, "")
+	}
+}
@@ -6,25 +6,45 @@ STATUS_DIR="$HOME/.cache/tsm"
 mkdir -p "$STATUS_DIR"
 
 # Read JSON from stdin (required by Claude Code hooks)
-cat > /dev/null
+INPUT=$(cat)
 
-# Get tmux session name
-TMUX_SESSION=$(tmux display-message -p '#{session_name}' 2>/dev/null)
+# Get the tmux session and window of Claude's pane (the active pane may be
+# elsewhere by the time the hook runs)
+TARGET=()
+[[ -n "$TMUX_PANE" ]] && TARGET=(-t "$TMUX_PANE")
+TMUX_SESSION=$(tmux display-message -p "${TARGET[@]}" '#{session_name}' 2>/dev/null)
 [[ -z "$TMUX_SESSION" ]] && exit 0
+TMUX_WINDOW=$(tmux display-message -p "${TARGET[@]}" '#{window_id}' 2>/dev/null)
 
 HOOK_TYPE="$1"
 STATUS_FILE="$STATUS_DIR/${TMUX_SESSION}.status"
 TIMESTAMP=$(date +%s)
 
+# Write the status file: JSON (v2) with message/model/pid when jq is
+# available, otherwise the legacy "state:timestamp" format
+write_status() {
+    local state="$1"
+    if command -v jq &>/dev/null; then
+        jq -c \
+            --arg state "$state" \
+            --argjson timestamp "$TIMESTAMP" \
+            --argjson pid "$PPID" \
+            --arg window "$TMUX_WINDOW" \
+            '{version: 2, state: $state, timestamp: $timestamp, message: (.message // ""), model: ((.model | objects | .display_name) // .model // ""), pid: $pid, window: $window}' \
+            <<<"${INPUT:-{\}}" > "$STATUS_FILE" 2>/dev/null && return
+    fi
+    echo "$state:$TIMESTAMP" > "$STATUS_FILE"
+}
+
 case "$HOOK_TYPE" in
     "SessionStart")
-        echo "new:$TIMESTAMP" > "$STATUS_FILE"
+        write_status "new"
         ;;
     "PreToolUse")
-        echo "working:$TIMESTAMP" > "$STATUS_FILE"
+        write_status "working"
         ;;
     "Stop"|"SubagentStop"|"Notification")
-        echo "waiting:$TIMESTAMP" > "$STATUS_FILE"
+        write_status "waiting"
         # Play notification sound (macOS)
         if command -v afplay &>/dev/null; then
             afplay /System/Library/Sounds/Pop.aiff 2>/dev/null &
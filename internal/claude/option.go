@@ -0,0 +1,24 @@
+package claude
+
+// Option is the tmux user option `tsm watch` mirrors each session's Claude
+// state into, e.g. for "#{@claude_status}" in the tmux status line
+const Option = "@claude_status"
+
+// OptionChanges compares the mirrored options (session name to value, as
+// tmux lists them) with the current statuses and returns the options to set,
+// keyed by session. An empty value unsets the option of a session whose
+// status cleared.
+func OptionChanges(mirrored map[string]string, statuses map[string]Status) map[string]string {
+	changes := make(map[string]string)
+	for name, status := range statuses {
+		if mirrored[name] != status.State {
+			changes[name] = status.State
+		}
+	}
+	for name := range mirrored {
+		if _, ok := statuses[name]; !ok {
+			changes[name] = ""
+		}
+	}
+	return changes
+}
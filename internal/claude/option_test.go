@@ -0,0 +1,24 @@
+package claude
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestOptionChanges(t *testing.T) {
+	mirrored := map[string]string{"api": "working", "web": "waiting", "docs": "new"}
+	statuses := map[string]Status{
+		"api":  {State: "waiting"},
+		"web":  {State: "waiting"},
+		"tool": {State: "new"},
+	}
+
+	want := map[string]string{"api": "waiting", "tool": "new", "docs": ""}
+	if got := OptionChanges(mirrored, statuses); !maps.Equal(got, want) {
+		t.Errorf("OptionChanges() = %v, want %v", got, want)
+	}
+	mirrored = map[string]string{"api": "waiting", "tool": "new"}
+	if got := OptionChanges(mirrored, nil); len(got) != 2 || got["api"] != "" || got["tool"] != "" {
+		t.Errorf("OptionChanges() with no statuses = %v, want api and tool unset", got)
+	}
+}
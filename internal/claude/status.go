@@ -1,10 +1,12 @@
 package claude
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -12,10 +14,29 @@ import (
 // If Claude Code hasn't updated the status file in this time, assume it's not running.
 const StaleThreshold = 2 * time.Minute
 
+// maxConcurrentReads bounds the number of status files read in parallel.
+// Keeps slow filesystems (e.g. NFS home dirs) from being flooded with requests.
+const maxConcurrentReads = 8
+
 // Status represents Claude Code status for a session
 type Status struct {
 	State     string    // "new", "working", "waiting", or ""
 	Timestamp time.Time // When the status was last updated
+	Message   string    // Latest notification text, e.g. a pending question (v2 only)
+	Model     string    // Model in use (v2 only)
+	PID       int       // Claude Code process ID (v2 only)
+	Window    string    // ID of the window Claude Code runs in, e.g. "@3" (v2 only)
+}
+
+// statusFileV2 is the JSON status file format written by the hook
+type statusFileV2 struct {
+	Version   int    `json:"version"`
+	State     string `json:"state"`
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message,omitempty"`
+	Model     string `json:"model,omitempty"`
+	PID       int    `json:"pid,omitempty"`
+	Window    string `json:"window,omitempty"`
 }
 
 // IsStale returns true if the status hasn't been updated within StaleThreshold.
@@ -35,37 +56,98 @@ func GetStatus(sessionName string, cacheDir string) Status {
 		return Status{}
 	}
 
-	// Parse format: "state:timestamp"
-	parts := strings.SplitN(strings.TrimSpace(string(content)), ":", 2)
-	if len(parts) != 2 {
+	status, ok := parseStatus(strings.TrimSpace(string(content)))
+	if !ok {
+		return Status{}
+	}
+
+	// If status is stale, treat it as no status
+	if status.IsStale() {
 		return Status{}
 	}
 
+	return status
+}
+
+// parseStatus parses a status file in either the JSON (v2) format or the
+// legacy "state:timestamp" format
+func parseStatus(content string) (Status, bool) {
+	if strings.HasPrefix(content, "{") {
+		var f statusFileV2
+		if err := json.Unmarshal([]byte(content), &f); err != nil || f.State == "" || f.Timestamp == 0 {
+			return Status{}, false
+		}
+		return Status{
+			State:     f.State,
+			Timestamp: time.Unix(f.Timestamp, 0),
+			Message:   f.Message,
+			Model:     f.Model,
+			PID:       f.PID,
+			Window:    f.Window,
+		}, true
+	}
+
+	// Legacy format: "state:timestamp"
+	parts := strings.SplitN(content, ":", 2)
+	if len(parts) != 2 {
+		return Status{}, false
+	}
+
 	timestamp, err := strconv.ParseInt(parts[1], 10, 64)
 	if err != nil {
-		return Status{}
+		return Status{}, false
 	}
 
-	status := Status{
+	return Status{
 		State:     parts[0],
 		Timestamp: time.Unix(timestamp, 0),
+	}, true
+}
+
+// GetStatuses reads the Claude Code status for all given sessions concurrently.
+// Only sessions with a non-empty, non-stale status are included in the result.
+func GetStatuses(sessionNames []string, cacheDir string) map[string]Status {
+	statuses := make(map[string]Status)
+	if len(sessionNames) == 0 {
+		return statuses
 	}
 
-	// If status is stale, treat it as no status
-	if status.IsStale() {
-		return Status{}
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, maxConcurrentReads)
+	)
+
+	for _, name := range sessionNames {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			status := GetStatus(name, cacheDir)
+			if status.State == "" {
+				return
+			}
+			mu.Lock()
+			statuses[name] = status
+			mu.Unlock()
+		}(name)
 	}
 
-	return status
+	wg.Wait()
+	return statuses
 }
 
-// CleanupStale removes status files for sessions that no longer exist
-func CleanupStale(cacheDir string, activeSessions []string) {
+// CleanupStale removes status files for sessions that no longer exist,
+// returning how many it removed
+func CleanupStale(cacheDir string, activeSessions []string) int {
 	entries, err := os.ReadDir(cacheDir)
 	if err != nil {
-		return
+		return 0
 	}
 
+	removed := 0
 	activeSet := make(map[string]bool)
 	for _, s := range activeSessions {
 		activeSet[s] = true
@@ -77,8 +159,9 @@ func CleanupStale(cacheDir string, activeSessions []string) {
 		}
 
 		sessionName := strings.TrimSuffix(entry.Name(), ".status")
-		if !activeSet[sessionName] {
-			_ = os.Remove(filepath.Join(cacheDir, entry.Name()))
+		if !activeSet[sessionName] && os.Remove(filepath.Join(cacheDir, entry.Name())) == nil {
+			removed++
 		}
 	}
+	return removed
 }
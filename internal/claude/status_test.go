@@ -1,8 +1,10 @@
 package claude
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -15,6 +17,9 @@ func TestGetStatus(t *testing.T) {
 	}
 	defer func() { _ = os.RemoveAll(tmpDir) }()
 
+	// Use a fresh timestamp so statuses aren't treated as stale
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
 	tests := []struct {
 		name        string
 		filename    string
@@ -25,21 +30,21 @@ func TestGetStatus(t *testing.T) {
 		{
 			name:        "valid working status",
 			filename:    "test-session.status",
-			content:     "working:1704067200",
+			content:     "working:" + now,
 			wantState:   "working",
 			wantTimeSet: true,
 		},
 		{
 			name:        "valid waiting status",
 			filename:    "test-session.status",
-			content:     "waiting:1704067200",
+			content:     "waiting:" + now,
 			wantState:   "waiting",
 			wantTimeSet: true,
 		},
 		{
 			name:        "valid new status",
 			filename:    "test-session.status",
-			content:     "new:1704067200",
+			content:     "new:" + now,
 			wantState:   "new",
 			wantTimeSet: true,
 		},
@@ -106,6 +111,34 @@ func TestGetStatus(t *testing.T) {
 	}
 }
 
+func TestParseStatusV2(t *testing.T) {
+	now := time.Now().Unix()
+
+	content := fmt.Sprintf(`{"version":2,"state":"waiting","timestamp":%d,"message":"Allow edit of main.go?","model":"opus","pid":4242,"window":"@7"}`, now)
+	status, ok := parseStatus(content)
+	if !ok {
+		t.Fatal("parseStatus() should accept v2 JSON")
+	}
+	if status.State != "waiting" || status.Message != "Allow edit of main.go?" || status.Model != "opus" || status.PID != 4242 || status.Window != "@7" {
+		t.Errorf("parseStatus() = %+v, want waiting with message, model, pid and window", status)
+	}
+	if status.Timestamp.Unix() != now {
+		t.Errorf("Timestamp = %v, want %d", status.Timestamp, now)
+	}
+
+	// Legacy files keep working
+	status, ok = parseStatus(fmt.Sprintf("working:%d", now))
+	if !ok || status.State != "working" || status.Message != "" {
+		t.Errorf("parseStatus(legacy) = %+v, %v, want working without message", status, ok)
+	}
+
+	for _, bad := range []string{`{"state":"working"`, `{"version":2,"state":"working"}`, `{"timestamp":1}`} {
+		if _, ok := parseStatus(bad); ok {
+			t.Errorf("parseStatus(%q) should fail", bad)
+		}
+	}
+}
+
 func TestCleanupStale(t *testing.T) {
 	// Create temp directory
 	tmpDir, err := os.MkdirTemp("", "claude-cleanup-test")
@@ -145,3 +178,51 @@ func TestCleanupStale(t *testing.T) {
 		t.Error("notastatus.txt should not be deleted")
 	}
 }
+
+func TestGetStatuses(t *testing.T) {
+	tmpDir := t.TempDir()
+	now := time.Now().Unix()
+
+	files := map[string]string{
+		"working.status": fmt.Sprintf("working:%d", now),
+		"waiting.status": fmt.Sprintf("waiting:%d", now),
+		"stale.status":   fmt.Sprintf("working:%d", now-int64(StaleThreshold.Seconds())-60),
+		"broken.status":  "garbage",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test file %s: %v", name, err)
+		}
+	}
+
+	statuses := GetStatuses([]string{"working", "waiting", "stale", "broken", "missing"}, tmpDir)
+
+	if len(statuses) != 2 {
+		t.Fatalf("len(statuses) = %d, want 2: %v", len(statuses), statuses)
+	}
+	if statuses["working"].State != "working" {
+		t.Errorf("working state = %q, want %q", statuses["working"].State, "working")
+	}
+	if statuses["waiting"].State != "waiting" {
+		t.Errorf("waiting state = %q, want %q", statuses["waiting"].State, "waiting")
+	}
+}
+
+func BenchmarkGetStatuses(b *testing.B) {
+	tmpDir := b.TempDir()
+	now := time.Now().Unix()
+
+	names := make([]string, 50)
+	for i := range names {
+		names[i] = fmt.Sprintf("session-%d", i)
+		content := fmt.Sprintf("working:%d", now)
+		if err := os.WriteFile(filepath.Join(tmpDir, names[i]+".status"), []byte(content), 0644); err != nil {
+			b.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GetStatuses(names, tmpDir)
+	}
+}
@@ -0,0 +1,60 @@
+// Package clipboard copies text to the system clipboard with whatever tool the
+// platform offers, falling back to tmux, which forwards it to the outer
+// terminal via OSC 52 (set-clipboard)
+package clipboard
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// tool is a clipboard command reading the text from stdin
+type tool struct {
+	name string
+	args []string
+	env  string // Environment variable that must be set, if any
+}
+
+var tools = []tool{
+	{name: "pbcopy"},
+	{name: "wl-copy", env: "WAYLAND_DISPLAY"},
+	{name: "xclip", args: []string{"-selection", "clipboard"}, env: "DISPLAY"},
+	{name: "xsel", args: []string{"--clipboard", "--input"}, env: "DISPLAY"},
+}
+
+// lookPath is exec.LookPath, replaced in tests
+var lookPath = exec.LookPath
+
+// findTool returns the first clipboard tool that is installed and usable
+func findTool() (tool, bool) {
+	for _, t := range tools {
+		if t.env != "" && os.Getenv(t.env) == "" {
+			continue
+		}
+		if _, err := lookPath(t.name); err == nil {
+			return t, true
+		}
+	}
+	return tool{}, false
+}
+
+// Copy copies text to the clipboard and returns how it was copied
+func Copy(text string) (string, error) {
+	if t, ok := findTool(); ok {
+		cmd := exec.Command(t.name, t.args...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("%s failed: %w", t.name, err)
+		}
+		return t.name, nil
+	}
+
+	if err := tmux.SetBuffer(text); err != nil {
+		return "", fmt.Errorf("no clipboard tool found and tmux failed: %w", err)
+	}
+	return "tmux", nil
+}
@@ -0,0 +1,37 @@
+package clipboard
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestFindTool(t *testing.T) {
+	installed := []string{"xclip", "wl-copy"}
+	orig := lookPath
+	t.Cleanup(func() { lookPath = orig })
+	lookPath = func(name string) (string, error) {
+		if slices.Contains(installed, name) {
+			return "/usr/bin/" + name, nil
+		}
+		return "", errors.New("not found")
+	}
+
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("DISPLAY", ":0")
+	if got, ok := findTool(); !ok || got.name != "xclip" {
+		t.Errorf("findTool() = %q, %v, want xclip on X11", got.name, ok)
+	}
+
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	if got, ok := findTool(); !ok || got.name != "wl-copy" {
+		t.Errorf("findTool() = %q, %v, want wl-copy on Wayland", got.name, ok)
+	}
+
+	// Over SSH there is no display: fall back to tmux
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("DISPLAY", "")
+	if got, ok := findTool(); ok {
+		t.Errorf("findTool() = %q, want none without a display", got.name)
+	}
+}
@@ -4,18 +4,37 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
+
+	"github.com/nikbrunner/tsm/internal/gitinfo"
+	"github.com/nikbrunner/tsm/internal/layout"
+	"github.com/nikbrunner/tsm/internal/ui"
 )
 
 // Config holds all configuration options for tsm
 type Config struct {
+	// Schema version of the config file, upgraded on load (see Version)
+	Version int `toml:"version"`
+
 	// Layout script name to apply when creating new sessions
 	Layout string `toml:"layout"`
 
 	// Directory containing layout scripts
 	LayoutDir string `toml:"layout_dir"`
 
+	// Command the first window of a new session runs instead of tmux's
+	// default-command, e.g. "nvim" (a layout's "# tsm-command:" overrides it)
+	DefaultCommand string `toml:"default_command"`
+
+	// Wait for the layout script before switching to a new session
+	// (false = switch right away while the script sets up the windows)
+	LayoutWait bool `toml:"layout_wait"`
+
 	// Enable Claude Code status integration
 	ClaudeStatusEnabled bool `toml:"claude_status_enabled"`
 
@@ -33,21 +52,242 @@ type Config struct {
 
 	// Default directory for new sessions created with C-n
 	DefaultSessionDir string `toml:"default_session_dir"`
+
+	// Name template for sessions created from the project picker, e.g.
+	// "{org}/{repo}@{branch}" (empty = the directory's last project_depth components)
+	SessionName string `toml:"session_name"`
+
+	// What the project picker does when the derived name belongs to a session
+	// in another directory: suffix (api~2), branch (api@branch) or switch
+	NameConflict string `toml:"name_conflict"`
+
+	// Directory for persistent state (session notes, etc.)
+	StateDir string `toml:"state_dir"`
+
+	// Where session notes and tags are kept: "file" (state_dir) or "tmux" (session options)
+	StateBackend string `toml:"state_backend"`
+
+	// How long the tags and notes of a closed session are kept (0 = forever)
+	StateTTL time.Duration `toml:"state_ttl"`
+
+	// Log every switch, create and kill to metrics.jsonl in the state dir for `tsm stats`
+	MetricsLog bool `toml:"metrics_log"`
+
+	// Additional tmux servers that can be targeted (e.g. outer server when nested)
+	Servers []Server `toml:"servers"`
+
+	// What Enter does per item type
+	Actions Actions `toml:"actions"`
+
+	// Number of recently closed sessions listed for resurrection (0 disables)
+	RecentSessions int `toml:"recent_sessions"`
+
+	// Timeout for a single tmux command before it is reported as hung
+	TmuxTimeout time.Duration `toml:"tmux_timeout"`
+
+	// How long after opening the picker keeps the session order of its first
+	// load, so number labels don't shift when sessions produce output (0 disables)
+	SortStability time.Duration `toml:"sort_stability"`
+
+	// Plain output: no icons, colors or box drawing (screen-reader friendly)
+	Plain bool `toml:"plain"`
+
+	// Icon set: auto (ascii without a UTF-8 locale), nerd or ascii
+	IconSet string `toml:"icon_set"`
+
+	// Single icons overriding the icon set, by name (e.g. last = "*")
+	Icons map[string]string `toml:"icons"`
+
+	// How the filter matches names: substring, fuzzy, smart-case or regex (M-f cycles)
+	Matcher string `toml:"matcher"`
+
+	// Session order: activity, name, manual (arranged with M-j/M-k) or command
+	Sort string `toml:"sort"`
+
+	// Command ranking the sessions for sort = "command": gets them as JSON on
+	// stdin and prints their names in order
+	SortCommand string `toml:"sort_command"`
+
+	// Split view: sessions on the left, highlighted session's windows on the right
+	SplitView bool `toml:"split_view"`
+
+	// How long the cursor rests on a row before the split view's preview
+	// pane (C-v) loads what its window shows
+	PreviewDelay time.Duration `toml:"preview_delay"`
+
+	// Create sessions from the C-n prompt without switching to them (M-enter inverts)
+	CreateInBackground bool `toml:"create_in_background"`
+
+	// How long a kill confirmation waits before it is cancelled (0 = forever)
+	ConfirmTimeout time.Duration `toml:"confirm_timeout"`
+
+	// Sessions with more windows than this are only killed after typing the
+	// first letters of their name (0 disables)
+	KillConfirmWindows int `toml:"kill_confirm_windows"`
+
+	// Vim-style two-key sequences (g g, d d, z a) typed with an empty filter
+	KeySequences bool `toml:"key_sequences"`
+
+	// How long the first key of a sequence waits for the second (0 = forever)
+	SequenceTimeout time.Duration `toml:"sequence_timeout"`
+
+	// Maximum number of sessions; creating more offers to kill the least recently used (0 = no limit)
+	MaxSessions int `toml:"max_sessions"`
+
+	// Read-only: disable kill, merge, rename, suspend and create (for demos and dashboards)
+	ReadOnly bool `toml:"read_only"`
+
+	// Actions offered when there are no other sessions, in display order
+	EmptyActions []string `toml:"empty_actions"`
+
+	// Size profile the picker opens with (M-p switches while it is open)
+	SizeProfile string `toml:"size_profile"`
+
+	// Size profiles by name, added to or replacing compact, default and full
+	SizeProfiles map[string]SizeProfile `toml:"size_profiles"`
+
+	// Commands run when the picker opens, showing whether a session's dev stack is up
+	HealthChecks []HealthCheck `toml:"health_checks"`
+
+	// Machine profiles by name, picked by $TSM_PROFILE or the hostname
+	Profiles map[string]MachineProfile `toml:"profiles"`
+
+	// Machine profile that was applied ("" = none)
+	MachineProfile string `toml:"-"`
+
+	// What upgrading an outdated config file did ("" = nothing)
+	Migrated string `toml:"-"`
+}
+
+// Empty-state actions
+const (
+	EmptyActionProjects = "projects" // Open the project directory picker
+	EmptyActionNew      = "new"      // Create a new session
+	EmptyActionRestore  = "restore"  // Recreate the most recently closed session
+	EmptyActionConfig   = "config"   // Edit the config file in $EDITOR
+)
+
+// emptyActions lists the supported empty-state actions
+var emptyActions = []string{EmptyActionProjects, EmptyActionNew, EmptyActionRestore, EmptyActionConfig}
+
+// Filter matchers
+const (
+	MatcherSubstring = "substring"  // Case-insensitive substring
+	MatcherFuzzy     = "fuzzy"      // Case-insensitive subsequence, like fzf
+	MatcherSmartCase = "smart-case" // Substring, case-sensitive once the filter has an uppercase letter
+	MatcherRegex     = "regex"      // Regular expression, smart-case
+)
+
+// Matchers lists the filter matchers in the order M-f cycles through them
+var Matchers = []string{MatcherSubstring, MatcherFuzzy, MatcherSmartCase, MatcherRegex}
+
+// Session orders
+const (
+	SortActivity = "activity" // Most recently active first, as tmux lists them
+	SortName     = "name"     // Alphabetical
+	SortManual   = "manual"   // As arranged with M-j/M-k, remembered across runs
+	SortExternal = "command"  // As ranked by sort_command
+)
+
+// Sorts lists the session orders
+var Sorts = []string{SortActivity, SortName, SortManual, SortExternal}
+
+// State backends, where tags and notes are kept
+const (
+	BackendFile = "file" // JSON and markdown files in the state directory
+	BackendTmux = "tmux" // User options on the sessions themselves
+)
+
+// Backends lists the state backends
+var Backends = []string{BackendFile, BackendTmux}
+
+// Name conflict strategies
+const (
+	NameConflictSuffix = "suffix" // Number the new session, e.g. api~2
+	NameConflictBranch = "branch" // Add the directory's git branch, e.g. api@main, else number it
+	NameConflictSwitch = "switch" // Switch to the existing session
+)
+
+// NameConflicts lists the supported name conflict strategies
+var NameConflicts = []string{NameConflictSuffix, NameConflictBranch, NameConflictSwitch}
+
+// Select actions
+const (
+	ActionSwitch = "switch" // Switch the client to the target
+	ActionZoom   = "zoom"   // Switch and zoom the target's active pane
+	ActionBreak  = "break"  // Break the pane out into its own window
+)
+
+// Actions configures what selecting an item does, per item type
+type Actions struct {
+	Session string `toml:"session"`
+	Window  string `toml:"window"`
+	Pane    string `toml:"pane"`
+}
+
+// Server describes a tmux server reachable through its socket
+type Server struct {
+	// Display name shown in the header when targeted
+	Name string `toml:"name"`
+
+	// Path to the server socket (as passed to tmux -S)
+	Socket string `toml:"socket"`
+}
+
+// Sockets returns the sockets of the configured servers
+func (c Config) Sockets() []string {
+	sockets := make([]string, len(c.Servers))
+	for i, srv := range c.Servers {
+		sockets[i] = srv.Socket
+	}
+	return sockets
 }
 
 // DefaultConfig returns configuration with sensible defaults
 func DefaultConfig() Config {
 	home := os.Getenv("HOME")
 	return Config{
+		Version:             Version,
 		Layout:              "",
 		LayoutDir:           filepath.Join(home, ".config", "tmux", "layouts"),
+		LayoutWait:          true,
 		ClaudeStatusEnabled: false,
 		CacheDir:            filepath.Join(home, ".cache", "tsm"),
 		ProjectDirs:         []string{filepath.Join(home, "repos")},
 		ProjectDepth:        2,
 		MaxVisibleItems:     10,
 		DefaultSessionDir:   home,
+		StateDir:            filepath.Join(home, ".local", "state", "tsm"),
+		StateBackend:        BackendFile,
+		StateTTL:            30 * 24 * time.Hour,
+		RecentSessions:      5,
+		TmuxTimeout:         5 * time.Second,
+		SortStability:       5 * time.Second,
+		ConfirmTimeout:      10 * time.Second,
+		KillConfirmWindows:  10,
+		SequenceTimeout:     time.Second,
+		PreviewDelay:        300 * time.Millisecond,
+		Matcher:             MatcherSubstring,
+		IconSet:             ui.IconsAuto,
+		Sort:                SortActivity,
+		NameConflict:        NameConflictSuffix,
+		EmptyActions:        slices.Clone(emptyActions),
+		Actions: Actions{
+			Session: ActionSwitch,
+			Window:  ActionSwitch,
+			Pane:    ActionSwitch,
+		},
+	}
+}
+
+// SessionCommand returns the command the first window of a session created
+// with a layout runs: the layout's "# tsm-command:", else default_command.
+// Empty leaves it to tmux.
+func (c Config) SessionCommand(layoutName string) string {
+	if command := layout.Command(c.LayoutDir, layoutName); command != "" {
+		return command
 	}
+	return c.DefaultCommand
 }
 
 // Path returns the path to the config file
@@ -61,24 +301,90 @@ func Path() string {
 func Load() (Config, error) {
 	cfg := DefaultConfig()
 
-	// Load from config file if it exists
+	// Upgrade an outdated config file before reading it
 	configPath := Path()
+	migrated, err := migrateFile(configPath)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Migrated = migrated
+
+	// Load from config file if it exists
 	if _, err := os.Stat(configPath); err == nil {
 		if _, err := toml.DecodeFile(configPath, &cfg); err != nil {
 			return cfg, fmt.Errorf("failed to parse config file: %w", err)
 		}
 	}
 
+	// Machine profiles override the shared settings, before paths are expanded
+	hostname, _ := os.Hostname()
+	profile, err := cfg.machineProfile(os.Getenv("TSM_PROFILE"), hostname)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.applyMachineProfile(profile)
+
 	// Expand ~ in paths
 	cfg.LayoutDir = expandPath(cfg.LayoutDir)
 	cfg.CacheDir = expandPath(cfg.CacheDir)
 	cfg.DefaultSessionDir = expandPath(cfg.DefaultSessionDir)
+	cfg.StateDir = expandPath(cfg.StateDir)
 
 	// Expand ~ in project directories
 	for i, d := range cfg.ProjectDirs {
 		cfg.ProjectDirs[i] = expandPath(d)
 	}
 
+	// Expand ~ in server sockets
+	for i, srv := range cfg.Servers {
+		cfg.Servers[i].Socket = expandPath(srv.Socket)
+	}
+
+	if err := cfg.Actions.validate(); err != nil {
+		return cfg, err
+	}
+	if err := validateEmptyActions(cfg.EmptyActions); err != nil {
+		return cfg, err
+	}
+	if !slices.Contains(Backends, cfg.StateBackend) {
+		return cfg, fmt.Errorf("invalid state_backend %q (valid: %s)", cfg.StateBackend, strings.Join(Backends, ", "))
+	}
+	if cfg.SequenceTimeout < 0 {
+		return cfg, fmt.Errorf("invalid sequence_timeout %s (must not be negative)", cfg.SequenceTimeout)
+	}
+	if cfg.StateTTL < 0 {
+		return cfg, fmt.Errorf("invalid state_ttl %s (must not be negative)", cfg.StateTTL)
+	}
+	if err := cfg.validateProfiles(); err != nil {
+		return cfg, err
+	}
+	if err := cfg.validateHealthChecks(); err != nil {
+		return cfg, err
+	}
+	if err := validateSessionName(cfg.SessionName); err != nil {
+		return cfg, err
+	}
+	if !slices.Contains(NameConflicts, cfg.NameConflict) {
+		return cfg, fmt.Errorf("invalid name_conflict %q (valid: %s)", cfg.NameConflict, strings.Join(NameConflicts, ", "))
+	}
+	if !slices.Contains(Matchers, cfg.Matcher) {
+		return cfg, fmt.Errorf("invalid matcher %q (valid: %s)", cfg.Matcher, strings.Join(Matchers, ", "))
+	}
+	if !slices.Contains(Sorts, cfg.Sort) {
+		return cfg, fmt.Errorf("invalid sort %q (valid: %s)", cfg.Sort, strings.Join(Sorts, ", "))
+	}
+	if cfg.Sort == SortExternal && cfg.SortCommand == "" {
+		return cfg, fmt.Errorf("sort = %q needs a sort_command", SortExternal)
+	}
+	if !slices.Contains(ui.IconSets, cfg.IconSet) {
+		return cfg, fmt.Errorf("invalid icon_set %q (valid: %s)", cfg.IconSet, strings.Join(ui.IconSets, ", "))
+	}
+	for name := range cfg.Icons {
+		if !slices.Contains(ui.IconNames(), name) {
+			return cfg, fmt.Errorf("icons: unknown icon %q (valid: %s)", name, strings.Join(ui.IconNames(), ", "))
+		}
+	}
+
 	// Ensure ProjectDepth is at least 1
 	if cfg.ProjectDepth < 1 {
 		cfg.ProjectDepth = 2
@@ -99,6 +405,10 @@ func Load() (Config, error) {
 	if os.Getenv("TMUX_SESSION_PICKER_CLAUDE_STATUS") == "1" {
 		cfg.ClaudeStatusEnabled = true
 	}
+	// https://no-color.org: any non-empty value disables color
+	if os.Getenv("NO_COLOR") != "" {
+		cfg.Plain = true
+	}
 
 	return cfg, nil
 }
@@ -122,12 +432,25 @@ func Init() error {
 	content := `# tsm configuration
 # Environment variables override these settings
 
+# Schema version of this file. tsm upgrades older files on load, keeping the
+# original as config.toml.v<N>.bak
+version = ` + strconv.Itoa(Version) + `
+
 # Layout script name to apply when creating new sessions
 # layout = "ide"
 
 # Directory containing layout scripts
 # layout_dir = "~/.config/tmux/layouts"
 
+# Command the first window of new sessions runs instead of tmux's
+# default-command / default-shell, e.g. an editor or a login shell with
+# arguments. A layout script overrides it with a "# tsm-command: ..." line
+# default_command = "zsh -l"
+
+# Wait for the layout script before switching to a new session. false
+# switches right away and the windows appear while the script runs
+# layout_wait = true
+
 # Enable Claude Code status integration
 # claude_status_enabled = false
 
@@ -148,6 +471,180 @@ func Init() error {
 
 # Default directory for new sessions created with C-n
 # default_session_dir = "~"
+
+# Name template for sessions created from the project picker (C-p). Variables:
+# {name} (the default name), {org} and {repo} (from the origin remote, else
+# the directory names) and {branch}. Separators left dangling by an empty
+# variable are dropped
+# session_name = "{org}/{repo}@{branch}"
+
+# When that name is taken by a session in another directory
+# suffix: number the new session (api~2)
+# branch: add the directory's git branch (api@main), else number it
+# switch: switch to the existing session
+# name_conflict = "suffix"
+
+# Directory for persistent state (session notes, etc.)
+# state_dir = "~/.local/state/tsm"
+
+# Where session notes and tags are kept
+# file: in state_dir
+# tmux: in user options (@tsm_notes, @tsm_tags) on each session, shared by
+#       every tsm client of the server and removed along with the session
+# state_backend = "file"
+
+# How long the tags and notes of a closed session are kept in state_dir before
+# the picker (or tsm clean) removes them. Archived sessions keep theirs ("0s" keeps them forever)
+# state_ttl = "720h"
+
+# Log every session switch, create and kill with a timestamp to metrics.jsonl
+# in state_dir. tsm stats shows where the time goes from it
+# metrics_log = false
+
+# Additional tmux servers to target (toggle with C-t)
+# Useful when running nested tmux, e.g. an inner server over SSH
+# [[servers]]
+# name = "outer"
+# socket = "/tmp/tmux-1000/default"
+
+# What Enter does per item type
+# session: switch
+# window:  switch, zoom (switch and zoom the active pane)
+# pane:    switch, zoom, break (break the pane out into its own window); pane
+#          rows are listed by tsm --tree
+# M-enter zooms instead, or only switches when the action is already zoom
+# [actions]
+# session = "switch"
+# window = "switch"
+# pane = "switch"
+
+# Number of recently closed sessions listed at the bottom for resurrection
+# Selecting one recreates it at its remembered path and layout (0 disables)
+# recent_sessions = 5
+
+# Timeout for a single tmux command (e.g. when the tmux server hangs)
+# tmux_timeout = "5s"
+
+# Sessions are sorted by activity. For this long after the picker opens they
+# keep the order of the first load, so the 1-9 labels don't shift while
+# background sessions produce output. New sessions are listed last ("0s" disables)
+# sort_stability = "5s"
+
+# Session order: "activity", "name" or "manual". Manual keeps the order
+# arranged with M-j/M-k (moving a session switches to it), so the 1-9 labels
+# stay where you put them. Sessions not arranged yet are listed last.
+# "command" lets sort_command rank them
+# sort = "activity"
+
+# Command ranking the sessions for sort = "command", run with sh -c on every
+# reload. It gets the sessions as a JSON array on stdin (name, path, group,
+# last_activity in Unix seconds, windows, tags) and prints their names, one
+# per line, in the order to list them. Sessions it leaves out follow in
+# activity order
+# sort_command = "~/bin/rank-sessions ~/.config/tsm/priorities"
+
+# How long a kill confirmation (C-x) waits before it is cancelled, so a
+# forgotten prompt can't be confirmed by a stray key later ("0s" waits forever)
+# confirm_timeout = "10s"
+
+# Killing a session with more windows than this asks to type the first
+# letters of its name instead of pressing C-x again (0 disables)
+# kill_confirm_windows = 10
+
+# Vim-style two-key sequences, typed while the filter is empty:
+# g g: cursor to the top, d d: kill (like C-x), z a: expand/collapse.
+# The first key waits sequence_timeout for the second ("0s" waits forever),
+# shown in the statusline; any other key types both into the filter
+# key_sequences = false
+# sequence_timeout = "1s"
+
+# How the filter matches session, window and project names
+# substring:  case-insensitive substring ("api" finds "my-api")
+# fuzzy:      case-insensitive subsequence like fzf ("mapi" finds "my-api")
+# smart-case: substring, case-sensitive once the filter has an uppercase letter
+# regex:      regular expression, smart-case ("^api-(web|db)$")
+# M-f cycles through them while the picker is open
+# matcher = "substring"
+
+# Plain output without icons, colors or box drawing (screen-reader friendly)
+# Also enabled by the --plain flag or the NO_COLOR environment variable
+# plain = false
+
+# Icons: nerd (nerd font glyphs), ascii (for terminals without a nerd font)
+# or auto: ascii on the Linux console and without a UTF-8 locale, else nerd.
+# The --ascii flag picks ascii for one run
+# icon_set = "auto"
+
+# Single icons overriding the set: expanded, collapsed, last, note, suspended,
+# branch, healthy, unhealthy, recent, archive, group, small_client, mark,
+# marked_pane, dead_pane, claude (the badge label), claude_waiting and
+# claude_working (empty animates dots)
+# [icons]
+# last = "*"
+# claude = "AI"
+
+# Split view: list sessions on the left and the highlighted session's windows
+# on the right instead of expanding windows inline (move between panes with C-h/C-l)
+# split_view = false
+
+# C-v shows a preview of the highlighted window in the split view's right pane.
+# It loads once the cursor rests on a row this long, so scrolling past many
+# windows doesn't capture each of them ("0s" loads right away)
+# preview_delay = "300ms"
+
+# Create sessions from the new-session prompt (C-n) without switching to them,
+# e.g. to pre-warm sessions for later. M-enter in the prompt does the opposite.
+# create_in_background = false
+
+# Maximum number of sessions (0 = no limit). Creating a session beyond it
+# offers to kill the least recently used one first
+# max_sessions = 0
+
+# Read-only mode: switching works, but killing, merging, renaming, suspending
+# and creating sessions is disabled (for screensharing or status dashboards).
+# Also enabled by the --read-only flag
+# read_only = false
+
+# Actions listed when there are no other sessions, in display order
+# projects: open the project picker (C-p), new: new session (C-n),
+# restore: recreate the last closed session (C-r), config: edit this file (C-f)
+# empty_actions = ["projects", "new", "restore", "config"]
+
+# Size profile the picker opens with: compact, default, full or one of
+# size_profiles below. M-p switches between them while the picker is open.
+# The popup size itself comes from display-popup -w/-h: width only narrows
+# the picker inside it, so size the popup for the largest profile you use
+# size_profile = "default"
+
+# Size profiles: width caps the picker width (0 = full popup/terminal),
+# columns picks the session row columns (time, claude, indicators, tags,
+# windows, git, path) and help renders the key help line
+# [size_profiles.compact]
+# width = 40
+# columns = ["claude"]
+# help = false
+
+# Health checks run when the picker opens and mark matching sessions with a
+# green (exit status 0) or red dot. A check applies to sessions whose
+# directory matches path (a glob) or that carry tag; the first match wins.
+# The command runs with sh -c in the session directory
+# [[health_checks]]
+# path = "~/work/*"
+# command = "curl -fsS http://localhost:3000 > /dev/null"
+# timeout = "5s"
+
+# Machine profiles override project_dirs, default_session_dir, servers, plain,
+# icon_set and icons (merged key by key), so one dotfiles-managed config works
+# everywhere. $TSM_PROFILE picks one by name; otherwise the first (by name)
+# whose hosts match the hostname applies
+# [profiles.work]
+# hosts = ["acme-*"]
+# project_dirs = ["~/work"]
+# icon_set = "ascii"
+#
+# [profiles.home]
+# hosts = ["laptop"]
+# project_dirs = ["~/repos", "~/sandbox"]
 `
 
 	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
@@ -157,6 +654,49 @@ func Init() error {
 	return nil
 }
 
+// validate checks that each configured action is supported for its item type
+func (a Actions) validate() error {
+	allowed := []struct {
+		kind    string
+		value   string
+		actions []string
+	}{
+		{"session", a.Session, []string{ActionSwitch}},
+		{"window", a.Window, []string{ActionSwitch, ActionZoom}},
+		{"pane", a.Pane, []string{ActionSwitch, ActionZoom, ActionBreak}},
+	}
+
+	for _, entry := range allowed {
+		if !slices.Contains(entry.actions, entry.value) {
+			return fmt.Errorf("invalid %s action %q (valid: %s)", entry.kind, entry.value, strings.Join(entry.actions, ", "))
+		}
+	}
+	return nil
+}
+
+// validateEmptyActions checks that each empty-state action is supported
+func validateEmptyActions(actions []string) error {
+	for _, action := range actions {
+		if !slices.Contains(emptyActions, action) {
+			return fmt.Errorf("invalid empty action %q (valid: %s)", action, strings.Join(emptyActions, ", "))
+		}
+	}
+	return nil
+}
+
+// sessionNameVars lists the variables a session_name template can use
+var sessionNameVars = []string{"name", "org", "repo", "branch"}
+
+// validateSessionName checks that a session_name template only uses known variables
+func validateSessionName(template string) error {
+	for _, name := range gitinfo.Placeholders(template) {
+		if !slices.Contains(sessionNameVars, name) {
+			return fmt.Errorf("session_name: unknown variable {%s} (valid: %s)", name, strings.Join(sessionNameVars, ", "))
+		}
+	}
+	return nil
+}
+
 // expandPath expands ~ to the user's home directory
 func expandPath(path string) string {
 	if len(path) > 0 && path[0] == '~' {
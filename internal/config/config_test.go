@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestExpandPath(t *testing.T) {
@@ -70,6 +72,49 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.Layout != "" {
 		t.Errorf("Layout = %q, want empty string", cfg.Layout)
 	}
+
+	if cfg.Matcher != MatcherSubstring {
+		t.Errorf("Matcher = %q, want %q", cfg.Matcher, MatcherSubstring)
+	}
+	if cfg.KillConfirmWindows != 10 {
+		t.Errorf("KillConfirmWindows = %d, want 10", cfg.KillConfirmWindows)
+	}
+	if cfg.PreviewDelay != 300*time.Millisecond {
+		t.Errorf("PreviewDelay = %v, want 300ms", cfg.PreviewDelay)
+	}
+	if cfg.Sort != SortActivity {
+		t.Errorf("Sort = %q, want %q", cfg.Sort, SortActivity)
+	}
+	if !cfg.LayoutWait {
+		t.Error("LayoutWait should default to true")
+	}
+	if cfg.NameConflict != NameConflictSuffix {
+		t.Errorf("NameConflict = %q, want %q", cfg.NameConflict, NameConflictSuffix)
+	}
+	if cfg.KeySequences || cfg.SequenceTimeout != time.Second {
+		t.Errorf("KeySequences = %v, SequenceTimeout = %s, want off and 1s", cfg.KeySequences, cfg.SequenceTimeout)
+	}
+	if cfg.StateTTL != 30*24*time.Hour {
+		t.Errorf("StateTTL = %s, want 720h", cfg.StateTTL)
+	}
+}
+
+func TestSessionCommand(t *testing.T) {
+	cfg := Config{LayoutDir: t.TempDir(), DefaultCommand: "zsh -l"}
+	if err := os.WriteFile(filepath.Join(cfg.LayoutDir, "edit.sh"), []byte("#!/bin/sh\n# tsm-command: nvim\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := cfg.SessionCommand("edit"); got != "nvim" {
+		t.Errorf("SessionCommand(edit) = %q, want the layout's command", got)
+	}
+	if got := cfg.SessionCommand(""); got != "zsh -l" {
+		t.Errorf("SessionCommand() = %q, want default_command", got)
+	}
+	cfg.DefaultCommand = ""
+	if got := cfg.SessionCommand("missing"); got != "" {
+		t.Errorf("SessionCommand(missing) = %q, want it left to tmux", got)
+	}
 }
 
 func TestPath(t *testing.T) {
@@ -81,3 +126,182 @@ func TestPath(t *testing.T) {
 		t.Errorf("Path() = %q, want %q", result, expected)
 	}
 }
+
+func TestActionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions Actions
+		wantErr bool
+	}{
+		{
+			name:    "defaults are valid",
+			actions: DefaultConfig().Actions,
+			wantErr: false,
+		},
+		{
+			name:    "zoom windows and break panes",
+			actions: Actions{Session: ActionSwitch, Window: ActionZoom, Pane: ActionBreak},
+			wantErr: false,
+		},
+		{
+			name:    "zoom is not a session action",
+			actions: Actions{Session: ActionZoom, Window: ActionSwitch, Pane: ActionSwitch},
+			wantErr: true,
+		},
+		{
+			name:    "break is not a window action",
+			actions: Actions{Session: ActionSwitch, Window: ActionBreak, Pane: ActionSwitch},
+			wantErr: true,
+		},
+		{
+			name:    "unknown action",
+			actions: Actions{Session: ActionSwitch, Window: ActionSwitch, Pane: "explode"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.actions.validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateEmptyActions(t *testing.T) {
+	if err := validateEmptyActions(DefaultConfig().EmptyActions); err != nil {
+		t.Errorf("default empty actions should be valid: %v", err)
+	}
+	if err := validateEmptyActions(nil); err != nil {
+		t.Errorf("no empty actions should be valid: %v", err)
+	}
+	if err := validateEmptyActions([]string{EmptyActionNew, "snapshot"}); err == nil {
+		t.Error("unknown empty action should be rejected")
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SizeProfiles = map[string]SizeProfile{
+		ProfileCompact: {Width: 30},
+		"demo":         {Columns: []string{ColumnPath}, Help: true},
+	}
+
+	if got := cfg.Profile(ProfileCompact).Width; got != 30 {
+		t.Errorf("compact width = %d, want the configured 30", got)
+	}
+	if !cfg.Profile("").Help || !cfg.Profile("").ShowsColumn(ColumnTime) {
+		t.Error("an unset profile should fall back to the default profile")
+	}
+	if got := cfg.ProfileNames(); len(got) != 4 || got[3] != "demo" {
+		t.Errorf("ProfileNames() = %v, want the built-ins then demo", got)
+	}
+
+	cfg.SizeProfile = "demo"
+	if err := cfg.validateProfiles(); err != nil {
+		t.Errorf("validateProfiles() error = %v", err)
+	}
+	cfg.SizeProfile = "huge"
+	if err := cfg.validateProfiles(); err == nil {
+		t.Error("unknown size_profile should be rejected")
+	}
+	cfg.SizeProfile = ""
+	cfg.SizeProfiles["demo"] = SizeProfile{Columns: []string{"cpu"}}
+	if err := cfg.validateProfiles(); err == nil {
+		t.Error("unknown column should be rejected")
+	}
+}
+
+func TestHealthChecks(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	cfg := DefaultConfig()
+	cfg.HealthChecks = []HealthCheck{
+		{Tag: "web", Command: "curl -fs localhost:3000"},
+		{Path: "~/work/*", Command: "true", Timeout: time.Second},
+	}
+	if err := cfg.validateHealthChecks(); err != nil {
+		t.Fatalf("validateHealthChecks() error = %v", err)
+	}
+	if cfg.HealthChecks[0].Timeout != DefaultHealthTimeout || cfg.HealthChecks[1].Path != "/home/me/work/*" {
+		t.Errorf("checks = %+v, want the default timeout and an expanded path", cfg.HealthChecks)
+	}
+
+	if check, ok := cfg.HealthCheckFor("/home/me/work/api", []string{"web"}); !ok || check.Tag != "web" {
+		t.Errorf("HealthCheckFor() = %+v, want the first match (tag web)", check)
+	}
+	if check, ok := cfg.HealthCheckFor("/home/me/work/api", nil); !ok || check.Command != "true" {
+		t.Errorf("HealthCheckFor() = %+v, want the path match", check)
+	}
+	if _, ok := cfg.HealthCheckFor("/home/me/notes", nil); ok {
+		t.Error("HealthCheckFor() matched a session no check applies to")
+	}
+
+	for _, check := range []HealthCheck{
+		{Tag: "web"},
+		{Command: "true"},
+		{Path: "[", Command: "true"},
+	} {
+		cfg.HealthChecks = []HealthCheck{check}
+		if err := cfg.validateHealthChecks(); err == nil {
+			t.Errorf("validateHealthChecks(%+v) should fail", check)
+		}
+	}
+}
+
+func TestValidateSessionName(t *testing.T) {
+	if err := validateSessionName("{org}/{repo}@{branch}"); err != nil {
+		t.Errorf("validateSessionName() error = %v", err)
+	}
+	if err := validateSessionName("{repo}-{sha}"); err == nil {
+		t.Error("unknown variable {sha} should be rejected")
+	}
+}
+
+func TestMachineProfiles(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Profiles = map[string]MachineProfile{
+		"work": {Hosts: []string{"acme-*"}, ProjectDirs: []string{"~/work"}},
+		"home": {Hosts: []string{"laptop"}, Servers: []Server{{Name: "outer", Socket: "/tmp/outer"}}},
+	}
+
+	tests := []struct {
+		env, hostname, want string
+	}{
+		{"", "acme-42", "work"},
+		{"", "laptop", "home"},
+		{"", "server", ""},
+		// $TSM_PROFILE wins over the hostname
+		{"home", "acme-42", "home"},
+	}
+	for _, tt := range tests {
+		got, err := cfg.machineProfile(tt.env, tt.hostname)
+		if err != nil || got != tt.want {
+			t.Errorf("machineProfile(%q, %q) = %q, %v, want %q", tt.env, tt.hostname, got, err, tt.want)
+		}
+	}
+	if _, err := cfg.machineProfile("office", "laptop"); err == nil {
+		t.Error("an unknown $TSM_PROFILE should be rejected")
+	}
+
+	projectDirs := cfg.ProjectDirs
+	cfg.applyMachineProfile("home")
+	if cfg.MachineProfile != "home" || len(cfg.Servers) != 1 || !slices.Equal(cfg.ProjectDirs, projectDirs) {
+		t.Errorf("after home: servers = %v, project dirs = %v, want the servers replaced only", cfg.Servers, cfg.ProjectDirs)
+	}
+	cfg.applyMachineProfile("work")
+	if !slices.Equal(cfg.ProjectDirs, []string{"~/work"}) {
+		t.Errorf("after work: project dirs = %v, want [~/work]", cfg.ProjectDirs)
+	}
+
+	// Terminal settings: plain can be turned off again, icons merge
+	plain := false
+	cfg.Plain = true
+	cfg.Icons = map[string]string{"session": "S", "window": "W"}
+	cfg.Profiles["laptop"] = MachineProfile{Plain: &plain, IconSet: "ascii", Icons: map[string]string{"window": "w"}}
+	cfg.applyMachineProfile("laptop")
+	if cfg.Plain || cfg.IconSet != "ascii" || cfg.Icons["session"] != "S" || cfg.Icons["window"] != "w" {
+		t.Errorf("after laptop: plain = %v, icon set = %q, icons = %v", cfg.Plain, cfg.IconSet, cfg.Icons)
+	}
+}
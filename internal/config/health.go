@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// DefaultHealthTimeout is how long a health check may run when it sets no timeout
+const DefaultHealthTimeout = 5 * time.Second
+
+// HealthCheck is a command telling whether a session's dev stack is up, e.g.
+// curl against its dev server. It applies to sessions matching its path or tag.
+type HealthCheck struct {
+	// Glob matched against the session directory (e.g. "~/work/*")
+	Path string `toml:"path"`
+
+	// Session tag the check applies to
+	Tag string `toml:"tag"`
+
+	// Shell command run in the session directory; exit status 0 is healthy
+	Command string `toml:"command"`
+
+	// How long the command may run before it counts as failed (0 = 5s)
+	Timeout time.Duration `toml:"timeout"`
+}
+
+// HealthCheckFor returns the first health check matching a session's
+// directory or tags
+func (c Config) HealthCheckFor(path string, tags []string) (HealthCheck, bool) {
+	for _, check := range c.HealthChecks {
+		if check.Tag != "" && slices.Contains(tags, check.Tag) {
+			return check, true
+		}
+		if check.Path != "" && path != "" {
+			if ok, _ := filepath.Match(check.Path, path); ok {
+				return check, true
+			}
+		}
+	}
+	return HealthCheck{}, false
+}
+
+// validateHealthChecks checks the health checks and expands ~ in their paths
+func (c Config) validateHealthChecks() error {
+	for i := range c.HealthChecks {
+		check := &c.HealthChecks[i]
+		if check.Command == "" {
+			return fmt.Errorf("health_checks[%d]: command is required", i)
+		}
+		if check.Path == "" && check.Tag == "" {
+			return fmt.Errorf("health_checks[%d]: set path or tag", i)
+		}
+		if check.Path != "" {
+			check.Path = expandPath(check.Path)
+			if _, err := filepath.Match(check.Path, ""); err != nil {
+				return fmt.Errorf("health_checks[%d]: invalid path pattern %q", i, check.Path)
+			}
+		}
+		if check.Timeout < 0 {
+			return fmt.Errorf("health_checks[%d]: timeout must not be negative", i)
+		}
+		if check.Timeout == 0 {
+			check.Timeout = DefaultHealthTimeout
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"sort"
+)
+
+// MachineProfile overrides machine-specific settings, so one config can be
+// shared across machines. Unset fields keep the top-level value.
+type MachineProfile struct {
+	// Hostnames the profile applies to; shell patterns such as "build-*" match too
+	Hosts []string `toml:"hosts"`
+
+	ProjectDirs       []string `toml:"project_dirs"`
+	DefaultSessionDir string   `toml:"default_session_dir"`
+	Servers           []Server `toml:"servers"`
+
+	// Terminal settings, e.g. plain output on a machine without a nerd font.
+	// Plain is a pointer so a profile can also turn it off; icons are merged
+	// over the shared ones.
+	Plain   *bool             `toml:"plain"`
+	IconSet string            `toml:"icon_set"`
+	Icons   map[string]string `toml:"icons"`
+}
+
+// machineProfile picks the machine profile to apply: the one named by
+// $TSM_PROFILE (env), otherwise the first, by name, listing the hostname.
+// Returns "" when none applies.
+func (c Config) machineProfile(env, hostname string) (string, error) {
+	if env != "" {
+		if _, ok := c.Profiles[env]; !ok {
+			return "", fmt.Errorf("TSM_PROFILE: unknown profile %q", env)
+		}
+		return env, nil
+	}
+
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, pattern := range c.Profiles[name].Hosts {
+			if ok, err := path.Match(pattern, hostname); err != nil {
+				return "", fmt.Errorf("profiles.%s: invalid host pattern %q", name, pattern)
+			} else if ok {
+				return name, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// applyMachineProfile overrides the settings the named profile sets
+func (c *Config) applyMachineProfile(name string) {
+	p, ok := c.Profiles[name]
+	if !ok {
+		return
+	}
+	c.MachineProfile = name
+	if p.ProjectDirs != nil {
+		c.ProjectDirs = p.ProjectDirs
+	}
+	if p.DefaultSessionDir != "" {
+		c.DefaultSessionDir = p.DefaultSessionDir
+	}
+	if p.Servers != nil {
+		c.Servers = p.Servers
+	}
+	if p.Plain != nil {
+		c.Plain = *p.Plain
+	}
+	if p.IconSet != "" {
+		c.IconSet = p.IconSet
+	}
+	if len(p.Icons) > 0 {
+		icons := make(map[string]string, len(c.Icons)+len(p.Icons))
+		maps.Copy(icons, c.Icons)
+		maps.Copy(icons, p.Icons)
+		c.Icons = icons
+	}
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Version is the config schema version this build reads. Bump it along with
+// a migration whenever a key is renamed or a table moves.
+const Version = 1
+
+// migration upgrades the config file to a schema version. It edits the lines
+// in place, so comments and the user's layout survive.
+type migration struct {
+	to      int // Version the config has afterwards
+	summary string
+	apply   func(lines []string) []string
+}
+
+// migrations lists the schema changes in version order, e.g.
+//
+//	{to: 2, summary: "matcher moved to [filter] match", apply: func(lines []string) []string {
+//		return moveKey(lines, "", "matcher", "filter", "match")
+//	}},
+var migrations []migration
+
+// migrateFile upgrades an outdated config file to Version, keeping the
+// original next to it (config.toml.v1.bak). Returns what was done, "" when
+// the file was current or doesn't exist.
+func migrateFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	from, migrated, done, err := migrate(string(content), Version, migrations)
+	if err != nil || migrated == string(content) {
+		return "", err
+	}
+
+	var check Config
+	if _, err := toml.Decode(migrated, &check); err != nil {
+		return "", fmt.Errorf("config migration to version %d produced an invalid file: %w", Version, err)
+	}
+	backup := fmt.Sprintf("%s.v%d.bak", path, from)
+	if err := os.WriteFile(backup, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to back up config before migrating: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(migrated), 0644); err != nil {
+		return "", fmt.Errorf("failed to write migrated config: %w", err)
+	}
+	summary := fmt.Sprintf("Migrated config from version %d to %d", from, Version)
+	if len(done) > 0 {
+		summary += ": " + strings.Join(done, ", ")
+	}
+	return summary + " (backup in " + backup + ")", nil
+}
+
+// migrate applies the steps past the content's version up to target and
+// stamps the new version, returning the version it started from and the
+// summaries of the steps applied. Configs without a version predate
+// versioning and have the version 1 shape. Content that doesn't parse is
+// returned as is, for the regular load to report.
+func migrate(content string, target int, steps []migration) (from int, migrated string, done []string, err error) {
+	var v struct {
+		Version int `toml:"version"`
+	}
+	if _, err := toml.Decode(content, &v); err != nil {
+		return 0, content, nil, nil
+	}
+	from = max(v.Version, 1)
+	if from > target {
+		return from, content, nil, fmt.Errorf("config version %d is newer than this tsm supports (%d), update tsm", from, target)
+	}
+	if from == target {
+		return from, content, nil, nil
+	}
+
+	lines := strings.Split(content, "\n")
+	for _, step := range steps {
+		if step.to > from && step.to <= target {
+			lines = step.apply(lines)
+			done = append(done, step.summary)
+		}
+	}
+	migrated, err = updateTOML(strings.Join(lines, "\n"), []Value{{Key: "version", Value: target}})
+	return from, migrated, done, err
+}
+
+// tableHeader matches a table header ("[actions]", "[[servers]]",
+// "[size_profiles.compact]") and captures its name
+var tableHeader = regexp.MustCompile(`^\s*\[\[?\s*([^\]]+?)\s*\]\]?`)
+
+// tableLines returns the range of lines holding the keys of a table, the
+// top-level keys for "". Returns -1s when the table doesn't exist.
+func tableLines(lines []string, table string) (start, end int) {
+	start, end = -1, len(lines)
+	if table == "" {
+		start = 0
+	}
+	for i, line := range lines {
+		match := tableHeader.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if start >= 0 {
+			return start, i
+		}
+		if match[1] == table {
+			start = i + 1
+		}
+	}
+	if start < 0 {
+		return -1, -1
+	}
+	return start, end
+}
+
+// renameKey renames a key of a table ("" = top level), commented-out
+// defaults included so the template's documentation follows
+func renameKey(lines []string, table, from, to string) []string {
+	start, end := tableLines(lines, table)
+	if start < 0 {
+		return lines
+	}
+	re := regexp.MustCompile(`^(\s*(?:#\s*)?)` + regexp.QuoteMeta(from) + `(\s*=)`)
+	for i := start; i < end; i++ {
+		lines[i] = re.ReplaceAllString(lines[i], "${1}"+to+"${2}")
+	}
+	return lines
+}
+
+// moveKey moves an assignment from one table into another under a new key,
+// adding the target table at the end when it doesn't exist yet
+func moveKey(lines []string, fromTable, from, toTable, to string) []string {
+	start, end := tableLines(lines, fromTable)
+	if start < 0 {
+		return lines
+	}
+	re := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(from) + `\s*=(.*)$`)
+	for i := start; i < end; i++ {
+		match := re.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+		assignment := to + " =" + match[1]
+		lines = slices.Delete(lines, i, i+1)
+
+		// Keep the blank lines separating the target table from the next one
+		if _, at := tableLines(lines, toTable); at >= 0 {
+			for at > 0 && strings.TrimSpace(lines[at-1]) == "" {
+				at--
+			}
+			return slices.Insert(lines, at, assignment)
+		}
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		return append(lines, "", "["+toTable+"]", assignment, "")
+	}
+	return lines
+}
+
+// renameTable renames a table and its subtables ("[size_profiles]",
+// "[size_profiles.compact]", "[[size_profiles]]"), commented-out ones included
+func renameTable(lines []string, from, to string) []string {
+	re := regexp.MustCompile(`^(\s*(?:#\s*)?\[\[?\s*)` + regexp.QuoteMeta(from) + `([.\]\s])`)
+	for i, line := range lines {
+		lines[i] = re.ReplaceAllString(line, "${1}"+to+"${2}")
+	}
+	return lines
+}
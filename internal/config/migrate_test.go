@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	content := `# tsm configuration
+
+# How the filter matches
+matcher = "fuzzy"
+# sort = "activity"
+
+[sizes.compact]
+width = 40
+`
+	steps := []migration{
+		{to: 2, summary: "sizes renamed to size_profiles", apply: func(lines []string) []string {
+			return renameTable(lines, "sizes", "size_profiles")
+		}},
+		{to: 3, summary: "matcher moved to [filter] match", apply: func(lines []string) []string {
+			return moveKey(lines, "", "matcher", "filter", "match")
+		}},
+		{to: 4, summary: "sort renamed to order", apply: func(lines []string) []string {
+			return renameKey(lines, "", "sort", "order")
+		}},
+	}
+
+	from, got, done, err := migrate(content, 3, steps)
+	if err != nil {
+		t.Fatalf("migrate() error = %v", err)
+	}
+	want := `# tsm configuration
+
+# How the filter matches
+# sort = "activity"
+version = 3
+
+[size_profiles.compact]
+width = 40
+
+[filter]
+match = "fuzzy"
+`
+	if from != 1 || got != want {
+		t.Errorf("migrate() from %d =\n%s\nwant from 1\n%s", from, got, want)
+	}
+	if len(done) != 2 || done[0] != "sizes renamed to size_profiles" {
+		t.Errorf("done = %q, want the steps up to version 3", done)
+	}
+
+	// Steps the config is past are skipped
+	_, got, _, err = migrate(want, 4, steps)
+	if err != nil || !strings.Contains(got, `# order = "activity"`) || !strings.Contains(got, "version = 4") || strings.Count(got, "[filter]") != 1 {
+		t.Errorf("migrate() from 3 =\n%s, error = %v", got, err)
+	}
+
+	if _, got, _, err := migrate(want, 3, steps); err != nil || got != want {
+		t.Errorf("migrate() of a current config = %q, %v, want it unchanged", got, err)
+	}
+	if _, _, _, err := migrate(want, 2, steps); err == nil {
+		t.Error("migrate() of a config newer than tsm should fail")
+	}
+}
+
+func TestMigrateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+
+	if note, err := migrateFile(path); note != "" || err != nil {
+		t.Errorf("migrateFile() of a missing file = %q, %v", note, err)
+	}
+
+	// Files written before versioning have the current shape
+	if err := os.WriteFile(path, []byte("layout = \"ide\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if note, err := migrateFile(path); note != "" || err != nil {
+		t.Errorf("migrateFile() of an unversioned file = %q, %v, want it left alone", note, err)
+	}
+	if _, err := os.Stat(path + ".v1.bak"); !os.IsNotExist(err) {
+		t.Error("a current config should not be backed up")
+	}
+
+	if err := os.WriteFile(path, []byte("version = 99\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrateFile(path); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("migrateFile() error = %v, want the version called newer than tsm", err)
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Session row columns a size profile can show
+const (
+	ColumnTime       = "time"       // Time since last activity
+	ColumnClaude     = "claude"     // Claude Code status
+	ColumnIndicators = "indicators" // Suspended and notes icons
+	ColumnTags       = "tags"       // Session tags
+	ColumnWindows    = "windows"    // Window count
+	ColumnPath       = "path"       // Session working directory
+	ColumnGit        = "git"        // Branch checked out in the session directory
+)
+
+var columns = []string{ColumnTime, ColumnClaude, ColumnIndicators, ColumnTags, ColumnWindows, ColumnGit, ColumnPath}
+
+// SizeProfile controls how much the picker shows
+type SizeProfile struct {
+	// Maximum picker width in cells (0 = the whole popup or terminal)
+	Width int `toml:"width"`
+
+	// Session row columns, in any order (see the Column constants)
+	Columns []string `toml:"columns"`
+
+	// Render the key help line
+	Help bool `toml:"help"`
+}
+
+// Built-in size profiles
+const (
+	ProfileCompact = "compact"
+	ProfileDefault = "default"
+	ProfileFull    = "full"
+)
+
+var builtinProfileNames = []string{ProfileCompact, ProfileDefault, ProfileFull}
+
+// builtinProfiles returns the built-in size profiles; config entries with the
+// same name replace them
+func builtinProfiles() map[string]SizeProfile {
+	return map[string]SizeProfile{
+		ProfileCompact: {Width: 40, Columns: []string{ColumnClaude}},
+		ProfileDefault: {Columns: []string{ColumnTime, ColumnClaude, ColumnIndicators, ColumnTags}, Help: true},
+		ProfileFull:    {Columns: slices.Clone(columns), Help: true},
+	}
+}
+
+// Profile returns the named size profile, falling back to the default profile
+func (c Config) Profile(name string) SizeProfile {
+	if p, ok := c.SizeProfiles[name]; ok {
+		return p
+	}
+	builtin := builtinProfiles()
+	if p, ok := builtin[name]; ok {
+		return p
+	}
+	return builtin[ProfileDefault]
+}
+
+// ProfileNames returns the size profiles in switching order: the built-in
+// ones, then those defined in the config sorted by name
+func (c Config) ProfileNames() []string {
+	names := slices.Clone(builtinProfileNames)
+	var custom []string
+	for name := range c.SizeProfiles {
+		if !slices.Contains(names, name) {
+			custom = append(custom, name)
+		}
+	}
+	sort.Strings(custom)
+	return append(names, custom...)
+}
+
+// ShowsColumn reports whether the profile includes a session row column
+func (p SizeProfile) ShowsColumn(column string) bool {
+	return slices.Contains(p.Columns, column)
+}
+
+// validateProfiles checks the configured size profiles and the starting one
+func (c Config) validateProfiles() error {
+	for name, p := range c.SizeProfiles {
+		if p.Width < 0 {
+			return fmt.Errorf("size_profiles.%s: width must not be negative", name)
+		}
+		for _, column := range p.Columns {
+			if !slices.Contains(columns, column) {
+				return fmt.Errorf("size_profiles.%s: unknown column %q (valid: %v)", name, column, columns)
+			}
+		}
+	}
+	if c.SizeProfile != "" && !slices.Contains(c.ProfileNames(), c.SizeProfile) {
+		return fmt.Errorf("invalid size_profile %q (valid: %v)", c.SizeProfile, c.ProfileNames())
+	}
+	return nil
+}
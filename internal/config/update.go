@@ -0,0 +1,186 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Value is a top-level config key and its new value
+type Value struct {
+	Key   string
+	Value any
+}
+
+// Update sets top-level keys in the config file while keeping comments and
+// unrelated lines. Existing assignments are replaced in place, commented-out
+// defaults ("# key = ...") are uncommented, and other keys are added before
+// the first table. The result is validated before the file is written.
+func Update(path string, values []Value) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	updated, err := updateTOML(string(content), values)
+	if err != nil {
+		return err
+	}
+
+	var check Config
+	if _, err := toml.Decode(updated, &check); err != nil {
+		return fmt.Errorf("refusing to write invalid config: %w", err)
+	}
+
+	return os.WriteFile(path, []byte(updated), 0644)
+}
+
+// updateTOML applies values to TOML content (see Update). A value written
+// over several lines (an array, an inline table or a multi-line string) is
+// replaced as a whole, commented out or not.
+func updateTOML(content string, values []Value) (string, error) {
+	lines := strings.Split(content, "\n")
+
+	var added []string
+	for _, v := range values {
+		assignment, err := encodeValue(v.Key, v.Value)
+		if err != nil {
+			return "", err
+		}
+
+		key := regexp.QuoteMeta(v.Key)
+		set := regexp.MustCompile(`^\s*` + key + `\s*=`)
+		commented := regexp.MustCompile(`^\s*#\s*` + key + `\s*=`)
+
+		top := lines[:tableStart(lines)]
+		if i := indexMatch(top, set); i >= 0 {
+			lines = slices.Replace(lines, i, valueEnd(lines, i, false)+1, assignment)
+		} else if i := indexMatch(top, commented); i >= 0 {
+			lines = slices.Replace(lines, i, valueEnd(lines, i, true)+1, assignment)
+		} else {
+			added = append(added, assignment)
+		}
+	}
+
+	if len(added) > 0 {
+		// Keep the blank lines separating top-level keys from the first table
+		at := tableStart(lines)
+		for at > 0 && strings.TrimSpace(lines[at-1]) == "" {
+			at--
+		}
+		lines = slices.Insert(lines, at, added...)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// assignmentLine matches the start of a "key = value" line
+var assignmentLine = regexp.MustCompile(`^\s*[A-Za-z0-9_-]+\s*=`)
+
+// tableStart returns the index of the first table header, where the
+// top-level keys end, skipping values spread over several lines
+func tableStart(lines []string) int {
+	for i := 0; i < len(lines); i++ {
+		if assignmentLine.MatchString(lines[i]) {
+			i = valueEnd(lines, i, false)
+		} else if strings.HasPrefix(strings.TrimSpace(lines[i]), "[") {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+// valueEnd returns the last line of the value assigned on lines[start],
+// following open brackets, braces and multi-line strings. A commented-out
+// value is read with the "#" of each line removed, and ends at the first
+// line that isn't a comment.
+func valueEnd(lines []string, start int, commented bool) int {
+	depth, quote := 0, ""
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		if commented {
+			line = strings.TrimPrefix(strings.TrimSpace(line), "#")
+		}
+		if i == start {
+			_, line, _ = strings.Cut(line, "=")
+		}
+
+		for j := 0; j < len(line); j++ {
+			rest := line[j:]
+			switch {
+			case quote != "":
+				if quote[0] == '"' && line[j] == '\\' {
+					j++
+				} else if strings.HasPrefix(rest, quote) {
+					j += len(quote) - 1
+					quote = ""
+				}
+			case strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, "'''"):
+				quote = rest[:3]
+				j += 2
+			case line[j] == '"' || line[j] == '\'':
+				quote = rest[:1]
+			case line[j] == '[' || line[j] == '{':
+				depth++
+			case line[j] == ']' || line[j] == '}':
+				depth--
+			case line[j] == '#':
+				j = len(line)
+			}
+		}
+		// Only multi-line strings stay open past the end of a line
+		if len(quote) == 1 {
+			quote = ""
+		}
+
+		if depth <= 0 && quote == "" {
+			return i
+		}
+		if commented && i+1 < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i+1]), "#") {
+			return i
+		}
+	}
+	return len(lines) - 1
+}
+
+// indexMatch returns the index of the first line matching re, or -1
+func indexMatch(lines []string, re *regexp.Regexp) int {
+	for i, line := range lines {
+		if re.MatchString(line) {
+			return i
+		}
+	}
+	return -1
+}
+
+// encodeValue renders a single "key = value" TOML assignment
+func encodeValue(key string, value any) (string, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(map[string]any{key: value}); err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// LoadFile reads the config file over the defaults without expanding paths
+// or applying environment overrides, for editing the file as written
+func LoadFile() (Config, error) {
+	cfg := DefaultConfig()
+	if _, err := os.Stat(Path()); err != nil {
+		return cfg, nil
+	}
+	migrated, err := migrateFile(Path())
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Migrated = migrated
+	if _, err := toml.DecodeFile(Path(), &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdateTOML(t *testing.T) {
+	content := `# tsm configuration
+
+# Layout script name
+# layout = "ide"
+
+project_depth = 3 # keep comment lines around
+
+[actions]
+window = "zoom"
+`
+
+	got, err := updateTOML(content, []Value{
+		{Key: "layout", Value: "dev"},
+		{Key: "project_depth", Value: 2},
+		{Key: "split_view", Value: true},
+		{Key: "project_dirs", Value: []string{"~/repos", "~/work"}},
+	})
+	if err != nil {
+		t.Fatalf("updateTOML() error = %v", err)
+	}
+
+	want := `# tsm configuration
+
+# Layout script name
+layout = "dev"
+
+project_depth = 2
+split_view = true
+project_dirs = ["~/repos", "~/work"]
+
+[actions]
+window = "zoom"
+`
+	if got != want {
+		t.Errorf("updateTOML() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUpdateTOMLMultiline(t *testing.T) {
+	content := `# Directories to pick projects from
+# project_dirs = [
+#   "~/code",
+#   "~/work",
+# ]
+
+exclude = [
+  "node_modules", # deps
+  "[build]",
+]
+notes_header = """
+# Notes
+[todo]
+"""
+layout = "ide"
+
+[actions]
+window = "zoom"
+`
+
+	got, err := updateTOML(content, []Value{
+		{Key: "project_dirs", Value: []string{"~/repos"}},
+		{Key: "exclude", Value: []string{"dist"}},
+		{Key: "notes_header", Value: "# Todo"},
+		{Key: "split_view", Value: true},
+	})
+	if err != nil {
+		t.Fatalf("updateTOML() error = %v", err)
+	}
+
+	want := `# Directories to pick projects from
+project_dirs = ["~/repos"]
+
+exclude = ["dist"]
+notes_header = "# Todo"
+layout = "ide"
+split_view = true
+
+[actions]
+window = "zoom"
+`
+	if got != want {
+		t.Errorf("updateTOML() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUpdateTemplate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if err := Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if err := Update(Path(), []Value{{Key: "layout", Value: "ide"}, {Key: "claude_status_enabled", Value: true}}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	cfg, err := LoadFile()
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if cfg.Layout != "ide" || !cfg.ClaudeStatusEnabled {
+		t.Errorf("LoadFile() layout = %q, claude = %v, want ide and true", cfg.Layout, cfg.ClaudeStatusEnabled)
+	}
+
+	// Comments of the template survive the update
+	content, _ := os.ReadFile(filepath.Join(home, ".config", "tsm", "config.toml"))
+	if !strings.Contains(string(content), "# Layout script name to apply when creating new sessions") {
+		t.Error("template comments should be preserved")
+	}
+}
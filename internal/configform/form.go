@@ -0,0 +1,246 @@
+// Package configform implements the `tsm config edit` form for the main options
+package configform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// fieldKind determines how a field is edited and encoded
+type fieldKind int
+
+const (
+	textField fieldKind = iota
+	intField
+	listField // Comma-separated list of strings
+	boolField
+)
+
+// field is a single editable config option
+type field struct {
+	key   string
+	label string
+	help  string
+	kind  fieldKind
+	input textinput.Model // Text, int and list fields
+	on    bool            // Bool fields
+}
+
+// Model is the config form state
+type Model struct {
+	fields  []field
+	cursor  int
+	path    string
+	message string
+	isError bool
+	saved   bool
+}
+
+// New creates a form for the config file at path, prefilled from cfg
+func New(path string, cfg config.Config) Model {
+	m := Model{path: path}
+	m.addText("layout", "Layout", "Layout script applied to new sessions", cfg.Layout)
+	m.addText("layout_dir", "Layout dir", "Directory containing layout scripts", cfg.LayoutDir)
+	m.addList("project_dirs", "Project dirs", "Directories scanned by the project picker (comma-separated)", cfg.ProjectDirs)
+	m.addInt("project_depth", "Project depth", "Scan depth for project directories", cfg.ProjectDepth)
+	m.addText("default_session_dir", "Session dir", "Directory for sessions created with C-n", cfg.DefaultSessionDir)
+	m.addBool("claude_status_enabled", "Claude status", "Show Claude Code status per session", cfg.ClaudeStatusEnabled)
+	m.addInt("recent_sessions", "Recent sessions", "Recently closed sessions listed (0 disables)", cfg.RecentSessions)
+	m.addBool("split_view", "Split view", "Show windows in a pane next to the sessions", cfg.SplitView)
+	m.addBool("create_in_background", "Background new", "Create C-n sessions without switching to them", cfg.CreateInBackground)
+	m.addBool("plain", "Plain output", "No icons, colors or box drawing", cfg.Plain)
+	m.focus()
+	return m
+}
+
+func (m *Model) addText(key, label, help, value string) {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.SetValue(value)
+	m.fields = append(m.fields, field{key: key, label: label, help: help, kind: textField, input: ti})
+}
+
+func (m *Model) addInt(key, label, help string, value int) {
+	m.addText(key, label, help, strconv.Itoa(value))
+	m.fields[len(m.fields)-1].kind = intField
+}
+
+func (m *Model) addList(key, label, help string, values []string) {
+	m.addText(key, label, help, strings.Join(values, ", "))
+	m.fields[len(m.fields)-1].kind = listField
+}
+
+func (m *Model) addBool(key, label, help string, on bool) {
+	m.fields = append(m.fields, field{key: key, label: label, help: help, kind: boolField, on: on})
+}
+
+// Saved reports whether the form was written to the config file
+func (m Model) Saved() bool {
+	return m.saved
+}
+
+// Init implements tea.Model
+func (m Model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update implements tea.Model
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, m.updateInput(msg)
+	}
+
+	keys := ui.DefaultKeyMap
+	current := &m.fields[m.cursor]
+
+	switch {
+	case key.Matches(keyMsg, keys.Quit), key.Matches(keyMsg, keys.Cancel):
+		return m, tea.Quit
+
+	case key.Matches(keyMsg, keys.Save):
+		if err := m.save(); err != nil {
+			m.message = err.Error()
+			m.isError = true
+			return m, nil
+		}
+		m.saved = true
+		return m, tea.Quit
+
+	case key.Matches(keyMsg, keys.Up), keyMsg.Type == tea.KeyShiftTab:
+		m.move(-1)
+
+	case key.Matches(keyMsg, keys.Down), keyMsg.Type == tea.KeyTab:
+		m.move(1)
+
+	case current.kind == boolField && (keyMsg.Type == tea.KeySpace || keyMsg.Type == tea.KeyEnter):
+		current.on = !current.on
+
+	case keyMsg.Type == tea.KeyEnter:
+		m.move(1)
+
+	default:
+		return m, m.updateInput(msg)
+	}
+
+	return m, nil
+}
+
+// updateInput passes a message to the focused text input
+func (m *Model) updateInput(msg tea.Msg) tea.Cmd {
+	current := &m.fields[m.cursor]
+	if current.kind == boolField {
+		return nil
+	}
+	var cmd tea.Cmd
+	current.input, cmd = current.input.Update(msg)
+	return cmd
+}
+
+// move moves the cursor by delta, wrapping around
+func (m *Model) move(delta int) {
+	m.fields[m.cursor].input.Blur()
+	m.cursor = (m.cursor + delta + len(m.fields)) % len(m.fields)
+	m.focus()
+	m.message = ""
+}
+
+func (m *Model) focus() {
+	if f := &m.fields[m.cursor]; f.kind != boolField {
+		f.input.Focus()
+		f.input.CursorEnd()
+	}
+}
+
+// Values returns the form contents as config values, validating numbers
+func (m Model) Values() ([]config.Value, error) {
+	values := make([]config.Value, 0, len(m.fields))
+	for _, f := range m.fields {
+		raw := strings.TrimSpace(f.input.Value())
+		switch f.kind {
+		case textField:
+			values = append(values, config.Value{Key: f.key, Value: raw})
+		case intField:
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%s must be a non-negative number", f.label)
+			}
+			values = append(values, config.Value{Key: f.key, Value: n})
+		case listField:
+			list := []string{}
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			values = append(values, config.Value{Key: f.key, Value: list})
+		case boolField:
+			values = append(values, config.Value{Key: f.key, Value: f.on})
+		}
+	}
+	return values, nil
+}
+
+func (m Model) save() error {
+	values, err := m.Values()
+	if err != nil {
+		return err
+	}
+	return config.Update(m.path, values)
+}
+
+// View implements tea.Model
+func (m Model) View() string {
+	var b strings.Builder
+
+	b.WriteString(ui.HeaderStyle.Render("tsm config"))
+	b.WriteString("  ")
+	b.WriteString(ui.TimeStyle.Render(m.path))
+	b.WriteString("\n\n")
+
+	labelWidth := 0
+	for _, f := range m.fields {
+		labelWidth = max(labelWidth, len(f.label))
+	}
+
+	for i, f := range m.fields {
+		selected := i == m.cursor
+		label := fmt.Sprintf("%-*s", labelWidth, f.label)
+		if selected {
+			b.WriteString(ui.SessionNameSelectedStyle.Render("> " + label))
+		} else {
+			b.WriteString("  " + label)
+		}
+		b.WriteString("  ")
+
+		if f.kind == boolField {
+			if f.on {
+				b.WriteString("[x]")
+			} else {
+				b.WriteString("[ ]")
+			}
+		} else {
+			b.WriteString(f.input.View())
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if m.message != "" && m.isError {
+		b.WriteString(ui.ErrorMessageStyle.Render(m.message))
+	} else {
+		b.WriteString(ui.StatuslineStyle.Render(m.fields[m.cursor].help))
+	}
+	b.WriteString("\n")
+	b.WriteString(ui.FooterStyle.Render(ui.HelpConfigForm()))
+
+	return ui.AppStyle.Render(b.String())
+}
@@ -0,0 +1,71 @@
+package configform
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/config"
+)
+
+// valueOf returns the value for key, failing the test if it is missing
+func valueOf(t *testing.T, values []config.Value, key string) any {
+	t.Helper()
+	for _, v := range values {
+		if v.Key == key {
+			return v.Value
+		}
+	}
+	t.Fatalf("no value for %s", key)
+	return nil
+}
+
+func TestValues(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Layout = "ide"
+	cfg.ProjectDirs = []string{"~/repos", "~/work"}
+	m := New("/tmp/config.toml", cfg)
+
+	values, err := m.Values()
+	if err != nil {
+		t.Fatalf("Values() error = %v", err)
+	}
+	if got := valueOf(t, values, "layout"); got != "ide" {
+		t.Errorf("layout = %v, want ide", got)
+	}
+	if got := valueOf(t, values, "project_dirs"); fmt.Sprint(got) != "[~/repos ~/work]" {
+		t.Errorf("project_dirs = %v, want [~/repos ~/work]", got)
+	}
+	if got := valueOf(t, values, "project_depth"); got != 2 {
+		t.Errorf("project_depth = %v, want 2", got)
+	}
+}
+
+func TestToggleAndValidate(t *testing.T) {
+	m := New("/tmp/config.toml", config.DefaultConfig())
+
+	// Move to the Claude status toggle and flip it
+	for m.fields[m.cursor].key != "claude_status_enabled" {
+		m.move(1)
+	}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	m = updated.(Model)
+
+	values, err := m.Values()
+	if err != nil {
+		t.Fatalf("Values() error = %v", err)
+	}
+	if got := valueOf(t, values, "claude_status_enabled"); got != true {
+		t.Errorf("claude_status_enabled = %v, want true after toggling", got)
+	}
+
+	// Numbers are validated
+	for m.fields[m.cursor].key != "recent_sessions" {
+		m.move(1)
+	}
+	m.fields[m.cursor].input.SetValue("many")
+	if _, err := m.Values(); err == nil {
+		t.Error("Values() should reject a non-numeric recent_sessions")
+	}
+}
@@ -0,0 +1,152 @@
+// Package gitinfo derives template variables ({org}, {repo}, {branch}) from
+// the git repository a session is created in, and manages the linked
+// worktrees sessions work in.
+package gitinfo
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// timeout bounds each git call, so a slow repository can't stall session creation
+const timeout = 2 * time.Second
+
+// Info is what a session name template or layout can use from a repository
+type Info struct {
+	Org    string // Owner of the origin remote, or the parent directory's name
+	Repo   string // Repository of the origin remote, or the directory's name
+	Branch string // Checked out branch (empty outside git or when detached)
+}
+
+// Read returns the git metadata of dir. Outside a repository, or without an
+// origin remote, org and repo fall back to the directory names.
+func Read(dir string) Info {
+	info := Info{
+		Org:  filepath.Base(filepath.Dir(dir)),
+		Repo: filepath.Base(dir),
+	}
+	if url := git(dir, "remote", "get-url", "origin"); url != "" {
+		if org, repo := parseRemote(url); repo != "" {
+			info.Org, info.Repo = org, repo
+		}
+	}
+	info.Branch = Branch(dir)
+	return info
+}
+
+// Branch returns the branch checked out in dir (empty outside git or when detached)
+func Branch(dir string) string {
+	return git(dir, "branch", "--show-current")
+}
+
+// Vars returns the info as template variables
+func (i Info) Vars() map[string]string {
+	return map[string]string{"org": i.Org, "repo": i.Repo, "branch": i.Branch}
+}
+
+// Env returns the info as TSM_ORG, TSM_REPO and TSM_BRANCH for layout scripts
+func (i Info) Env() []string {
+	return []string{"TSM_ORG=" + i.Org, "TSM_REPO=" + i.Repo, "TSM_BRANCH=" + i.Branch}
+}
+
+// Worktree is a linked worktree (git worktree add) a session works in
+type Worktree struct {
+	Path   string // Top directory of the worktree
+	Branch string // Branch checked out in it (empty when detached)
+}
+
+// LinkedWorktree returns the linked worktree dir belongs to. The main
+// checkout of a repository isn't one, and neither is a directory outside git.
+func LinkedWorktree(dir string) (Worktree, bool) {
+	out := git(dir, "rev-parse", "--path-format=absolute", "--show-toplevel", "--git-dir", "--git-common-dir")
+	parts := strings.Split(out, "\n")
+	if len(parts) != 3 || parts[1] == parts[2] {
+		return Worktree{}, false
+	}
+	return Worktree{Path: parts[0], Branch: Branch(parts[0])}, true
+}
+
+// Changes returns the uncommitted changes in dir, one `git status --short`
+// line each
+func Changes(dir string) []string {
+	var changes []string
+	for _, line := range strings.Split(git(dir, "status", "--short"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			changes = append(changes, line)
+		}
+	}
+	return changes
+}
+
+// RemoveWorktree deletes a linked worktree and its directory. git refuses
+// one with uncommitted changes unless force is set. The branch is kept.
+func RemoveWorktree(path string, force bool) error {
+	args := []string{"-C", path, "worktree", "remove", path}
+	if force {
+		args = append(args, "--force")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, "git", args...).CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(out)), "fatal:")); msg != "" {
+			return errors.New(msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// git runs a git command in dir and returns its trimmed output, empty on error
+func git(dir string, args ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// parseRemote extracts owner and repository from a remote URL such as
+// https://github.com/acme/api.git or git@github.com:acme/api.git
+func parseRemote(url string) (org, repo string) {
+	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	} else if i := strings.Index(url, ":"); i >= 0 {
+		url = url[i+1:] // scp-like git@host:owner/repo
+	}
+	parts := strings.Split(url, "/")
+	repo = parts[len(parts)-1]
+	if len(parts) > 1 {
+		org = parts[len(parts)-2]
+	}
+	return org, repo
+}
+
+// placeholderRe matches template variables like {repo}
+var placeholderRe = regexp.MustCompile(`\{(\w+)\}`)
+
+// Placeholders returns the variable names a template uses
+func Placeholders(template string) []string {
+	var names []string
+	for _, match := range placeholderRe.FindAllStringSubmatch(template, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// Expand replaces the {name} placeholders of a template with their values.
+// Separators left dangling at either end by an empty value are dropped, so
+// "{repo}@{branch}" outside git is just the repository.
+func Expand(template string, vars map[string]string) string {
+	out := placeholderRe.ReplaceAllStringFunc(template, func(match string) string {
+		return vars[match[1:len(match)-1]]
+	})
+	return strings.Trim(out, "@/-_ ")
+}
@@ -0,0 +1,106 @@
+package gitinfo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		url, org, repo string
+	}{
+		{"https://github.com/acme/api.git", "acme", "api"},
+		{"git@github.com:acme/api.git", "acme", "api"},
+		{"ssh://git@gitlab.com:2222/group/sub/tool", "sub", "tool"},
+		{"https://github.com/acme/api/", "acme", "api"},
+		{"/srv/git/api.git", "git", "api"},
+	}
+	for _, tt := range tests {
+		if org, repo := parseRemote(tt.url); org != tt.org || repo != tt.repo {
+			t.Errorf("parseRemote(%q) = %q, %q, want %q, %q", tt.url, org, repo, tt.org, tt.repo)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	vars := map[string]string{"org": "acme", "repo": "api", "branch": "main"}
+	if got := Expand("{org}/{repo}@{branch}", vars); got != "acme/api@main" {
+		t.Errorf("Expand() = %q, want acme/api@main", got)
+	}
+
+	vars["branch"] = ""
+	if got := Expand("{org}/{repo}@{branch}", vars); got != "acme/api" {
+		t.Errorf("Expand() = %q, want the dangling @ dropped", got)
+	}
+
+	if got := Placeholders("{org}/{repo}@{sha}"); len(got) != 3 || got[2] != "sha" {
+		t.Errorf("Placeholders() = %v, want org, repo and sha", got)
+	}
+}
+
+func TestRead(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := filepath.Join(t.TempDir(), "work", "api")
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main", dir},
+		{"-C", dir, "remote", "add", "origin", "git@github.com:acme/api-server.git"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	if got := Read(dir); got != (Info{Org: "acme", Repo: "api-server", Branch: "main"}) {
+		t.Errorf("Read() = %+v, want acme/api-server on main", got)
+	}
+
+	plain := t.TempDir()
+	if got := Read(plain); got.Repo != filepath.Base(plain) || got.Branch != "" {
+		t.Errorf("Read() outside git = %+v, want the directory name and no branch", got)
+	}
+}
+
+func TestWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	repo, linked := filepath.Join(root, "api"), filepath.Join(root, "api-feat")
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main", repo},
+		{"-C", repo, "-c", "user.name=tsm", "-c", "user.email=tsm@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"-C", repo, "worktree", "add", "-q", "-b", "feat", linked},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	if _, ok := LinkedWorktree(repo); ok {
+		t.Error("the main checkout is not a linked worktree")
+	}
+	wt, ok := LinkedWorktree(linked)
+	if !ok || wt.Branch != "feat" || filepath.Base(wt.Path) != "api-feat" {
+		t.Fatalf("LinkedWorktree() = %+v, %v, want api-feat on feat", wt, ok)
+	}
+
+	if err := os.WriteFile(filepath.Join(linked, "notes.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changes := Changes(linked); len(changes) != 1 || changes[0] != "?? notes.txt" {
+		t.Errorf("Changes() = %q, want the untracked file", changes)
+	}
+	if err := RemoveWorktree(linked, false); err == nil {
+		t.Error("RemoveWorktree() should refuse uncommitted changes without force")
+	}
+	if err := RemoveWorktree(linked, true); err != nil {
+		t.Fatalf("RemoveWorktree() error = %v", err)
+	}
+	if _, err := os.Stat(linked); !os.IsNotExist(err) {
+		t.Error("the worktree directory should be gone")
+	}
+}
@@ -0,0 +1,24 @@
+// Package health runs the health check commands that tell whether a session's
+// dev stack is up.
+package health
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Check runs command with sh -c in dir and reports whether it exited with
+// status 0 before the timeout. The session name is passed as TSM_SESSION.
+func Check(command, dir, session string, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "TSM_SESSION="+session)
+	// Don't wait for background processes holding on to stdout/stderr
+	cmd.WaitDelay = time.Second
+	return cmd.Run() == nil
+}
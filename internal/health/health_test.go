@@ -0,0 +1,25 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+
+	if !Check(`test "$TSM_SESSION" = api && test "$PWD" = "`+dir+`"`, dir, "api", time.Second) {
+		t.Error("Check() = false, want the command to run in dir with TSM_SESSION set")
+	}
+	if Check("exit 1", dir, "api", time.Second) {
+		t.Error("Check() = true for a failing command")
+	}
+
+	start := time.Now()
+	if Check("sleep 5", dir, "api", 50*time.Millisecond) {
+		t.Error("Check() = true for a command exceeding the timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Check() took %s, want it killed at the timeout", elapsed)
+	}
+}
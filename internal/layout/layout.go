@@ -0,0 +1,172 @@
+// Package layout runs layout scripts, which set up the windows of a new
+// session. A layout named "ide" is the script ide.sh in the layout directory;
+// it gets the session name and working directory as arguments and as
+// TMUX_SESSION / TMUX_WORKING_DIR, plus the repository's TSM_ORG, TSM_REPO
+// and TSM_BRANCH. Comments in the script declare variables to ask for
+// ("# tsm-var: PORT=3000") and the command the first window runs
+// ("# tsm-command: nvim").
+package layout
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/nikbrunner/tsm/internal/gitinfo"
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// Var is a variable a layout script asks for when creating a session
+type Var struct {
+	Name    string
+	Default string
+	Prompt  string
+}
+
+// varRe matches declarations like "# tsm-var: PORT=3000 Dev server port"
+var varRe = regexp.MustCompile(`^#\s*tsm-var:\s*([A-Za-z_][A-Za-z0-9_]*)(?:=(\S*))?\s*(.*)$`)
+
+// ParseVars returns the variables declared in a layout script
+func ParseVars(script string) []Var {
+	var vars []Var
+	for _, line := range strings.Split(script, "\n") {
+		match := varRe.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		v := Var{Name: match[1], Default: match[2], Prompt: strings.TrimSpace(match[3])}
+		if v.Prompt == "" {
+			v.Prompt = v.Name
+		}
+		vars = append(vars, v)
+	}
+	return vars
+}
+
+// commandRe matches a declaration like "# tsm-command: nvim"
+var commandRe = regexp.MustCompile(`^#\s*tsm-command:\s*(.+)$`)
+
+// ParseCommand returns the command a layout script declares for the first
+// window of its sessions, empty when it declares none
+func ParseCommand(script string) string {
+	for _, line := range strings.Split(script, "\n") {
+		if match := commandRe.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			return strings.TrimSpace(match[1])
+		}
+	}
+	return ""
+}
+
+// Path returns the script of a layout
+func Path(dir, name string) string {
+	return filepath.Join(dir, name+".sh")
+}
+
+// Exists reports whether a layout has a script
+func Exists(dir, name string) bool {
+	if name == "" {
+		return false
+	}
+	_, err := os.Stat(Path(dir, name))
+	return err == nil
+}
+
+// Vars returns the variables declared by a layout, none if it has no script
+func Vars(dir, name string) []Var {
+	if name == "" {
+		return nil
+	}
+	content, err := os.ReadFile(Path(dir, name))
+	if err != nil {
+		return nil
+	}
+	return ParseVars(string(content))
+}
+
+// Command returns the command declared by a layout (see ParseCommand), empty
+// if it has no script
+func Command(dir, name string) string {
+	if name == "" {
+		return ""
+	}
+	content, err := os.ReadFile(Path(dir, name))
+	if err != nil {
+		return ""
+	}
+	return ParseCommand(string(content))
+}
+
+// Env returns the NAME=value pairs for a layout's variables, taking values
+// from the given map and falling back to the declared defaults. Unknown names
+// and required variables without a value are errors.
+func Env(vars []Var, values map[string]string) ([]string, error) {
+	declared := make(map[string]bool, len(vars))
+	env := make([]string, 0, len(vars))
+	for _, v := range vars {
+		declared[v.Name] = true
+		value := values[v.Name]
+		if value == "" {
+			value = v.Default
+		}
+		if value == "" {
+			return nil, fmt.Errorf("%s is required (%s)", v.Name, v.Prompt)
+		}
+		env = append(env, v.Name+"="+value)
+	}
+	for name := range values {
+		if !declared[name] {
+			return nil, fmt.Errorf("the layout declares no variable %s", name)
+		}
+	}
+	return env, nil
+}
+
+// Apply runs a layout's script for a new session and waits for it. Layouts
+// without a script are skipped; env is added to the script's environment,
+// along with TSM_ORG, TSM_REPO and TSM_BRANCH of the working directory.
+// A failing script's error carries the last line it wrote to stderr.
+func Apply(dir, name, session, workingDir string, env []string) error {
+	if !Exists(dir, name) {
+		return nil
+	}
+
+	var stderr bytes.Buffer
+	cmd := command(dir, name, session, workingDir, env)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if lines := strings.Split(strings.TrimSpace(stderr.String()), "\n"); lines[len(lines)-1] != "" {
+			return fmt.Errorf("%w: %s", err, lines[len(lines)-1])
+		}
+		return err
+	}
+	return nil
+}
+
+// Start runs a layout's script like Apply, without waiting for it
+func Start(dir, name, session, workingDir string, env []string) error {
+	if !Exists(dir, name) {
+		return nil
+	}
+	return command(dir, name, session, workingDir, env).Start()
+}
+
+// command builds the invocation of a layout's script. Its tmux calls reach
+// the server the session was created on: when the picker targets another
+// server than the one in $TMUX, TMUX points the script at that socket.
+func command(dir, name, session, workingDir string, env []string) *exec.Cmd {
+	cmd := exec.Command(Path(dir, name), session, workingDir)
+	cmd.Env = append(os.Environ(),
+		"TMUX_SESSION="+session,
+		"TMUX_WORKING_DIR="+workingDir,
+	)
+	if socket := tmux.Socket(); socket != "" {
+		cmd.Env = append(cmd.Env, "TMUX="+socket+",0,0")
+	}
+	cmd.Env = append(cmd.Env, gitinfo.Read(workingDir).Env()...)
+	cmd.Env = append(cmd.Env, env...)
+	return cmd
+}
@@ -0,0 +1,105 @@
+package layout
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+func TestParseVars(t *testing.T) {
+	script := `#!/usr/bin/env bash
+# tsm-var: PORT=3000 Dev server port
+#tsm-var: BRANCH
+# tsm-var: 9BAD not a name
+echo "$PORT"
+`
+	vars := ParseVars(script)
+	if len(vars) != 2 {
+		t.Fatalf("ParseVars() = %+v, want PORT and BRANCH", vars)
+	}
+	if vars[0] != (Var{Name: "PORT", Default: "3000", Prompt: "Dev server port"}) {
+		t.Errorf("vars[0] = %+v", vars[0])
+	}
+	if vars[1] != (Var{Name: "BRANCH", Prompt: "BRANCH"}) {
+		t.Errorf("vars[1] = %+v", vars[1])
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	script := `#!/usr/bin/env bash
+# tsm-var: PORT=3000 Dev server port
+#  tsm-command:  nvim -c 'Telescope find_files'
+tmux new-window -t "$1"
+`
+	if got := ParseCommand(script); got != "nvim -c 'Telescope find_files'" {
+		t.Errorf("ParseCommand() = %q, want the declared command", got)
+	}
+	if got := ParseCommand("#!/bin/sh\n"); got != "" {
+		t.Errorf("ParseCommand() = %q, want none declared", got)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	vars := []Var{{Name: "PORT", Default: "3000", Prompt: "Port"}, {Name: "BRANCH", Prompt: "Branch"}}
+
+	env, err := Env(vars, map[string]string{"BRANCH": "main"})
+	if err != nil {
+		t.Fatalf("Env() error = %v", err)
+	}
+	if got := strings.Join(env, " "); got != "PORT=3000 BRANCH=main" {
+		t.Errorf("Env() = %q, want the default PORT and the given BRANCH", got)
+	}
+
+	if _, err := Env(vars, nil); err == nil || !strings.Contains(err.Error(), "BRANCH is required") {
+		t.Errorf("Env() error = %v, want BRANCH required", err)
+	}
+	if _, err := Env(vars, map[string]string{"BRANCH": "main", "HOST": "x"}); err == nil {
+		t.Error("Env() should refuse variables the layout doesn't declare")
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	script := "#!/bin/sh\necho \"$1 $2 $TMUX_SESSION $PORT\" > " + out + "\n"
+	if err := os.WriteFile(Path(dir, "ide"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Apply(dir, "ide", "api", "/work/api", []string{"PORT=4000"}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	got, _ := os.ReadFile(out)
+	if want := "api /work/api api 4000\n"; string(got) != want {
+		t.Errorf("script saw %q, want %q", got, want)
+	}
+
+	// On another server than the picker's own, the script's tmux calls go there
+	t.Cleanup(func() { tmux.SetSocket("") })
+	tmux.SetSocket("/tmp/work.sock")
+	tmuxScript := "#!/bin/sh\necho \"$TMUX\" > " + out + "\n"
+	if err := os.WriteFile(Path(dir, "remote"), []byte(tmuxScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(dir, "remote", "api", "/work/api", nil); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if got, _ := os.ReadFile(out); !strings.HasPrefix(string(got), "/tmp/work.sock,") {
+		t.Errorf("script saw TMUX=%q, want the targeted socket", got)
+	}
+
+	if err := Apply(dir, "missing", "api", "/work/api", nil); err != nil {
+		t.Errorf("Apply() of a layout without script = %v, want nil", err)
+	}
+
+	failing := "#!/bin/sh\necho starting >&2\necho no such window >&2\nexit 1\n"
+	if err := os.WriteFile(Path(dir, "broken"), []byte(failing), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(dir, "broken", "api", "/work/api", nil); err == nil || !strings.HasSuffix(err.Error(), ": no such window") {
+		t.Errorf("Apply() of a failing script = %v, want its last stderr line", err)
+	}
+}
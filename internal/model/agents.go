@@ -0,0 +1,59 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// agentRanks orders the agents view: sessions waiting for input first, then
+// working ones, then fresh ones
+var agentRanks = map[string]int{"waiting": 0, "working": 1, "new": 2}
+
+// toggleAgentsView lists only the sessions running Claude Code (M-W), the
+// ones waiting for input first, or the full list again
+func (m *Model) toggleAgentsView() (tea.Model, tea.Cmd) {
+	if !m.config.ClaudeStatusEnabled {
+		m.setError("Claude status is off (claude_status_enabled = true)")
+		return m, nil
+	}
+
+	m.agentsView = !m.agentsView
+	m.cursor = 0
+	m.rebuildItems()
+	if m.agentsView && len(m.items) == 0 {
+		m.setInfo("No session runs Claude Code")
+	}
+	return m, nil
+}
+
+// agentSessions keeps the sessions with a Claude status, ordered waiting >
+// working > new and, within a state, the longest in it first
+func (m Model) agentSessions(indices []int) []int {
+	var agents []int
+	for _, i := range indices {
+		if _, ok := agentRanks[m.claudeStatuses[m.sessions[i].Name].State]; ok {
+			agents = append(agents, i)
+		}
+	}
+	slices.SortStableFunc(agents, func(a, b int) int {
+		sa, sb := m.claudeStatuses[m.sessions[a].Name], m.claudeStatuses[m.sessions[b].Name]
+		if ra, rb := agentRanks[sa.State], agentRanks[sb.State]; ra != rb {
+			return ra - rb
+		}
+		return sa.Timestamp.Compare(sb.Timestamp)
+	})
+	return agents
+}
+
+// agentDuration returns how long a session's Claude has been in its state,
+// e.g. "waiting 12m", for the agents view
+func (m Model) agentDuration(name string) string {
+	status, ok := m.claudeStatuses[name]
+	if !ok || status.Timestamp.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%s %s", status.State, strings.TrimSuffix(formatTimeAgo(status.Timestamp), " ago"))
+}
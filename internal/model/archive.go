@@ -0,0 +1,166 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/tmux"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// loadArchived reads the archived sessions. Like the history, the archive
+// belongs to the default server.
+func (m Model) loadArchived() []state.ArchivedSession {
+	if m.serverIdx != 0 {
+		return nil
+	}
+	a, err := state.LoadArchive(m.config.StateDir)
+	if err != nil {
+		return nil
+	}
+	return a.Sessions
+}
+
+// recentName returns the session name of a recent or archived item
+func (m Model) recentName(item Item) string {
+	if item.Archived {
+		return m.archived[item.RecentIndex].Name
+	}
+	return m.recent[item.RecentIndex].Name
+}
+
+// archiveCurrent snapshots the selected session's windows, panes and working
+// directories into the archive, then kills it. A window stands for its
+// session.
+func (m *Model) archiveCurrent() (tea.Model, tea.Cmd) {
+	item, ok := m.selectedItem()
+	if !ok || item.IsRecent {
+		return m, nil
+	}
+	if m.serverIdx != 0 {
+		m.setError("Archiving works on the default server only")
+		return m, nil
+	}
+
+	session := m.sessions[item.SessionIndex]
+	windows, err := tmux.SnapshotSession(session.Name)
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+
+	entry := state.ArchivedSession{
+		Name:       session.Name,
+		Path:       session.Path,
+		Windows:    windows,
+		ArchivedAt: time.Now(),
+	}
+	if h, err := state.LoadHistory(m.config.StateDir); err == nil {
+		if remembered, ok := h.Get(session.Name); ok {
+			entry.Layout = remembered.Layout
+		}
+	}
+
+	err = state.UpdateArchive(m.config.StateDir, func(a *state.Archive) error {
+		a.Add(entry)
+		return nil
+	})
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+
+	// Only kill once the snapshot is safely stored
+	if err := tmux.KillSessionSafe(session.Name); err != nil {
+		m.setError("Archived but failed to kill: %v", err)
+		return m, m.loadSessions
+	}
+
+	m.setInfo("Archived \"%s\" (%s)", session.Name, pluralize(len(windows), "window"))
+	return m, m.loadSessions
+}
+
+// restoreArchived recreates an archived session's windows and panes, drops
+// it from the archive and switches to it
+func (m *Model) restoreArchived(entry state.ArchivedSession) (tea.Model, tea.Cmd) {
+	if m.sessionLimitReached() {
+		return m.confirmEvict(func(m *Model) (tea.Model, tea.Cmd) { return m.restoreArchived(entry) })
+	}
+
+	// Directories that are gone fall back rather than failing the restore
+	windows := state.RestorableWindows(entry.Windows, m.config.DefaultSessionDir)
+	if err := tmux.RestoreSession(entry.Name, windows); err != nil {
+		m.setError("Error: %v", err)
+		return m, m.loadSessions
+	}
+
+	err := state.UpdateArchive(m.config.StateDir, func(a *state.Archive) error {
+		a.Remove(entry.Name)
+		return nil
+	})
+	if err != nil {
+		m.setError("Restored but failed to update the archive: %v", err)
+		return m, m.loadSessions
+	}
+	m.rememberSession(entry.Name, entry.Path, entry.Layout)
+
+	if err := switchClient(entry.Name); err != nil {
+		m.setError("Restored but failed to switch: %v", err)
+		return m, m.loadSessions
+	}
+	m.recordSwitch(entry.Name)
+
+	return m, tea.Quit
+}
+
+// deleteArchived drops a session's snapshot from the archive
+func (m *Model) deleteArchived(name string) (tea.Model, tea.Cmd) {
+	err := state.UpdateArchive(m.config.StateDir, func(a *state.Archive) error {
+		a.Remove(name)
+		return nil
+	})
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+
+	m.setInfo("Deleted archived \"%s\"", name)
+	return m, m.loadSessions
+}
+
+// renderArchived renders an archived session row
+func (m Model) renderArchived(entry state.ArchivedSession, selected bool) string {
+	var b strings.Builder
+
+	// No number label: archived sessions can't be jumped to
+	b.WriteString(ui.IndexStyle.Render(""))
+	b.WriteString(" ")
+	b.WriteString(ui.ArchiveIcon)
+	b.WriteString("   ")
+
+	namePadded := fmt.Sprintf("%-*s", m.maxNameWidth, entry.Name)
+	if selected {
+		b.WriteString(ui.SessionNameSelectedStyle.Render(namePadded))
+	} else {
+		b.WriteString(ui.RecentNameStyle.Render(namePadded))
+	}
+	if m.showsColumn(config.ColumnTime) {
+		b.WriteString("  ")
+		b.WriteString(ui.TimeStyle.Render(fmt.Sprintf("%-8s", formatTimeAgo(entry.ArchivedAt))))
+	}
+	b.WriteString("  ")
+	b.WriteString(ui.TimeStyle.Render(pluralize(len(entry.Windows), "window")))
+
+	return ui.SessionStyle.Render(b.String())
+}
+
+// renderArchivedPlain renders an archived session row as plain text
+func (m Model) renderArchivedPlain(entry state.ArchivedSession, selected bool) string {
+	return fmt.Sprintf("%sarchived: %s, %s, archived %s", plainCursor(selected), entry.Name,
+		pluralize(len(entry.Windows), "window"), formatTimeAgo(entry.ArchivedAt))
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// startBreakOut opens the name prompt for a new session the selected window
+// moves into (M-b), the reverse of a merge. The name defaults to the
+// window's.
+func (m *Model) startBreakOut() (tea.Model, tea.Cmd) {
+	item, ok := m.selectedItem()
+	if !ok || item.IsRecent || item.IsSession || item.IsPane {
+		m.setError("Select a window to break out (expand a session with C-l)")
+		return m, nil
+	}
+	session := m.sessions[item.SessionIndex]
+	window := session.Windows[item.WindowIndex]
+	label := m.displayName(item)
+
+	model, cmd := m.startCreate("", sanitizeSessionName(window.Name))
+	m.breakOutWindow = window.Target(session.Name)
+	m.breakOutLabel = label
+	return model, cmd
+}
+
+// breakOut moves the window picked by startBreakOut into a new session,
+// started in the directory of the window's active pane
+func (m *Model) breakOut(name string, background bool) (tea.Model, tea.Cmd) {
+	if name == m.currentSession || slices.Contains(m.sessionNames(), name) {
+		m.setError("\"%s\" already exists", name)
+		return m, nil
+	}
+
+	dir, err := tmux.PanePath(m.breakOutWindow)
+	if err != nil || dir == "" {
+		dir = m.config.DefaultSessionDir
+	}
+	if err := tmux.BreakWindow(m.breakOutWindow, name, dir); err != nil {
+		m.setError("Error: %v", err)
+		m.mode = ModeNormal
+		m.input.Blur()
+		return m, m.loadSessions
+	}
+
+	m.setInfo("Broke %s out into \"%s\"", m.breakOutLabel, name)
+	return m.openCreated(name, dir, "", background)
+}
@@ -0,0 +1,116 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// shells are the commands a broadcast types into; other programs (editors,
+// servers) would take the keys as input
+var shells = []string{"bash", "zsh", "fish", "sh", "dash", "ksh", "mksh", "tcsh", "csh", "nu", "elvish", "xonsh", "pwsh"}
+
+// isShell reports whether a pane's current command is an interactive shell
+func isShell(command string) bool {
+	return slices.Contains(shells, strings.TrimPrefix(command, "-"))
+}
+
+// confirmBroadcast asks to send a command line to the active pane of every
+// marked session (and marked window), e.g. `git pull` in many checkouts
+// (:send git pull)
+func (m *Model) confirmBroadcast(args []string) (tea.Model, tea.Cmd) {
+	command := strings.TrimSpace(strings.Join(args, " "))
+	if command == "" {
+		m.setError("Usage: :send <command>")
+		return m, nil
+	}
+	sessions, windows := m.markedTargets()
+	targets := sessions
+	for _, w := range windows {
+		targets = append(targets, w.target)
+	}
+	if len(targets) == 0 {
+		m.setError("Mark the sessions to send to (tab)")
+		return m, nil
+	}
+
+	m.broadcastCommand = command
+	m.broadcastTargets = targets
+	m.killPreview = targets
+	m.prompt = fmt.Sprintf("Run \"%s\" in %s?", command, pluralize(len(targets), "marked item"))
+	m.mode = ModeConfirmBroadcast
+	return m, nil
+}
+
+func (m *Model) handleConfirmBroadcastMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+
+	switch {
+	case key.Matches(msg, keys.Confirm):
+		return m.broadcast()
+	case key.Matches(msg, keys.Cancel):
+		m.resetBroadcast()
+	}
+	return m, nil
+}
+
+// broadcast sends the confirmed command line and reports, per target,
+// whether it was sent. Panes not sitting at a shell prompt are skipped.
+func (m *Model) broadcast() (tea.Model, tea.Cmd) {
+	command, targets := m.broadcastCommand, m.broadcastTargets
+	m.resetBroadcast()
+
+	report := make([]string, 0, len(targets)+1)
+	sent := 0
+	for _, target := range targets {
+		if err := sendToShell(target, command); err != nil {
+			report = append(report, fmt.Sprintf("✗ %s: %v", target, err))
+			continue
+		}
+		report = append(report, "✓ "+target)
+		sent++
+	}
+
+	m.clearMarks()
+	m.broadcastReport = append([]string{fmt.Sprintf("Sent \"%s\" to %d of %d", command, sent, len(targets))}, report...)
+	m.mode = ModeBroadcastReport
+	return m, nil
+}
+
+// sendToShell types command into the active pane of target, refusing panes
+// running anything but a shell
+func sendToShell(target, command string) error {
+	running, err := tmux.PaneCommand(target)
+	if err != nil {
+		return err
+	}
+	if !isShell(running) {
+		return fmt.Errorf("pane runs %s", running)
+	}
+	return tmux.SendCommand(target, command)
+}
+
+func (m *Model) handleBroadcastReportMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+
+	if key.Matches(msg, keys.Cancel) || key.Matches(msg, keys.Select) {
+		m.mode = ModeNormal
+		m.broadcastReport = nil
+	}
+	return m, nil
+}
+
+// resetBroadcast leaves the broadcast confirmation
+func (m *Model) resetBroadcast() {
+	m.mode = ModeNormal
+	m.prompt = ""
+	m.broadcastCommand = ""
+	m.broadcastTargets = nil
+	m.killPreview = nil
+}
@@ -0,0 +1,148 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// openClientTarget switches another attached client (e.g. on a second
+// monitor) to the selected session or window. With several other clients,
+// the client is picked from a list first.
+func (m *Model) openClientTarget() (tea.Model, tea.Cmd) {
+	item, ok := m.selectedItem()
+	if !ok || item.IsRecent {
+		return m, nil
+	}
+
+	clients, err := tmux.ListClients()
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+	own, _ := tmux.CurrentClient()
+	var others []tmux.Client
+	for _, c := range clients {
+		if c.Name != own {
+			others = append(others, c)
+		}
+	}
+
+	m.clientTarget = m.getTargetName(item)
+	m.clientLabel = m.sessions[item.SessionIndex].Name
+	if !item.IsSession {
+		m.clientLabel = fmt.Sprintf("%s:%d", m.clientLabel, m.sessions[item.SessionIndex].Windows[item.WindowIndex].Index)
+	}
+
+	switch len(others) {
+	case 0:
+		m.setError("No other clients attached")
+		return m, nil
+	case 1:
+		return m.switchClientTo(others[0])
+	}
+
+	m.clients = others
+	m.clientCursor = 0
+	m.mode = ModeClientTarget
+	return m, tea.WindowSize()
+}
+
+func (m *Model) handleClientTargetMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+
+	switch {
+	case key.Matches(msg, keys.Cancel):
+		m.mode = ModeNormal
+		return m, nil
+
+	case key.Matches(msg, keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, keys.Up):
+		if m.clientCursor > 0 {
+			m.clientCursor--
+		}
+
+	case key.Matches(msg, keys.Down):
+		if m.clientCursor < len(m.clients)-1 {
+			m.clientCursor++
+		}
+
+	case key.Matches(msg, keys.Select):
+		if m.clientCursor < len(m.clients) {
+			return m.switchClientTo(m.clients[m.clientCursor])
+		}
+	}
+
+	return m, nil
+}
+
+// switchClientTo switches client to the chosen target. The picker stays open
+// since its own client doesn't move.
+func (m *Model) switchClientTo(client tmux.Client) (tea.Model, tea.Cmd) {
+	m.mode = ModeNormal
+	if err := tmux.SwitchClientFor(client.Name, m.clientTarget); err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+
+	m.setInfo("Switched %s to \"%s\"", client.Name, m.clientLabel)
+	return m, nil
+}
+
+// viewClientTarget renders the client picker
+func (m Model) viewClientTarget() string {
+	var b strings.Builder
+	usedLines := 0
+
+	b.WriteString(ui.HeaderStyle.Render(fmt.Sprintf("Switch which client to \"%s\"", m.clientLabel)))
+	b.WriteString("\n")
+	usedLines++
+
+	b.WriteString(ui.RenderBorder(m.borderWidth()))
+	b.WriteString("\n")
+	usedLines++
+
+	maxItems := m.projectMaxVisibleItems()
+	offset := max(m.clientCursor-maxItems+1, 0)
+	endIdx := min(offset+maxItems, len(m.clients))
+
+	for i := offset; i < endIdx; i++ {
+		c := m.clients[i]
+		selected := i == m.clientCursor
+		name := c.Name
+		if selected && !ui.Plain {
+			name = ui.FilterStyle.Render(name)
+		}
+		if ui.Plain {
+			b.WriteString(plainCursor(selected))
+		} else {
+			b.WriteString("  ")
+		}
+		fmt.Fprintf(&b, "%s  %s  %s", name, c.Session, ui.TimeStyle.Render(c.Size))
+		b.WriteString("\n")
+		usedLines++
+	}
+
+	// Footer = border (1) + statusline (1) + help line (1) = 3 lines
+	footerLines := 3
+	if contentH := m.contentHeight(); contentH > 0 {
+		for i := 0; i < contentH-usedLines-footerLines; i++ {
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(ui.RenderBorder(m.borderWidth()))
+	b.WriteString("\n")
+	b.WriteString(ui.StatuslineStyle.Render(fmt.Sprintf("%d other clients attached", len(m.clients))))
+	b.WriteString("\n")
+	b.WriteString(ui.FooterStyle.Render(ui.HelpClientTarget()))
+
+	return ui.AppStyle.Render(b.String())
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// clientSizesMsg carries the clients much smaller than the picker's, keyed
+// by the session they are attached to
+type clientSizesMsg struct {
+	small map[string][]tmux.Client
+}
+
+// loadClientSizes returns a command finding the sessions attached from a
+// client much smaller than the picker's. tmux sizes a window to its smaller
+// clients (window-size smallest, or latest once the small one was active),
+// leaving the rest of a larger terminal filled with dots.
+func (m *Model) loadClientSizes() tea.Cmd {
+	return func() tea.Msg {
+		if size, err := tmux.GetOption("window-size"); err != nil || size == "largest" || size == "manual" {
+			return clientSizesMsg{}
+		}
+		clients, err := tmux.ListClients()
+		if err != nil {
+			return clientSizesMsg{}
+		}
+		own, err := tmux.CurrentClient()
+		if err != nil {
+			return clientSizesMsg{}
+		}
+		return clientSizesMsg{small: smallClients(clients, own)}
+	}
+}
+
+// smallClients picks the clients much smaller (by a quarter or more in
+// either direction) than the client named own, keyed by session
+func smallClients(clients []tmux.Client, own string) map[string][]tmux.Client {
+	var mine tmux.Client
+	for _, c := range clients {
+		if c.Name == own {
+			mine = c
+		}
+	}
+	if mine.Width == 0 || mine.Height == 0 {
+		return nil
+	}
+
+	small := make(map[string][]tmux.Client)
+	for _, c := range clients {
+		if c.Name != own && (c.Width*4 < mine.Width*3 || c.Height*4 < mine.Height*3) {
+			small[c.Session] = append(small[c.Session], c)
+		}
+	}
+	return small
+}
+
+// cursorClientHint offers to detach the small clients of the session under
+// the cursor, empty when it has none
+func (m Model) cursorClientHint() string {
+	clients := m.smallClients[m.cursorSessionName()]
+	if len(clients) == 0 {
+		return ""
+	}
+	sizes := make([]string, len(clients))
+	for i, c := range clients {
+		sizes[i] = c.Size
+	}
+	noun := "client"
+	if len(clients) > 1 {
+		noun = "clients"
+	}
+	return fmt.Sprintf("shrunk by %s %s · M-x detach", strings.Join(sizes, ", "), noun)
+}
+
+// detachSmallClients detaches the small clients of the session under the
+// cursor, so its windows grow back to the picker's terminal
+func (m *Model) detachSmallClients() (tea.Model, tea.Cmd) {
+	name := m.cursorSessionName()
+	clients := m.smallClients[name]
+	if len(clients) == 0 {
+		m.setError("No small clients attached to this session")
+		return m, nil
+	}
+
+	for _, c := range clients {
+		if err := tmux.DetachClient(c.Name); err != nil {
+			m.setError("Error: %v", err)
+			return m, m.loadClientSizes()
+		}
+	}
+	delete(m.smallClients, name)
+	m.setInfo("Detached %s from \"%s\"", pluralize(len(clients), "client"), name)
+	return m, m.loadClientSizes()
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// panesMsg carries the panes of every window, loaded for a ">cmd" filter
+type panesMsg struct {
+	server int // serverIdx the load started on
+	panes  map[string][]tmux.Pane
+}
+
+// hasCommandFilter reports whether the filter has a ">cmd" word
+func hasCommandFilter(filter string) bool {
+	_, commands, _ := parseFilter(filter)
+	return len(commands) > 0
+}
+
+// loadPanes fetches the panes once a ">cmd" filter is typed. They are kept
+// until the next session reload, so typing on doesn't list them again.
+func (m *Model) loadPanes() tea.Cmd {
+	if m.panes != nil || m.panesLoading || !hasCommandFilter(m.filter) {
+		return nil
+	}
+	m.panesLoading = true
+	server := m.serverIdx
+	return func() tea.Msg {
+		panes, _ := tmux.ListAllPanes()
+		if panes == nil {
+			panes = map[string][]tmux.Pane{}
+		}
+		return panesMsg{server: server, panes: panes}
+	}
+}
+
+// handlePanes stores the loaded panes and filters with them
+func (m *Model) handlePanes(msg panesMsg) {
+	m.panesLoading = false
+	if msg.server != m.serverIdx {
+		return
+	}
+	m.panes = msg.panes
+	m.rebuildItems()
+}
+
+// windowRunsCommands reports whether a pane of the window runs each of the
+// commands (substrings of the lowercased command name). Before the panes are
+// loaded the window's active pane stands in.
+func (m Model) windowRunsCommands(window tmux.Window, commands []string) bool {
+	running := []string{strings.ToLower(window.Command)}
+	if panes, ok := m.panes[window.ID]; ok {
+		running = running[:0]
+		for _, p := range panes {
+			running = append(running, strings.ToLower(p.Command))
+		}
+	}
+
+	for _, command := range commands {
+		found := false
+		for _, r := range running {
+			if strings.Contains(r, command) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sessionRunsCommands reports whether a window of the session runs the
+// commands, true without commands
+func (m Model) sessionRunsCommands(session tmux.Session, commands []string) bool {
+	if len(commands) == 0 {
+		return true
+	}
+	for _, window := range session.Windows {
+		if m.windowRunsCommands(window, commands) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// forbiddenNameChars are refused in session names: tmux rewrites them, since
+// they separate the session from the window and pane in targets
+const forbiddenNameChars = ".:"
+
+// slugSessionName turns free text (e.g. a pasted title) into a session name:
+// runs of spaces, slashes and forbidden characters become a single dash
+func slugSessionName(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.TrimSpace(text) {
+		switch {
+		case unicode.IsSpace(r) || r == '/' || strings.ContainsRune(forbiddenNameChars, r) || r == '-':
+			dash = b.Len() > 0
+		case unicode.IsControl(r):
+		default:
+			if dash {
+				b.WriteByte('-')
+				dash = false
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// sanitizeCreateKey cleans text typed or pasted into the session name
+// prompt. Pastes are slugified; forbidden characters typed one at a time are
+// dropped. Returns the key to pass on (false when nothing is left) and a hint
+// on what changed.
+func sanitizeCreateKey(msg tea.KeyMsg) (tea.KeyMsg, bool, string) {
+	if msg.Type != tea.KeyRunes && msg.Type != tea.KeySpace {
+		return msg, true, ""
+	}
+
+	text := string(msg.Runes)
+	if msg.Paste || len(msg.Runes) > 1 {
+		slug := slugSessionName(text)
+		hint := ""
+		if slug != text {
+			hint = "pasted text slugified"
+		}
+		if slug == "" {
+			return msg, false, hint
+		}
+		msg.Type = tea.KeyRunes
+		msg.Runes = []rune(slug)
+		return msg, true, hint
+	}
+
+	if strings.ContainsAny(text, forbiddenNameChars) {
+		return msg, false, fmt.Sprintf("'%s' isn't allowed in session names", text)
+	}
+	return msg, true, ""
+}
+
+// createStatus renders the name length against the input's limit, followed
+// by the hint on the last sanitized input
+func (m Model) createStatus() string {
+	if m.createHint == "" {
+		return m.createCounter()
+	}
+	if ui.Plain {
+		return m.createCounter() + " [" + m.createHint + "]"
+	}
+	return m.createCounter() + " " + ui.InputWarningStyle.Render(m.createHint)
+}
+
+// createCounter renders the name length against the input's limit
+func (m Model) createCounter() string {
+	counter := fmt.Sprintf("%d/%d", len([]rune(m.input.Value())), m.input.CharLimit)
+	if ui.Plain {
+		return " (" + counter + ")"
+	}
+	if len([]rune(m.input.Value())) >= m.input.CharLimit {
+		return " " + ui.InputWarningStyle.Render(counter)
+	}
+	return " " + ui.TimeStyle.Render(counter)
+}
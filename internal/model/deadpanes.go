@@ -0,0 +1,127 @@
+package model
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// deadPaneCount returns how many panes of a window exited but stay open
+// (remain-on-exit)
+func (m Model) deadPaneCount(window tmux.Window) int {
+	return len(m.deadPanes[window.ID])
+}
+
+// sessionDeadPanes returns how many dead panes a session's windows hold
+func (m Model) sessionDeadPanes(session tmux.Session) int {
+	n := 0
+	for _, w := range session.Windows {
+		n += m.deadPaneCount(w)
+	}
+	return n
+}
+
+// isDeadPane reports whether a pane of the tree view exited
+func (m Model) isDeadPane(pane tmux.Pane) bool {
+	for _, ids := range m.deadPanes {
+		if slices.Contains(ids, pane.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectedDeadPanes returns the dead panes of the selected session, window
+// or pane, or of every session with all
+func (m *Model) selectedDeadPanes(all bool) (panes []string, scope string) {
+	if all {
+		for _, ids := range m.deadPanes {
+			panes = append(panes, ids...)
+		}
+		return panes, "any session"
+	}
+
+	item, ok := m.selectedItem()
+	if !ok || item.IsRecent {
+		return nil, ""
+	}
+	session := m.sessions[item.SessionIndex]
+	switch {
+	case item.IsSession:
+		// Grouped sessions share windows, count each one once
+		seen := make(map[string]bool)
+		for _, w := range session.Windows {
+			if !seen[w.ID] {
+				seen[w.ID] = true
+				panes = append(panes, m.deadPanes[w.ID]...)
+			}
+		}
+	case item.IsPane:
+		if pane := m.itemPane(item); m.isDeadPane(pane) {
+			panes = []string{pane.ID}
+		}
+	default:
+		panes = m.deadPanes[session.Windows[item.WindowIndex].ID]
+	}
+	return panes, m.displayName(item)
+}
+
+// paletteRespawn restarts the dead panes of the selected item (:respawn), or
+// of every session (:respawn all)
+func (m *Model) paletteRespawn(args []string) (tea.Model, tea.Cmd) {
+	return m.handleDeadPanes(args, true)
+}
+
+// paletteReap closes the dead panes of the selected item (:reap), or of
+// every session (:reap all). tmux closes a window along with its last pane.
+func (m *Model) paletteReap(args []string) (tea.Model, tea.Cmd) {
+	return m.handleDeadPanes(args, false)
+}
+
+func (m *Model) handleDeadPanes(args []string, respawn bool) (tea.Model, tea.Cmd) {
+	usage := ":reap [all]"
+	if respawn {
+		usage = ":respawn [all]"
+	}
+	if len(args) > 1 || len(args) == 1 && args[0] != "all" {
+		m.setError("Usage: %s", usage)
+		return m, nil
+	}
+
+	panes, scope := m.selectedDeadPanes(len(args) == 1)
+	if scope == "" {
+		m.setError("Select a session or window")
+		return m, nil
+	}
+	if len(panes) == 0 {
+		m.setWarning("No dead panes in %s", scope)
+		return m, nil
+	}
+
+	done := 0
+	var err error
+	for _, pane := range panes {
+		if respawn {
+			err = tmux.RespawnPane(pane)
+		} else {
+			err = tmux.KillPane(pane)
+		}
+		if err != nil {
+			break
+		}
+		done++
+	}
+
+	switch {
+	case err != nil:
+		m.setError("Error after %s: %v", pluralize(done, "pane"), err)
+	case respawn:
+		m.setInfo("Respawned %s", pluralize(done, "pane"))
+	default:
+		m.setInfo("Closed %s", pluralize(done, "dead pane"))
+	}
+	// Closing panes can take windows and sessions along, reload everything
+	return m, m.loadSessions
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+type configEditedMsg struct {
+	err error
+}
+
+// isEmptyState reports whether sessions were loaded and there are none to pick
+func (m *Model) isEmptyState() bool {
+	return m.sessionsLoaded && len(m.sessions) == 0 && m.filter == ""
+}
+
+// emptyActionEnabled reports whether an empty-state action is configured and
+// usable right now
+func (m *Model) emptyActionEnabled(action string) bool {
+	if m.config.ReadOnly || !m.isEmptyState() || !slices.Contains(m.config.EmptyActions, action) {
+		return false
+	}
+	if action == config.EmptyActionRestore {
+		return len(m.recent) > 0
+	}
+	return true
+}
+
+// emptyStateLines renders the empty-state menu of configured actions
+func (m Model) emptyStateLines() []string {
+	keys := ui.DefaultKeyMap
+	lines := []string{"  No other sessions"}
+
+	for _, action := range m.config.EmptyActions {
+		if !m.emptyActionEnabled(action) {
+			continue
+		}
+
+		var binding key.Binding
+		var desc string
+		switch action {
+		case config.EmptyActionProjects:
+			binding, desc = keys.PickDirectory, "Open a project"
+		case config.EmptyActionNew:
+			binding, desc = keys.Create, "New session"
+		case config.EmptyActionRestore:
+			binding, desc = keys.Restore, fmt.Sprintf("Restore \"%s\"", m.recent[0].Name)
+		case config.EmptyActionConfig:
+			binding, desc = keys.EditConfig, "Edit config"
+		}
+		lines = append(lines, "  "+ui.HelpKeyStyle.Render(fmt.Sprintf("%-4s", binding.Help().Key))+" "+desc)
+	}
+
+	return lines
+}
+
+// editConfig opens the config file in $EDITOR, creating it first if needed
+func (m *Model) editConfig() (tea.Model, tea.Cmd) {
+	path := config.Path()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := config.Init(); err != nil {
+			m.setError("Error: %v", err)
+			return m, nil
+		}
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return configEditedMsg{err: err}
+	})
+}
+
+// handleConfigEdited reports the result of editing the config file
+func (m *Model) handleConfigEdited(msg configEditedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.setError("Error running editor: %v", msg.err)
+		return nil
+	}
+	m.setInfo("Config saved. Restart tsm to apply changes.")
+	return nil
+}
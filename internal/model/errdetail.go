@@ -0,0 +1,66 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// recordTmuxError keeps the failed tmux command behind an error message, so
+// M-e can show its details. Reports whether there was one.
+func (m *Model) recordTmuxError(args []any) bool {
+	for _, arg := range args {
+		err, ok := arg.(error)
+		if !ok {
+			continue
+		}
+		var cmdErr *tmux.CommandError
+		if errors.As(err, &cmdErr) {
+			m.tmuxError = cmdErr
+			return true
+		}
+	}
+	return false
+}
+
+// openErrorDetail shows the last failed tmux command
+func (m *Model) openErrorDetail() (tea.Model, tea.Cmd) {
+	if m.tmuxError == nil {
+		m.setInfo("No tmux errors")
+		return m, nil
+	}
+	m.mode = ModeErrorDetail
+	return m, nil
+}
+
+func (m *Model) handleErrorDetailMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+
+	if key.Matches(msg, keys.Cancel) || key.Matches(msg, keys.ErrorDetail) {
+		m.mode = ModeNormal
+	}
+	return m, nil
+}
+
+// errorDetailLines describes the last failed tmux command
+func (m *Model) errorDetailLines() []string {
+	err := m.tmuxError
+	lines := []string{
+		"Command:   " + err.Command(),
+		fmt.Sprintf("Exit code: %d", err.ExitCode),
+	}
+	if err.Stderr == "" {
+		return append(lines, "Stderr:    (empty)")
+	}
+	lines = append(lines, "Stderr:")
+	for _, line := range strings.Split(err.Stderr, "\n") {
+		lines = append(lines, "  "+line)
+	}
+	return lines
+}
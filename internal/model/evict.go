@@ -0,0 +1,104 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/tmux"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// createFn continues creating a session after a prompt (eviction, layout variables)
+type createFn func(*Model) (tea.Model, tea.Cmd)
+
+// sessionLimitReached reports whether creating a session would exceed max_sessions
+func (m *Model) sessionLimitReached() bool {
+	if m.config.MaxSessions <= 0 {
+		return false
+	}
+	count := len(m.sessions)
+	if m.listedExclusion() != "" {
+		count++ // The list excludes the current session
+	}
+	return count >= m.config.MaxSessions
+}
+
+// leastRecentlyUsed returns the listed session with the oldest activity.
+// The current session is never evicted, even when it is listed.
+func (m *Model) leastRecentlyUsed() (tmux.Session, bool) {
+	var lru tmux.Session
+	found := false
+	for _, s := range m.sessions {
+		if s.Name == m.currentSession {
+			continue
+		}
+		if !found || s.LastActivity.Before(lru.LastActivity) {
+			lru = s
+			found = true
+		}
+	}
+	return lru, found
+}
+
+// confirmEvict asks to kill the least recently used session to make room for
+// a new one. then creates the session once the victim is gone.
+func (m *Model) confirmEvict(then createFn) (tea.Model, tea.Cmd) {
+	m.input.Blur()
+
+	victim, ok := m.leastRecentlyUsed()
+	if !ok {
+		m.mode = ModeNormal
+		m.setError("Session limit (%d) reached", m.config.MaxSessions)
+		return m, nil
+	}
+
+	m.evictVictim = victim.Name
+	m.evictThen = then
+	m.killPreview = killPreviewForWindows(victim.Windows)
+	m.prompt = fmt.Sprintf("Session limit (%d) reached. Kill least recently used \"%s\"? (%s)",
+		m.config.MaxSessions, victim.Name, pluralize(len(victim.Windows), "window"))
+	m.mode = ModeConfirmEvict
+	return m, nil
+}
+
+func (m *Model) handleConfirmEvictMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+
+	switch {
+	case key.Matches(msg, keys.Kill):
+		victim, then := m.evictVictim, m.evictThen
+		m.resetEvict()
+		if err := tmux.KillSessionSafe(victim); err != nil {
+			m.setError("Error: %v", err)
+			return m, m.loadSessions
+		}
+		m.logEvent(state.EventKill, victim)
+		// Drop the victim right away so the limit check passes before the reload
+		m.removeSession(victim)
+		return then(m)
+
+	case key.Matches(msg, keys.Cancel):
+		m.resetEvict()
+	}
+
+	return m, nil
+}
+
+// resetEvict leaves eviction confirmation
+func (m *Model) resetEvict() {
+	m.mode = ModeNormal
+	m.prompt = ""
+	m.evictVictim = ""
+	m.evictThen = nil
+	m.killPreview = nil
+}
+
+// removeSession drops a killed session from the list
+func (m *Model) removeSession(name string) {
+	m.sessions = slices.DeleteFunc(m.sessions, func(s tmux.Session) bool { return s.Name == name })
+	m.rebuildItems()
+}
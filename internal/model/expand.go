@@ -0,0 +1,60 @@
+package model
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ExpandOnStart opens the picker with the named session's windows shown and
+// the cursor on it, or with every session expanded when all is set. Naming the
+// current session lists it too, so the picker can replace tmux's window
+// chooser (prefix+w) for the session it was opened from.
+func (m *Model) ExpandOnStart(session string, all bool) {
+	m.startExpand = session
+	m.startExpandAll = all
+	m.listCurrent = session != "" && session == m.currentSession
+}
+
+// listedExclusion returns the session left out of the list: the current one,
+// unless ExpandOnStart asked for it
+func (m *Model) listedExclusion() string {
+	if m.listCurrent {
+		return ""
+	}
+	return m.currentSession
+}
+
+// applyStartExpand expands the sessions requested by ExpandOnStart once the
+// first session list arrived, returning the command that loads their window
+// summaries
+func (m *Model) applyStartExpand() tea.Cmd {
+	name, all := m.startExpand, m.startExpandAll
+	m.startExpand, m.startExpandAll = "", false
+	if name == "" && !all {
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	for i := range m.sessions {
+		session := &m.sessions[i]
+		if all || session.Name == name {
+			session.Expanded = true
+			cmds = append(cmds, loadWindowSummaries(session.Name, session.Windows))
+		}
+	}
+	m.rebuildItems()
+
+	if name != "" {
+		found := false
+		for i, item := range m.items {
+			if item.IsSession && m.sessions[item.SessionIndex].Name == name {
+				m.cursor = i
+				found = true
+				break
+			}
+		}
+		if !found {
+			m.setError("No session \"%s\" to expand", name)
+		}
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/state"
+)
+
+type filterHistoryMsg struct {
+	filters []string
+}
+
+// loadFilterHistory reads previously used filter strings from the state directory
+func (m Model) loadFilterHistory() tea.Msg {
+	filters, err := state.LoadFilterHistory(m.config.StateDir)
+	if err != nil {
+		return nil
+	}
+	return filterHistoryMsg{filters: filters}
+}
+
+// recordFilter remembers the active filter for later recall
+func (m *Model) recordFilter() {
+	if m.filter == "" {
+		return
+	}
+	_ = state.RecordFilter(m.config.StateDir, m.filter)
+}
+
+// filterHistoryMatches returns remembered filters that extend the typed prefix,
+// most recent first
+func (m *Model) filterHistoryMatches() []string {
+	var matches []string
+	for _, f := range m.filterHistory {
+		if f != m.historyPrefix && strings.HasPrefix(f, m.historyPrefix) {
+			matches = append(matches, f)
+		}
+	}
+	return matches
+}
+
+// cycleFilterHistory replaces the filter with an older (delta > 0) or newer
+// (delta < 0) remembered filter starting with what was typed. Cycling past the
+// newest entry restores the typed text.
+func (m *Model) cycleFilterHistory(delta int) {
+	if m.historyIdx < 0 {
+		m.historyPrefix = m.filter
+		m.rememberPreFilterSession()
+	}
+
+	matches := m.filterHistoryMatches()
+	idx := m.historyIdx + delta
+	if idx >= len(matches) {
+		return
+	}
+
+	m.historyIdx = max(idx, -1)
+	if m.historyIdx < 0 {
+		m.filter = m.historyPrefix
+	} else {
+		m.filter = matches[m.historyIdx]
+	}
+	m.rebuildItems()
+}
+
+// resetFilterHistory ends history cycling after the filter was edited
+func (m *Model) resetFilterHistory() {
+	m.historyIdx = -1
+	m.historyPrefix = ""
+}
+
+// rememberPreFilterSession notes the session under the cursor before the
+// first character of a filter, for clearFilter to return to
+func (m *Model) rememberPreFilterSession() {
+	if m.filter == "" {
+		m.preFilterSession = m.cursorSessionName()
+	}
+}
+
+// clearFilter empties the filter (esc) and puts the cursor back on the
+// session it was on before filtering, so browsing the matches and clearing
+// doesn't lose the place. The cursor stays where it is when that session is
+// gone.
+func (m *Model) clearFilter() {
+	name := m.preFilterSession
+	m.filter = ""
+	m.preFilterSession = ""
+	m.resetFilterHistory()
+	m.rebuildItems()
+
+	if name == "" {
+		return
+	}
+	for i, item := range m.items {
+		if item.IsSession && m.sessions[item.SessionIndex].Name == name {
+			m.cursor = i
+			m.updateScrollOffset()
+			return
+		}
+	}
+}
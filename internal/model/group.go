@@ -0,0 +1,143 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// startGrouped opens the name prompt for a session grouped with the one
+// under the cursor (new-session -t): a mirror sharing its windows, with its
+// own current window, e.g. to show another window of a project on a second
+// monitor
+func (m *Model) startGrouped() (tea.Model, tea.Cmd) {
+	item, ok := m.selectedItem()
+	if !ok || item.IsRecent {
+		m.setError("Select a session to group with")
+		return m, nil
+	}
+	target := m.sessions[item.SessionIndex].Name
+	model, cmd := m.startCreate("", m.groupedName(target))
+	m.createGroup = target
+	return model, cmd
+}
+
+// groupedName suggests a free name for a session grouped with target:
+// target~2, target~3, …
+func (m *Model) groupedName(target string) string {
+	taken := map[string]bool{m.currentSession: true}
+	for _, s := range m.sessions {
+		taken[s.Name] = true
+	}
+	for i := 2; i <= maxNameSuffix; i++ {
+		if name := fmt.Sprintf("%s~%d", target, i); !taken[name] {
+			return name
+		}
+	}
+	return target
+}
+
+// groupShared reports whether another listed session is in the group of the
+// session at idx, so killing it loses no windows
+func (m *Model) groupShared(idx int) bool {
+	if m.sessions[idx].Group == "" {
+		return false
+	}
+	for i, s := range m.sessions {
+		if i != idx && s.Group == m.sessions[idx].Group {
+			return true
+		}
+	}
+	return false
+}
+
+// createGrouped creates the session prompted for by startGrouped. It shares
+// the group's windows, so no layout is applied.
+func (m *Model) createGrouped(name string, background bool) (tea.Model, tea.Cmd) {
+	group := m.createGroup
+	if err := tmux.CreateGroupedSession(name, group); err != nil {
+		m.setError("Error: %v", err)
+		m.mode = ModeNormal
+		m.input.Blur()
+		return m, nil
+	}
+
+	path := ""
+	for _, s := range m.sessions {
+		if s.Name == group {
+			path = s.Path
+		}
+	}
+	return m.openCreated(name, path, "", background)
+}
+
+// paletteFocus narrows the list to a session group (:focus work), so the
+// 1-9 labels count within it and each group gets small, stable numbers.
+// Without a name it focuses the selected session's group, or leaves the
+// focus when one is set.
+func (m *Model) paletteFocus(args []string) (tea.Model, tea.Cmd) {
+	if len(args) > 1 {
+		m.setError("Usage: :focus [group]")
+		return m, nil
+	}
+
+	group := ""
+	switch {
+	case len(args) == 1:
+		group = args[0]
+		if !slices.Contains(m.groupNames(), group) {
+			m.setError("No session group \"%s\"", group)
+			return m, nil
+		}
+	case m.focusGroup != "":
+		m.clearFocus()
+		return m, nil
+	default:
+		if item, ok := m.selectedItem(); ok && !item.IsRecent {
+			group = m.sessions[item.SessionIndex].Group
+		}
+		if group == "" {
+			m.setError("Select a grouped session or name a group")
+			return m, nil
+		}
+	}
+
+	m.focusGroup = group
+	m.cursor = 0
+	m.splitFocus = false
+	m.rebuildItems()
+	m.updateScrollOffset()
+	m.setInfo("Focused group %s (esc leaves)", group)
+	return m, nil
+}
+
+// clearFocus lists every session again
+func (m *Model) clearFocus() {
+	m.setInfo("Left group %s", m.focusGroup)
+	m.focusGroup = ""
+	m.cursor = 0
+	m.rebuildItems()
+	m.updateScrollOffset()
+}
+
+// groupNames lists the session groups, for :focus completion
+func (m *Model) groupNames() []string {
+	var names []string
+	for _, s := range m.sessions {
+		if s.Group != "" && !slices.Contains(names, s.Group) {
+			names = append(names, s.Group)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// groupSessions keeps the sessions of the focused group
+func (m Model) groupSessions(indices []int) []int {
+	return slices.DeleteFunc(indices, func(i int) bool {
+		return m.sessions[i].Group != m.focusGroup
+	})
+}
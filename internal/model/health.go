@@ -0,0 +1,55 @@
+package model
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/health"
+)
+
+// healthMsg carries the result of a session's health check
+type healthMsg struct {
+	session string
+	healthy bool
+}
+
+// runHealthChecks starts the health checks (see config.HealthChecks) of the
+// sessions not checked since the picker opened, concurrently
+func (m *Model) runHealthChecks() tea.Cmd {
+	if len(m.config.HealthChecks) == 0 {
+		return nil
+	}
+	if m.healthStarted == nil {
+		m.healthStarted = make(map[string]bool)
+	}
+
+	var cmds []tea.Cmd
+	for _, s := range m.sessions {
+		if m.healthStarted[s.Name] {
+			continue
+		}
+		check, ok := m.config.HealthCheckFor(s.Path, m.tags[s.Name])
+		if !ok {
+			continue
+		}
+		m.healthStarted[s.Name] = true
+		name, dir := s.Name, s.Path
+		cmds = append(cmds, func() tea.Msg {
+			return healthMsg{session: name, healthy: health.Check(check.Command, dir, name, check.Timeout)}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleHealth records a finished health check
+func (m *Model) handleHealth(msg healthMsg) {
+	if m.health == nil {
+		m.health = make(map[string]bool)
+	}
+	m.health[msg.session] = msg.healthy
+}
+
+// resetHealth forgets the health of the sessions, e.g. when targeting another server
+func (m *Model) resetHealth() {
+	m.health = nil
+	m.healthStarted = nil
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// killTypeLetters is how many letters of a big session's name are typed to
+// confirm its kill (the whole name when shorter)
+const killTypeLetters = 3
+
+// needsKillTypeName reports whether the session at idx has more windows than
+// kill_confirm_windows. Sessions sharing their windows with a group lose none.
+func (m *Model) needsKillTypeName(idx int) bool {
+	n := m.config.KillConfirmWindows
+	return n > 0 && len(m.sessions[idx].Windows) > n && !m.groupShared(idx)
+}
+
+// startKillTypeName asks for the first letters of the big session being
+// killed instead of a second C-x
+func (m *Model) startKillTypeName(session string) (tea.Model, tea.Cmd) {
+	name := []rune(session)
+	m.killTypeName = string(name[:min(len(name), killTypeLetters)])
+	m.prompt += fmt.Sprintf(" Type \"%s\" to confirm:", m.killTypeName)
+	m.input.Reset()
+	m.input.Focus()
+	m.mode = ModeConfirmKill
+	return m, tea.Batch(textinput.Blink, m.confirmKillTimeout())
+}
+
+// handleKillTypeName confirms the kill of a big session once the first
+// letters of its name are typed, so a reflexive C-x C-x can't take it
+func (m *Model) handleKillTypeName(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+
+	switch {
+	case key.Matches(msg, keys.Cancel):
+		m.cancelKill()
+		return m, nil
+	case key.Matches(msg, keys.Kill):
+		m.setWarning("Type \"%s\" to confirm", m.killTypeName)
+		return m, nil
+	case msg.Type == tea.KeyEnter:
+		if m.input.Value() == m.killTypeName {
+			return m.killCurrent()
+		}
+		m.setError("Type \"%s\" to confirm", m.killTypeName)
+		m.input.Reset()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	// Typing takes longer than a key press, every key restarts the timeout
+	return m, tea.Batch(cmd, m.confirmKillTimeout())
+}
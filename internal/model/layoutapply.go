@@ -0,0 +1,78 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/layout"
+	"github.com/nikbrunner/tsm/internal/state"
+)
+
+// layoutAppliedMsg reports that a new session's layout script finished
+type layoutAppliedMsg struct {
+	session    string
+	layout     string
+	background bool // Stay in the picker rather than switching
+	err        error
+}
+
+// openCreated applies the layout to a session tsm just created, then
+// switches to it, or announces it when created in the background. With
+// layout_wait the picker reports progress and only switches once the script
+// is done; otherwise the script is started and the switch happens right away.
+func (m *Model) openCreated(session, dir, layoutName string, background bool) (tea.Model, tea.Cmd) {
+	m.rememberSession(session, dir, layoutName)
+	m.logEvent(state.EventCreate, session)
+	m.mode = ModeNormal
+	m.input.Blur()
+
+	if layout.Exists(m.config.LayoutDir, layoutName) {
+		if m.config.LayoutWait {
+			m.prompt = fmt.Sprintf("Applying layout %s…", layoutName)
+			return m, applyLayout(m.config.LayoutDir, layoutName, session, dir, slices.Clone(m.layoutEnv), background)
+		}
+		if err := layout.Start(m.config.LayoutDir, layoutName, session, dir, m.layoutEnv); err != nil {
+			m.setError("Layout %s failed: %v", layoutName, err)
+			return m, m.loadSessions
+		}
+	}
+	return m.enterCreated(session, background)
+}
+
+// applyLayout runs a layout script as a command, reporting a layoutAppliedMsg
+func applyLayout(dir, name, session, workingDir string, env []string, background bool) tea.Cmd {
+	return func() tea.Msg {
+		err := layout.Apply(dir, name, session, workingDir, env)
+		return layoutAppliedMsg{session: session, layout: name, background: background, err: err}
+	}
+}
+
+// handleLayoutApplied switches to the session once its layout is in place.
+// A failed layout keeps the picker open on the half set up session.
+func (m *Model) handleLayoutApplied(msg layoutAppliedMsg) (tea.Model, tea.Cmd) {
+	m.prompt = ""
+	if msg.err != nil {
+		m.setError("Layout %s failed for %s: %v", msg.layout, msg.session, msg.err)
+		return m, m.loadSessions
+	}
+	m.setInfo("Applied layout %s", msg.layout)
+	return m.enterCreated(msg.session, msg.background)
+}
+
+// enterCreated switches to a created session. In the background it stays in
+// the picker; the session is announced once the reloaded list tells its
+// jump number.
+func (m *Model) enterCreated(session string, background bool) (tea.Model, tea.Cmd) {
+	if background {
+		m.createdSession = session
+		return m, m.loadSessions
+	}
+	if err := switchClient(session); err != nil {
+		m.setError("Created but failed to switch: %v", err)
+		return m, m.loadSessions
+	}
+	m.recordSwitch(session)
+	return m, tea.Quit
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/layout"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// layoutVars returns the variables declared by a layout, none if it has no script
+func (m *Model) layoutVars(name string) []layout.Var {
+	return layout.Vars(m.config.LayoutDir, name)
+}
+
+// needsLayoutVars reports whether a layout's variables still have to be prompted for
+func (m *Model) needsLayoutVars(name string) bool {
+	return m.layoutEnv == nil && len(m.layoutVars(name)) > 0
+}
+
+// promptLayoutVars asks for each of the layout's variables in turn, then
+// creates the session with them in the layout script's environment
+func (m *Model) promptLayoutVars(name string, then createFn) (tea.Model, tea.Cmd) {
+	m.layoutPending = m.layoutVars(name)
+	m.layoutValues = nil
+	m.layoutThen = then
+	m.prompt = ""
+	m.mode = ModeLayoutVars
+	return m, m.focusLayoutVar()
+}
+
+// focusLayoutVar prepares the input for the next variable
+func (m *Model) focusLayoutVar() tea.Cmd {
+	v := m.layoutPending[len(m.layoutValues)]
+	m.input.Reset()
+	m.input.Placeholder = v.Default
+	m.input.Focus()
+	return textinput.Blink
+}
+
+func (m *Model) handleLayoutVarsMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+
+	switch {
+	case key.Matches(msg, keys.Cancel):
+		m.resetLayoutVars()
+		return m, nil
+
+	case msg.Type == tea.KeyEnter:
+		v := m.layoutPending[len(m.layoutValues)]
+		value := strings.TrimSpace(m.input.Value())
+		if value == "" {
+			value = v.Default
+		}
+		if value == "" {
+			m.setError("%s is required", v.Name)
+			return m, nil
+		}
+		m.layoutValues = append(m.layoutValues, value)
+		if len(m.layoutValues) < len(m.layoutPending) {
+			return m, m.focusLayoutVar()
+		}
+
+		env := make([]string, len(m.layoutPending))
+		for i, v := range m.layoutPending {
+			env[i] = v.Name + "=" + m.layoutValues[i]
+		}
+		then := m.layoutThen
+		m.resetLayoutVars()
+
+		// The environment only applies to this creation
+		m.layoutEnv = env
+		model, cmd := then(m)
+		m.layoutEnv = nil
+		return model, cmd
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// resetLayoutVars leaves the layout variable prompt
+func (m *Model) resetLayoutVars() {
+	m.mode = ModeNormal
+	m.input.Blur()
+	m.input.Placeholder = ""
+	m.layoutPending = nil
+	m.layoutValues = nil
+	m.layoutThen = nil
+}
+
+// layoutVarPrompt renders the prompt for the variable being asked for
+func (m *Model) layoutVarPrompt() string {
+	v := m.layoutPending[len(m.layoutValues)]
+	return fmt.Sprintf(" %s (%d/%d): ", v.Prompt, len(m.layoutValues)+1, len(m.layoutPending))
+}
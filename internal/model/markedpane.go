@@ -0,0 +1,55 @@
+package model
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// holdsMarkedPane reports whether tmux's marked pane (select-pane -m) is in
+// the window
+func (m Model) holdsMarkedPane(window tmux.Window) bool {
+	return m.markedPaneWindow != "" && window.ID == m.markedPaneWindow
+}
+
+// useMarkedPane joins tmux's marked pane into the selected window, or swaps
+// it with the window's active pane. A session stands for its current window.
+func (m *Model) useMarkedPane(swap bool) (tea.Model, tea.Cmd) {
+	item, ok := m.selectedItem()
+	if !ok || item.IsRecent {
+		return m, nil
+	}
+	if m.markedPaneWindow == "" {
+		m.setError("No marked pane (mark one with prefix+m)")
+		return m, nil
+	}
+
+	target := m.sessions[item.SessionIndex].Name + ":"
+	if !item.IsSession {
+		window := m.sessions[item.SessionIndex].Windows[item.WindowIndex]
+		if m.holdsMarkedPane(window) {
+			m.setError("The marked pane is already in %s", m.displayName(item))
+			return m, nil
+		}
+		target = window.Target(m.sessions[item.SessionIndex].Name)
+	}
+
+	var err error
+	if swap {
+		err = tmux.SwapMarkedPane(target)
+	} else {
+		err = tmux.JoinMarkedPane(target)
+	}
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, m.loadSessions
+	}
+
+	if swap {
+		m.setInfo("Swapped the marked pane with %s", m.displayName(item))
+	} else {
+		m.setInfo("Joined the marked pane into %s", m.displayName(item))
+	}
+	// The marked pane's window may be gone, reload everything
+	return m, m.loadSessions
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// isMarked reports whether the item is marked for a batch action
+func (m *Model) isMarked(item Item) bool {
+	return m.marked[m.getTargetName(item)]
+}
+
+// toggleMark toggles the mark on the item under the cursor and moves down
+func (m *Model) toggleMark() {
+	item, ok := m.selectedItem()
+	if !ok || item.IsRecent || item.IsPane {
+		return
+	}
+
+	if m.marked == nil {
+		m.marked = make(map[string]bool)
+	}
+
+	target := m.getTargetName(item)
+	if m.marked[target] {
+		delete(m.marked, target)
+	} else {
+		m.marked[target] = true
+	}
+
+	if m.splitFocus {
+		m.moveSplitCursor(1)
+	} else if m.cursor < len(m.items)-1 {
+		m.cursor++
+		m.updateScrollOffset()
+	}
+}
+
+// markAll marks every item currently visible (i.e. matching the filter)
+func (m *Model) markAll() {
+	if m.marked == nil {
+		m.marked = make(map[string]bool)
+	}
+	for _, item := range m.items {
+		if !item.IsRecent && !item.IsPane {
+			m.marked[m.getTargetName(item)] = true
+		}
+	}
+}
+
+// clearMarks removes all marks
+func (m *Model) clearMarks() {
+	m.marked = nil
+}
+
+// pruneMarks drops marks whose session or window is no longer listed
+func (m *Model) pruneMarks() {
+	if len(m.marked) == 0 {
+		return
+	}
+
+	existing := make(map[string]bool)
+	for _, s := range m.sessions {
+		existing[s.Name] = true
+		for _, w := range s.Windows {
+			existing[w.Target(s.Name)] = true
+		}
+	}
+
+	for target := range m.marked {
+		if !existing[target] {
+			delete(m.marked, target)
+		}
+	}
+}
+
+// markedWindow identifies a marked window
+type markedWindow struct {
+	session string
+	index   int
+	target  string // Stable tmux target (see tmux.Window.Target)
+}
+
+// markedTargets returns the marked sessions and windows in list order.
+// Windows belonging to a marked session are omitted since killing the session covers them.
+func (m *Model) markedTargets() (sessions []string, windows []markedWindow) {
+	for _, s := range m.sessions {
+		if m.marked[s.Name] {
+			sessions = append(sessions, s.Name)
+			continue
+		}
+		for _, w := range s.Windows {
+			if target := w.Target(s.Name); m.marked[target] {
+				windows = append(windows, markedWindow{session: s.Name, index: w.Index, target: target})
+			}
+		}
+	}
+	return sessions, windows
+}
+
+// markedPreview describes the marked items for the kill confirmation
+func (m *Model) markedPreview() []string {
+	sessions, windows := m.markedTargets()
+	lines := make([]string, 0, len(sessions)+len(windows))
+	for _, name := range sessions {
+		lines = append(lines, "session "+name)
+	}
+	for _, w := range windows {
+		lines = append(lines, fmt.Sprintf("window %s:%d", w.session, w.index))
+	}
+	return lines
+}
+
+// killMarked kills all marked sessions and windows, returning the number killed
+func (m *Model) killMarked() (int, error) {
+	sessions, windows := m.markedTargets()
+	killed := 0
+
+	for _, w := range windows {
+		if err := tmux.KillWindow(w.target); err != nil {
+			return killed, err
+		}
+		killed++
+	}
+	for _, name := range sessions {
+		if err := tmux.KillSessionSafe(name); err != nil {
+			return killed, err
+		}
+		m.logEvent(state.EventKill, name)
+		killed++
+	}
+
+	m.clearMarks()
+	return killed, nil
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// Filter match ranks, best first. A session is ranked by its best matching
+// field, so name matches are listed before sessions found by other fields.
+const (
+	rankName   = iota // Session name (or no filter text)
+	rankWindow        // A window name
+	rankOther         // Working directory, tags or note text
+	rankNone          // No match
+)
+
+// matchRank returns how well a session matches the filter text
+func (m *Model) matchRank(session tmux.Session, tm textMatcher) int {
+	switch {
+	case matches(tm, session.Name):
+		return rankName
+	case hasMatchingWindow(session, tm):
+		return rankWindow
+	case m.matchesDetails(session, tm):
+		return rankOther
+	}
+	return rankNone
+}
+
+// matchesDetails reports whether the session's working directory, tags or
+// notes match the filter text. The home directory is left out of the path,
+// otherwise its name would match every session.
+func (m *Model) matchesDetails(session tmux.Session, tm textMatcher) bool {
+	if home := os.Getenv("HOME"); home != "" {
+		if matches(tm, strings.TrimPrefix(session.Path, home)) {
+			return true
+		}
+	} else if matches(tm, session.Path) {
+		return true
+	}
+	if slices.ContainsFunc(m.tags[session.Name], func(tag string) bool { return matches(tm, tag) }) {
+		return true
+	}
+	return matches(tm, m.notes[session.Name])
+}
+
+// filteredSessions returns the indices of the sessions matching the filter,
+// best match first and otherwise in list order. Only the candidate indices
+// are checked, or all sessions when candidates is nil.
+func (m *Model) filteredSessions(tags, commands []string, tm textMatcher, candidates []int) []int {
+	type match struct{ index, rank int }
+	matches := make([]match, 0, len(m.sessions))
+	check := func(i int) {
+		session := m.sessions[i]
+		if !m.matchesTags(session.Name, tags) || !m.sessionRunsCommands(session, commands) {
+			return
+		}
+		if rank := m.matchRank(session, tm); rank != rankNone {
+			matches = append(matches, match{i, rank})
+		}
+	}
+
+	if candidates == nil {
+		for i := range m.sessions {
+			check(i)
+		}
+	} else {
+		// Candidates come in display order, restore list order before ranking
+		candidates = slices.Clone(candidates)
+		slices.Sort(candidates)
+		for _, i := range candidates {
+			check(i)
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int { return a.rank - b.rank })
+	indices := make([]int, len(matches))
+	for i, match := range matches {
+		indices[i] = match.index
+	}
+	return indices
+}
+
+// windowMatchesFilter reports whether the filter picked out a window: it runs
+// the ">cmd" commands or, without them, its name matches the filter text
+func (m Model) windowMatchesFilter(window tmux.Window) bool {
+	switch {
+	case len(m.filterCommands) > 0:
+		return m.windowRunsCommands(window, m.filterCommands)
+	case m.filterMatcher != nil:
+		return matches(m.filterMatcher, window.Name)
+	}
+	return false
+}
+
+// filterStatus counts what the filter left of the sessions, e.g. "7/23
+// sessions · 3 windows" with the windows it matched in them
+func (m Model) filterStatus() string {
+	sessions, windows := 0, 0
+	for _, item := range m.items {
+		if !item.IsSession {
+			continue
+		}
+		sessions++
+		for _, window := range m.sessions[item.SessionIndex].Windows {
+			if m.windowMatchesFilter(window) {
+				windows++
+			}
+		}
+	}
+
+	status := fmt.Sprintf("%d/%d sessions", sessions, len(m.sessions))
+	if windows > 0 {
+		status += " · " + pluralize(windows, "window")
+	}
+	return status
+}
@@ -0,0 +1,161 @@
+package model
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/config"
+)
+
+// span is the byte range [start, end) of a match, for highlighting
+type span [2]int
+
+// textMatcher matches filter text against names (see config.Matcher)
+type textMatcher interface {
+	// match reports whether text matches and where, if the matched bytes
+	// can be told
+	match(text string) ([]span, bool)
+}
+
+// newMatcher compiles the filter text for a matcher kind. Empty text gives a
+// nil matcher, which callers treat as matching everything.
+func newMatcher(kind, pattern string) textMatcher {
+	if pattern == "" {
+		return nil
+	}
+	caseSensitive := strings.IndexFunc(pattern, unicode.IsUpper) >= 0
+
+	switch kind {
+	case config.MatcherFuzzy:
+		return subsequenceMatcher{pattern: []rune(strings.ToLower(pattern))}
+	case config.MatcherSmartCase:
+		if caseSensitive {
+			return substringMatcher{pattern: pattern, caseSensitive: true}
+		}
+	case config.MatcherRegex:
+		prefix := "(?i)"
+		if caseSensitive {
+			prefix = ""
+		}
+		// A half-typed expression matches literally until it compiles
+		if re, err := regexp.Compile(prefix + pattern); err == nil {
+			return regexMatcher{re}
+		}
+		return regexMatcher{regexp.MustCompile(prefix + regexp.QuoteMeta(pattern))}
+	}
+	return substringMatcher{pattern: strings.ToLower(pattern)}
+}
+
+// matches reports whether text matches; a nil matcher matches everything
+func matches(tm textMatcher, text string) bool {
+	if tm == nil {
+		return true
+	}
+	_, ok := tm.match(text)
+	return ok
+}
+
+// narrowsOnAppend reports whether appending to the filter only removes
+// matches, so the list can be narrowed instead of rebuilt (see narrowItems).
+// Appending to a regex can widen it ("a" → "a|b").
+func narrowsOnAppend(kind string) bool {
+	return kind != config.MatcherRegex
+}
+
+// substringMatcher matches a substring, ignoring case unless caseSensitive
+type substringMatcher struct {
+	pattern       string // Lowercase unless caseSensitive
+	caseSensitive bool
+}
+
+func (s substringMatcher) match(text string) ([]span, bool) {
+	haystack := text
+	if !s.caseSensitive {
+		if !fuzzyMatch(text, s.pattern) {
+			return nil, false
+		}
+		haystack = strings.ToLower(text)
+		// Lowercasing can change byte lengths for some runes; skip highlighting then
+		if len(haystack) != len(text) {
+			return nil, true
+		}
+	}
+	idx := strings.Index(haystack, s.pattern)
+	if idx < 0 {
+		return nil, false
+	}
+	return []span{{idx, idx + len(s.pattern)}}, true
+}
+
+// subsequenceMatcher matches the pattern's runes in order, with anything in
+// between, ignoring case
+type subsequenceMatcher struct {
+	pattern []rune // Lowercase
+}
+
+func (s subsequenceMatcher) match(text string) ([]span, bool) {
+	var spans []span
+	next := 0
+	for i, r := range text {
+		if next == len(s.pattern) {
+			break
+		}
+		if unicode.ToLower(r) != s.pattern[next] {
+			continue
+		}
+		next++
+		end := i + utf8.RuneLen(r)
+		// Adjacent matched runes form one span
+		if n := len(spans); n > 0 && spans[n-1][1] == i {
+			spans[n-1][1] = end
+		} else {
+			spans = append(spans, span{i, end})
+		}
+	}
+	if next < len(s.pattern) {
+		return nil, false
+	}
+	return spans, true
+}
+
+// regexMatcher matches a regular expression, highlighting its first match
+type regexMatcher struct {
+	re *regexp.Regexp
+}
+
+func (r regexMatcher) match(text string) ([]span, bool) {
+	loc := r.re.FindStringIndex(text)
+	if loc == nil {
+		return nil, false
+	}
+	if loc[0] == loc[1] {
+		return nil, true
+	}
+	return []span{{loc[0], loc[1]}}, true
+}
+
+// cycleMatcher switches to the next filter matcher for this run
+func (m *Model) cycleMatcher() (tea.Model, tea.Cmd) {
+	i := slices.Index(config.Matchers, m.matcherKind())
+	m.matcher = config.Matchers[(i+1)%len(config.Matchers)]
+	m.rebuildItems()
+	m.setInfo("Matcher: %s", m.matcher)
+	return m, nil
+}
+
+// matcherKind returns the active filter matcher: the one picked with M-f,
+// otherwise the configured one
+func (m Model) matcherKind() string {
+	if m.matcher != "" {
+		return m.matcher
+	}
+	if m.config.Matcher != "" {
+		return m.config.Matcher
+	}
+	return config.MatcherSubstring
+}
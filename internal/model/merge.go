@@ -0,0 +1,182 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// openMergeTarget starts picking the session that receives the windows of the
+// session under the cursor
+func (m *Model) openMergeTarget() (tea.Model, tea.Cmd) {
+	source := m.cursorSessionName()
+	if source == "" {
+		return m, nil
+	}
+
+	var targets []string
+	if m.currentSession != "" && m.currentSession != source {
+		targets = append(targets, m.currentSession)
+	}
+	for _, s := range m.sessions {
+		if s.Name != source && s.Name != m.currentSession {
+			targets = append(targets, s.Name)
+		}
+	}
+	if len(targets) == 0 {
+		m.setError("No other session to merge \"%s\" into", source)
+		return m, nil
+	}
+
+	m.mergeSource = source
+	m.mergeTargets = targets
+	m.mergeFilter = ""
+	m.mergeCursor = 0
+	m.mode = ModeMergeTarget
+	return m, tea.WindowSize()
+}
+
+// filteredMergeTargets returns the merge targets matching the merge filter
+func (m *Model) filteredMergeTargets() []string {
+	if m.mergeFilter == "" {
+		return m.mergeTargets
+	}
+	tm := newMatcher(m.matcherKind(), m.mergeFilter)
+	var targets []string
+	for _, name := range m.mergeTargets {
+		if matches(tm, name) {
+			targets = append(targets, name)
+		}
+	}
+	return targets
+}
+
+func (m *Model) handleMergeTargetMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+	targets := m.filteredMergeTargets()
+
+	switch {
+	case key.Matches(msg, keys.Cancel):
+		// Clear filter first, then exit on second press
+		if m.mergeFilter != "" {
+			m.mergeFilter = ""
+			m.mergeCursor = 0
+			return m, nil
+		}
+		m.mode = ModeNormal
+		return m, nil
+
+	case key.Matches(msg, keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, keys.Up):
+		if m.mergeCursor > 0 {
+			m.mergeCursor--
+		}
+
+	case key.Matches(msg, keys.Down):
+		if m.mergeCursor < len(targets)-1 {
+			m.mergeCursor++
+		}
+
+	case key.Matches(msg, keys.Select):
+		if m.mergeCursor < len(targets) {
+			return m.mergeInto(targets[m.mergeCursor])
+		}
+
+	case msg.Type == tea.KeyBackspace:
+		if len(m.mergeFilter) > 0 {
+			m.mergeFilter = m.mergeFilter[:len(m.mergeFilter)-1]
+			m.mergeCursor = 0
+		}
+
+	case msg.Type == tea.KeyRunes:
+		m.mergeFilter += string(msg.Runes)
+		m.mergeCursor = 0
+	}
+
+	return m, nil
+}
+
+// mergeInto moves all windows of the merge source into target and kills the source
+func (m *Model) mergeInto(target string) (tea.Model, tea.Cmd) {
+	m.mode = ModeNormal
+	if err := tmux.MergeSession(m.mergeSource, target); err != nil {
+		m.setError("Error: %v", err)
+		return m, m.loadSessions
+	}
+
+	m.setInfo("Merged \"%s\" into \"%s\"", m.mergeSource, target)
+	return m, m.loadSessions
+}
+
+// viewMergeTarget renders the merge target picker
+func (m Model) viewMergeTarget() string {
+	var b strings.Builder
+	usedLines := 0
+
+	b.WriteString(ui.HeaderStyle.Render(fmt.Sprintf("Merge \"%s\" into", m.mergeSource)))
+	if m.mergeFilter != "" {
+		b.WriteString("  ")
+		b.WriteString(ui.FilterStyle.Render(m.mergeFilter))
+	}
+	b.WriteString("\n")
+	usedLines++
+
+	b.WriteString(ui.RenderBorder(m.borderWidth()))
+	b.WriteString("\n")
+	usedLines++
+
+	targets := m.filteredMergeTargets()
+	maxItems := m.projectMaxVisibleItems()
+	offset := max(m.mergeCursor-maxItems+1, 0)
+	endIdx := min(offset+maxItems, len(targets))
+	scrollbar := ui.ScrollbarChars(len(targets), maxItems, offset, endIdx-offset)
+
+	for i := offset; i < endIdx; i++ {
+		if lineIdx := i - offset; lineIdx < len(scrollbar) {
+			b.WriteString(scrollbar[lineIdx])
+			b.WriteString(" ")
+		}
+
+		name := targets[i]
+		if name == m.currentSession {
+			name += " (current)"
+		}
+		if ui.Plain {
+			b.WriteString(plainCursor(i == m.mergeCursor) + name)
+		} else if i == m.mergeCursor {
+			b.WriteString(ui.FilterStyle.Render(name))
+		} else {
+			b.WriteString(name)
+		}
+		b.WriteString("\n")
+		usedLines++
+	}
+
+	if len(targets) == 0 {
+		b.WriteString("  No sessions matching filter\n")
+		usedLines++
+	}
+
+	// Footer = border (1) + statusline (1) + help line (1) = 3 lines
+	footerLines := 3
+	if contentH := m.contentHeight(); contentH > 0 {
+		for i := 0; i < contentH-usedLines-footerLines; i++ {
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(ui.RenderBorder(m.borderWidth()))
+	b.WriteString("\n")
+	b.WriteString(ui.StatuslineStyle.Render("Windows are appended, then the session is killed"))
+	b.WriteString("\n")
+	b.WriteString(ui.FooterStyle.Render(ui.HelpMergeTarget()))
+
+	return ui.AppStyle.Render(b.String())
+}
@@ -2,18 +2,24 @@ package model
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
 	"github.com/nikbrunner/tsm/internal/claude"
 	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/gitinfo"
+	"github.com/nikbrunner/tsm/internal/layout"
+	"github.com/nikbrunner/tsm/internal/profile"
+	"github.com/nikbrunner/tsm/internal/state"
 	"github.com/nikbrunner/tsm/internal/tmux"
 	"github.com/nikbrunner/tsm/internal/ui"
 )
@@ -26,44 +32,190 @@ const (
 	ModeConfirmKill
 	ModeCreate
 	ModePickDirectory
+	ModeNoteInput
+	ModeNotes
+	ModeTagInput
+	ModeMergeTarget
+	ModeConfirmEvict
+	ModeLayoutVars
+	ModeRename
+	ModeClientTarget
+	ModeErrorDetail
+	ModeSnapshotDiff
+	ModeCommand
+	ModeConfirmBroadcast
+	ModeBroadcastReport
+	ModeConfirmWorktree
 )
 
-// Item represents either a session or a window in the flattened list
+// Item represents a session, a window or a recent (dead) session in the flattened list
 type Item struct {
 	IsSession    bool
-	SessionIndex int // Index in the sessions slice
-	WindowIndex  int // Index in the session's windows slice (only for windows)
+	IsRecent     bool // Previously known session that no longer exists
+	Archived     bool // Recent item from the archive; RecentIndex indexes archived
+	SessionIndex int  // Index in the sessions slice
+	WindowIndex  int  // Index in the session's windows slice (only for windows)
+	RecentIndex  int  // Index in the recent slice (only for recent sessions)
+	IsPane       bool // Pane row of the tree view, below window WindowIndex
+	PaneIndex    int  // Index in the window's tree panes (only for panes)
 }
 
 // Model is the main application state
 type Model struct {
 	sessions       []tmux.Session
+	sessionsLoaded bool                 // Whether the first session list arrived
+	recent         []state.HistoryEntry // Recently seen sessions that no longer exist
+	archived       []state.ArchivedSession
 	claudeStatuses map[string]claude.Status
 	currentSession string
+	homeSession    string // Session the picker was opened from (on the default server)
+	serverIdx      int    // 0 = default server, otherwise index+1 into config.Servers
 	cursor         int
 	items          []Item // Flattened list of visible items
 	mode           Mode
-	message        string
-	messageIsError bool
+	toasts         []toast // Stacked messages, oldest first (see pushToast)
+	prompt         string  // Confirmation or progress shown until the action ends
 	input          textinput.Model
-	killTarget     string // Name of session/window being killed
+	createDir      string   // Working directory for the session being created (empty = default)
+	createGroup    string   // Session the one being created is grouped with (M-g)
+	breakOutWindow string   // Window moving into the session being created (M-b)
+	breakOutLabel  string   // Readable name of breakOutWindow, e.g. "api:2"
+	createdSession string   // Session created in the background, announced after the reload
+	createHint     string   // What the name prompt last changed in the input (see sanitizeCreateKey)
+	killTarget     string   // Name of session/window being killed
+	killPreview    []string // Windows/panes that will be lost by the kill
+	killTypeName   string   // Letters of the session name typed to confirm the kill (see kill_confirm_windows)
+	confirmSeq     int      // Counts kill confirmations, so a stale timeout can't cancel a newer one
+	evictVictim    string   // Least recently used session offered for eviction
+	evictThen      createFn // Creates the session once the victim is killed
 	config         config.Config
-	maxNameWidth   int    // For column alignment
-	filter         string // Current filter text for fuzzy matching
+	maxNameWidth   int             // For column alignment
+	filter         string          // Current filter text for fuzzy matching
+	filterMatcher  textMatcher     // Filter text compiled for matching (nil = no filter text), set by buildItems
+	filterCommands []string        // Commands a pane has to run (">psql" in the filter), set by buildItems
+	matcher        string          // Filter matcher picked with M-f for this run (see matcherKind)
+	marked         map[string]bool // Targets (session or session:window) marked for batch actions
+	tags           state.Tags      // Session tags, matched by "#tag" filter terms
+	store          state.Store     // Where notes and tags are kept
+	suspended      map[string]bool // Sessions whose pane processes are all stopped
+	tagTarget      string          // Session whose tags are being edited
+
+	// Filter history state
+	filterHistory []string // Previously used filters, most recent first
+	historyIdx    int      // Index into the matching history entries, -1 when not cycling
+	historyPrefix string   // Filter text typed before cycling started
+
+	preFilterSession string // Session under the cursor when filtering started, selected again when esc clears the filter
+
+	// Split view state (see config.SplitView)
+	splitSession string // Session whose windows the window pane shows
+	splitCursor  int    // Selected window in the window pane
+	splitFocus   bool   // Whether the window pane has focus
+	splitNotes   bool   // Whether the window pane shows the session's notes instead
+	agentsView   bool   // Only sessions running Claude Code, waiting ones first (M-W)
+	focusGroup   string // Session group the list is narrowed to (:focus), "" lists all
+
+	// Preview pane state (see previewpane.go)
+	splitPreview  bool     // Whether the window pane shows a preview of the highlighted row
+	previewTarget string   // Target the preview shows or waits to capture
+	previewLines  []string // Captured content of previewTarget, nil while loading
+	previewSeq    int      // Bumped when the cursor moves on, cancelling the pending capture
 
 	// Directory picker state
 	projectDirs     []string // All scanned directories
+	projectScanning bool     // Whether projectDirs come from the cache while a rescan runs
 	projectFiltered []string // Filtered list based on projectFilter
 	projectFilter   string   // Current filter text for directory picker
 	projectCursor   int      // Selected item in directory list
 
+	// Client picker state (switching another attached client)
+	clients      []tmux.Client // Other clients attached to the server
+	clientCursor int           // Selected client
+	clientTarget string        // Target the client is switched to
+	clientLabel  string        // Readable target ("session" or "session:index")
+
+	// Batch rename state
+	renameTargets []string // Sessions the rename pattern applies to
+
+	// Broadcast state (:send)
+	broadcastCommand string   // Command line typed into every target
+	broadcastTargets []string // Marked sessions and windows
+	broadcastReport  []string // Whether it reached each target
+
+	worktreeRemoval *worktreeRemoval // Session killed with its worktree (:worktree)
+
+	// Startup expansion state (see ExpandOnStart)
+	startExpand    string // Session to expand once the first list arrives
+	startExpandAll bool   // Expand every session once the first list arrives
+	listCurrent    bool   // List the current session too
+
+	// Key sequence state (see handleSequence)
+	lastKey     string    // First key of a sequence, held for the second
+	lastKeyTime time.Time // When lastKey was typed
+
+	// Tree view state (see TreeView)
+	tree         bool                   // Draw windows and panes as a tree
+	panes        map[string][]tmux.Pane // Panes of every window, keyed by window ID (tree view and ">cmd" filters)
+	panesLoading bool                   // Panes are being loaded for a ">cmd" filter
+
+	// Merge target picker state
+	mergeSource  string   // Session whose windows are merged
+	mergeTargets []string // Sessions that can receive the windows
+	mergeFilter  string   // Current filter text for merge targets
+	mergeCursor  int      // Selected item in the filtered merge targets
+
+	// Layout variable prompt state
+	layoutPending []layout.Var // Variables declared by the layout being applied
+	layoutValues  []string     // Values entered so far, in declaration order
+	layoutThen    createFn     // Creates the session once all values are entered
+	layoutEnv     []string     // NAME=value pairs passed to the layout script
+
+	// Health check results per session (see config.HealthChecks)
+	health        map[string]bool          // Whether the session's check passed
+	smallClients  map[string][]tmux.Client // Clients much smaller than the picker's, by session
+	healthStarted map[string]bool          // Sessions whose check ran since the picker opened
+
+	// Last failed tmux command, shown by the error detail view
+	tmuxError *tmux.CommandError
+
+	// Lines of the snapshot diff view
+	snapshotDiff []string
+
+	// Window holding tmux's marked pane (select-pane -m), empty when none
+	markedPaneWindow string
+
+	// Panes whose command exited (remain-on-exit), keyed by window ID
+	deadPanes map[string][]string
+
+	// Window summaries: last pane line per window target, loaded on expand
+	windowSummaries map[string]string
+
+	// Notes state
+	noteInput         textarea.Model
+	noteTarget        string              // Session the note input/view belongs to
+	notedSessions     map[string]bool     // Sessions that have a notes file
+	notes             map[string]string   // Note text per noted session, matched by the filter
+	notesLines        []string            // Lines of the notes being viewed
+	notesScrollOffset int                 // Scroll offset for notes view
+	renderedNotes     map[string][]string // Markdown renders for the notes pane (see renderNotes)
+
+	// Session order snapshot (see stabilizeOrder)
+	openedAt     time.Time      // When the picker opened or switched servers
+	sessionOrder map[string]int // Position per session at the first load
+	sortBy       string         // Order chosen with :sort or the sort option ("" = tmux's activity order)
+	manualOrder  []string       // Session names as arranged with M-j/M-k (see moveSession)
+	commandOrder []string       // Session names as ranked by sort_command (see rankSessions)
+
 	// Scroll state
 	scrollOffset        int // Scroll offset for session list
 	projectScrollOffset int // Scroll offset for directory picker
 
 	// Window size
-	width  int
-	height int
+	width     int
+	height    int
+	profile   string            // Active size profile (see config.SizeProfile)
+	rowDetail string            // Row detail overriding the profile's columns (see toggleRowDetail)
+	branches  map[string]string // Branch checked out per session, for the git column
 
 	// Animation state
 	animationFrame int
@@ -74,45 +226,182 @@ func New(currentSession string, cfg config.Config) Model {
 	ti := textinput.New()
 	ti.CharLimit = 50
 
-	return Model{
+	ta := textarea.New()
+	ta.ShowLineNumbers = false
+	ta.Prompt = ""
+	ta.Placeholder = "Write a note..."
+	ta.SetHeight(5)
+
+	// tmux already lists sessions by activity, in the order sort_stability holds
+	sortBy := cfg.Sort
+	if sortBy == config.SortActivity {
+		sortBy = ""
+	}
+
+	m := Model{
 		currentSession: currentSession,
+		homeSession:    currentSession,
 		input:          ti,
+		noteInput:      ta,
 		config:         cfg,
+		store:          state.NewStore(cfg.StateBackend, cfg.StateDir),
+		historyIdx:     -1,
+		profile:        cfg.SizeProfile,
+		openedAt:       time.Now(),
+		sortBy:         sortBy,
+		renderedNotes:  make(map[string][]string),
+	}
+	if cfg.Migrated != "" {
+		m.setInfo("%s", cfg.Migrated)
 	}
+	return m
 }
 
 // Init implements tea.Model
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(m.loadSessions, animationTick())
+	return tea.Batch(m.loadSessions, m.loadFilterHistory, m.loadPrefs, animationTick(), detectNested)
+}
+
+// detectNested checks whether the picker runs inside a nested tmux
+func detectNested() tea.Msg {
+	if tmux.IsNested() {
+		return nestedMsg{}
+	}
+	return nil
 }
 
 // loadSessions fetches sessions from tmux
 func (m Model) loadSessions() tea.Msg {
-	sessions, err := tmux.ListSessions(m.currentSession)
+	defer profile.Start("load sessions")()
+
+	sessions, err := tmux.ListSessions(m.listedExclusion())
 	if err != nil {
 		return errMsg{err}
 	}
-	return sessionsMsg{sessions}
+
+	// Windows are listed up front so the filter can match window names
+	if windows, err := tmux.ListAllWindows(); err == nil {
+		for i := range sessions {
+			sessions[i].Windows = windows[sessions[i].Name]
+		}
+	}
+	markedPane, _ := tmux.MarkedWindow()
+	deadPanes, _ := tmux.DeadPanes()
+	var panes map[string][]tmux.Pane
+	if m.tree || hasCommandFilter(m.filter) {
+		panes, _ = tmux.ListAllPanes()
+	}
+	archived := m.loadArchived()
+	// With state_backend = "tmux" this asks the server, so not in Update
+	tags, _ := m.store.LoadTags()
+	var ranked []string
+	var rankErr error
+	if m.sortBy == config.SortExternal {
+		ranked, rankErr = m.rankSessions(sessions, tags)
+	}
+	return sessionsMsg{
+		server:     m.serverIdx,
+		sessions:   sessions,
+		recent:     m.updateHistory(sessions, archived),
+		archived:   archived,
+		suspended:  suspendedSessions(),
+		notes:      m.loadNotes(),
+		tags:       tags,
+		markedPane: markedPane,
+		panes:      panes,
+		deadPanes:  deadPanes,
+		ranked:     ranked,
+		rankErr:    rankErr,
+	}
+}
+
+// loadNotes reads the notes of every session that has some
+func (m Model) loadNotes() map[string]string {
+	notes := make(map[string]string)
+	for name := range m.store.NotedSessions() {
+		if text, err := m.store.ReadNotes(name); err == nil && text != "" {
+			notes[name] = text
+		}
+	}
+	return notes
+}
+
+// updateHistory records the listed sessions in the history file and returns
+// the recently seen sessions that no longer exist, leaving out archived ones
+func (m Model) updateHistory(sessions []tmux.Session, archived []state.ArchivedSession) []state.HistoryEntry {
+	// History only tracks the default server
+	if m.serverIdx != 0 {
+		return nil
+	}
+
+	var h state.History
+	now := time.Now()
+	live := make([]string, 0, len(sessions)+1)
+	err := state.UpdateHistory(m.config.StateDir, func(saved *state.History) error {
+		for _, s := range sessions {
+			saved.Touch(s.Name, s.Path, now)
+			live = append(live, s.Name)
+		}
+		if m.currentSession != "" {
+			saved.Touch(m.currentSession, "", now)
+			live = append(live, m.currentSession)
+		}
+		h = *saved
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+
+	if m.config.RecentSessions <= 0 {
+		return nil
+	}
+	for _, a := range archived {
+		live = append(live, a.Name)
+	}
+	return h.Recent(live, m.config.RecentSessions)
+}
+
+// rememberSession records a session created by tsm along with its layout
+func (m *Model) rememberSession(name, dir, layout string) {
+	_ = state.UpdateHistory(m.config.StateDir, func(h *state.History) error {
+		h.Touch(name, dir, time.Now())
+		h.SetLayout(name, layout)
+		return nil
+	})
 }
 
 type sessionsMsg struct {
-	sessions []tmux.Session
+	server     int // serverIdx the load started on
+	sessions   []tmux.Session
+	recent     []state.HistoryEntry
+	archived   []state.ArchivedSession
+	suspended  map[string]bool
+	notes      map[string]string
+	tags       state.Tags
+	markedPane string                 // Window holding tmux's marked pane
+	panes      map[string][]tmux.Pane // Panes by window ID, only for the tree view and ">cmd" filters
+	deadPanes  map[string][]string    // Dead panes (remain-on-exit) by window ID
+	ranked     []string               // Session names ranked by sort_command, only for sort = "command"
+	rankErr    error                  // Why sort_command failed
+}
+
+type claudeStatusesMsg struct {
+	statuses map[string]claude.Status
 }
 
 type errMsg struct {
 	err error
 }
 
-type clearMessageMsg struct{}
+// confirmTimeoutMsg cancels the kill confirmation it was started for
+type confirmTimeoutMsg struct {
+	seq int
+}
 
-type animationTickMsg struct{}
+type nestedMsg struct{}
 
-// clearMessageAfter returns a command that clears the message after a delay
-func clearMessageAfter(d time.Duration) tea.Cmd {
-	return tea.Tick(d, func(time.Time) tea.Msg {
-		return clearMessageMsg{}
-	})
-}
+type animationTickMsg struct{}
 
 // animationTick returns a command that ticks the animation
 func animationTick() tea.Cmd {
@@ -125,26 +414,131 @@ func animationTick() tea.Cmd {
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case sessionsMsg:
+		if msg.server != m.serverIdx {
+			// Loaded from the server targeted before C-t switched away
+			return m, nil
+		}
+		if msg.rankErr != nil {
+			// Keep the last ranking rather than reshuffling on a failed run
+			m.setError("sort_command failed: %v", msg.rankErr)
+		} else if msg.ranked != nil {
+			m.commandOrder = msg.ranked
+		}
+		m.stabilizeOrder(msg.sessions)
+		m.sortSessions(msg.sessions)
 		m.sessions = msg.sessions
-		m.loadClaudeStatuses()
+		first := !m.sessionsLoaded
+		m.sessionsLoaded = true
+		m.recent = msg.recent
+		m.archived = msg.archived
+		m.suspended = msg.suspended
+		m.notes = msg.notes
+		m.markedPaneWindow = msg.markedPane
+		m.panes = msg.panes
+		m.deadPanes = msg.deadPanes
+		m.notedSessions = make(map[string]bool, len(msg.notes))
+		for name := range msg.notes {
+			m.notedSessions[name] = true
+		}
+		m.tags = msg.tags
+		m.pruneMarks()
 		m.calculateColumnWidths()
 		m.rebuildItems()
-		if len(m.items) == 0 {
-			m.message = "No other sessions. Press c to create one."
+		if m.createdSession != "" {
+			m.announceCreated()
+		}
+		expand := m.applyStartExpand()
+		var cleanup tea.Cmd
+		if first {
+			cleanup = m.cleanupState()
+		}
+		// Reload the window pane, the session's windows may have changed
+		return m, tea.Batch(m.loadClaudeStatuses(), m.syncSplitPane(true), m.schedulePreview(true), expand, m.runHealthChecks(), m.loadBranches(), m.loadClientSizes(), cleanup)
+
+	case previewIdleMsg:
+		return m, m.handlePreviewIdle(msg)
+
+	case previewMsg:
+		m.handlePreview(msg)
+		return m, nil
+
+	case panesMsg:
+		m.handlePanes(msg)
+		return m, nil
+
+	case splitWindowsMsg:
+		m.handleSplitWindows(msg)
+		return m, nil
+
+	case sessionWindowsMsg:
+		return m, m.handleSessionWindows(msg)
+
+	case windowSummariesMsg:
+		m.handleWindowSummaries(msg)
+		return m, nil
+
+	case healthMsg:
+		m.handleHealth(msg)
+		return m, nil
+
+	case configEditedMsg:
+		return m, m.handleConfigEdited(msg)
+
+	case claudeStatusesMsg:
+		m.claudeStatuses = msg.statuses
+		if m.agentsView {
+			m.rebuildItems()
+		}
+		return m, nil
+
+	case filterHistoryMsg:
+		m.filterHistory = msg.filters
+		return m, nil
+
+	case prefsMsg:
+		m.rowDetail = msg.prefs.RowDetail
+		m.manualOrder = msg.prefs.Order
+		if m.sortBy == config.SortManual {
+			m.sortSessions(m.sessions)
+			m.rebuildItems()
 		}
+		return m, m.loadBranches()
+
+	case branchesMsg:
+		m.branches = msg.branches
+		return m, nil
+
+	case projectsScannedMsg:
+		m.handleProjectsScanned(msg)
+		return m, nil
+
+	case clientSizesMsg:
+		m.smallClients = msg.small
 		return m, nil
 
 	case errMsg:
 		m.setError("Error: %v", msg.err)
 		return m, nil
 
-	case clearMessageMsg:
-		m.message = ""
-		m.messageIsError = false
+	case nestedMsg:
+		if len(m.config.Servers) > 0 {
+			m.setWarning("Nested tmux detected. Press C-t to target another server.")
+		} else {
+			m.setWarning("Nested tmux detected. Add [[servers]] to config to target the outer server.")
+		}
+		return m, nil
+
+	case confirmTimeoutMsg:
+		if m.mode != ModeConfirmKill || msg.seq != m.confirmSeq {
+			return m, nil
+		}
+		m.cancelKill()
+		m.setInfo("Kill cancelled (timed out)")
 		return m, nil
 
 	case animationTickMsg:
 		m.animationFrame = (m.animationFrame + 1) % 3
+		m.expireToasts(time.Now())
 		return m, animationTick()
 
 	case tea.WindowSizeMsg:
@@ -152,50 +546,124 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		return m, nil
 
+	case sequenceTimeoutMsg:
+		m.handleSequenceTimeout(msg)
+		return m, nil
+
+	case layoutAppliedMsg:
+		return m.handleLayoutApplied(msg)
+
+	case tea.ResumeMsg:
+		// Sessions may have changed while tsm was stopped
+		return m, tea.Batch(m.loadSessions, m.loadClaudeStatuses())
+
 	case tea.KeyMsg:
 		return m.handleKey(msg)
 	}
 
-	// Handle text input updates in create mode
-	if m.mode == ModeCreate {
+	// Handle text input updates in create, tag input and layout variable modes
+	if m.mode == ModeCreate || m.mode == ModeTagInput || m.mode == ModeLayoutVars || m.mode == ModeRename || m.mode == ModeCommand {
 		var cmd tea.Cmd
 		m.input, cmd = m.input.Update(msg)
 		return m, cmd
 	}
+	if m.mode == ModeNoteInput {
+		var cmd tea.Cmd
+		m.noteInput, cmd = m.noteInput.Update(msg)
+		return m, cmd
+	}
 
 	return m, nil
 }
 
 func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Stop tsm like any job in a shell; bubbletea restores the terminal on fg
+	if key.Matches(msg, ui.DefaultKeyMap.SuspendPicker) {
+		return m, tea.Suspend
+	}
+
 	switch m.mode {
 	case ModeNormal:
-		return m.handleNormalMode(msg)
+		model, cmd := m.handleNormalMode(msg)
+		return model, tea.Batch(cmd, m.syncSplitPane(false), m.schedulePreview(false), m.loadPanes())
 	case ModeConfirmKill:
 		return m.handleConfirmKillMode(msg)
 	case ModeCreate:
 		return m.handleCreateMode(msg)
 	case ModePickDirectory:
 		return m.handlePickDirectoryMode(msg)
+	case ModeNoteInput:
+		return m.handleNoteInputMode(msg)
+	case ModeNotes:
+		return m.handleNotesMode(msg)
+	case ModeTagInput:
+		return m.handleTagInputMode(msg)
+	case ModeMergeTarget:
+		return m.handleMergeTargetMode(msg)
+	case ModeConfirmEvict:
+		return m.handleConfirmEvictMode(msg)
+	case ModeLayoutVars:
+		return m.handleLayoutVarsMode(msg)
+	case ModeRename:
+		return m.handleRenameMode(msg)
+	case ModeClientTarget:
+		return m.handleClientTargetMode(msg)
+	case ModeErrorDetail:
+		return m.handleErrorDetailMode(msg)
+	case ModeSnapshotDiff:
+		return m.handleSnapshotDiffMode(msg)
+	case ModeCommand:
+		return m.handleCommandMode(msg)
+	case ModeConfirmBroadcast:
+		return m.handleConfirmBroadcastMode(msg)
+	case ModeBroadcastReport:
+		return m.handleBroadcastReportMode(msg)
+	case ModeConfirmWorktree:
+		return m.handleConfirmWorktreeMode(msg)
 	}
 	return m, nil
 }
 
 func (m *Model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	keys := ui.DefaultKeyMap
+	if model, cmd, ok := m.handleSequence(msg); ok {
+		return model, cmd
+	}
 
 	switch {
 	case key.Matches(msg, keys.Quit):
 		return m, tea.Quit
 
 	case key.Matches(msg, keys.Cancel):
-		// Escape: clear filter if active, otherwise quit
+		// Escape: leave the window pane, clear marks, then filter, then the
+		// group focus, otherwise quit
+		if m.splitFocus {
+			m.splitFocus = false
+			return m, nil
+		}
+		if len(m.marked) > 0 {
+			m.clearMarks()
+			return m, nil
+		}
 		if m.filter != "" {
-			m.filter = ""
-			m.rebuildItems()
+			m.clearFilter()
+			return m, nil
+		}
+		if m.focusGroup != "" {
+			m.clearFocus()
 			return m, nil
 		}
 		return m, tea.Quit
 
+	case m.readOnlyBlocked(msg):
+		return m.refuseReadOnly()
+
+	case m.splitFocus && key.Matches(msg, keys.Up):
+		m.moveSplitCursor(-1)
+
+	case m.splitFocus && key.Matches(msg, keys.Down):
+		m.moveSplitCursor(1)
+
 	case key.Matches(msg, keys.Up):
 		if m.cursor > 0 {
 			m.cursor--
@@ -208,26 +676,106 @@ func (m *Model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.updateScrollOffset()
 		}
 
+	case key.Matches(msg, keys.Mark):
+		m.toggleMark()
+
+	case key.Matches(msg, keys.MarkAll):
+		m.markAll()
+
+	// In the split view, expand/collapse move focus between the panes
+	case m.config.SplitView && key.Matches(msg, keys.Expand):
+		m.focusWindowPane()
+
+	case m.config.SplitView && key.Matches(msg, keys.Collapse):
+		m.splitFocus = false
+
 	case key.Matches(msg, keys.Expand):
-		m.expandCurrent()
+		return m, m.expandCurrent()
 
 	case key.Matches(msg, keys.Collapse):
 		m.collapseCurrent()
 
 	case key.Matches(msg, keys.Select):
-		return m.selectCurrent()
+		return m.selectCurrent(false)
+
+	case key.Matches(msg, keys.SelectZoom):
+		return m.selectCurrent(true)
 
 	case key.Matches(msg, keys.Kill):
 		return m.confirmKill()
 
+	case key.Matches(msg, keys.KillNumber):
+		num, _ := strconv.Atoi(strings.TrimPrefix(msg.String(), "alt+"))
+		return m.confirmKillNumber(num)
+
+	// Filter history (only while filtering, otherwise C-p/C-n open projects/create)
+	case m.filter != "" && key.Matches(msg, keys.HistoryPrev):
+		m.cycleFilterHistory(1)
+
+	case m.filter != "" && key.Matches(msg, keys.HistoryNext):
+		m.cycleFilterHistory(-1)
+
 	case key.Matches(msg, keys.Create):
-		m.mode = ModeCreate
-		m.filter = "" // Clear any active filter
-		// Reset input completely
-		m.input.Reset()
-		m.input.SetValue("")
-		m.input.Focus()
-		return m, textinput.Blink
+		return m.startCreate("", "")
+
+	case key.Matches(msg, keys.Back):
+		return m.stepSwitchHistory(-1)
+
+	case key.Matches(msg, keys.Forward):
+		return m.stepSwitchHistory(1)
+
+	case key.Matches(msg, keys.Suspend):
+		return m.toggleSuspend()
+
+	case key.Matches(msg, keys.SizeProfile):
+		return m.cycleSizeProfile()
+
+	case key.Matches(msg, keys.RowDetail):
+		return m.toggleRowDetail()
+
+	case key.Matches(msg, keys.MoveUp):
+		return m.moveSession(-1)
+
+	case key.Matches(msg, keys.MoveDown):
+		return m.moveSession(1)
+
+	case key.Matches(msg, keys.SwitchClient):
+		return m.openClientTarget()
+
+	case key.Matches(msg, keys.DetachSmall):
+		return m.detachSmallClients()
+
+	case key.Matches(msg, keys.Yank):
+		return m.yank(false)
+
+	case key.Matches(msg, keys.YankPath):
+		return m.yank(true)
+
+	case key.Matches(msg, keys.ErrorDetail):
+		return m.openErrorDetail()
+
+	case key.Matches(msg, keys.SnapshotDiff):
+		return m.openSnapshotDiff()
+
+	case key.Matches(msg, keys.CycleMatcher):
+		return m.cycleMatcher()
+
+	case key.Matches(msg, keys.JoinMarked):
+		return m.useMarkedPane(false)
+
+	case key.Matches(msg, keys.SwapMarked):
+		return m.useMarkedPane(true)
+
+	case key.Matches(msg, keys.CreateGrouped):
+		return m.startGrouped()
+
+	case key.Matches(msg, keys.CreateHere):
+		dir, err := tmux.CurrentPanePath()
+		if err != nil {
+			m.setError("Error: %v", err)
+			return m, nil
+		}
+		return m.startCreate(dir, sanitizeSessionName(filepath.Base(dir)))
 
 	case key.Matches(msg, keys.PickDirectory):
 		m.mode = ModePickDirectory
@@ -235,10 +783,53 @@ func (m *Model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.projectFilter = ""
 		m.projectCursor = 0
 		m.projectScrollOffset = 0
-		m.projectDirs = m.scanProjectDirectories()
+		scan := m.loadProjectDirs()
 		m.projectFiltered = m.projectDirs
 		// Request window size to get proper height for layout
-		return m, tea.WindowSize()
+		return m, tea.Batch(tea.WindowSize(), scan)
+
+	case key.Matches(msg, keys.ToggleServer):
+		return m.toggleServer()
+
+	case key.Matches(msg, keys.AddNote):
+		return m.openNoteInput()
+
+	case key.Matches(msg, keys.ViewNotes):
+		return m.openNotes()
+
+	case key.Matches(msg, keys.NotesPane):
+		return m.toggleNotesPane()
+
+	case key.Matches(msg, keys.PreviewPane):
+		return m.togglePreviewPane()
+
+	case key.Matches(msg, keys.Archive):
+		return m.archiveCurrent()
+
+	case key.Matches(msg, keys.JumpWaiting):
+		return m.jumpToWaiting()
+
+	case key.Matches(msg, keys.AgentsView):
+		return m.toggleAgentsView()
+
+	case key.Matches(msg, keys.Tag):
+		return m.openTagInput()
+
+	case key.Matches(msg, keys.Merge):
+		return m.openMergeTarget()
+
+	case key.Matches(msg, keys.BreakOut):
+		return m.startBreakOut()
+
+	case key.Matches(msg, keys.Rename):
+		return m.openRename()
+
+	// Empty-state actions (projects and new are always available)
+	case key.Matches(msg, keys.Restore) && m.emptyActionEnabled(config.EmptyActionRestore):
+		return m.resurrectSession(m.recent[0])
+
+	case key.Matches(msg, keys.EditConfig) && m.emptyActionEnabled(config.EmptyActionConfig):
+		return m.editConfig()
 
 	// Number jumps (only when no filter active)
 	case m.filter == "" && key.Matches(msg, keys.Jump1):
@@ -263,34 +854,90 @@ func (m *Model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case msg.Type == tea.KeyBackspace:
 		if len(m.filter) > 0 {
 			m.filter = m.filter[:len(m.filter)-1]
+			m.resetFilterHistory()
 			m.rebuildItems()
 		}
 
+	case msg.Type == tea.KeyRunes && m.filter == "" && msg.Runes[0] == ':':
+		return m.openPalette(string(msg.Runes[1:]))
+
 	case msg.Type == tea.KeyRunes:
-		// Add typed characters to filter
-		m.filter += string(msg.Runes)
-		m.rebuildItems()
+		m.typeFilter(string(msg.Runes))
 	}
 
 	return m, nil
 }
 
+// typeFilter adds typed characters to the filter
+func (m *Model) typeFilter(text string) {
+	m.rememberPreFilterSession()
+	m.filter += text
+	m.resetFilterHistory()
+	if narrowsOnAppend(m.matcherKind()) {
+		m.narrowItems()
+	} else {
+		m.rebuildItems()
+	}
+}
+
 func (m *Model) handleConfirmKillMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	keys := ui.DefaultKeyMap
+	if m.killTypeName != "" {
+		return m.handleKillTypeName(msg)
+	}
 
 	switch {
 	case key.Matches(msg, keys.Kill):
 		// Double C-x confirms the kill
 		return m.killCurrent()
 	case key.Matches(msg, keys.Cancel):
-		m.mode = ModeNormal
-		m.message = ""
-		m.killTarget = ""
+		m.cancelKill()
 	}
 
 	return m, nil
 }
 
+// cancelKill leaves the kill confirmation without killing
+func (m *Model) cancelKill() {
+	m.mode = ModeNormal
+	m.prompt = ""
+	m.killTarget = ""
+	m.killPreview = nil
+	m.killTypeName = ""
+	m.worktreeRemoval = nil
+	m.input.Blur()
+}
+
+// confirmKillTimeout returns a command that cancels the kill confirmation
+// once confirm_timeout passes, so a forgotten prompt can't be confirmed later
+func (m *Model) confirmKillTimeout() tea.Cmd {
+	m.confirmSeq++
+	if m.config.ConfirmTimeout <= 0 {
+		return nil
+	}
+	seq := m.confirmSeq
+	return tea.Tick(m.config.ConfirmTimeout, func(time.Time) tea.Msg {
+		return confirmTimeoutMsg{seq: seq}
+	})
+}
+
+// startCreate switches to create mode with the name input prefilled.
+// An empty dir creates the session in the default session directory.
+func (m *Model) startCreate(dir, name string) (tea.Model, tea.Cmd) {
+	m.mode = ModeCreate
+	m.filter = "" // Clear any active filter
+	m.createDir = dir
+	m.createGroup = ""
+	m.breakOutWindow = ""
+	m.createHint = ""
+	// Reset input completely
+	m.input.Reset()
+	m.input.SetValue(name)
+	m.input.CursorEnd()
+	m.input.Focus()
+	return m, textinput.Blink
+}
+
 func (m *Model) handleCreateMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	keys := ui.DefaultKeyMap
 
@@ -306,7 +953,9 @@ func (m *Model) handleCreateMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.setError("Session name cannot be empty")
 			return m, nil
 		}
-		return m.createSession(name)
+		// M-enter inverts the configured behavior
+		background := m.config.CreateInBackground != key.Matches(msg, keys.CreateQuiet)
+		return m.createSession(name, background)
 	}
 
 	// Ignore ctrl key combinations - only pass regular typing to input
@@ -318,6 +967,14 @@ func (m *Model) handleCreateMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	msg, ok, hint := sanitizeCreateKey(msg)
+	if hint != "" {
+		m.createHint = hint
+	}
+	if !ok {
+		return m, nil
+	}
+
 	var cmd tea.Cmd
 	m.input, cmd = m.input.Update(msg)
 	return m, cmd
@@ -378,11 +1035,11 @@ func (m *Model) filterProjectDirs() {
 	if m.projectFilter == "" {
 		m.projectFiltered = m.projectDirs
 	} else {
-		filterLower := strings.ToLower(m.projectFilter)
+		tm := newMatcher(m.matcherKind(), m.projectFilter)
 		m.projectFiltered = nil
 		for _, fullPath := range m.projectDirs {
 			displayPath := m.extractDisplayPath(fullPath)
-			if fuzzyMatch(displayPath, filterLower) {
+			if matches(tm, displayPath) {
 				m.projectFiltered = append(m.projectFiltered, fullPath)
 			}
 		}
@@ -398,34 +1055,58 @@ func (m *Model) filterProjectDirs() {
 }
 
 func (m *Model) createSessionFromDir(fullPath string) (tea.Model, tea.Cmd) {
-	// Extract session name from full path (last N components based on depth)
-	name := m.extractSessionName(fullPath)
+	name := m.resolveNameConflict(m.projectSessionName(fullPath), fullPath)
 
 	// Check if session already exists - if so, just switch to it
 	if tmux.SessionExists(name) {
-		if err := tmux.SwitchClient(name); err != nil {
+		if err := switchClient(name); err != nil {
 			m.setError("Failed to switch: %v", err)
 			return m, m.loadSessions
 		}
+		m.recordSwitch(name)
 		return m, tea.Quit
 	}
 
-	if err := tmux.CreateSession(name, fullPath); err != nil {
+	if m.sessionLimitReached() {
+		return m.confirmEvict(func(m *Model) (tea.Model, tea.Cmd) { return m.createSessionFromDir(fullPath) })
+	}
+	if m.needsLayoutVars(m.config.Layout) {
+		return m.promptLayoutVars(m.config.Layout, func(m *Model) (tea.Model, tea.Cmd) { return m.createSessionFromDir(fullPath) })
+	}
+
+	if err := tmux.CreateSession(name, fullPath, m.config.SessionCommand(m.config.Layout)); err != nil {
 		m.setError("Error: %v", err)
 		m.mode = ModeNormal
 		return m, nil
 	}
 
-	// Apply layout if configured
-	m.applyLayout(name, fullPath)
+	return m.openCreated(name, fullPath, m.config.Layout, false)
+}
+
+// ProjectSessionName names a new session for a project directory like the
+// picker does, following project_depth, session_name and name_conflict
+func ProjectSessionName(cfg config.Config, dir string) string {
+	m := Model{config: cfg}
+	return m.resolveNameConflict(m.projectSessionName(dir), dir)
+}
 
-	// Switch to the new session
-	if err := tmux.SwitchClient(name); err != nil {
-		m.setError("Created but failed to switch: %v", err)
-		return m, m.loadSessions
+// projectSessionName names a session created from a project directory: the
+// session_name template when set, otherwise the directory's last components
+func (m *Model) projectSessionName(fullPath string) string {
+	name := m.extractSessionName(fullPath)
+	if m.config.SessionName == "" {
+		return name
 	}
 
-	return m, tea.Quit
+	vars := gitinfo.Read(fullPath).Vars()
+	for k, v := range vars {
+		vars[k] = sanitizeSessionName(v)
+	}
+	vars["name"] = name
+	if expanded := gitinfo.Expand(m.config.SessionName, vars); expanded != "" {
+		return expanded
+	}
+	return name
 }
 
 // extractSessionName extracts a session name from a full path
@@ -451,101 +1132,121 @@ func (m *Model) extractDisplayPath(fullPath string) string {
 	return strings.Join(parts[len(parts)-depth:], "/")
 }
 
-// scanProjectDirectories scans all configured project directories at the configured depth
-// and returns full paths to each discovered directory
-func (m *Model) scanProjectDirectories() []string {
-	var dirs []string
-	depth := m.config.ProjectDepth
-
-	// Scan each configured base directory
-	for _, baseDir := range m.config.ProjectDirs {
-		m.walkAtDepth(baseDir, "", depth, &dirs)
-	}
-
-	return dirs
-}
-
-// walkAtDepth recursively walks directories and collects full paths at the target depth
-func (m *Model) walkAtDepth(baseDir, currentPath string, remainingDepth int, dirs *[]string) {
-	if remainingDepth == 0 {
-		// We've reached the target depth - add the full path
-		if currentPath != "" {
-			fullPath := filepath.Join(baseDir, currentPath)
-			*dirs = append(*dirs, fullPath)
-		}
-		return
-	}
-
-	// Read the current directory
-	scanPath := filepath.Join(baseDir, currentPath)
-	entries, err := os.ReadDir(scanPath)
-	if err != nil {
-		return
-	}
-
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-		// Skip hidden directories
-		if strings.HasPrefix(entry.Name(), ".") {
-			continue
-		}
-
-		var nextPath string
-		if currentPath == "" {
-			nextPath = entry.Name()
-		} else {
-			nextPath = filepath.Join(currentPath, entry.Name())
-		}
-
-		m.walkAtDepth(baseDir, nextPath, remainingDepth-1, dirs)
-	}
-}
+// switchClient is tmux.SwitchClient, replaced in tests. Every switch the
+// picker makes goes through it.
+var switchClient = tmux.SwitchClient
 
+// handleJump switches to the session labelled num, or to the window labelled
+// num inside an expanded session
 func (m *Model) handleJump(num int) (tea.Model, tea.Cmd) {
 	// Check if we're inside an expanded session - numbers switch to windows
-	if m.cursor >= 0 && m.cursor < len(m.items) {
+	if m.isCursorValid() && !m.items[m.cursor].IsRecent {
 		item := m.items[m.cursor]
 		session := &m.sessions[item.SessionIndex]
 
-		if session.Expanded {
-			// Jump to window number within this session
-			for _, w := range session.Windows {
-				if w.Index == num {
-					target := fmt.Sprintf("%s:%d", session.Name, w.Index)
-					if err := tmux.SwitchClient(target); err != nil {
-						m.setError("Error: %v", err)
-						return m, nil
-					}
-					return m, tea.Quit
+		if session.Expanded || m.splitFocus {
+			// Jump to the window labelled num within this session
+			if w, ok := windowForKey(session.Windows, num); ok {
+				if err := performAction(m.config.Actions.Window, w.Target(session.Name)); err != nil {
+					m.setError("Error: %v", err)
+					return m, nil
 				}
+				m.recordSwitch(session.Name)
+				return m, tea.Quit
 			}
 		}
 	}
 
-	// Session labels: 1, 2, 3... map to session indices 0, 1, 2...
-	sessionIdx := num - 1
-	if sessionIdx >= 0 && sessionIdx < len(m.sessions) {
-		session := m.sessions[sessionIdx]
-		if err := tmux.SwitchClient(session.Name); err != nil {
+	// Session labels count the listed sessions, like the rows number them
+	if i, ok := m.labelledItem(num); ok {
+		session := m.sessions[m.items[i].SessionIndex]
+		if err := switchClient(session.Name); err != nil {
 			m.setError("Error: %v", err)
 			return m, nil
 		}
+		m.recordSwitch(session.Name)
 		return m, tea.Quit
 	}
 
 	return m, nil
 }
 
-func (m *Model) expandCurrent() {
+// windowForKey returns the window number key num jumps to: the num-th window
+// as listed, whatever gaps closed windows left in tmux's indexes
+func windowForKey(windows []tmux.Window, num int) (tmux.Window, bool) {
+	if num < 1 || num > len(windows) {
+		return tmux.Window{}, false
+	}
+	return windows[num-1], true
+}
+
+// windowPickLabel returns the label of the window at position pos of its
+// session: the number key jumping to it, blank past 9
+func windowPickLabel(pos int, selected bool) string {
+	if pos >= 9 {
+		return " "
+	}
+	if selected {
+		return ui.WindowNameSelectedStyle.Render(strconv.Itoa(pos + 1))
+	}
+	return ui.WindowPickStyle.Render(strconv.Itoa(pos + 1))
+}
+
+// toggleServer cycles the targeted tmux server between the default server
+// and the servers configured in config.Servers, then reloads sessions
+func (m *Model) toggleServer() (tea.Model, tea.Cmd) {
+	if len(m.config.Servers) == 0 {
+		m.setError("No servers configured. Add [[servers]] to config.")
+		return m, nil
+	}
+
+	m.serverIdx = (m.serverIdx + 1) % (len(m.config.Servers) + 1)
+	if m.serverIdx == 0 {
+		tmux.SetSocket("")
+		m.currentSession = m.homeSession
+	} else {
+		tmux.SetSocket(m.config.Servers[m.serverIdx-1].Socket)
+		// The picker isn't attached to other servers, so list all their sessions
+		m.currentSession = ""
+	}
+
+	m.sessions = nil
+	m.sessionsLoaded = false
+	m.resetOrder()
+	m.resetHealth()
+	m.items = nil
+	m.filter = ""
+	m.resetFilterHistory()
+	m.cursor = 0
+	m.scrollOffset = 0
+	m.setInfo("Targeting %s server", m.serverName())
+	return m, m.loadSessions
+}
+
+// serverName returns the display name of the targeted tmux server
+func (m *Model) serverName() string {
+	if m.serverIdx == 0 || m.serverIdx > len(m.config.Servers) {
+		return "default"
+	}
+	return m.config.Servers[m.serverIdx-1].Name
+}
+
+// expandCurrent expands the session under the cursor, returning the command
+// that loads its window summaries. When the filter leaves a single session,
+// that one expands wherever the cursor is, and the cursor moves onto its
+// first window.
+func (m *Model) expandCurrent() tea.Cmd {
 	if !m.isCursorValid() {
-		return
+		return nil
 	}
 
 	item := m.items[m.cursor]
+	isolated, ok := m.isolatedSession()
+	if ok {
+		item = m.items[isolated]
+	}
 	if !item.IsSession {
-		return
+		return nil
 	}
 
 	// Collapse all other sessions first
@@ -559,12 +1260,51 @@ func (m *Model) expandCurrent() {
 		windows, err := tmux.ListWindows(session.Name)
 		if err != nil {
 			m.setError("Error loading windows: %v", err)
-			return
+			return nil
 		}
 		session.Windows = windows
 	}
 	session.Expanded = true
 	m.rebuildItems()
+	if ok {
+		m.cursor = m.firstWindowItem(item.SessionIndex)
+	}
+	return loadWindowSummaries(session.Name, session.Windows)
+}
+
+// isolatedSession returns the index of the only session item when the filter
+// leaves exactly one session
+func (m Model) isolatedSession() (int, bool) {
+	if m.filter == "" {
+		return 0, false
+	}
+	found := -1
+	for i, item := range m.items {
+		if !item.IsSession {
+			continue
+		}
+		if found >= 0 {
+			return 0, false
+		}
+		found = i
+	}
+	return found, found >= 0
+}
+
+// firstWindowItem returns the item of a session's first window, or of the
+// session itself when it has no window items
+func (m Model) firstWindowItem(sessionIdx int) int {
+	session := -1
+	for i, item := range m.items {
+		if item.IsRecent || item.SessionIndex != sessionIdx {
+			continue
+		}
+		if !item.IsSession {
+			return i
+		}
+		session = i
+	}
+	return max(session, 0)
 }
 
 func (m *Model) collapseCurrent() {
@@ -573,6 +1313,9 @@ func (m *Model) collapseCurrent() {
 	}
 
 	item := m.items[m.cursor]
+	if item.IsRecent {
+		return
+	}
 
 	var sessionIdx int
 	if item.IsSession {
@@ -593,124 +1336,382 @@ func (m *Model) collapseCurrent() {
 	m.rebuildItems()
 }
 
-func (m *Model) selectCurrent() (tea.Model, tea.Cmd) {
-	if !m.isCursorValid() {
+// selectCurrent runs the select action for the item under the cursor.
+// The zoom variant zooms the active pane, or only switches if the
+// configured action already zooms.
+func (m *Model) selectCurrent(zoom bool) (tea.Model, tea.Cmd) {
+	item, ok := m.selectedItem()
+	if !ok {
+		return m, nil
+	}
+
+	if item.IsRecent {
+		if m.config.ReadOnly {
+			return m.refuseReadOnly()
+		}
+		m.recordFilter()
+		if item.Archived {
+			return m.restoreArchived(m.archived[item.RecentIndex])
+		}
+		return m.resurrectSession(m.recent[item.RecentIndex])
+	}
+	action := m.selectAction(item)
+	if zoom {
+		action = zoomVariant(action)
+	}
+	if err := performAction(action, m.getTargetName(item)); err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+
+	m.recordSwitch(m.sessions[item.SessionIndex].Name)
+	m.recordFilter()
+	return m, tea.Quit
+}
+
+// selectAction returns the configured select action for the item type
+func (m *Model) selectAction(item Item) string {
+	if item.IsSession {
+		return m.config.Actions.Session
+	}
+	if item.IsPane {
+		return m.config.Actions.Pane
+	}
+	return m.config.Actions.Window
+}
+
+// zoomVariant returns the action run by the zoom select variant
+func zoomVariant(action string) string {
+	if action == config.ActionZoom {
+		return config.ActionSwitch
+	}
+	return config.ActionZoom
+}
+
+// performAction executes a select action against a tmux target
+func performAction(action, target string) error {
+	switch action {
+	case config.ActionZoom:
+		if err := switchClient(target); err != nil {
+			return err
+		}
+		return tmux.ZoomPane(target)
+	case config.ActionBreak:
+		return tmux.BreakPane(target)
+	default:
+		return switchClient(target)
+	}
+}
+
+func (m *Model) confirmKill() (tea.Model, tea.Cmd) {
+	if len(m.marked) > 0 {
+		m.killPreview = m.markedPreview()
+		m.killTarget = pluralize(len(m.killPreview), "marked item")
+		m.prompt = fmt.Sprintf("Kill %s?", m.killTarget)
+		if sessions, _ := m.markedTargets(); len(sessions) > 0 {
+			if warning := m.detachWarning(sessions); warning != "" {
+				m.prompt = fmt.Sprintf("Kill %s? %s", m.killTarget, warning)
+			}
+		}
+		// A big session among the marks asks for its name like on its own
+		for i, s := range m.sessions {
+			if m.marked[s.Name] && m.needsKillTypeName(i) {
+				return m.startKillTypeName(s.Name)
+			}
+		}
+		m.mode = ModeConfirmKill
+		return m, m.confirmKillTimeout()
+	}
+
+	item, ok := m.selectedItem()
+	if !ok {
+		return m, nil
+	}
+
+	if item.IsRecent && item.Archived {
+		return m.deleteArchived(m.archived[item.RecentIndex].Name)
+	}
+	if item.IsRecent {
+		return m.forgetRecent(m.recent[item.RecentIndex].Name)
+	}
+	m.killTarget = m.displayName(item)
+	m.killPreview = nil
+
+	if item.IsSession {
+		m.prompt = fmt.Sprintf("Kill \"%s\"?", m.killTarget)
+		if windows, err := tmux.ListWindows(m.getTargetName(item)); err == nil {
+			m.killPreview = killPreviewForWindows(windows)
+			m.prompt = fmt.Sprintf("Kill \"%s\"? (%s)", m.killTarget, pluralize(len(windows), "window"))
+		}
+		if m.groupShared(item.SessionIndex) {
+			m.killPreview = nil
+			m.prompt = fmt.Sprintf("Kill \"%s\"? (its windows stay in group %s)", m.killTarget, m.sessions[item.SessionIndex].Group)
+		}
+		if warning := m.detachWarning([]string{m.getTargetName(item)}); warning != "" {
+			m.prompt += " " + warning
+		}
+		if m.needsKillTypeName(item.SessionIndex) {
+			return m.startKillTypeName(m.killTarget)
+		}
+	} else if item.IsPane {
+		m.prompt = fmt.Sprintf("Kill pane \"%s\"? (%s)", m.killTarget, m.itemPane(item).Command)
+	} else {
+		m.prompt = fmt.Sprintf("Kill window \"%s\"?", m.killTarget)
+		if panes, err := tmux.ListPanes(m.getTargetName(item)); err == nil {
+			m.killPreview = killPreviewForPanes(panes)
+			m.prompt = fmt.Sprintf("Kill window \"%s\"? (%s)", m.killTarget, pluralize(len(panes), "pane"))
+		}
+	}
+
+	m.mode = ModeConfirmKill
+	return m, m.confirmKillTimeout()
+}
+
+// confirmKillNumber asks to kill the session labelled num, moving the cursor
+// onto it so the list shows what goes
+func (m *Model) confirmKillNumber(num int) (tea.Model, tea.Cmd) {
+	if len(m.marked) > 0 {
+		m.setError("Clear the marks (esc) to kill by number")
 		return m, nil
 	}
 
-	target := m.getTargetName(m.items[m.cursor])
-	if err := tmux.SwitchClient(target); err != nil {
-		m.setError("Error: %v", err)
-		return m, nil
+	if i, ok := m.labelledItem(num); ok {
+		m.cursor = i
+		m.splitFocus = false
+		m.updateScrollOffset()
+		return m.confirmKill()
 	}
+	return m, nil
+}
 
-	return m, tea.Quit
+// labelledItem returns the index of the session item labelled num. Labels
+// count the listed sessions, which the agents view filters and reorders, so
+// they don't follow m.sessions.
+func (m *Model) labelledItem(num int) (int, bool) {
+	label := 0
+	for i, item := range m.items {
+		if !item.IsSession {
+			continue
+		}
+		if label++; label == num {
+			return i, true
+		}
+	}
+	return 0, false
 }
 
-func (m *Model) confirmKill() (tea.Model, tea.Cmd) {
-	if !m.isCursorValid() {
-		return m, nil
+// killPreviewForWindows describes the windows (and their running commands) lost by a session kill
+func killPreviewForWindows(windows []tmux.Window) []string {
+	lines := make([]string, len(windows))
+	for i, w := range windows {
+		lines[i] = fmt.Sprintf("%d: %s", w.Index, w.Name)
+		if w.Command != "" {
+			lines[i] += "  (" + w.Command + ")"
+		}
 	}
+	return lines
+}
 
-	item := m.items[m.cursor]
-	m.killTarget = m.getTargetName(item)
+// killPreviewForPanes describes the panes (and their running commands) lost by a window kill
+func killPreviewForPanes(panes []tmux.Pane) []string {
+	lines := make([]string, len(panes))
+	for i, p := range panes {
+		lines[i] = fmt.Sprintf("pane %d: %s", p.Index, p.Command)
+	}
+	return lines
+}
 
-	if item.IsSession {
-		m.message = fmt.Sprintf("Kill \"%s\"?", m.killTarget)
-	} else {
-		m.message = fmt.Sprintf("Kill window \"%s\"?", m.killTarget)
+// killPreviewLines returns the kill preview truncated to maxLines
+func (m *Model) killPreviewLines(maxLines int) []string {
+	return truncateLines(m.killPreview, maxLines)
+}
+
+// truncateLines returns lines truncated to maxLines, replacing the last line
+// with a count of hidden entries when needed
+func truncateLines(lines []string, maxLines int) []string {
+	if len(lines) <= maxLines {
+		return lines
 	}
+	shown := append([]string{}, lines[:maxLines-1]...)
+	return append(shown, fmt.Sprintf("… %d more", len(lines)-maxLines+1))
+}
 
-	m.mode = ModeConfirmKill
-	return m, nil
+// pluralize formats a count with a singular or plural noun
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
 }
 
 func (m *Model) killCurrent() (tea.Model, tea.Cmd) {
-	if !m.isCursorValid() {
+	if len(m.marked) > 0 {
+		// Only windows marked: their sessions are all that changed
+		reload := m.loadSessions
+		if sessions, windows := m.markedTargets(); len(sessions) == 0 {
+			names := make([]string, len(windows))
+			for i, w := range windows {
+				names[i] = w.session
+			}
+			reload = refreshSessions(m.serverIdx, names)
+		}
+
+		killed, err := m.killMarked()
+		if err != nil {
+			m.setError("Error: %v", err)
+		} else {
+			m.setInfo("Killed %s", pluralize(killed, "item"))
+		}
+		m.cancelKill()
+		return m, reload
+	}
+
+	item, ok := m.selectedItem()
+	if !ok {
 		return m, nil
 	}
 
-	item := m.items[m.cursor]
 	var err error
+	reload := m.loadSessions
 
 	if item.IsSession {
 		session := m.sessions[item.SessionIndex]
-		err = tmux.KillSession(session.Name)
+		err = tmux.KillSessionSafe(session.Name)
+		if err == nil {
+			m.setInfo("Killed \"%s\"", session.Name)
+			m.logEvent(state.EventKill, session.Name)
+		}
+	} else if item.IsPane {
+		// The full reload lists the remaining panes
+		pane := m.itemPane(item)
+		err = tmux.KillPane(pane.ID)
 		if err == nil {
-			m.message = fmt.Sprintf("Killed \"%s\"", session.Name)
+			m.setInfo("Killed pane %d", pane.Index)
 		}
 	} else {
 		session := m.sessions[item.SessionIndex]
 		window := session.Windows[item.WindowIndex]
-		err = tmux.KillWindow(session.Name, window.Index)
+		err = tmux.KillWindow(window.Target(session.Name))
 		if err == nil {
-			m.message = fmt.Sprintf("Killed window %d", window.Index)
+			m.setInfo("Killed window %d", window.Index)
 		}
+		reload = refreshSession(m.serverIdx, session.Name)
 	}
 
 	if err != nil {
 		m.setError("Error: %v", err)
 	}
 
-	m.mode = ModeNormal
-	m.killTarget = ""
+	removal := m.worktreeRemoval
+	m.cancelKill()
+
+	// :worktree asks again before the worktree goes too
+	if err == nil && removal != nil && item.IsSession && m.sessions[item.SessionIndex].Name == removal.session {
+		m.confirmWorktreeRemoval(removal)
+	}
 
-	// Reload sessions and clear message after 5 seconds
-	return m, tea.Batch(m.loadSessions, clearMessageAfter(5*time.Second))
+	// Reload, the killed item is gone
+	return m, reload
 }
 
-func (m *Model) createSession(name string) (tea.Model, tea.Cmd) {
+// createSession creates a session in the prompt's directory and applies the
+// layout. In the background it stays in the picker, otherwise it switches.
+func (m *Model) createSession(name string, background bool) (tea.Model, tea.Cmd) {
+	// tmux would destroy the session before anyone attaches to it
+	if background && destroysUnattached() {
+		m.setError("tmux destroys unattached sessions (destroy-unattached), create it in the foreground")
+		return m, nil
+	}
+	if m.sessionLimitReached() {
+		return m.confirmEvict(func(m *Model) (tea.Model, tea.Cmd) { return m.createSession(name, background) })
+	}
+
 	// Sanitize session name (spaces, dots, colons break tmux target syntax)
 	name = sanitizeSessionName(name)
-	workingDir := m.config.DefaultSessionDir
-	if err := tmux.CreateSession(name, workingDir); err != nil {
+	workingDir := m.createDir
+	if workingDir == "" {
+		workingDir = m.config.DefaultSessionDir
+	}
+	if m.createGroup != "" {
+		return m.createGrouped(name, background)
+	}
+	if m.breakOutWindow != "" {
+		return m.breakOut(name, background)
+	}
+	if m.needsLayoutVars(m.config.Layout) {
+		return m.promptLayoutVars(m.config.Layout, func(m *Model) (tea.Model, tea.Cmd) { return m.createSession(name, background) })
+	}
+	if err := tmux.CreateSession(name, workingDir, m.config.SessionCommand(m.config.Layout)); err != nil {
 		m.setError("Error: %v", err)
 		m.mode = ModeNormal
 		m.input.Blur()
 		return m, nil
 	}
 
-	// Apply layout if configured
-	m.applyLayout(name, workingDir)
+	return m.openCreated(name, workingDir, m.config.Layout, background)
+}
 
-	// Switch to the new session
-	if err := tmux.SwitchClient(name); err != nil {
-		m.setError("Created but failed to switch: %v", err)
-		return m, m.loadSessions
-	}
+// announceCreated reports a session created in the background along with
+// the number that switches to it
+func (m *Model) announceCreated() {
+	name := m.createdSession
+	m.createdSession = ""
 
-	return m, tea.Quit
+	for num := 1; num <= 9; num++ {
+		if i, ok := m.labelledItem(num); ok && m.sessions[m.items[i].SessionIndex].Name == name {
+			m.setInfo("Created %s (press %d to switch)", name, num)
+			return
+		}
+	}
+	m.setInfo("Created %s", name)
 }
 
-func (m *Model) applyLayout(sessionName, workingDir string) {
-	if m.config.Layout == "" {
-		return
+// loadClaudeStatuses returns a command that reads all session status files
+// concurrently, so slow filesystems don't block the Update loop
+func (m *Model) loadClaudeStatuses() tea.Cmd {
+	if !m.config.ClaudeStatusEnabled {
+		return nil
 	}
-
-	scriptPath := fmt.Sprintf("%s/%s.sh", m.config.LayoutDir, m.config.Layout)
-	if _, err := os.Stat(scriptPath); err != nil {
-		return
+	names := make([]string, len(m.sessions))
+	for i, s := range m.sessions {
+		names[i] = s.Name
+	}
+	cacheDir := m.config.CacheDir
+	return func() tea.Msg {
+		defer profile.Start("claude statuses")()
+		return claudeStatusesMsg{claude.GetStatuses(names, cacheDir)}
 	}
-
-	// Run layout script synchronously before switching to the session
-	cmd := exec.Command(scriptPath, sessionName, workingDir)
-	cmd.Env = append(os.Environ(),
-		"TMUX_SESSION="+sessionName,
-		"TMUX_WORKING_DIR="+workingDir,
-	)
-	_ = cmd.Run()
 }
 
-func (m *Model) loadClaudeStatuses() {
-	m.claudeStatuses = make(map[string]claude.Status)
-	if !m.config.ClaudeStatusEnabled {
-		return
+// cleanupState returns a command that removes the Claude status files of
+// sessions that are gone and the tags and notes of sessions closed for longer
+// than state_ttl. Runs on the first load; the sessions of the configured
+// servers count as live too, and when one of them can't be listed nothing is
+// removed.
+func (m *Model) cleanupState() tea.Cmd {
+	if m.serverIdx != 0 {
+		return nil
 	}
+	live := make([]string, 0, len(m.sessions)+1)
 	for _, s := range m.sessions {
-		status := claude.GetStatus(s.Name, m.config.CacheDir)
-		if status.State != "" {
-			m.claudeStatuses[s.Name] = status
+		live = append(live, s.Name)
+	}
+	if m.currentSession != "" {
+		live = append(live, m.currentSession)
+	}
+	cfg := m.config
+	return func() tea.Msg {
+		defer profile.Start("cleanup")()
+		others, err := tmux.SessionNames(cfg.Sockets()...)
+		if err != nil {
+			return nil
+		}
+		live = append(live, others...)
+		claude.CleanupStale(cfg.CacheDir, live)
+		if cfg.StateBackend == config.BackendFile && cfg.StateTTL > 0 {
+			_, _ = state.PruneClosed(cfg.StateDir, live, cfg.StateTTL, time.Now())
 		}
+		return nil
 	}
 }
 
@@ -724,31 +1725,113 @@ func (m *Model) calculateColumnWidths() {
 }
 
 func (m *Model) rebuildItems() {
-	m.items = nil
-	filterLower := strings.ToLower(m.filter)
+	m.buildItems(nil, nil, nil)
+}
 
-	for i, session := range m.sessions {
-		// Apply fuzzy filter if active
-		if m.filter != "" && !fuzzyMatch(session.Name, filterLower) {
-			continue
+// narrowItems rebuilds the items after characters were appended to the filter.
+// Appending only adds constraints (text matches other than regex, and tag
+// prefix matches), so only sessions, recent and archived entries that
+// matched the shorter filter are checked.
+func (m *Model) narrowItems() {
+	sessions := []int{}
+	recent := []int{}
+	archived := []int{}
+	for _, item := range m.items {
+		switch {
+		case item.Archived:
+			archived = append(archived, item.RecentIndex)
+		case item.IsRecent:
+			recent = append(recent, item.RecentIndex)
+		case item.IsSession:
+			sessions = append(sessions, item.SessionIndex)
 		}
+	}
+	m.buildItems(sessions, recent, archived)
+}
+
+// buildItems flattens the sessions, their windows, and the recent and
+// archived entries matching the filter into items. Nil candidates check
+// everything; otherwise only the given session, recent and archived indices
+// are checked. The items slice is reused to avoid an allocation per
+// keystroke.
+func (m *Model) buildItems(sessions, recent, archived []int) {
+	m.items = m.items[:0]
+	filterTags, filterCommands, filterText := parseFilter(m.filter)
+	m.filterMatcher = newMatcher(m.matcherKind(), filterText)
+	m.filterCommands = filterCommands
+
+	listed := m.filteredSessions(filterTags, filterCommands, m.filterMatcher, sessions)
+	if m.agentsView {
+		listed = m.agentSessions(listed)
+	}
+	if m.focusGroup != "" {
+		listed = m.groupSessions(listed)
+	}
+	for _, i := range listed {
+		session := m.sessions[i]
+		windowMatch := m.filterMatcher != nil && hasMatchingWindow(session, m.filterMatcher) || len(filterCommands) > 0
 
 		m.items = append(m.items, Item{
 			IsSession:    true,
 			SessionIndex: i,
 		})
 
-		if session.Expanded {
-			for j := range session.Windows {
+		// Show where a window match lives; the split view shows windows in its pane
+		if session.Expanded || (windowMatch && !m.config.SplitView) {
+			for j, window := range session.Windows {
 				m.items = append(m.items, Item{
 					IsSession:    false,
 					SessionIndex: i,
 					WindowIndex:  j,
 				})
+				for k := range m.treePanes(window) {
+					m.items = append(m.items, Item{
+						SessionIndex: i,
+						WindowIndex:  j,
+						IsPane:       true,
+						PaneIndex:    k,
+					})
+				}
 			}
 		}
 	}
 
+	// Closed sessions run nothing and belong to no group, a command filter or
+	// group focus leaves them out
+	for i, entry := range m.recent {
+		if m.agentsView || m.focusGroup != "" || len(filterCommands) > 0 {
+			break
+		}
+		if recent != nil && !slices.Contains(recent, i) {
+			continue
+		}
+		if !m.matchesFilter(entry.Name, filterTags, m.filterMatcher) {
+			continue
+		}
+		m.items = append(m.items, Item{
+			IsRecent:    true,
+			RecentIndex: i,
+		})
+	}
+
+	// Archived sessions come last, after the recent ones
+	for i, entry := range m.archived {
+		if m.agentsView || m.focusGroup != "" || len(filterCommands) > 0 {
+			break
+		}
+		if archived != nil && !slices.Contains(archived, i) {
+			continue
+		}
+		if !m.matchesFilter(entry.Name, filterTags, m.filterMatcher) {
+			continue
+		}
+		m.items = append(m.items, Item{
+			IsRecent:    true,
+			Archived:    true,
+			RecentIndex: i,
+		})
+	}
+
 	// Ensure cursor is in bounds
 	if m.cursor >= len(m.items) {
 		m.cursor = len(m.items) - 1
@@ -778,10 +1861,14 @@ func (m *Model) updateScrollOffset() {
 
 // contentWidth returns the available width inside the app border/padding
 func (m *Model) contentWidth() int {
+	width := 60 // Default fallback
 	if m.width > 0 {
-		return m.width - ui.AppBorderOverheadX
+		width = m.width
 	}
-	return 56 // Default fallback (60 - 4)
+	if limit := m.sizeProfile().Width; limit > 0 && limit < width {
+		width = limit
+	}
+	return width - ui.AppBorderOverheadX
 }
 
 // contentHeight returns the available height inside the app border/padding
@@ -803,9 +1890,10 @@ func (m *Model) sessionMaxVisibleItems() int {
 	maxItems := m.config.MaxVisibleItems
 	contentH := m.contentHeight()
 	if contentH > 0 {
-		// Reserve: header(1) + header border(1) + footer border(1) + statusline(1) + help(1) = 5 lines
-		// Message line adds 1 when present, but we use 5 for normal case
-		availableForContent := contentH - 5
+		// Reserve: header(1) + header border(1) + footer border(1) + statusline(1) + help(1 unless hidden)
+		// Message line adds 1 when present, but we ignore it for the normal case;
+		// toasts stacked beyond it do count
+		availableForContent := contentH - 4 - m.helpLines() - m.extraMessageLines()
 		if availableForContent < maxItems && availableForContent > 0 {
 			maxItems = availableForContent
 		}
@@ -852,12 +1940,68 @@ func (m *Model) updateProjectScrollOffset() {
 	}
 }
 
+// hasMatchingWindow reports whether any loaded window name matches the filter
+func hasMatchingWindow(session tmux.Session, tm textMatcher) bool {
+	for _, w := range session.Windows {
+		if tm != nil && matches(tm, w.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// windowLabel renders "index: name" for a window row. While filtering, the
+// matched part of the name is highlighted and non-matching windows are dimmed.
+func (m Model) windowLabel(window tmux.Window, nameWidth int, selected bool) string {
+	name := ui.TruncateMiddle(window.Name, nameWidth)
+	label := fmt.Sprintf("%d: %s", window.Index, name)
+
+	switch {
+	case selected:
+		return ui.WindowNameSelectedStyle.Render(label)
+	case len(m.filterCommands) > 0 && !m.windowRunsCommands(window, m.filterCommands):
+		return ui.DimStyle.Render(label)
+	case m.filterMatcher == nil:
+		return label
+	}
+	if spans, ok := m.filterMatcher.match(name); ok {
+		return fmt.Sprintf("%d: %s", window.Index, highlightMatch(name, spans))
+	}
+	// The match may be in the part cut from the name
+	if matches(m.filterMatcher, window.Name) {
+		return label
+	}
+	return ui.DimStyle.Render(label)
+}
+
+// highlightMatch renders the matched spans of text with the match style
+func highlightMatch(text string, spans []span) string {
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		b.WriteString(text[last:s[0]])
+		b.WriteString(ui.MatchStyle.Render(text[s[0]:s[1]]))
+		last = s[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
 // fuzzyMatch checks if the pattern matches the text (case-insensitive, substring match)
 func fuzzyMatch(text, pattern string) bool {
 	textLower := strings.ToLower(text)
 	return strings.Contains(textLower, pattern)
 }
 
+// scrollPosition tells the cursor's row out of all rows (e.g. "12/47") when
+// the list is longer than the viewport, empty otherwise
+func (m Model) scrollPosition(maxVisible int) string {
+	if len(m.items) <= maxVisible || !m.isCursorValid() {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", m.cursor+1, len(m.items))
+}
+
 // isCursorValid returns true if cursor points to a valid item
 func (m *Model) isCursorValid() bool {
 	return m.cursor >= 0 && m.cursor < len(m.items)
@@ -865,18 +2009,45 @@ func (m *Model) isCursorValid() bool {
 
 // getTargetName returns the tmux target name for the given item
 func (m *Model) getTargetName(item Item) string {
+	if item.IsRecent {
+		return m.recentName(item)
+	}
+	if item.IsSession {
+		return m.sessions[item.SessionIndex].Name
+	}
+	if item.IsPane {
+		return m.itemPane(item).ID
+	}
+	session := m.sessions[item.SessionIndex]
+	window := session.Windows[item.WindowIndex]
+	return window.Target(session.Name)
+}
+
+// displayName returns a human-readable name (session, session:index or
+// session:index.pane) for the given item
+func (m *Model) displayName(item Item) string {
+	if item.IsRecent {
+		return m.recentName(item)
+	}
 	if item.IsSession {
 		return m.sessions[item.SessionIndex].Name
 	}
 	session := m.sessions[item.SessionIndex]
 	window := session.Windows[item.WindowIndex]
+	if item.IsPane {
+		return fmt.Sprintf("%s:%d.%d", session.Name, window.Index, m.itemPane(item).Index)
+	}
 	return fmt.Sprintf("%s:%d", session.Name, window.Index)
 }
 
-// setError sets an error message on the model
+// setError shows an error toast, pointing to the details of a failed tmux
+// command among the arguments
 func (m *Model) setError(format string, args ...any) {
-	m.message = fmt.Sprintf(format, args...)
-	m.messageIsError = true
+	text := fmt.Sprintf(format, args...)
+	if m.recordTmuxError(args) {
+		text += " (M-e: details)"
+	}
+	m.pushToast(toastError, text)
 }
 
 // sanitizeSessionName converts a path to a valid tmux session name
@@ -894,8 +2065,19 @@ func sanitizeSessionName(name string) string {
 
 // View implements tea.Model
 func (m Model) View() string {
-	if m.mode == ModePickDirectory {
+	profile.Mark("first render")
+
+	switch m.mode {
+	case ModePickDirectory:
 		return m.viewPickDirectory()
+	case ModeNoteInput:
+		return m.viewNoteInput()
+	case ModeNotes:
+		return m.viewNotes()
+	case ModeMergeTarget:
+		return m.viewMergeTarget()
+	case ModeClientTarget:
+		return m.viewClientTarget()
 	}
 	return m.viewSessionList()
 }
@@ -987,6 +2169,9 @@ func (m Model) viewPickDirectory() string {
 	} else {
 		statusline = fmt.Sprintf("%d directories", len(m.projectDirs))
 	}
+	if m.projectScanning {
+		statusline += " · refreshing"
+	}
 	b.WriteString(ui.StatuslineStyle.Render(statusline))
 	b.WriteString("\n")
 
@@ -1003,13 +2188,26 @@ func (m Model) viewSessionList() string {
 	var b strings.Builder
 	usedLines := 0
 
-	// Header with optional filter
+	// Header with optional server and filter
+	b.WriteString(ui.HeaderStyle.Render("tsm"))
+	if m.serverIdx > 0 {
+		b.WriteString(ui.ServerStyle.Render("@" + m.serverName()))
+	}
+	if m.focusGroup != "" {
+		b.WriteString("  " + ui.GroupIcon + " ")
+		b.WriteString(ui.ServerStyle.Render(m.focusGroup))
+	}
 	if m.filter != "" {
-		b.WriteString(ui.HeaderStyle.Render("tsm"))
 		b.WriteString("  ")
 		b.WriteString(ui.FilterStyle.Render(m.filter))
-	} else {
-		b.WriteString(ui.HeaderStyle.Render("tsm"))
+		if kind := m.matcherKind(); kind != config.MatcherSubstring {
+			b.WriteString(" ")
+			b.WriteString(ui.TimeStyle.Render(kind))
+		}
+	}
+	if len(m.marked) > 0 {
+		b.WriteString("  ")
+		b.WriteString(ui.MarkedCountStyle.Render(fmt.Sprintf("%d marked", len(m.marked))))
 	}
 	b.WriteString("\n")
 	usedLines++
@@ -1038,32 +2236,124 @@ func (m Model) viewSessionList() string {
 	}
 
 	contentLines := 0
-	for i := m.scrollOffset; i < endIdx; i++ {
-		item := m.items[i]
-		selected := i == m.cursor
-		lineIdx := i - m.scrollOffset
+	if (m.mode == ModeConfirmKill || m.mode == ModeConfirmEvict || m.mode == ModeConfirmBroadcast || m.mode == ModeConfirmWorktree) && len(m.killPreview) > 0 {
+		// Show what will be lost instead of the list
+		for _, line := range m.killPreviewLines(maxVisible) {
+			b.WriteString(ui.KillPreviewStyle.Render(line))
+			b.WriteString("\n")
+			contentLines++
+		}
+	} else if m.mode == ModeErrorDetail {
+		// Show the failed tmux command instead of the list
+		for _, line := range truncateLines(m.errorDetailLines(), maxVisible) {
+			b.WriteString("  " + truncate(line, m.contentWidth()-2))
+			b.WriteString("\n")
+			contentLines++
+		}
+	} else if m.mode == ModeSnapshotDiff {
+		// Show what tsm restore would change instead of the list
+		for _, line := range truncateLines(m.snapshotDiff, maxVisible) {
+			b.WriteString("  " + truncate(line, m.contentWidth()-2))
+			b.WriteString("\n")
+			contentLines++
+		}
+	} else if m.mode == ModeRename {
+		// Show the resulting names instead of the list
+		for _, line := range truncateLines(m.renamePreview(), maxVisible) {
+			b.WriteString("  " + line)
+			b.WriteString("\n")
+			contentLines++
+		}
+	} else if m.mode == ModeBroadcastReport {
+		// Show where the command was sent instead of the list
+		for _, line := range truncateLines(m.broadcastReport, maxVisible) {
+			b.WriteString("  " + truncate(line, m.contentWidth()-2))
+			b.WriteString("\n")
+			contentLines++
+		}
+	} else if m.mode == ModeCommand {
+		// Show the commands or arguments the command line completes to
+		for _, line := range truncateLines(m.palettePreview(), maxVisible) {
+			b.WriteString("  " + truncate(line, m.contentWidth()-2))
+			b.WriteString("\n")
+			contentLines++
+		}
+	} else {
+		var rows []string
+		for i := m.scrollOffset; i < endIdx; i++ {
+			item := m.items[i]
+			selected := i == m.cursor
+			lineIdx := i - m.scrollOffset
+
+			if ui.Plain {
+				if item.Archived {
+					rows = append(rows, m.renderArchivedPlain(m.archived[item.RecentIndex], selected))
+				} else if item.IsRecent {
+					rows = append(rows, m.renderRecentPlain(m.recent[item.RecentIndex], selected))
+				} else if item.IsSession {
+					sessionNum++
+					session := m.sessions[item.SessionIndex]
+					rows = append(rows, m.renderSessionPlain(session, sessionNum, m.isLastUsed(sessionNum), m.isMarked(item), selected))
+				} else if item.IsPane {
+					rows = append(rows, m.renderPanePlain(m.itemPane(item), m.treePrefix(item), selected))
+				} else {
+					session := m.sessions[item.SessionIndex]
+					rows = append(rows, m.renderWindowPlain(session.Name, session.Windows[item.WindowIndex], item.WindowIndex, m.treePrefix(item), m.isMarked(item), selected))
+				}
+				continue
+			}
 
-		// Scrollbar on the left
-		if lineIdx < len(scrollbar) {
-			b.WriteString(scrollbar[lineIdx])
+			var row strings.Builder
+
+			// Scrollbar on the left
+			if lineIdx < len(scrollbar) {
+				row.WriteString(scrollbar[lineIdx])
+			}
+
+			// Mark column
+			if m.isMarked(item) {
+				row.WriteString(ui.MarkIcon)
+			} else {
+				row.WriteString(" ")
+			}
+
+			if item.Archived {
+				row.WriteString(m.renderArchived(m.archived[item.RecentIndex], selected))
+			} else if item.IsRecent {
+				row.WriteString(m.renderRecent(m.recent[item.RecentIndex], selected))
+			} else if item.IsSession {
+				session := m.sessions[item.SessionIndex]
+				sessionNum++
+				isFirst := m.isLastUsed(sessionNum)
+				row.WriteString(m.renderSessionWithLabel(session, sessionNum, isFirst, selected))
+			} else if item.IsPane {
+				row.WriteString(m.renderPane(m.itemPane(item), m.treePrefix(item), selected))
+			} else {
+				session := m.sessions[item.SessionIndex]
+				window := session.Windows[item.WindowIndex]
+				row.WriteString(m.renderWindow(session.Name, window, item.WindowIndex, m.treePrefix(item), selected))
+			}
+			rows = append(rows, row.String())
 		}
 
-		if item.IsSession {
-			session := m.sessions[item.SessionIndex]
-			sessionNum++
-			isFirst := sessionNum == 1
-			b.WriteString(m.renderSessionWithLabel(session, sessionNum, isFirst, selected))
-		} else {
-			session := m.sessions[item.SessionIndex]
-			window := session.Windows[item.WindowIndex]
-			b.WriteString(m.renderWindow(window, selected))
+		if m.config.SplitView && len(rows) > 0 {
+			rows = m.joinSplitPane(rows, maxVisible)
+		}
+		for _, row := range rows {
+			b.WriteString(row)
+			b.WriteString("\n")
+			contentLines++
 		}
-		b.WriteString("\n")
-		contentLines++
 	}
 
 	// Empty state
-	if len(m.items) == 0 {
+	if m.isEmptyState() && m.mode != ModeConfirmKill && m.mode != ModeConfirmEvict {
+		for _, line := range m.emptyStateLines() {
+			b.WriteString(line)
+			b.WriteString("\n")
+			contentLines++
+		}
+	} else if len(m.items) == 0 {
 		if m.filter != "" {
 			b.WriteString("  No sessions matching filter\n")
 		} else {
@@ -1073,21 +2363,44 @@ func (m Model) viewSessionList() string {
 	}
 	usedLines += contentLines
 
-	// Message line content (only rendered when there's content)
+	// Message line content: the pending confirmation or the mode's input
 	var messageContent string
-	if m.message != "" {
-		if m.messageIsError {
-			messageContent = ui.ErrorMessageStyle.Render(m.message)
-		} else {
-			messageContent = ui.MessageStyle.Render(m.message)
+	if m.prompt != "" {
+		messageContent = ui.MessageStyle.Render(m.prompt)
+		if m.killTypeName != "" {
+			messageContent += " " + m.input.View()
 		}
+	} else if m.mode == ModeTagInput {
+		messageContent = ui.InputPromptStyle.Render(fmt.Sprintf(" Tags for %s: ", m.tagTarget)) + m.input.View()
+	} else if m.mode == ModeRename {
+		prompt := fmt.Sprintf(" Rename %s: ", pluralize(len(m.renameTargets), "session"))
+		messageContent = ui.InputPromptStyle.Render(prompt) + m.input.View()
+	} else if m.mode == ModeLayoutVars {
+		messageContent = ui.InputPromptStyle.Render(m.layoutVarPrompt()) + m.input.View()
+	} else if m.mode == ModeCommand {
+		messageContent = ui.InputPromptStyle.Render(" :") + m.input.View()
 	} else if m.mode == ModeCreate {
-		messageContent = ui.InputPromptStyle.Render(" New session: ") + m.input.View()
+		prompt := " New session: "
+		switch {
+		case m.createGroup != "":
+			prompt = fmt.Sprintf(" New session grouped with %s: ", m.createGroup)
+		case m.breakOutWindow != "":
+			prompt = fmt.Sprintf(" Break %s out into session: ", m.breakOutLabel)
+		case m.createDir != "":
+			prompt = fmt.Sprintf(" New session in %s: ", m.extractDisplayPath(m.createDir))
+		}
+		messageContent = ui.InputPromptStyle.Render(prompt) + m.input.View() + m.createStatus()
+	}
+
+	// Toasts stack above the message line; the newest takes it when empty
+	messageLines := append(m.toastLines(), messageContent)
+	if messageContent == "" && len(m.toasts) > 0 {
+		messageLines = messageLines[:len(messageLines)-1]
 	}
 
 	// Add padding to push footer to bottom
-	// Footer: border (1) + message (1) + statusline (1) + help (1)
-	footerLines := 4
+	// Footer: border (1) + messages (1 or more) + statusline (1) + help (1 unless hidden)
+	footerLines := 2 + len(messageLines) + m.helpLines()
 	contentH := m.contentHeight()
 	if contentH > 0 {
 		padding := contentH - usedLines - footerLines
@@ -1099,108 +2412,285 @@ func (m Model) viewSessionList() string {
 	b.WriteString(ui.RenderBorder(m.borderWidth()))
 	b.WriteString("\n")
 
-	// Message line (always present, may be empty)
-	b.WriteString(messageContent)
+	// Message lines (at least one, may be empty)
+	b.WriteString(strings.Join(messageLines, "\n"))
 	b.WriteString("\n")
 
 	// Statusline (session counts)
 	var statusline string
-	if m.filter != "" {
-		// Count visible sessions (items that are sessions, not windows)
-		visibleSessions := 0
-		for _, item := range m.items {
-			if item.IsSession {
-				visibleSessions++
-			}
+	statuslineStyle := ui.StatuslineStyle
+	if m.filter != "" || m.agentsView || m.focusGroup != "" {
+		statusline = m.filterStatus()
+		if m.filter != "" && len(m.items) == 0 {
+			statusline += " · no match"
+			statuslineStyle = ui.StatuslineWarningStyle
 		}
-		statusline = fmt.Sprintf("%d/%d sessions", visibleSessions, len(m.sessions))
 	} else {
 		statusline = fmt.Sprintf("%d sessions", len(m.sessions))
 	}
-	b.WriteString(ui.StatuslineStyle.Render(statusline))
-	b.WriteString("\n")
+	if m.agentsView {
+		statusline += " · agents (M-W all)"
+	}
+	if position := m.scrollPosition(maxVisible); position != "" {
+		statusline += " · " + position
+	}
+	if m.config.ReadOnly {
+		statusline += " · read-only"
+	}
+	if m.lastKey != "" {
+		statusline += " · " + m.lastKey + "-"
+	}
+	if hint := m.cursorClientHint(); hint != "" {
+		statusline += " · " + hint
+	}
+	if msg := m.cursorClaudeMessage(); msg != "" {
+		statusline += " · CC: " + msg
+	}
+	statusline = truncate(statusline, m.contentWidth()-2)
+	b.WriteString(statuslineStyle.Render(statusline))
 
 	// Help line
+	if m.helpLines() == 0 {
+		return ui.AppStyle.Render(b.String())
+	}
+	b.WriteString("\n")
 	switch m.mode {
 	case ModeNormal:
-		if m.filter != "" {
+		if m.splitFocus {
+			b.WriteString(ui.FooterStyle.Render(ui.HelpWindowPane()))
+		} else if len(m.marked) > 0 {
+			b.WriteString(ui.FooterStyle.Render(ui.HelpMarked()))
+		} else if m.filter != "" {
 			b.WriteString(ui.FooterStyle.Render(ui.HelpFiltering()))
+		} else if m.config.ReadOnly {
+			b.WriteString(ui.FooterStyle.Render(ui.HelpReadOnly()))
 		} else {
 			b.WriteString(ui.FooterStyle.Render(ui.HelpNormal()))
 		}
-	case ModeConfirmKill:
-		b.WriteString(ui.FooterStyle.Render(ui.HelpConfirmKill()))
+	case ModeConfirmKill, ModeConfirmEvict:
+		if m.killTypeName != "" {
+			b.WriteString(ui.FooterStyle.Render(ui.HelpKillTypeName()))
+		} else {
+			b.WriteString(ui.FooterStyle.Render(ui.HelpConfirmKill()))
+		}
 	case ModeCreate:
-		b.WriteString(ui.FooterStyle.Render(ui.HelpCreate()))
+		b.WriteString(ui.FooterStyle.Render(ui.HelpCreate(m.config.CreateInBackground)))
+	case ModeTagInput:
+		b.WriteString(ui.FooterStyle.Render(ui.HelpTagInput()))
+	case ModeLayoutVars:
+		b.WriteString(ui.FooterStyle.Render(ui.HelpLayoutVars()))
+	case ModeRename:
+		b.WriteString(ui.FooterStyle.Render(ui.HelpRename()))
+	case ModeErrorDetail:
+		b.WriteString(ui.FooterStyle.Render(ui.HelpErrorDetail()))
+	case ModeSnapshotDiff:
+		b.WriteString(ui.FooterStyle.Render(ui.HelpSnapshotDiff()))
+	case ModeCommand:
+		b.WriteString(ui.FooterStyle.Render(ui.HelpCommand()))
+	case ModeConfirmBroadcast:
+		b.WriteString(ui.FooterStyle.Render(ui.HelpConfirm()))
+	case ModeBroadcastReport:
+		b.WriteString(ui.FooterStyle.Render(ui.HelpBroadcastReport()))
+	case ModeConfirmWorktree:
+		b.WriteString(ui.FooterStyle.Render(ui.HelpConfirm()))
 	}
 
 	return ui.AppStyle.Render(b.String())
 }
 
 func (m Model) renderSessionWithLabel(session tmux.Session, num int, isFirst bool, selected bool) string {
-	// Build the row with fixed-width columns
-	var b strings.Builder
-
 	// Number label
 	label := fmt.Sprintf("%d", num)
 	if selected {
-		b.WriteString(ui.IndexSelectedStyle.Render(label))
+		label = ui.IndexSelectedStyle.Render(label)
 	} else {
-		b.WriteString(ui.IndexStyle.Render(label))
+		label = ui.IndexStyle.Render(label)
 	}
-	b.WriteString(" ")
 
 	// Last session icon (fixed width column)
+	last := strings.Repeat(" ", lipgloss.Width(ui.LastIcon))
 	if isFirst {
-		b.WriteString(ui.LastIcon)
-	} else {
-		b.WriteString(" ")
+		last = ui.LastIcon
 	}
-	b.WriteString(" ")
 
 	// Expand icon
+	expand := ui.CollapsedIcon
 	if session.Expanded {
-		b.WriteString(ui.ExpandedIcon)
-	} else {
-		b.WriteString(ui.CollapsedIcon)
+		expand = ui.ExpandedIcon
 	}
-	b.WriteString(" ")
 
 	// Session name (padded to max width)
-	namePadded := fmt.Sprintf("%-*s", m.maxNameWidth, session.Name)
+	name := session.Name
 	if selected {
-		b.WriteString(ui.SessionNameSelectedStyle.Render(namePadded))
-	} else {
-		b.WriteString(namePadded)
+		name = ui.SessionNameSelectedStyle.Render(name)
+	}
+
+	cols := []ui.Column{
+		{Text: label},
+		{Text: last, Gap: 1},
+		{Text: expand, Gap: 1},
+		{Text: name, Gap: 1, Width: m.maxNameWidth, Flex: true},
+	}
+	// The columns after the name keep two cells from it
+	gap := 2
+	add := func(c ui.Column) {
+		c.Gap += gap
+		gap = 0
+		cols = append(cols, c)
 	}
-	b.WriteString("  ")
 
 	// Time ago (fixed width 8)
-	timeAgo := formatTimeAgo(session.LastActivity)
-	timePadded := fmt.Sprintf("%-8s", timeAgo)
-	b.WriteString(ui.TimeStyle.Render(timePadded))
+	if m.showsColumn(config.ColumnTime) {
+		add(ui.Column{Text: ui.TimeStyle.Render(formatTimeAgo(session.LastActivity)), Width: 8})
+	}
+
+	// Window count
+	if m.showsColumn(config.ColumnWindows) && len(session.Windows) > 0 {
+		add(ui.Column{Text: ui.TimeStyle.Render(fmt.Sprintf("%dw", len(session.Windows)))})
+	}
 
 	// Claude status
-	if status, ok := m.claudeStatuses[session.Name]; ok {
-		b.WriteString(" ")
-		b.WriteString(ui.FormatClaudeStatus(status.State, m.animationFrame))
+	if status, ok := m.claudeStatuses[session.Name]; ok && m.showsColumn(config.ColumnClaude) {
+		add(ui.Column{Text: ui.FormatClaudeStatus(status.State, m.animationFrame), Gap: 1})
 	}
 
-	return ui.SessionStyle.Render(b.String())
+	// Time in the Claude state, what the agents view is sorted by
+	if d := m.agentDuration(session.Name); m.agentsView && d != "" {
+		add(ui.Column{Text: ui.TimeStyle.Render(d), Gap: 1})
+	}
+
+	if m.showsColumn(config.ColumnIndicators) {
+		// Health check result
+		if healthy, ok := m.health[session.Name]; ok {
+			icon := ui.UnhealthyIcon
+			if healthy {
+				icon = ui.HealthyIcon
+			}
+			add(ui.Column{Text: icon, Gap: 1})
+		}
+
+		// Suspended indicator
+		if m.suspended[session.Name] {
+			add(ui.Column{Text: ui.SuspendedIcon, Gap: 1})
+		}
+
+		// Notes indicator
+		if m.notedSessions[session.Name] {
+			add(ui.Column{Text: ui.NoteIcon, Gap: 1})
+		}
+
+		// Session group, shared with the sessions showing the same name
+		if session.Group != "" {
+			add(ui.Column{Text: ui.GroupIcon + ui.TimeStyle.Render(session.Group), Gap: 1})
+		}
+
+		// Attached from a client small enough to shrink its windows
+		if len(m.smallClients[session.Name]) > 0 {
+			add(ui.Column{Text: ui.SmallClientIcon, Gap: 1})
+		}
+
+		// Panes whose command exited, kept open by remain-on-exit
+		if m.sessionDeadPanes(session) > 0 {
+			add(ui.Column{Text: ui.DeadPaneIcon, Gap: 1})
+		}
+	}
+
+	// Tags, branch and directory give way on narrow terminals
+	if tags := m.tags[session.Name]; len(tags) > 0 && m.showsColumn(config.ColumnTags) {
+		add(ui.Column{Text: ui.TagStyle.Render(formatTags(tags)), Gap: 1, Drop: true})
+	}
+
+	// Git branch
+	if branch := m.branches[session.Name]; branch != "" && m.showsColumn(config.ColumnGit) {
+		add(ui.Column{Text: ui.BranchIcon + " " + ui.TimeStyle.Render(branch), Gap: 1, Drop: true})
+	}
+
+	// Working directory
+	if m.showsColumn(config.ColumnPath) && session.Path != "" {
+		add(ui.Column{Text: ui.TimeStyle.Render(tildePath(session.Path)), Gap: 1, Flex: true, Drop: true})
+	}
+
+	budget := m.contentWidth() - ui.SessionStyle.GetHorizontalPadding() - 2 // Scrollbar and mark columns
+	return ui.SessionStyle.Render(ui.Row(cols, budget))
+}
+
+func (m Model) renderWindow(session string, window tmux.Window, pos int, prefix string, selected bool) string {
+	// Pick label, then tmux's index and the name, the name cut to the room
+	// the other columns leave
+	index := len(fmt.Sprintf("%d: ", window.Index))
+	cols := []ui.Column{
+		{Text: windowPickLabel(pos, selected)},
+		{Text: prefix, Gap: 1},
+		{Text: m.windowLabel(window, 0, selected), Flex: true, Fit: func(width int) string {
+			return m.windowLabel(window, width-index, selected)
+		}},
+	}
+	if m.holdsMarkedPane(window) {
+		cols = append(cols, ui.Column{Text: ui.MarkedPaneIcon, Gap: 1})
+	}
+	if m.deadPaneCount(window) > 0 {
+		cols = append(cols, ui.Column{Text: ui.DeadPaneIcon, Gap: 1})
+	}
+
+	// Time since the window's last activity
+	if m.showsColumn(config.ColumnTime) && !window.LastActivity.IsZero() {
+		cols = append(cols, ui.Column{Text: ui.TimeStyle.Render(formatTimeAgo(window.LastActivity)), Gap: 1})
+	}
+
+	// Last line of the active pane, e.g. test results or a server's address
+	if line := m.windowSummaries[window.Target(session)]; line != "" {
+		cols = append(cols, ui.Column{Text: ui.DimStyle.Render(line), Gap: 1, Flex: true, Drop: true, Fit: func(width int) string {
+			return ui.DimStyle.Render(truncate(line, width))
+		}})
+	}
+
+	budget := m.contentWidth() - ui.WindowStyle.GetHorizontalPadding() - 2 // Scrollbar and mark columns
+	return ui.WindowStyle.Render(ui.Row(cols, budget))
 }
 
-func (m Model) renderWindow(window tmux.Window, selected bool) string {
+func (m Model) renderRecent(entry state.HistoryEntry, selected bool) string {
 	var b strings.Builder
 
-	// Window index and name
-	windowText := fmt.Sprintf("%d: %s", window.Index, window.Name)
+	// No number label: recent sessions can't be jumped to
+	b.WriteString(ui.IndexStyle.Render(""))
+	b.WriteString(" ")
+	b.WriteString(ui.RecentIcon)
+	b.WriteString("   ")
+
+	namePadded := fmt.Sprintf("%-*s", m.maxNameWidth, entry.Name)
 	if selected {
-		b.WriteString(ui.WindowNameSelectedStyle.Render(windowText))
+		b.WriteString(ui.SessionNameSelectedStyle.Render(namePadded))
 	} else {
-		b.WriteString(windowText)
+		b.WriteString(ui.RecentNameStyle.Render(namePadded))
+	}
+	if m.showsColumn(config.ColumnTime) {
+		b.WriteString("  ")
+		b.WriteString(ui.TimeStyle.Render(fmt.Sprintf("%-8s", formatTimeAgo(entry.LastSeen))))
 	}
 
-	return ui.WindowStyle.Render(b.String())
+	return ui.SessionStyle.Render(b.String())
+}
+
+// cursorClaudeMessage returns the Claude Code message (e.g. a pending
+// question) of the session under the cursor, flattened to a single line
+func (m Model) cursorClaudeMessage() string {
+	name := m.cursorSessionName()
+	if name == "" {
+		return ""
+	}
+	return strings.Join(strings.Fields(m.claudeStatuses[name].Message), " ")
+}
+
+// truncate shortens s to at most width cells, ending in an ellipsis when cut
+func truncate(s string, width int) string {
+	if width <= 0 || lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
 }
 
 func formatTimeAgo(t time.Time) string {
@@ -1,10 +1,25 @@
 package model
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/nikbrunner/tsm/internal/claude"
 	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/gitinfo"
+	"github.com/nikbrunner/tsm/internal/state"
 	"github.com/nikbrunner/tsm/internal/tmux"
+	"github.com/nikbrunner/tsm/internal/ui"
 )
 
 func TestFuzzyMatch(t *testing.T) {
@@ -114,7 +129,7 @@ func TestGetTargetName(t *testing.T) {
 			{
 				Name: "session2",
 				Windows: []tmux.Window{
-					{Index: 1, Name: "main"},
+					{ID: "@7", Index: 1, Name: "main"},
 				},
 			},
 		},
@@ -145,6 +160,11 @@ func TestGetTargetName(t *testing.T) {
 			item: Item{IsSession: false, SessionIndex: 0, WindowIndex: 1},
 			want: "session1:2",
 		},
+		{
+			name: "window with ID",
+			item: Item{IsSession: false, SessionIndex: 1, WindowIndex: 0},
+			want: "@7",
+		},
 	}
 
 	for _, tt := range tests {
@@ -162,11 +182,11 @@ func TestSetError(t *testing.T) {
 
 	m.setError("test error: %d", 42)
 
-	if m.message != "test error: 42" {
-		t.Errorf("message = %q, want %q", m.message, "test error: 42")
+	if m.lastToast() != "test error: 42" {
+		t.Errorf("message = %q, want %q", m.lastToast(), "test error: 42")
 	}
 
-	if !m.messageIsError {
+	if !m.hasError() {
 		t.Error("messageIsError should be true")
 	}
 }
@@ -412,3 +432,2421 @@ func TestProjectMaxVisibleItems(t *testing.T) {
 		})
 	}
 }
+
+func TestKillPreviewForWindows(t *testing.T) {
+	windows := []tmux.Window{
+		{Index: 1, Name: "editor", Command: "nvim"},
+		{Index: 2, Name: "server", Command: "node"},
+		{Index: 3, Name: "scratch"},
+	}
+
+	got := killPreviewForWindows(windows)
+	want := []string{"1: editor  (nvim)", "2: server  (node)", "3: scratch"}
+
+	if len(got) != len(want) {
+		t.Fatalf("killPreviewForWindows() returned %d lines, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestKillPreviewLines(t *testing.T) {
+	m := Model{killPreview: []string{"a", "b", "c", "d", "e"}}
+
+	if got := m.killPreviewLines(10); len(got) != 5 {
+		t.Errorf("killPreviewLines(10) returned %d lines, want 5", len(got))
+	}
+
+	got := m.killPreviewLines(3)
+	want := []string{"a", "b", "… 3 more"}
+	if len(got) != len(want) {
+		t.Fatalf("killPreviewLines(3) returned %d lines, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{n: 0, want: "0 windows"},
+		{n: 1, want: "1 window"},
+		{n: 12, want: "12 windows"},
+	}
+
+	for _, tt := range tests {
+		if got := pluralize(tt.n, "window"); got != tt.want {
+			t.Errorf("pluralize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestServerName(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Servers = []config.Server{{Name: "outer", Socket: "/tmp/outer"}}
+
+	m := Model{config: cfg}
+	if got := m.serverName(); got != "default" {
+		t.Errorf("serverName() = %q, want %q", got, "default")
+	}
+
+	m.serverIdx = 1
+	if got := m.serverName(); got != "outer" {
+		t.Errorf("serverName() = %q, want %q", got, "outer")
+	}
+}
+
+func TestToggleServerDropsStaleLoads(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Servers = []config.Server{{Name: "outer", Socket: "/tmp/outer"}}
+	m := New("home", cfg)
+	t.Cleanup(func() { tmux.SetSocket("") })
+
+	stale := sessionsMsg{server: 0, sessions: []tmux.Session{{Name: "api"}}}
+	m.toggleServer()
+	if tmux.Socket() != "/tmp/outer" {
+		t.Fatalf("Socket() = %q, want the outer server", tmux.Socket())
+	}
+	model, _ := m.Update(stale)
+	m = model.(Model)
+	if len(m.sessions) != 0 || m.sessionsLoaded {
+		t.Errorf("sessions = %v, want the default server's load dropped", m.sessions)
+	}
+	if cmd := m.handleSessionWindows(sessionWindowsMsg{server: 0, session: "api", ended: true}); cmd != nil {
+		t.Error("a stale window reload should be dropped")
+	}
+}
+
+func TestMarks(t *testing.T) {
+	m := Model{
+		sessions: []tmux.Session{
+			{
+				Name:     "api",
+				Expanded: true,
+				Windows: []tmux.Window{
+					{Index: 1, Name: "editor"},
+					{Index: 3, Name: "server"},
+				},
+			},
+			{Name: "web"},
+		},
+	}
+	m.rebuildItems()
+
+	// Mark the api:3 window (cursor 2) and the web session (cursor 3)
+	m.cursor = 2
+	m.toggleMark()
+	if m.cursor != 3 {
+		t.Errorf("cursor after toggleMark = %d, want 3", m.cursor)
+	}
+	m.toggleMark()
+
+	sessions, windows := m.markedTargets()
+	if len(sessions) != 1 || sessions[0] != "web" {
+		t.Errorf("marked sessions = %v, want [web]", sessions)
+	}
+	if len(windows) != 1 || windows[0] != (markedWindow{session: "api", index: 3, target: "api:3"}) {
+		t.Errorf("marked windows = %v, want [api:3]", windows)
+	}
+
+	// Toggling again unmarks
+	m.cursor = 3
+	m.toggleMark()
+	if m.isMarked(m.items[3]) {
+		t.Error("web should be unmarked after second toggle")
+	}
+
+	// Marking the parent session covers its windows
+	m.markAll()
+	sessions, windows = m.markedTargets()
+	if len(sessions) != 2 || len(windows) != 0 {
+		t.Errorf("markAll targets = %v, %v, want 2 sessions and no windows", sessions, windows)
+	}
+
+	// Pruning drops marks for sessions that disappeared
+	m.sessions = m.sessions[:1]
+	m.pruneMarks()
+	if m.marked["web"] {
+		t.Error("web mark should be pruned")
+	}
+	if !m.marked["api:1"] {
+		t.Error("api:1 mark should be kept")
+	}
+
+	m.clearMarks()
+	if len(m.marked) != 0 {
+		t.Errorf("marks after clearMarks = %v, want none", m.marked)
+	}
+}
+
+func TestRebuildItemsIncludesRecent(t *testing.T) {
+	m := Model{
+		sessions: []tmux.Session{{Name: "api"}},
+		recent: []state.HistoryEntry{
+			{Name: "billing", Path: "/work/billing"},
+			{Name: "docs", Path: "/work/docs"},
+		},
+	}
+
+	m.rebuildItems()
+	if len(m.items) != 3 {
+		t.Fatalf("len(items) = %d, want 3", len(m.items))
+	}
+	last := m.items[2]
+	if !last.IsRecent || last.RecentIndex != 1 {
+		t.Errorf("last item = %+v, want recent index 1", last)
+	}
+	if got := m.getTargetName(last); got != "docs" {
+		t.Errorf("getTargetName(recent) = %q, want %q", got, "docs")
+	}
+
+	// Recent sessions are filtered like live ones
+	m.filter = "bill"
+	m.rebuildItems()
+	if len(m.items) != 1 || !m.items[0].IsRecent {
+		t.Errorf("filtered items = %+v, want only the billing recent item", m.items)
+	}
+}
+
+func TestArchivedItems(t *testing.T) {
+	m := New("home", config.Config{StateDir: t.TempDir()})
+	m.sessions = []tmux.Session{{Name: "api"}}
+	m.recent = []state.HistoryEntry{{Name: "billing"}}
+	m.archived = []state.ArchivedSession{
+		{Name: "docs", Windows: []tmux.WindowSnapshot{{Panes: []string{"/work/docs"}}}},
+		{Name: "wiki"},
+	}
+	a := state.Archive{Sessions: m.archived}
+	if err := state.SaveArchive(m.config.StateDir, a); err != nil {
+		t.Fatalf("SaveArchive() error = %v", err)
+	}
+
+	// Archived sessions are listed after the recent ones
+	m.rebuildItems()
+	if len(m.items) != 4 {
+		t.Fatalf("len(items) = %d, want 4", len(m.items))
+	}
+	if item := m.items[2]; !item.IsRecent || !item.Archived || item.RecentIndex != 0 {
+		t.Errorf("items[2] = %+v, want archived index 0", item)
+	}
+	if got := m.getTargetName(m.items[3]); got != "wiki" {
+		t.Errorf("getTargetName(archived) = %q, want wiki", got)
+	}
+
+	// Killing an archived item deletes its snapshot
+	m.cursor = 2
+	m.confirmKill()
+	loaded, _ := state.LoadArchive(m.config.StateDir)
+	if got := loaded.Names(); !slices.Equal(got, []string{"wiki"}) {
+		t.Errorf("archive after kill = %v, want [wiki]", got)
+	}
+
+	// Archived sessions don't show up as recent too
+	h := state.History{}
+	h.Touch("wiki", "/work/wiki", time.Now())
+	if err := state.SaveHistory(m.config.StateDir, h); err != nil {
+		t.Fatalf("SaveHistory() error = %v", err)
+	}
+	m.config.RecentSessions = 5
+	if recent := m.updateHistory(nil, loaded.Sessions); len(recent) != 0 {
+		t.Errorf("recent = %+v, want none besides the archived wiki", recent)
+	}
+}
+
+func TestCycleFilterHistory(t *testing.T) {
+	m := New("", config.Config{})
+	m.filterHistory = []string{"api", "web", "apps", "a"}
+	m.filter = "a"
+
+	m.cycleFilterHistory(1)
+	if m.filter != "api" {
+		t.Errorf("after C-p filter = %q, want %q", m.filter, "api")
+	}
+	m.cycleFilterHistory(1)
+	if m.filter != "apps" {
+		t.Errorf("after 2x C-p filter = %q, want %q", m.filter, "apps")
+	}
+
+	// Older than the oldest match stays put
+	m.cycleFilterHistory(1)
+	if m.filter != "apps" {
+		t.Errorf("past oldest filter = %q, want %q", m.filter, "apps")
+	}
+
+	m.cycleFilterHistory(-1)
+	m.cycleFilterHistory(-1)
+	if m.filter != "a" {
+		t.Errorf("back past newest filter = %q, want typed %q", m.filter, "a")
+	}
+	if m.historyIdx != -1 {
+		t.Errorf("historyIdx = %d, want -1", m.historyIdx)
+	}
+}
+
+func TestClearFilterRestoresCursor(t *testing.T) {
+	m := New("", config.Config{})
+	m.sessions = []tmux.Session{{Name: "api"}, {Name: "docs"}, {Name: "web"}}
+	m.rebuildItems()
+	m.cursor = 1
+
+	m.typeFilter("w")
+	m.typeFilter("e")
+	if len(m.items) != 1 || m.cursor != 0 {
+		t.Fatalf("filtered items = %d, cursor = %d, want web alone", len(m.items), m.cursor)
+	}
+
+	m.clearFilter()
+	if m.filter != "" || m.cursorSessionName() != "docs" {
+		t.Errorf("after esc filter = %q, cursor on %q, want docs as before filtering", m.filter, m.cursorSessionName())
+	}
+
+	// A session gone while filtering leaves the cursor where it is
+	m.typeFilter("a")
+	m.sessions = m.sessions[:1]
+	m.clearFilter()
+	if m.cursor != 0 || m.preFilterSession != "" {
+		t.Errorf("cursor = %d, preFilterSession = %q after the session went away", m.cursor, m.preFilterSession)
+	}
+}
+
+func TestSplitViewSelectedItem(t *testing.T) {
+	m := New("", config.Config{SplitView: true})
+	m.sessions = []tmux.Session{{Name: "api"}, {Name: "web"}}
+	m.rebuildItems()
+
+	if cmd := m.syncSplitPane(false); cmd == nil {
+		t.Fatal("syncSplitPane() should load windows for the highlighted session")
+	}
+
+	// Focusing the window pane needs loaded windows
+	m.focusWindowPane()
+	if m.splitFocus {
+		t.Error("window pane should not take focus before windows are loaded")
+	}
+
+	// Results for a session that is no longer highlighted are dropped
+	m.handleSplitWindows(splitWindowsMsg{session: "web", windows: []tmux.Window{{Index: 1}}})
+	if len(m.sessions[1].Windows) != 0 {
+		t.Error("stale window load should be ignored")
+	}
+
+	m.handleSplitWindows(splitWindowsMsg{session: "api", windows: []tmux.Window{
+		{ID: "@1", Index: 1, Name: "editor"},
+		{ID: "@2", Index: 2, Name: "server"},
+	}})
+	m.focusWindowPane()
+	m.moveSplitCursor(5)
+
+	item, ok := m.selectedItem()
+	if !ok || item.IsSession || item.WindowIndex != 1 {
+		t.Fatalf("selectedItem() = %+v, %v, want window index 1", item, ok)
+	}
+	if got := m.getTargetName(item); got != "@2" {
+		t.Errorf("getTargetName(selected) = %q, want %q", got, "@2")
+	}
+
+	// While windows reload, actions must not fall back to the session
+	m.sessions[0].Windows = nil
+	if _, ok := m.selectedItem(); ok {
+		t.Error("selectedItem() should be empty while the window pane reloads")
+	}
+}
+
+func TestStartCreate(t *testing.T) {
+	m := New("", config.Config{})
+	m.filter = "api"
+
+	m.startCreate("/work/my.project", sanitizeSessionName("my.project"))
+	if m.mode != ModeCreate {
+		t.Errorf("mode = %v, want ModeCreate", m.mode)
+	}
+	if m.filter != "" {
+		t.Errorf("filter = %q, want cleared", m.filter)
+	}
+	if m.createDir != "/work/my.project" {
+		t.Errorf("createDir = %q, want %q", m.createDir, "/work/my.project")
+	}
+	if got := m.input.Value(); got != "my-project" {
+		t.Errorf("input = %q, want %q", got, "my-project")
+	}
+
+	// Plain create resets the directory to the default
+	m.startCreate("", "")
+	if m.createDir != "" || m.input.Value() != "" {
+		t.Errorf("createDir = %q, input = %q, want both empty", m.createDir, m.input.Value())
+	}
+}
+
+func TestCursorClaudeMessage(t *testing.T) {
+	m := New("", config.Config{})
+	m.sessions = []tmux.Session{{Name: "api"}, {Name: "web"}}
+	m.claudeStatuses = map[string]claude.Status{
+		"api": {State: "waiting", Message: "Allow edit\nof main.go?"},
+	}
+	m.rebuildItems()
+
+	if got := m.cursorClaudeMessage(); got != "Allow edit of main.go?" {
+		t.Errorf("cursorClaudeMessage() = %q, want flattened message", got)
+	}
+
+	m.cursor = 1
+	if got := m.cursorClaudeMessage(); got != "" {
+		t.Errorf("cursorClaudeMessage() without status = %q, want empty", got)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"much too long", 8, "much to…"},
+		{"anything", 0, "anything"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.width); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestRenderWindowTruncates(t *testing.T) {
+	// Long window names are cut to fit the row instead of wrapping it
+	m := New("home", config.Config{})
+	m.width = 50 + ui.AppBorderOverheadX
+	window := tmux.Window{Index: 1, Name: "feature/" + strings.Repeat("x", 60) + "-fix"}
+	row := m.renderWindow("api", window, 0, "", false)
+	if w := lipgloss.Width(row) + 2; w > 50 {
+		t.Errorf("window row is %d cells wide, want at most 50: %q", w, row)
+	}
+	if !strings.Contains(row, "feature/") || !strings.Contains(row, "-fix") {
+		t.Errorf("renderWindow() = %q, want both ends of the name", row)
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	tags, commands, text := parseFilter("#Work api #exp >PSQL")
+	if len(tags) != 2 || tags[0] != "work" || tags[1] != "exp" {
+		t.Errorf("tags = %v, want [work exp]", tags)
+	}
+	if len(commands) != 1 || commands[0] != "psql" {
+		t.Errorf("commands = %v, want [psql]", commands)
+	}
+	if text != "api" {
+		t.Errorf("text = %q, want %q", text, "api")
+	}
+
+	tags, _, text = parseFilter("# web >")
+	if len(tags) != 0 || text != "web" {
+		t.Errorf("parseFilter(%q) = %v, %q, want no tags and %q", "# web", tags, text, "web")
+	}
+}
+
+func TestRebuildItemsTagFilter(t *testing.T) {
+	m := Model{
+		sessions: []tmux.Session{{Name: "api"}, {Name: "api-legacy"}, {Name: "web"}},
+		tags: state.Tags{
+			"api": {"work"},
+			"web": {"work", "exp"},
+		},
+	}
+
+	tests := []struct {
+		filter string
+		want   int
+	}{
+		{"#work", 2},
+		{"#wo", 2},
+		{"#work api", 1},
+		{"#work #exp", 1},
+		{"#client", 0},
+		{"api", 2},
+	}
+	for _, tt := range tests {
+		m.filter = tt.filter
+		m.rebuildItems()
+		if len(m.items) != tt.want {
+			t.Errorf("filter %q: %d items, want %d", tt.filter, len(m.items), tt.want)
+		}
+	}
+}
+
+func TestOpenMergeTarget(t *testing.T) {
+	m := New("home", config.Config{})
+	m.sessions = []tmux.Session{{Name: "api"}, {Name: "api-legacy"}, {Name: "web"}}
+	m.rebuildItems()
+	m.cursor = 1
+
+	m.openMergeTarget()
+	if m.mode != ModeMergeTarget {
+		t.Fatalf("mode = %v, want ModeMergeTarget", m.mode)
+	}
+	if m.mergeSource != "api-legacy" {
+		t.Errorf("mergeSource = %q, want %q", m.mergeSource, "api-legacy")
+	}
+
+	// The current session is offered first, the source itself is not
+	want := []string{"home", "api", "web"}
+	if got := m.filteredMergeTargets(); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("targets = %v, want %v", got, want)
+	}
+
+	m.mergeFilter = "AP"
+	if got := m.filteredMergeTargets(); len(got) != 1 || got[0] != "api" {
+		t.Errorf("filtered targets = %v, want [api]", got)
+	}
+}
+
+func TestEmptyStateLines(t *testing.T) {
+	m := New("", config.Config{EmptyActions: []string{config.EmptyActionNew, config.EmptyActionRestore}})
+
+	if m.isEmptyState() {
+		t.Error("empty state should wait for the first session list")
+	}
+
+	m.sessionsLoaded = true
+	lines := m.emptyStateLines()
+	if len(lines) != 2 {
+		t.Fatalf("lines = %q, want header and new (restore needs a recent session)", lines)
+	}
+	if !m.emptyActionEnabled(config.EmptyActionNew) || m.emptyActionEnabled(config.EmptyActionConfig) {
+		t.Error("only configured actions should be enabled")
+	}
+
+	m.recent = []state.HistoryEntry{{Name: "billing"}}
+	lines = m.emptyStateLines()
+	if len(lines) != 3 || !strings.Contains(lines[2], `Restore "billing"`) {
+		t.Errorf("lines = %q, want restore of billing last", lines)
+	}
+
+	m.filter = "x"
+	if m.isEmptyState() {
+		t.Error("an active filter is not the empty state")
+	}
+}
+
+func TestRebuildItemsWindowFilter(t *testing.T) {
+	m := Model{
+		sessions: []tmux.Session{
+			{Name: "api", Windows: []tmux.Window{{Index: 1, Name: "editor"}, {Index: 2, Name: "server"}}},
+			{Name: "web", Windows: []tmux.Window{{Index: 1, Name: "shell"}}},
+		},
+	}
+
+	// A window match lists its session with all sibling windows
+	m.filter = "serv"
+	m.rebuildItems()
+	if len(m.items) != 3 || !m.items[0].IsSession || m.items[2].WindowIndex != 1 {
+		t.Errorf("filter %q: items = %+v, want api and both its windows", m.filter, m.items)
+	}
+
+	// Session name matches don't expand the session
+	m.filter = "web"
+	m.rebuildItems()
+	if len(m.items) != 1 {
+		t.Errorf("filter %q: %d items, want 1", m.filter, len(m.items))
+	}
+}
+
+func TestFilterStatus(t *testing.T) {
+	m := Model{
+		sessions: []tmux.Session{
+			{Name: "api", Windows: []tmux.Window{{Index: 1, Name: "editor"}, {Index: 2, Name: "server"}}},
+			{Name: "web", Windows: []tmux.Window{{Index: 1, Name: "server"}}},
+			{Name: "docs", Windows: []tmux.Window{{Index: 1, Name: "shell"}}},
+		},
+	}
+
+	m.filter = "serv"
+	m.rebuildItems()
+	if got := m.filterStatus(); got != "2/3 sessions · 2 windows" {
+		t.Errorf("filterStatus() = %q, want both server windows counted", got)
+	}
+
+	m.filter = "web"
+	m.rebuildItems()
+	if got := m.filterStatus(); got != "1/3 sessions" {
+		t.Errorf("filterStatus() = %q, want no windows for a session name match", got)
+	}
+}
+
+func TestRebuildItemsCommandFilter(t *testing.T) {
+	m := Model{
+		sessions: []tmux.Session{
+			{Name: "api", Windows: []tmux.Window{{ID: "@1", Name: "editor", Command: "nvim"}, {ID: "@2", Name: "db", Command: "zsh"}}},
+			{Name: "web", Windows: []tmux.Window{{ID: "@3", Name: "shell", Command: "zsh"}}},
+		},
+		recent: []state.HistoryEntry{{Name: "old"}},
+	}
+
+	// Before the panes load, the windows' active panes stand in
+	m.filter = ">NVIM"
+	m.rebuildItems()
+	if len(m.items) != 3 || m.sessions[m.items[0].SessionIndex].Name != "api" {
+		t.Errorf("filter %q: items = %+v, want api and its windows", m.filter, m.items)
+	}
+
+	// Any pane counts, not only the active one; closed sessions run nothing
+	m.panes = map[string][]tmux.Pane{
+		"@1": {{Command: "nvim"}},
+		"@2": {{Command: "zsh"}, {Command: "psql"}},
+		"@3": {{Command: "zsh"}},
+	}
+	m.filter = ">psql"
+	m.rebuildItems()
+	if len(m.items) != 3 || !m.windowRunsCommands(m.sessions[0].Windows[1], m.filterCommands) {
+		t.Errorf("filter %q: items = %+v, want api with its db window matching", m.filter, m.items)
+	}
+
+	m.filter = ">zsh web"
+	m.rebuildItems()
+	if len(m.items) != 2 || m.sessions[m.items[0].SessionIndex].Name != "web" {
+		t.Errorf("filter %q: items = %+v, want web and its window", m.filter, m.items)
+	}
+}
+
+func TestHighlightMatch(t *testing.T) {
+	if got := highlightMatch("Server", []span{{3, 6}}); !strings.HasPrefix(got, "Ser") || !strings.Contains(got, "ver") {
+		t.Errorf("highlightMatch() = %q, want the name around the match kept", got)
+	}
+	if got := highlightMatch("editor", nil); got != "editor" {
+		t.Errorf("highlightMatch() = %q, want the name unchanged without a match", got)
+	}
+	if got := ansi.Strip(highlightMatch("my-api", []span{{0, 1}, {3, 6}})); got != "my-api" {
+		t.Errorf("highlightMatch() = %q, want every span kept in place", got)
+	}
+}
+
+func TestAnnounceCreated(t *testing.T) {
+	m := New("home", config.Config{})
+	m.sessions = []tmux.Session{{Name: "home"}, {Name: "api"}}
+	m.rebuildItems()
+
+	m.createdSession = "api"
+	m.announceCreated()
+	if m.lastToast() != "Created api (press 2 to switch)" || m.createdSession != "" {
+		t.Errorf("message = %q, createdSession = %q", m.lastToast(), m.createdSession)
+	}
+}
+
+func TestSelectAction(t *testing.T) {
+	m := New("", config.Config{Actions: config.Actions{
+		Session: config.ActionSwitch,
+		Window:  config.ActionZoom,
+		Pane:    config.ActionBreak,
+	}})
+	tests := []struct {
+		name string
+		item Item
+		want string
+	}{
+		{"session", Item{IsSession: true}, config.ActionSwitch},
+		{"window", Item{}, config.ActionZoom},
+		{"pane", Item{IsPane: true}, config.ActionBreak},
+	}
+	for _, tt := range tests {
+		if got := m.selectAction(tt.item); got != tt.want {
+			t.Errorf("selectAction(%s row) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestZoomVariant(t *testing.T) {
+	tests := []struct{ action, want string }{
+		{config.ActionSwitch, config.ActionZoom},
+		{config.ActionZoom, config.ActionSwitch},
+		{config.ActionBreak, config.ActionZoom},
+	}
+	for _, tt := range tests {
+		if got := zoomVariant(tt.action); got != tt.want {
+			t.Errorf("zoomVariant(%q) = %q, want %q", tt.action, got, tt.want)
+		}
+	}
+}
+
+func TestConfirmEvict(t *testing.T) {
+	now := time.Now()
+	m := New("home", config.Config{MaxSessions: 3})
+	m.sessions = []tmux.Session{
+		{Name: "api", LastActivity: now.Add(-time.Minute)},
+		{Name: "old", LastActivity: now.Add(-time.Hour), Windows: []tmux.Window{{Index: 1, Name: "shell"}}},
+	}
+	if !m.sessionLimitReached() {
+		t.Fatal("two listed sessions plus the current one should reach a limit of 3")
+	}
+
+	created := false
+	m.confirmEvict(func(m *Model) (tea.Model, tea.Cmd) {
+		created = true
+		return m, nil
+	})
+	if m.mode != ModeConfirmEvict || m.evictVictim != "old" || len(m.killPreview) != 1 {
+		t.Errorf("mode = %v, victim = %q, preview = %v, want old offered", m.mode, m.evictVictim, m.killPreview)
+	}
+
+	m.handleConfirmEvictMode(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != ModeNormal || m.evictThen != nil || created {
+		t.Error("esc should cancel without creating")
+	}
+}
+
+func TestRebuildItemsCompositeMatch(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	m := Model{
+		sessions: []tmux.Session{
+			{Name: "billing", Path: "/home/me/work/invoicing"},
+			{Name: "notes", Path: "/home/me/notes"},
+			{Name: "invoices", Path: "/home/me/repos/invoices"},
+		},
+		tags:  state.Tags{"notes": {"client-inv"}},
+		notes: map[string]string{"billing": "ask about the Q3 invoice"},
+	}
+
+	m.filter = "inv"
+	m.rebuildItems()
+	var names []string
+	for _, item := range m.items {
+		names = append(names, m.sessions[item.SessionIndex].Name)
+	}
+	// The name match comes first, the rest keep their order
+	if want := "invoices billing notes"; strings.Join(names, " ") != want {
+		t.Errorf("filter %q lists %v, want %s", m.filter, names, want)
+	}
+
+	// The home directory itself doesn't match
+	m.filter = "me"
+	m.rebuildItems()
+	if len(m.items) != 0 {
+		t.Errorf("filter %q lists %d sessions, want none", m.filter, len(m.items))
+	}
+}
+
+func TestPromptLayoutVars(t *testing.T) {
+	dir := t.TempDir()
+	script := "# tsm-var: PORT=3000 Port\n# tsm-var: BRANCH Branch\n"
+	if err := os.WriteFile(filepath.Join(dir, "ide.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	m := New("home", config.Config{LayoutDir: dir})
+	if !m.needsLayoutVars("ide") || m.needsLayoutVars("missing") {
+		t.Fatal("only layouts declaring variables need a prompt")
+	}
+
+	var env []string
+	m.promptLayoutVars("ide", func(m *Model) (tea.Model, tea.Cmd) {
+		env = m.layoutEnv
+		return m, nil
+	})
+
+	// Default for PORT, BRANCH is required
+	m.handleLayoutVarsMode(tea.KeyMsg{Type: tea.KeyEnter})
+	m.handleLayoutVarsMode(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.hasError() || env != nil {
+		t.Fatalf("an empty required variable should be rejected, message = %q", m.lastToast())
+	}
+	m.input.SetValue("main")
+	m.handleLayoutVarsMode(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if strings.Join(env, " ") != "PORT=3000 BRANCH=main" {
+		t.Errorf("env = %v, want PORT=3000 BRANCH=main", env)
+	}
+	if m.mode != ModeNormal || m.layoutEnv != nil {
+		t.Error("the prompt should end and not leak its environment into later creations")
+	}
+}
+
+func TestSwitchHistoryKeys(t *testing.T) {
+	m := New("api", config.Config{StateDir: t.TempDir()})
+	m.sessions = []tmux.Session{{Name: "home"}}
+
+	m.stepSwitchHistory(-1)
+	if m.lastToast() != "No older session in switch history" {
+		t.Errorf("message = %q, want no older session", m.lastToast())
+	}
+
+	m.recordSwitch("api")
+	h, _ := state.LoadSwitchHistory(m.config.StateDir)
+	if len(h.Sessions) != 1 || h.Sessions[0] != "api" {
+		t.Errorf("history = %v, want [api]", h.Sessions)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	m := New("home", config.Config{ReadOnly: true, EmptyActions: []string{config.EmptyActionNew}})
+	m.sessions = []tmux.Session{{Name: "home"}, {Name: "api"}}
+	m.rebuildItems()
+
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyCtrlX}, {Type: tea.KeyCtrlN}, {Type: tea.KeyCtrlW}, {Type: tea.KeyTab},
+	} {
+		m.toasts = nil
+		m.handleNormalMode(msg)
+		if m.mode != ModeNormal || m.lastToast() != "Read-only mode" {
+			t.Errorf("%s: mode = %v, message = %q, want refused", msg, m.mode, m.lastToast())
+		}
+	}
+
+	// C-n cycles the filter history while filtering
+	m.filter = "a"
+	if m.readOnlyBlocked(tea.KeyMsg{Type: tea.KeyCtrlN}) {
+		t.Error("C-n should cycle the filter history while filtering")
+	}
+
+	m.filter = ""
+	m.sessions = nil
+	m.sessionsLoaded = true
+	if m.emptyActionEnabled(config.EmptyActionNew) {
+		t.Error("empty-state actions should be disabled in read-only mode")
+	}
+}
+
+func TestCycleSizeProfile(t *testing.T) {
+	m := New("home", config.Config{})
+	m.width = 120
+
+	if got := m.contentWidth(); got != 120-ui.AppBorderOverheadX {
+		t.Errorf("default contentWidth() = %d, want the whole width", got)
+	}
+
+	m.cycleSizeProfile()
+	if m.profile != config.ProfileFull {
+		t.Fatalf("profile = %q, want full after default", m.profile)
+	}
+	m.cycleSizeProfile()
+	if m.profile != config.ProfileCompact {
+		t.Fatalf("profile = %q, want compact after full", m.profile)
+	}
+	if got := m.contentWidth(); got != 40-ui.AppBorderOverheadX {
+		t.Errorf("compact contentWidth() = %d, want %d", got, 40-ui.AppBorderOverheadX)
+	}
+	if m.helpLines() != 0 || m.showsColumn(config.ColumnTime) {
+		t.Error("compact should hide the help line and the time column")
+	}
+}
+
+func TestStabilizeOrder(t *testing.T) {
+	m := New("home", config.Config{SortStability: time.Minute})
+	names := func(sessions []tmux.Session) string {
+		var out []string
+		for _, s := range sessions {
+			out = append(out, s.Name)
+		}
+		return strings.Join(out, " ")
+	}
+
+	first := []tmux.Session{{Name: "api"}, {Name: "web"}, {Name: "docs"}}
+	m.stabilizeOrder(first)
+
+	// Background output moved docs to the front, and a session was created
+	reload := []tmux.Session{{Name: "new"}, {Name: "docs"}, {Name: "api"}, {Name: "web"}}
+	m.stabilizeOrder(reload)
+	if got := names(reload); got != "api web docs new" {
+		t.Errorf("order = %q, want the first load's order with new sessions last", got)
+	}
+
+	// After the window, activity order applies again
+	m.openedAt = time.Now().Add(-2 * time.Minute)
+	reload = []tmux.Session{{Name: "docs"}, {Name: "api"}, {Name: "web"}}
+	m.stabilizeOrder(reload)
+	if got := names(reload); got != "docs api web" {
+		t.Errorf("order = %q, want activity order once the window passed", got)
+	}
+}
+
+func TestApplyRenamePattern(t *testing.T) {
+	tests := []struct {
+		pattern, name, want string
+		wantErr             bool
+	}{
+		{"", "api", "api", false},
+		{"+work-", "api", "work-api", false},
+		{"-work-", "work-api", "api", false},
+		{"-work-", "web", "web", false},
+		{"legacy/old", "api-legacy", "api-old", false},
+		{"api/", "api-v2", "-v2", false},
+		{"+x.", "api", "x-api", false}, // Sanitized like new sessions
+		{"/x", "api", "", true},
+		{"work", "api", "", true},
+	}
+	for _, tt := range tests {
+		got, err := applyRenamePattern(tt.pattern, tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("applyRenamePattern(%q, %q) = %q, %v, want %q (error %v)", tt.pattern, tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRenamePlan(t *testing.T) {
+	m := New("home", config.Config{})
+	m.sessions = []tmux.Session{{Name: "api"}, {Name: "web"}, {Name: "work-web"}}
+	m.rebuildItems()
+	m.marked = map[string]bool{"api": true, "web": true}
+
+	m.openRename()
+	if m.mode != ModeRename || len(m.renameTargets) != 2 {
+		t.Fatalf("mode = %v, targets = %v, want rename of api and web", m.mode, m.renameTargets)
+	}
+
+	m.input.SetValue("+work-")
+	if _, err := m.renamePlan(); err == nil || !strings.Contains(err.Error(), "work-web") {
+		t.Errorf("renamePlan() error = %v, want work-web taken", err)
+	}
+
+	m.input.SetValue("+team-")
+	if got := m.renamePreview(); len(got) != 2 || got[0] != "api → team-api" {
+		t.Errorf("renamePreview() = %q, want api → team-api first", got)
+	}
+
+	// Renaming into the current session's name is refused too
+	m.renameTargets = []string{"api"}
+	m.input.SetValue("api/home")
+	if _, err := m.renamePlan(); err == nil {
+		t.Error("renamePlan() should refuse the current session's name")
+	}
+
+	// Names given up within the batch are taken once their session is renamed
+	m.sessions = []tmux.Session{{Name: "aab"}, {Name: "ab"}}
+	m.renameTargets = []string{"aab", "ab"}
+	m.input.SetValue("ab/b")
+	steps, err := m.renamePlan()
+	if err != nil {
+		t.Fatalf("renamePlan() error = %v", err)
+	}
+	want := []renameStep{{"ab", "b"}, {"aab", "ab"}}
+	if got := orderRenames(steps, m.takenNames()); !slices.Equal(got, want) {
+		t.Errorf("orderRenames() = %v, want %v", got, want)
+	}
+
+	// Swapped names go through a temporary one
+	swap := []renameStep{{"a", "b"}, {"b", "a"}, {"c", "c"}}
+	want = []renameStep{{"a", "a~"}, {"b", "a"}, {"a~", "b"}}
+	if got := orderRenames(swap, map[string]bool{"a": true, "b": true, "c": true}); !slices.Equal(got, want) {
+		t.Errorf("orderRenames() = %v, want %v", got, want)
+	}
+}
+
+func TestWindowForKey(t *testing.T) {
+	// Keys count the listed windows, not tmux's indexes with their gaps
+	windows := []tmux.Window{{Index: 1, Name: "editor"}, {Index: 3, Name: "server"}, {Index: 7, Name: "logs"}}
+
+	tests := []struct {
+		num  int
+		want string
+	}{
+		{1, "editor"},
+		{2, "server"},
+		{3, "logs"},
+		{4, ""},
+		{0, ""},
+	}
+	for _, tt := range tests {
+		w, ok := windowForKey(windows, tt.num)
+		if ok != (tt.want != "") || w.Name != tt.want {
+			t.Errorf("windowForKey(%d) = %q, %v, want %q", tt.num, w.Name, ok, tt.want)
+		}
+	}
+}
+
+func TestJumpToWindow(t *testing.T) {
+	orig := switchClient
+	t.Cleanup(func() { switchClient = orig })
+	var switched string
+	switchClient = func(target string) error {
+		switched = target
+		return nil
+	}
+
+	m := New("home", config.Config{StateDir: t.TempDir()})
+	m.sessions = []tmux.Session{{Name: "api", Expanded: true, Windows: []tmux.Window{
+		{ID: "@1", Index: 1, Name: "editor"},
+		{ID: "@4", Index: 4, Name: "server"},
+	}}}
+	m.rebuildItems()
+
+	// Inside an expanded session numbers jump to its windows
+	if _, cmd := m.handleJump(2); cmd == nil || switched != "@4" {
+		t.Errorf("2 switched to %q, want the second window @4", switched)
+	}
+
+	switchClient = func(string) error { return errors.New("no client") }
+	if _, cmd := m.handleJump(1); cmd != nil || !m.hasError() {
+		t.Error("handleJump() with a failing switch: want an error and the picker open")
+	}
+}
+
+func TestWindowPickLabel(t *testing.T) {
+	m := New("home", config.Config{})
+	m.width = 60
+	window := tmux.Window{Index: 7, Name: "logs"}
+
+	if got := m.renderWindowPlain("api", window, 2, "", false, false); !strings.Contains(got, "3. window 7: logs") {
+		t.Errorf("renderWindowPlain() = %q, want the pick label 3 beside index 7", got)
+	}
+	if got := m.renderWindowPlain("api", window, 9, "", false, false); strings.Contains(got, "10.") {
+		t.Errorf("renderWindowPlain() = %q, want no pick label past 9", got)
+	}
+}
+
+func TestWindowSummary(t *testing.T) {
+	m := New("home", config.Config{})
+	m.width = 60
+	window := tmux.Window{ID: "@3", Index: 1, Name: "tests"}
+
+	m.handleWindowSummaries(windowSummariesMsg{summaries: map[string]string{
+		window.Target("api"): "ok  github.com/nikbrunner/tsm/internal/model  0.011s",
+	}})
+
+	got := m.windowSummary("api", window, 20)
+	if !strings.HasPrefix(got, "ok  github.com") || lipgloss.Width(got) > m.contentWidth()-21 {
+		t.Errorf("windowSummary() = %q, want the line truncated to the remaining width", got)
+	}
+	if got := m.windowSummary("api", window, 50); got != "" {
+		t.Errorf("windowSummary() = %q, want nothing without room", got)
+	}
+	if got := m.windowSummary("api", tmux.Window{ID: "@4", Index: 2}, 20); got != "" {
+		t.Errorf("windowSummary() = %q, want nothing for an unloaded window", got)
+	}
+}
+
+func TestWindowActivity(t *testing.T) {
+	m := New("home", config.Config{})
+	m.width = 80
+	window := tmux.Window{ID: "@3", Index: 1, Name: "tests", LastActivity: time.Now().Add(-5 * time.Minute)}
+
+	if got := m.renderWindow("api", window, 0, "", false); !strings.Contains(got, "5m ago") {
+		t.Errorf("renderWindow() = %q, want the time since the window's activity", got)
+	}
+	if got := m.renderWindowPlain("api", window, 0, "", false, false); !strings.Contains(got, "tests, 5m ago") {
+		t.Errorf("renderWindowPlain() = %q, want the time since the window's activity", got)
+	}
+
+	m.profile = config.ProfileCompact
+	if got := m.renderWindow("api", window, 0, "", false); strings.Contains(got, "ago") {
+		t.Errorf("renderWindow() = %q, want no time without the time column", got)
+	}
+}
+
+func TestNarrowItemsMatchesRebuild(t *testing.T) {
+	m := New("home", config.Config{})
+	m.sessions = []tmux.Session{
+		{Name: "api", Windows: []tmux.Window{{Index: 1, Name: "server"}}},
+		{Name: "web", Path: "/srv/api-web"},
+		{Name: "docs", Windows: []tmux.Window{{Index: 1, Name: "apidoc"}}},
+		{Name: "infra"},
+	}
+	m.tags = state.Tags{"api": {"work"}, "docs": {"work", "oss"}}
+	m.recent = []state.HistoryEntry{{Name: "api-old"}, {Name: "blog"}}
+	m.archived = []state.ArchivedSession{{Name: "api-archived"}, {Name: "wiki"}}
+	m.rebuildItems()
+
+	// Type the filter one character at a time, comparing against a full rebuild
+	for _, r := range "#wo api" {
+		m.filter += string(r)
+		m.narrowItems()
+		narrowed := slices.Clone(m.items)
+
+		m.rebuildItems()
+		if !slices.Equal(narrowed, m.items) {
+			t.Errorf("filter %q: narrowed items = %+v, want %+v", m.filter, narrowed, m.items)
+		}
+	}
+}
+
+func BenchmarkTypeFilter(b *testing.B) {
+	m := New("home", config.Config{})
+	for i := range 50 {
+		session := tmux.Session{Name: fmt.Sprintf("project-%d", i), Expanded: i == 0}
+		for j := range 10 {
+			session.Windows = append(session.Windows, tmux.Window{Index: j, Name: fmt.Sprintf("window-%d", j)})
+		}
+		m.sessions = append(m.sessions, session)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.filter = ""
+		m.rebuildItems()
+		for _, r := range "window-4" {
+			m.filter += string(r)
+			m.narrowItems()
+		}
+	}
+}
+
+func TestYankText(t *testing.T) {
+	m := New("home", config.Config{})
+	m.sessions = []tmux.Session{{Name: "api", Path: "/src/api", Windows: []tmux.Window{{ID: "@7", Index: 2, Name: "server"}}}}
+
+	tests := []struct {
+		item Item
+		path bool
+		want string
+	}{
+		{Item{IsSession: true}, false, "api"},
+		{Item{IsSession: true}, true, "/src/api"},
+		{Item{WindowIndex: 0}, false, "api:2"}, // Readable target, not the window ID
+	}
+	for _, tt := range tests {
+		if got, err := m.yankText(tt.item, tt.path); err != nil || got != tt.want {
+			t.Errorf("yankText(%+v, %v) = %q, %v, want %q", tt.item, tt.path, got, err, tt.want)
+		}
+	}
+}
+
+func TestClientTargetMode(t *testing.T) {
+	m := New("home", config.Config{})
+	m.mode = ModeClientTarget
+	m.clients = []tmux.Client{{Name: "/dev/pts/1", Session: "api"}, {Name: "/dev/pts/2", Session: "web"}}
+
+	m.handleClientTargetMode(tea.KeyMsg{Type: tea.KeyDown})
+	m.handleClientTargetMode(tea.KeyMsg{Type: tea.KeyDown})
+	if m.clientCursor != 1 {
+		t.Errorf("clientCursor = %d, want 1 (stays on the last client)", m.clientCursor)
+	}
+	if view := m.viewClientTarget(); !strings.Contains(view, "/dev/pts/2") {
+		t.Errorf("view should list the clients:\n%s", view)
+	}
+
+	m.handleClientTargetMode(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != ModeNormal {
+		t.Errorf("mode = %v, want normal after esc", m.mode)
+	}
+}
+
+func TestExpandOnStart(t *testing.T) {
+	windows := []tmux.Window{{ID: "@1", Index: 1, Name: "edit"}, {ID: "@2", Index: 2, Name: "run"}}
+
+	m := New("home", config.Config{})
+	m.ExpandOnStart("home", false)
+	if m.listedExclusion() != "" {
+		t.Error("expanding the current session should list it")
+	}
+	m.sessions = []tmux.Session{{Name: "api", Windows: windows}, {Name: "home", Windows: windows}}
+	m.rebuildItems()
+	m.applyStartExpand()
+
+	if m.sessions[0].Expanded || !m.sessions[1].Expanded {
+		t.Errorf("expanded = %v, %v, want only home", m.sessions[0].Expanded, m.sessions[1].Expanded)
+	}
+	if item := m.items[m.cursor]; !item.IsSession || item.SessionIndex != 1 {
+		t.Errorf("cursor on %+v, want the home session", item)
+	}
+	if len(m.items) != 4 {
+		t.Errorf("items = %d, want 2 sessions and 2 windows", len(m.items))
+	}
+
+	// Only the first load expands
+	m.sessions[1].Expanded = false
+	m.applyStartExpand()
+	if m.sessions[1].Expanded {
+		t.Error("later loads should not expand again")
+	}
+
+	m = New("home", config.Config{})
+	m.ExpandOnStart("", true)
+	if m.listedExclusion() != "home" {
+		t.Error("--expand-all should still leave out the current session")
+	}
+	m.sessions = []tmux.Session{{Name: "api", Windows: windows}, {Name: "web", Windows: windows}}
+	m.applyStartExpand()
+	if len(m.items) != 6 {
+		t.Errorf("items = %d, want every session expanded", len(m.items))
+	}
+
+	m = New("home", config.Config{})
+	m.ExpandOnStart("gone", false)
+	m.sessions = []tmux.Session{{Name: "api"}}
+	m.applyStartExpand()
+	if !m.hasError() {
+		t.Error("expanding a missing session should report an error")
+	}
+}
+
+func TestErrorDetail(t *testing.T) {
+	m := New("home", config.Config{})
+
+	m.openErrorDetail()
+	if m.mode != ModeNormal || m.lastToast() != "No tmux errors" {
+		t.Fatalf("mode = %v, message = %q, want nothing to show", m.mode, m.lastToast())
+	}
+
+	cmdErr := &tmux.CommandError{
+		Args:     []string{"kill-session", "-t", "api"},
+		ExitCode: 1,
+		Stderr:   "can't find session: api",
+	}
+	m.setError("Error: %v", fmt.Errorf("killing: %w", cmdErr))
+	if !strings.HasSuffix(m.lastToast(), "(M-e: details)") {
+		t.Errorf("message = %q, want a hint to the details", m.lastToast())
+	}
+
+	m.handleNormalMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e"), Alt: true})
+	if m.mode != ModeErrorDetail {
+		t.Fatalf("mode = %v, want ModeErrorDetail", m.mode)
+	}
+	want := []string{
+		"Command:   tmux kill-session -t api",
+		"Exit code: 1",
+		"Stderr:",
+		"  can't find session: api",
+	}
+	if got := m.errorDetailLines(); !slices.Equal(got, want) {
+		t.Errorf("errorDetailLines() = %q, want %q", got, want)
+	}
+
+	m.handleErrorDetailMode(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != ModeNormal {
+		t.Errorf("mode = %v, want esc to close the details", m.mode)
+	}
+
+	// Errors that didn't come from tmux keep the last details
+	m.setError("Error: %v", errors.New("disk full"))
+	if m.tmuxError != cmdErr || strings.Contains(m.lastToast(), "M-e") {
+		t.Errorf("message = %q, want no hint for a non-tmux error", m.lastToast())
+	}
+}
+
+func TestRunHealthChecks(t *testing.T) {
+	dir := t.TempDir()
+	m := New("home", config.Config{HealthChecks: []config.HealthCheck{
+		{Tag: "down", Command: "exit 1", Timeout: time.Second},
+		{Path: dir, Command: "true", Timeout: time.Second},
+	}})
+	m.sessions = []tmux.Session{{Name: "api", Path: dir}, {Name: "web", Path: "/elsewhere"}, {Name: "docs", Path: "/elsewhere"}}
+	m.tags = state.Tags{"web": {"down"}}
+
+	batch, ok := m.runHealthChecks()().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("runHealthChecks() = %v, want a check for api and web", batch)
+	}
+	for _, cmd := range batch {
+		m.handleHealth(cmd().(healthMsg))
+	}
+	if healthy, ok := m.health["api"]; !ok || !healthy {
+		t.Error("api should be healthy")
+	}
+	if healthy, ok := m.health["web"]; !ok || healthy {
+		t.Error("web should be unhealthy")
+	}
+	if _, ok := m.health["docs"]; ok {
+		t.Error("docs has no check and should have no result")
+	}
+
+	// Reloads don't run the checks again
+	if cmd := m.runHealthChecks(); cmd != nil {
+		t.Error("checks should run once per picker open")
+	}
+}
+
+func TestProjectSessionName(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "acme", "api.v2")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New("home", config.Config{ProjectDepth: 2})
+	if got := m.projectSessionName(dir); got != "acme-api-v2" {
+		t.Errorf("projectSessionName() = %q, want the last two components", got)
+	}
+
+	// Outside git: org and repo are the directory names, the empty branch is dropped
+	m.config.SessionName = "{org}/{repo}@{branch}"
+	if got := m.projectSessionName(dir); got != "acme/api-v2" {
+		t.Errorf("projectSessionName() = %q, want acme/api-v2", got)
+	}
+}
+
+func TestToggleRowDetail(t *testing.T) {
+	m := New("home", config.Config{StateDir: t.TempDir()})
+	if !m.showsColumn(config.ColumnTime) || m.showsColumn(config.ColumnPath) {
+		t.Fatal("without a toggle the size profile should pick the columns")
+	}
+
+	m.toggleRowDetail()
+	if m.rowDetail != state.RowDetailed || !m.showsColumn(config.ColumnPath) || !m.showsColumn(config.ColumnGit) {
+		t.Errorf("rowDetail = %q, want every column shown", m.rowDetail)
+	}
+
+	m.toggleRowDetail()
+	if m.rowDetail != state.RowCompact || m.showsColumn(config.ColumnTime) {
+		t.Errorf("rowDetail = %q, want number and name only", m.rowDetail)
+	}
+
+	// The choice is remembered for the next picker
+	next := New("home", m.config)
+	msg, ok := next.loadPrefs().(prefsMsg)
+	if !ok || msg.prefs.RowDetail != state.RowCompact {
+		t.Errorf("loadPrefs() = %v, want the compact rows remembered", msg)
+	}
+}
+
+func TestConfirmKillTimeout(t *testing.T) {
+	m := New("home", config.Config{ConfirmTimeout: time.Second})
+	m.sessions = []tmux.Session{{Name: "api"}}
+	m.rebuildItems()
+
+	if _, cmd := m.confirmKill(); cmd == nil || m.mode != ModeConfirmKill {
+		t.Fatal("confirmKill() should start the timeout")
+	}
+	stale := m.confirmSeq
+
+	// A new confirmation isn't cancelled by the first one's timeout
+	m.cancelKill()
+	m.confirmKill()
+	model, _ := m.Update(confirmTimeoutMsg{seq: stale})
+	m = model.(Model)
+	if m.mode != ModeConfirmKill {
+		t.Fatal("a stale timeout cancelled the current confirmation")
+	}
+
+	model, _ = m.Update(confirmTimeoutMsg{seq: m.confirmSeq})
+	m = model.(Model)
+	if m.mode != ModeNormal || m.killTarget != "" || m.lastToast() != "Kill cancelled (timed out)" {
+		t.Errorf("mode = %v, message = %q, want the confirmation cancelled", m.mode, m.lastToast())
+	}
+
+	m.config.ConfirmTimeout = 0
+	if _, cmd := m.confirmKill(); cmd != nil {
+		t.Error("confirm_timeout = 0 should wait forever")
+	}
+}
+
+func TestWorktreeRemoval(t *testing.T) {
+	m := New("home", config.Config{})
+	m.sessions = []tmux.Session{{Name: "api", Path: t.TempDir()}}
+	m.rebuildItems()
+
+	// A directory outside git has no worktree to remove
+	m.paletteWorktree(nil)
+	if m.mode != ModeNormal || !m.hasError() {
+		t.Errorf("mode = %v, want an error outside a worktree", m.mode)
+	}
+
+	// Once the kill went through, the removal asks on its own
+	removal := &worktreeRemoval{session: "api", worktree: gitinfo.Worktree{Path: m.sessions[0].Path, Branch: "feat"}}
+	m.confirmWorktreeRemoval(removal)
+	if m.mode != ModeConfirmWorktree || !strings.Contains(m.prompt, "branch feat is kept") {
+		t.Errorf("mode = %v, prompt = %q, want the removal confirmed", m.mode, m.prompt)
+	}
+	m.handleConfirmWorktreeMode(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != ModeNormal || m.worktreeRemoval != nil || !strings.HasPrefix(m.lastToast(), "Kept worktree") {
+		t.Errorf("mode = %v, message = %q, want the worktree kept", m.mode, m.lastToast())
+	}
+
+	// Other sessions working in the worktree keep it from being removed
+	m.sessions = []tmux.Session{
+		{Name: "api", Path: "/work/api-feat"},
+		{Name: "tests", Path: "/work/api-feat/test"},
+		{Name: "api-feat2", Path: "/work/api-feat2"},
+		{Name: "notes"},
+	}
+	if got := m.sessionsInDir("/work/api-feat", "api"); !slices.Equal(got, []string{"tests"}) {
+		t.Errorf("sessionsInDir() = %v, want tests alone", got)
+	}
+
+	// Cancelling the kill drops the pending removal
+	m.worktreeRemoval = removal
+	m.cancelKill()
+	if m.worktreeRemoval != nil {
+		t.Error("cancelKill() should forget the worktree")
+	}
+}
+
+func TestHandleSessionWindows(t *testing.T) {
+	m := New("home", config.Config{})
+	m.sessions = []tmux.Session{
+		{Name: "api", Expanded: true, Windows: []tmux.Window{{ID: "@1", Index: 1}, {ID: "@2", Index: 2}}},
+		{Name: "web", Expanded: true, Windows: []tmux.Window{{ID: "@3", Index: 1}}},
+	}
+	m.rebuildItems()
+	m.cursor = 2
+
+	if cmd := m.handleSessionWindows(sessionWindowsMsg{session: "api", windows: []tmux.Window{{ID: "@1", Index: 1}}}); cmd != nil {
+		t.Error("refreshing a live session should not reload everything")
+	}
+	if len(m.sessions[0].Windows) != 1 || !m.sessions[0].Expanded || !m.sessions[1].Expanded {
+		t.Errorf("sessions = %+v, want api's window gone and both still expanded", m.sessions)
+	}
+	if len(m.items) != 4 || m.cursor != 2 {
+		t.Errorf("items = %d, cursor = %d, want 4 items and the cursor kept", len(m.items), m.cursor)
+	}
+
+	// A failed refresh falls back to reloading everything
+	if cmd := m.handleSessionWindows(sessionWindowsMsg{session: "web", err: errors.New("server busy")}); cmd == nil {
+		t.Error("a failed refresh should reload everything")
+	}
+
+	// The last window was killed, taking the session with it
+	if cmd := m.handleSessionWindows(sessionWindowsMsg{session: "web", ended: true}); cmd == nil {
+		t.Error("an ended session should reload everything")
+	}
+	if len(m.sessions) != 1 || len(m.items) != 2 {
+		t.Errorf("sessions = %+v, want web dropped before the reload", m.sessions)
+	}
+	if m.lastToast() != "Session web ended" {
+		t.Errorf("message = %q, want the session announced as ended", m.lastToast())
+	}
+}
+
+func TestUseMarkedPane(t *testing.T) {
+	m := New("home", config.Config{})
+	m.sessions = []tmux.Session{{Name: "api", Expanded: true, Windows: []tmux.Window{{ID: "@1", Index: 1}, {ID: "@2", Index: 2}}}}
+	m.rebuildItems()
+	m.cursor = 1
+
+	m.useMarkedPane(false)
+	if !m.hasError() || !strings.Contains(m.lastToast(), "No marked pane") {
+		t.Errorf("message = %q, want no marked pane", m.lastToast())
+	}
+
+	m.markedPaneWindow = "@1"
+	if !m.holdsMarkedPane(m.sessions[0].Windows[0]) || m.holdsMarkedPane(m.sessions[0].Windows[1]) {
+		t.Error("only @1 should hold the marked pane")
+	}
+	m.useMarkedPane(true)
+	if !strings.Contains(m.lastToast(), "already in api:1") {
+		t.Errorf("message = %q, want the marked pane's own window refused", m.lastToast())
+	}
+}
+
+func TestNotesPane(t *testing.T) {
+	m := New("home", config.Config{})
+	if m.toggleNotesPane(); !m.hasError() {
+		t.Error("the notes pane should need the split view")
+	}
+
+	m = New("home", config.Config{SplitView: true})
+	m.sessions = []tmux.Session{{Name: "api", Windows: []tmux.Window{{ID: "@1", Index: 1, Name: "edit"}}}}
+	m.notes = map[string]string{"api": "## 2026-10-16 09:00\n\nShip the **release** today\n"}
+	m.rebuildItems()
+	m.syncSplitPane(false)
+
+	m.toggleNotesPane()
+	if !m.showsNotesPane() {
+		t.Fatal("toggleNotesPane() should show the session's notes")
+	}
+	if m.focusWindowPane(); m.splitFocus {
+		t.Error("the notes pane should not take focus")
+	}
+	rows := strings.Join(m.splitPaneRows(40, 10), "\n")
+	if !strings.Contains(rows, "Notes") || !strings.Contains(ansi.Strip(rows), "release") || strings.Contains(rows, "**") {
+		t.Errorf("splitPaneRows() = %q, want the rendered notes", rows)
+	}
+	if len(m.renderedNotes) != 1 {
+		t.Errorf("renderedNotes = %d entries, want the render cached", len(m.renderedNotes))
+	}
+
+	m.notes = nil
+	if m.showsNotesPane() {
+		t.Error("a session without notes should show its windows")
+	}
+}
+
+func TestKillTypeName(t *testing.T) {
+	m := New("home", config.Config{KillConfirmWindows: 2})
+	windows := []tmux.Window{{Index: 1}, {Index: 2}, {Index: 3}}
+	m.sessions = []tmux.Session{{Name: "backend", Windows: windows}, {Name: "web", Windows: windows[:2]}}
+	m.rebuildItems()
+
+	m.confirmKill()
+	if m.killTypeName != "bac" || !strings.Contains(m.prompt, `Type "bac"`) {
+		t.Fatalf("killTypeName = %q, prompt = %q, want the first letters asked for", m.killTypeName, m.prompt)
+	}
+
+	// A second C-x doesn't confirm, neither do the wrong letters
+	m.handleConfirmKillMode(tea.KeyMsg{Type: tea.KeyCtrlX})
+	m.input.SetValue("bax")
+	m.handleConfirmKillMode(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != ModeConfirmKill || !m.hasError() || m.input.Value() != "" {
+		t.Errorf("mode = %v, input = %q, want still confirming after a wrong name", m.mode, m.input.Value())
+	}
+
+	m.handleConfirmKillMode(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != ModeNormal || m.killTypeName != "" {
+		t.Errorf("mode = %v, killTypeName = %q, want the confirmation cancelled", m.mode, m.killTypeName)
+	}
+
+	// Sessions up to kill_confirm_windows windows confirm with C-x
+	m.cursor = 1
+	m.confirmKill()
+	if m.killTypeName != "" {
+		t.Errorf("killTypeName = %q for a small session, want C-x to confirm", m.killTypeName)
+	}
+
+	// Marking a big session along with others still asks for its name
+	m.marked = map[string]bool{"web": true, "backend": true}
+	m.confirmKill()
+	if m.killTypeName != "bac" || !strings.Contains(m.prompt, "Kill 2 marked items?") {
+		t.Errorf("killTypeName = %q, prompt = %q, want backend's letters asked for", m.killTypeName, m.prompt)
+	}
+}
+
+func TestKillNumber(t *testing.T) {
+	m := New("home", config.Config{})
+	m.sessions = []tmux.Session{{Name: "api", Expanded: true, Windows: []tmux.Window{{ID: "@1", Index: 1}}}, {Name: "web"}, {Name: "docs"}}
+	m.rebuildItems()
+
+	alt2 := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2"), Alt: true}
+	m.handleKey(alt2)
+	if m.mode != ModeConfirmKill || m.killTarget != "web" {
+		t.Fatalf("mode = %v, killTarget = %q, want session 2 (web) awaiting confirmation", m.mode, m.killTarget)
+	}
+	m.cancelKill()
+
+	// Labels count the listed sessions, so they follow the filter
+	m.filter = "doc"
+	m.rebuildItems()
+	m.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1"), Alt: true})
+	if m.killTarget != "docs" || m.filter != "doc" {
+		t.Errorf("killTarget = %q, filter = %q, want docs without touching the filter", m.killTarget, m.filter)
+	}
+	m.cancelKill()
+
+	m.marked = map[string]bool{"api": true}
+	if m.handleKey(alt2); m.mode == ModeConfirmKill {
+		t.Error("killing by number should refuse while items are marked")
+	}
+}
+
+func TestSlugSessionName(t *testing.T) {
+	tests := map[string]string{
+		"  Fix login bug  ":       "Fix-login-bug",
+		"api/v2.3: hotfix":        "api-v2-3-hotfix",
+		"already-a-slug":          "already-a-slug",
+		"--- trailing / dashes -": "trailing-dashes",
+		"acme@main":               "acme@main",
+		". :":                     "",
+	}
+	for in, want := range tests {
+		if got := slugSessionName(in); got != want {
+			t.Errorf("slugSessionName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCreateInputSanitized(t *testing.T) {
+	m := New("home", config.Config{})
+	m.startCreate("", "")
+
+	for _, r := range "ap.i" {
+		m.handleCreateMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if m.input.Value() != "api" {
+		t.Errorf("input = %q, want the dot dropped", m.input.Value())
+	}
+	if !strings.Contains(m.createHint, "'.'") {
+		t.Errorf("createHint = %q, want a hint on the dropped dot", m.createHint)
+	}
+
+	m.handleCreateMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" login page v2.1"), Paste: true})
+	if m.input.Value() != "apilogin-page-v2-1" || m.createHint != "pasted text slugified" {
+		t.Errorf("input = %q, hint = %q, want the paste slugified", m.input.Value(), m.createHint)
+	}
+	if got := ansi.Strip(m.createCounter()); got != " 18/50" {
+		t.Errorf("createCounter() = %q, want the length against CharLimit", got)
+	}
+
+	m.startCreate("", "")
+	if m.createHint != "" {
+		t.Error("a new prompt should start without a hint")
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	tests := []struct {
+		kind, pattern, text string
+		want                bool
+		spans               []span
+	}{
+		{config.MatcherSubstring, "API", "my-api", true, []span{{3, 6}}},
+		{config.MatcherSubstring, "mapi", "my-api", false, nil},
+		{config.MatcherFuzzy, "mapi", "my-api", true, []span{{0, 1}, {3, 6}}},
+		{config.MatcherFuzzy, "ipa", "my-api", false, nil},
+		{config.MatcherSmartCase, "api", "My-API", true, []span{{3, 6}}},
+		{config.MatcherSmartCase, "Api", "my-api", false, nil},
+		{config.MatcherSmartCase, "API", "My-API", true, []span{{3, 6}}},
+		{config.MatcherRegex, "^api-(web|db)$", "API-db", true, []span{{0, 6}}},
+		{config.MatcherRegex, "^api-(web|db)$", "api-cli", false, nil},
+		{config.MatcherRegex, "api-(", "my-api-(x)", true, []span{{3, 8}}}, // Half-typed: literal
+	}
+	for _, tt := range tests {
+		spans, ok := newMatcher(tt.kind, tt.pattern).match(tt.text)
+		if ok != tt.want || !slices.Equal(spans, tt.spans) {
+			t.Errorf("%s %q on %q = %v, %v, want %v, %v", tt.kind, tt.pattern, tt.text, spans, ok, tt.spans, tt.want)
+		}
+	}
+
+	if newMatcher(config.MatcherFuzzy, "") != nil || !matches(nil, "anything") {
+		t.Error("empty filter text should match everything")
+	}
+}
+
+func TestCycleMatcher(t *testing.T) {
+	m := New("home", config.Config{Matcher: config.MatcherSmartCase})
+	m.sessions = []tmux.Session{{Name: "api"}, {Name: "api-web"}, {Name: "docs"}}
+	m.filter = "^api$"
+	m.rebuildItems()
+	if len(m.items) != 0 {
+		t.Fatalf("smart-case should take %q literally, got %d items", m.filter, len(m.items))
+	}
+
+	m.cycleMatcher()
+	if m.matcherKind() != config.MatcherRegex || m.lastToast() != "Matcher: regex" {
+		t.Errorf("matcher = %q, message = %q, want regex after smart-case", m.matcherKind(), m.lastToast())
+	}
+	if len(m.items) != 1 || m.sessions[m.items[0].SessionIndex].Name != "api" {
+		t.Errorf("items = %+v, want the list refiltered as a regex", m.items)
+	}
+
+	// Appending can widen a regex, so the list is rebuilt rather than narrowed
+	m.handleNormalMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("|docs")})
+	if len(m.items) != 2 {
+		t.Errorf("filter %q: %d items, want api and docs", m.filter, len(m.items))
+	}
+
+	m.cycleMatcher()
+	if m.matcherKind() != config.MatcherSubstring {
+		t.Errorf("matcher = %q, want the cycle to wrap to substring", m.matcherKind())
+	}
+}
+
+func TestDetachedClients(t *testing.T) {
+	clients := []tmux.Client{
+		{Name: "/dev/pts/1", Session: "home"},
+		{Name: "/dev/pts/2", Session: "api"},
+		{Name: "/dev/pts/3", Session: "api"},
+	}
+
+	tests := []struct {
+		option    string
+		remaining []string
+		want      int
+	}{
+		{"on", []string{"home", "web"}, 2},
+		{"off", []string{"home", "web"}, 0},
+		{"off", nil, 2},
+		{"next", []string{"home"}, 0},
+		// no-detached only switches to a session nobody is attached to
+		{"no-detached", []string{"home", "web"}, 0},
+		{"no-detached", []string{"home"}, 2},
+	}
+	for _, tt := range tests {
+		if got := detachedClients(tt.option, clients, []string{"api"}, tt.remaining); got != tt.want {
+			t.Errorf("detachedClients(%q, remaining %v) = %d, want %d", tt.option, tt.remaining, got, tt.want)
+		}
+	}
+
+	if got := detachedClients("on", clients, []string{"web"}, []string{"home", "api"}); got != 0 {
+		t.Errorf("detachedClients() for an unattached session = %d, want 0", got)
+	}
+}
+
+func TestSmallClients(t *testing.T) {
+	clients := []tmux.Client{
+		{Name: "/dev/pts/1", Session: "home", Width: 200, Height: 50},
+		{Name: "/dev/pts/2", Session: "api", Size: "80x24", Width: 80, Height: 24},
+		{Name: "/dev/pts/3", Session: "web", Width: 190, Height: 48},
+		{Name: "/dev/pts/4", Session: "api", Size: "200x30", Width: 200, Height: 30},
+	}
+	small := smallClients(clients, "/dev/pts/1")
+	if len(small) != 1 || len(small["api"]) != 2 {
+		t.Errorf("smallClients() = %v, want both api clients and not the web one", small)
+	}
+	if got := smallClients(clients, "/dev/pts/9"); got != nil {
+		t.Errorf("smallClients() = %v, want none without the own client", got)
+	}
+
+	m := New("home", config.Config{})
+	m.sessions = []tmux.Session{{Name: "api"}, {Name: "web"}}
+	m.rebuildItems()
+	m.smallClients = small
+	if hint := m.cursorClientHint(); !strings.Contains(hint, "80x24, 200x30 clients") {
+		t.Errorf("cursorClientHint() = %q, want the client sizes", hint)
+	}
+	m.cursor = 1
+	if hint := m.cursorClientHint(); hint != "" {
+		t.Errorf("cursorClientHint() = %q, want none for web", hint)
+	}
+}
+
+func TestSuspendPicker(t *testing.T) {
+	m := New("home", config.Config{})
+	m.mode = ModeCreate
+
+	_, cmd := m.handleKey(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	if cmd == nil || cmd() != tea.Suspend() {
+		t.Error("C-z should suspend tsm in any mode")
+	}
+
+	if _, cmd := m.Update(tea.ResumeMsg{}); cmd == nil {
+		t.Error("resuming should reload the sessions")
+	}
+}
+
+func TestDisambiguateName(t *testing.T) {
+	paths := map[string]string{
+		"api":      "/work/acme/api",
+		"api~2":    "/work/other/api",
+		"api@main": "/work/fork/api",
+	}
+
+	tests := []struct {
+		dir, branch, want string
+	}{
+		// Same directory: reuse the session
+		{"/work/acme/api/", "", "api"},
+		{"/work/other/api", "", "api~2"},
+		{"/work/new/api", "", "api~3"},
+		{"/work/new/api", "dev", "api@dev"},
+		// The branch name is taken by another directory too
+		{"/work/new/api", "main", "api~3"},
+		{"/work/fork/api", "main", "api@main"},
+	}
+	for _, tt := range tests {
+		if got := disambiguateName("api", tt.dir, tt.branch, paths); got != tt.want {
+			t.Errorf("disambiguateName(api, %q, %q) = %q, want %q", tt.dir, tt.branch, got, tt.want)
+		}
+	}
+
+	if got := disambiguateName("web", "/work/web", "main", paths); got != "web" {
+		t.Errorf("disambiguateName(web) = %q, want the free name kept", got)
+	}
+}
+
+func TestOpenCreatedWaitsForLayout(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ide.sh"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.sh"), []byte("#!/bin/sh\necho no such pane >&2\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	m := New("home", config.Config{LayoutDir: dir, LayoutWait: true, StateDir: t.TempDir()})
+
+	_, cmd := m.openCreated("api", "/work/api", "ide", true)
+	if m.prompt != "Applying layout ide…" || m.createdSession != "" {
+		t.Fatalf("prompt = %q, want progress while the layout runs", m.prompt)
+	}
+	msg, ok := cmd().(layoutAppliedMsg)
+	if !ok || msg.err != nil {
+		t.Fatalf("layout command = %+v, want a successful layoutAppliedMsg", msg)
+	}
+	m.handleLayoutApplied(msg)
+	if m.createdSession != "api" {
+		t.Errorf("createdSession = %q, want api announced once the layout is done", m.createdSession)
+	}
+
+	m.createdSession = ""
+	_, cmd = m.openCreated("web", "/work/web", "broken", true)
+	m.handleLayoutApplied(cmd().(layoutAppliedMsg))
+	if !m.hasError() || !strings.Contains(m.lastToast(), "no such pane") || m.createdSession != "" {
+		t.Errorf("message = %q, want the layout failure and no switch", m.lastToast())
+	}
+}
+
+func TestSnapshotDiffView(t *testing.T) {
+	m := New("home", config.Config{StateDir: t.TempDir()})
+
+	m.openSnapshotDiff()
+	if m.mode != ModeNormal || !strings.Contains(m.lastToast(), "tsm save") {
+		t.Fatalf("message = %q, want a hint to save a snapshot first", m.lastToast())
+	}
+
+	snapshot := state.Snapshot{SavedAt: time.Now().Add(-2 * time.Hour)}
+	lines := snapshotDiffLines(snapshot, []state.SessionDiff{{Name: "docs", Kind: state.DiffMissing, Windows: 2}})
+	if len(lines) != 2 || lines[0] != "Snapshot saved 2h ago" || !strings.HasPrefix(lines[1], "+ docs") {
+		t.Errorf("lines = %q, want the age then the missing session", lines)
+	}
+	if lines := snapshotDiffLines(snapshot, nil); lines[1] != "Live sessions match the snapshot" {
+		t.Errorf("lines = %q, want a match", lines)
+	}
+
+	m.mode = ModeSnapshotDiff
+	m.snapshotDiff = lines
+	m.handleSnapshotDiffMode(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != ModeNormal || m.snapshotDiff != nil {
+		t.Error("esc should close the snapshot diff")
+	}
+}
+
+func TestScrollPosition(t *testing.T) {
+	m := New("home", config.Config{})
+	for i := range 12 {
+		m.sessions = append(m.sessions, tmux.Session{Name: fmt.Sprintf("s%d", i)})
+	}
+	m.rebuildItems()
+	m.cursor = 9
+
+	if got := m.scrollPosition(5); got != "10/12" {
+		t.Errorf("scrollPosition(5) = %q, want 10/12", got)
+	}
+	if got := m.scrollPosition(12); got != "" {
+		t.Errorf("scrollPosition(12) = %q, want empty when every row fits", got)
+	}
+}
+
+func TestWaitingTarget(t *testing.T) {
+	m := New("", config.Config{})
+	now := time.Now()
+	m.sessions = []tmux.Session{
+		{Name: "api", Windows: []tmux.Window{{ID: "@1"}, {ID: "@2"}}},
+		{Name: "web", Windows: []tmux.Window{{ID: "@3"}}},
+		{Name: "docs", Windows: []tmux.Window{{ID: "@4"}}},
+	}
+	m.claudeStatuses = map[string]claude.Status{
+		"api":  {State: "waiting", Timestamp: now.Add(-time.Minute), Window: "@2"},
+		"web":  {State: "working", Timestamp: now.Add(-time.Hour), Window: "@3"},
+		"docs": {State: "waiting", Timestamp: now, Window: "@4"},
+	}
+
+	name, target, ok := m.waitingTarget()
+	if !ok || name != "api" || target != "@2" {
+		t.Errorf("waitingTarget() = %q, %q, %v, want api's window @2", name, target, ok)
+	}
+
+	// A window that is gone falls back to the session
+	m.claudeStatuses["api"] = claude.Status{State: "waiting", Timestamp: now.Add(-time.Minute), Window: "@9"}
+	if name, target, _ = m.waitingTarget(); name != "api" || target != "api" {
+		t.Errorf("waitingTarget() = %q, %q, want the api session", name, target)
+	}
+
+	m.claudeStatuses = map[string]claude.Status{"web": {State: "working"}}
+	if _, _, ok = m.waitingTarget(); ok {
+		t.Error("waitingTarget() found a target with no waiting session")
+	}
+}
+
+func TestToasts(t *testing.T) {
+	m := New("home", config.Config{})
+	m.setInfo("Renamed %s", "api")
+	m.setError("Error: %v", errors.New("boom"))
+	m.setInfo("Size: %s", "compact")
+
+	// A later message stacks instead of replacing the earlier ones
+	if len(m.toasts) != 3 || m.lastToast() != "Size: compact" || m.lastError() != "Error: boom" {
+		t.Fatalf("toasts = %+v, want all three stacked", m.toasts)
+	}
+
+	// Repeating a toast moves it to the top instead of stacking it twice
+	m.setInfo("Renamed %s", "api")
+	if len(m.toasts) != 3 || m.lastToast() != "Renamed api" {
+		t.Errorf("toasts = %+v, want the repeated toast moved to the top", m.toasts)
+	}
+
+	// Infos expire first, errors stay longer
+	m.expireToasts(time.Now().Add(toastLifetimes[toastInfo]))
+	if len(m.toasts) != 1 || !m.hasError() {
+		t.Errorf("toasts = %+v, want only the error left", m.toasts)
+	}
+	m.expireToasts(time.Now().Add(toastLifetimes[toastError]))
+	if len(m.toasts) != 0 {
+		t.Errorf("toasts = %+v, want all expired", m.toasts)
+	}
+
+	for i := range maxToasts + 2 {
+		m.setWarning("warning %d", i)
+	}
+	if len(m.toasts) != maxToasts || m.toasts[0].text != "warning 2" {
+		t.Errorf("toasts = %+v, want the oldest dropped", m.toasts)
+	}
+}
+
+func TestExpandIsolatedSession(t *testing.T) {
+	m := New("home", config.Config{})
+	m.recent = []state.HistoryEntry{{Name: "api-old"}}
+	m.sessions = []tmux.Session{
+		{Name: "api", Windows: []tmux.Window{{ID: "@1", Name: "editor"}, {ID: "@2", Name: "server"}}},
+		{Name: "web", Windows: []tmux.Window{{ID: "@3", Name: "editor"}}},
+	}
+	m.filter = "api"
+	m.rebuildItems()
+
+	// The recent entry also matches, the cursor sits on it
+	m.cursor = len(m.items) - 1
+	m.expandCurrent()
+	if !m.sessions[0].Expanded {
+		t.Fatal("the only matching session should expand wherever the cursor is")
+	}
+	if item := m.items[m.cursor]; item.IsSession || item.IsRecent || item.SessionIndex != 0 || item.WindowIndex != 0 {
+		t.Errorf("cursor on %+v, want api's first window", item)
+	}
+
+	// With several sessions left, only the one under the cursor expands
+	m.sessions[0].Expanded = false
+	m.filter = "e"
+	m.rebuildItems()
+	m.cursor = 0
+	m.expandCurrent()
+	if item := m.items[m.cursor]; !item.IsSession {
+		t.Errorf("cursor on %+v, want it to stay on the session", item)
+	}
+}
+
+func TestTreeView(t *testing.T) {
+	m := New("home", config.Config{})
+	m.TreeView()
+	m.sessions = []tmux.Session{{
+		Name:     "api",
+		Expanded: true,
+		Windows:  []tmux.Window{{ID: "@1", Index: 1, Name: "editor"}, {ID: "@2", Index: 2, Name: "server"}},
+	}}
+	m.panes = map[string][]tmux.Pane{
+		"@1": {{ID: "%1", Index: 0, Command: "nvim"}, {ID: "%2", Index: 1, Command: "zsh"}},
+		"@2": {{ID: "%3", Index: 0, Command: "go"}},
+	}
+	m.rebuildItems()
+
+	// The single-pane window lists no panes
+	want := []string{"", "├─ ", "│  ├─ ", "│  └─ ", "└─ "}
+	if len(m.items) != len(want) {
+		t.Fatalf("got %d items, want %d", len(m.items), len(want))
+	}
+	for i, prefix := range want {
+		if got := m.treePrefix(m.items[i]); got != prefix {
+			t.Errorf("treePrefix(item %d) = %q, want %q", i, got, prefix)
+		}
+	}
+
+	pane := m.items[3]
+	if !pane.IsPane || pane.WindowIndex != 0 {
+		t.Fatalf("item 3 = %+v, want the second pane of window 1", pane)
+	}
+	if got := m.getTargetName(pane); got != "%2" {
+		t.Errorf("getTargetName() = %q, want the pane ID", got)
+	}
+	if got := m.displayName(pane); got != "api:1.1" {
+		t.Errorf("displayName() = %q, want api:1.1", got)
+	}
+
+	// Outside the tree view, windows have no panes and no connectors
+	m.tree = false
+	m.rebuildItems()
+	if len(m.items) != 3 || m.treePrefix(m.items[1]) != "" {
+		t.Errorf("got %d items, want the session and its 2 windows without connectors", len(m.items))
+	}
+}
+
+func TestKeySequences(t *testing.T) {
+	typeKey := func(m *Model, k string) {
+		m.handleNormalMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	}
+	m := New("home", config.Config{KeySequences: true, SequenceTimeout: time.Second})
+	m.sessions = []tmux.Session{
+		{Name: "api", Windows: []tmux.Window{{ID: "@1", Name: "editor"}}},
+		{Name: "web", Windows: []tmux.Window{{ID: "@2", Name: "editor"}}},
+	}
+	m.rebuildItems()
+
+	// z a expands the session under the cursor, then collapses it again
+	m.cursor = 1
+	typeKey(&m, "z")
+	if m.lastKey != "z" || m.filter != "" {
+		t.Fatalf("lastKey = %q, filter = %q, want z held", m.lastKey, m.filter)
+	}
+	typeKey(&m, "a")
+	if !m.sessions[1].Expanded || m.filter != "" {
+		t.Fatalf("z a should expand web, filter = %q", m.filter)
+	}
+	typeKey(&m, "z")
+	typeKey(&m, "a")
+	if m.sessions[1].Expanded {
+		t.Error("z a should collapse web again")
+	}
+
+	// g g moves to the top
+	typeKey(&m, "g")
+	typeKey(&m, "g")
+	if m.cursor != 0 {
+		t.Errorf("cursor = %d after g g, want 0", m.cursor)
+	}
+
+	// A key that doesn't complete a sequence types both into the filter
+	typeKey(&m, "g")
+	typeKey(&m, "o")
+	if m.filter != "go" || m.lastKey != "" {
+		t.Errorf("filter = %q, lastKey = %q, want go typed", m.filter, m.lastKey)
+	}
+
+	// A sequence typed too slowly filters, as does a held key once it times out
+	m.filter = ""
+	typeKey(&m, "d")
+	m.lastKeyTime = m.lastKeyTime.Add(-2 * time.Second)
+	typeKey(&m, "d")
+	if m.mode == ModeConfirmKill || m.filter != "dd" {
+		t.Errorf("mode = %v, filter = %q, want an expired d d typed into the filter", m.mode, m.filter)
+	}
+	m.filter = ""
+	typeKey(&m, "d")
+	m.handleSequenceTimeout(sequenceTimeoutMsg{at: m.lastKeyTime})
+	if m.filter != "d" || m.lastKey != "" {
+		t.Errorf("filter = %q after the timeout, want d", m.filter)
+	}
+}
+
+func TestStartGrouped(t *testing.T) {
+	m := New("home", config.Config{})
+	m.sessions = []tmux.Session{{Name: "api", Group: "api"}, {Name: "api~2", Group: "api"}, {Name: "web"}}
+	m.rebuildItems()
+
+	m.startGrouped()
+	if m.mode != ModeCreate || m.createGroup != "api" {
+		t.Fatalf("mode = %v, createGroup = %q, want the name prompt for api's group", m.mode, m.createGroup)
+	}
+	if got := m.input.Value(); got != "api~3" {
+		t.Errorf("suggested name = %q, want the first free api~N", got)
+	}
+	if !m.groupShared(0) || m.groupShared(2) {
+		t.Error("api shares its group with api~2, web has none")
+	}
+
+	// A plain create prompt doesn't group
+	m.startCreate("", "")
+	if m.createGroup != "" {
+		t.Errorf("createGroup = %q after C-n, want empty", m.createGroup)
+	}
+}
+
+func TestProjectsScanned(t *testing.T) {
+	m := New("home", config.Config{ProjectDepth: 1})
+	m.mode = ModePickDirectory
+	m.projectScanning = true
+	m.projectDirs = []string{"/repos/api", "/repos/web"}
+	m.projectFilter = "w"
+	m.filterProjectDirs()
+
+	// A fresh scan keeps the filter and the selected directory
+	m.handleProjectsScanned(projectsScannedMsg{dirs: []string{"/repos/api", "/repos/new-web", "/repos/web"}})
+	if m.projectScanning || len(m.projectFiltered) != 2 {
+		t.Errorf("projectFiltered = %v, want the rescanned matches", m.projectFiltered)
+	}
+	if got := m.projectFiltered[m.projectCursor]; got != "/repos/web" {
+		t.Errorf("selected %q, want /repos/web", got)
+	}
+}
+
+func TestCommandPalette(t *testing.T) {
+	m := New("home", config.Config{StateDir: t.TempDir()})
+	m.sessions = []tmux.Session{{Name: "web"}, {Name: "api"}, {Name: "apps"}}
+	m.rebuildItems()
+
+	m.handleNormalMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(":")})
+	if m.mode != ModeCommand {
+		t.Fatalf("mode = %v, want the command line after :", m.mode)
+	}
+
+	// Tab completes the command, then as far as the session names agree
+	m.input.SetValue("ki")
+	m.completePalette()
+	if got := m.input.Value(); got != "kill " {
+		t.Errorf("completed %q, want %q", got, "kill ")
+	}
+	m.input.SetValue("kill a")
+	m.completePalette()
+	if got := m.input.Value(); got != "kill ap" {
+		t.Errorf("completed %q, want %q", got, "kill ap")
+	}
+	if got := m.paletteCandidates(); len(got) != 2 {
+		t.Errorf("paletteCandidates() = %v, want api and apps", got)
+	}
+
+	m.input.SetValue("sort name")
+	m.handleCommandMode(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != ModeNormal || m.sessions[0].Name != "api" || m.sessions[2].Name != "web" {
+		t.Errorf("sessions = %v, want sorted by name", m.sessionNames())
+	}
+
+	m.openPalette("")
+	m.input.SetValue("tag work client-x")
+	m.handleCommandMode(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := m.tags["api"]; len(got) != 2 {
+		t.Errorf("tags = %v, want work and client-x on the selected session", got)
+	}
+
+	m.openPalette("")
+	m.input.SetValue("frobnicate")
+	m.handleCommandMode(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.hasError() {
+		t.Error("an unknown command should show an error")
+	}
+
+	// :kill finds a session the group focus hides
+	m.sessions[2].Group = "work"
+	m.focusGroup = "work"
+	m.rebuildItems()
+	m.openPalette("")
+	m.input.SetValue("kill api")
+	m.handleCommandMode(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != ModeConfirmKill || m.killTarget != "api" || m.focusGroup != "" {
+		t.Errorf("mode = %v, killTarget = %q, want api awaiting confirmation with the focus left", m.mode, m.killTarget)
+	}
+	m.cancelKill()
+
+	m.config.ReadOnly = true
+	for _, command := range []string{"rename foo", "tag home"} {
+		m.openPalette("")
+		m.input.SetValue(command)
+		m.handleCommandMode(tea.KeyMsg{Type: tea.KeyEnter})
+		if m.lastToast() != "Read-only mode" {
+			t.Errorf("error = %q, want :%s refused in read-only mode", m.lastToast(), command)
+		}
+	}
+}
+
+func TestConfirmBroadcast(t *testing.T) {
+	m := New("home", config.Config{})
+	m.sessions = []tmux.Session{{Name: "api"}, {Name: "web", Windows: []tmux.Window{{Index: 2}}}}
+	m.rebuildItems()
+
+	m.openPalette("send git pull")
+	m.handleCommandMode(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != ModeNormal || !m.hasError() {
+		t.Errorf("mode = %v, want an error without marks", m.mode)
+	}
+
+	m.marked = map[string]bool{"api": true, "web:2": true}
+	m.openPalette("send  awk '{print  $1}' ")
+	m.handleCommandMode(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.mode != ModeConfirmBroadcast || m.broadcastCommand != "awk '{print  $1}'" {
+		t.Fatalf("mode = %v, command = %q, want the command confirmed with its spacing", m.mode, m.broadcastCommand)
+	}
+	if !slices.Equal(m.broadcastTargets, []string{"api", "web:2"}) {
+		t.Errorf("targets = %v, want the marked session and window", m.broadcastTargets)
+	}
+
+	m.handleConfirmBroadcastMode(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.mode != ModeNormal || m.prompt != "" || len(m.marked) != 2 {
+		t.Errorf("esc should cancel and keep the marks, mode = %v", m.mode)
+	}
+
+	for command, want := range map[string]bool{"zsh": true, "-bash": true, "nvim": false, "node": false} {
+		if got := isShell(command); got != want {
+			t.Errorf("isShell(%q) = %v, want %v", command, got, want)
+		}
+	}
+}
+
+func TestMoveSession(t *testing.T) {
+	dir := t.TempDir()
+	m := New("home", config.Config{StateDir: dir})
+	m.sessions = []tmux.Session{{Name: "web"}, {Name: "api"}, {Name: "docs"}}
+	m.manualOrder = []string{"api", "home", "web"}
+	m.rebuildItems()
+
+	m.handleNormalMode(tea.KeyMsg{Type: tea.KeyDown, Alt: true})
+	if got := m.sessionNames(); !slices.Equal(got, []string{"api", "web", "docs"}) {
+		t.Fatalf("sessions = %v, want web moved down", got)
+	}
+	if m.sortBy != config.SortManual || m.cursorSessionName() != "web" {
+		t.Errorf("sortBy = %q, cursor on %q, want manual with the cursor on web", m.sortBy, m.cursorSessionName())
+	}
+
+	// The current session keeps its slot, new sessions go last
+	want := []string{"api", "home", "web", "docs"}
+	if !slices.Equal(m.manualOrder, want) {
+		t.Errorf("manualOrder = %v, want %v", m.manualOrder, want)
+	}
+	if prefs, _ := state.LoadPrefs(dir); !slices.Equal(prefs.Order, want) {
+		t.Errorf("saved order = %v, want %v", prefs.Order, want)
+	}
+
+	// The first session can't move further up
+	m.cursor = 0
+	m.moveSession(-1)
+	if got := m.sessionNames(); got[0] != "api" {
+		t.Errorf("sessions = %v, want api still first", got)
+	}
+
+	// Loading the sessions again keeps the arrangement
+	m.sessions = []tmux.Session{{Name: "docs"}, {Name: "web"}, {Name: "api"}, {Name: "new"}}
+	m.sortSessions(m.sessions)
+	if got := m.sessionNames(); !slices.Equal(got, []string{"api", "web", "docs", "new"}) {
+		t.Errorf("sessions = %v, want the manual order with new last", got)
+	}
+
+	// Sessions hidden by a group focus are skipped: web swaps with the
+	// next listed session, not with docs
+	m.sessions = []tmux.Session{{Name: "api", Group: "work"}, {Name: "web", Group: "work"}, {Name: "docs"}, {Name: "new", Group: "work"}}
+	m.focusGroup = "work"
+	m.rebuildItems()
+	m.cursor = 1
+	m.moveSession(1)
+	if got := m.sessionNames(); !slices.Equal(got, []string{"api", "new", "docs", "web"}) {
+		t.Errorf("sessions = %v, want web swapped with new", got)
+	}
+	if m.cursorSessionName() != "web" {
+		t.Errorf("cursor on %q, want web", m.cursorSessionName())
+	}
+	m.focusGroup = ""
+
+	m.filter = "a"
+	m.moveSession(1)
+	if !m.hasError() {
+		t.Error("moveSession() with a filter: want an error")
+	}
+}
+
+func TestSortCommand(t *testing.T) {
+	m := New("", config.Config{StateDir: t.TempDir(), Sort: config.SortExternal, SortCommand: "grep -q urgent && echo docs; echo web"})
+	if err := m.store.SetTags("docs", []string{"urgent"}); err != nil {
+		t.Fatal(err)
+	}
+	load := func(names ...string) sessionsMsg {
+		sessions := make([]tmux.Session, len(names))
+		for i, name := range names {
+			sessions[i] = tmux.Session{Name: name}
+		}
+		tags, _ := m.store.LoadTags()
+		ranked, err := m.rankSessions(sessions, tags)
+		return sessionsMsg{sessions: sessions, tags: tags, ranked: ranked, rankErr: err}
+	}
+
+	msg := load("api", "web", "docs")
+	if !slices.Equal(msg.ranked, []string{"docs", "web"}) || msg.rankErr != nil {
+		t.Fatalf("ranked = %v, %v, want docs (tagged urgent) and web", msg.ranked, msg.rankErr)
+	}
+	model, _ := m.Update(msg)
+	m = model.(Model)
+	if got := m.sessionNames(); !slices.Equal(got, []string{"docs", "web", "api"}) {
+		t.Errorf("sessions = %v, want the ranking with unranked api last", got)
+	}
+	if !m.tags.Has("docs", "urgent") {
+		t.Errorf("tags = %v, want them taken from the load", m.tags)
+	}
+
+	// A failing command keeps the last ranking
+	m.config.SortCommand = "exit 3"
+	model, _ = m.Update(load("web", "api", "docs"))
+	m = model.(Model)
+	if got := m.sessionNames(); !m.hasError() || !slices.Equal(got, []string{"docs", "web", "api"}) {
+		t.Errorf("sessions = %v, error shown = %v, want the last ranking and an error", got, m.hasError())
+	}
+
+	m.config.SortCommand = ""
+	m.paletteSort([]string{config.SortExternal})
+	if !m.hasError() {
+		t.Error(":sort command without sort_command: want an error")
+	}
+}
+
+func TestPreviewPane(t *testing.T) {
+	m := New("home", config.Config{SplitView: true, PreviewDelay: time.Second})
+	m.sessions = []tmux.Session{{Name: "api"}, {Name: "web"}}
+	m.rebuildItems()
+
+	if _, cmd := m.togglePreviewPane(); cmd == nil || m.previewTarget != "api" {
+		t.Fatalf("previewTarget = %q, want the wait for api started", m.previewTarget)
+	}
+	stale := previewIdleMsg{seq: m.previewSeq}
+
+	// Moving on cancels the pending capture and drops a late one
+	m.handleNormalMode(tea.KeyMsg{Type: tea.KeyDown})
+	m.schedulePreview(false)
+	if m.previewTarget != "web" || m.handlePreviewIdle(stale) != nil {
+		t.Errorf("previewTarget = %q, want web with api's capture cancelled", m.previewTarget)
+	}
+	m.handlePreview(previewMsg{target: "api", lines: []string{"api output"}})
+	if m.previewLines != nil {
+		t.Errorf("previewLines = %v, want api's late capture dropped", m.previewLines)
+	}
+
+	if m.handlePreviewIdle(previewIdleMsg{seq: m.previewSeq}) == nil {
+		t.Error("handlePreviewIdle() = nil, want web captured once the cursor rested")
+	}
+	m.handlePreview(previewMsg{target: "web", lines: []string{"$ make", "ok"}})
+	rows := m.previewPaneRows(40, 3)
+	if len(rows) != 3 || !strings.Contains(rows[2], "ok") {
+		t.Errorf("previewPaneRows() = %q, want the heading and web's output", rows)
+	}
+}
+
+func TestDeadPanes(t *testing.T) {
+	m := New("home", config.Config{})
+	m.width = 80
+	editor := tmux.Window{ID: "@1", Index: 1, Name: "editor"}
+	server := tmux.Window{ID: "@2", Index: 2, Name: "server"}
+	m.sessions = []tmux.Session{{Name: "api", Windows: []tmux.Window{editor, server}}, {Name: "web"}}
+	m.deadPanes = map[string][]string{"@2": {"%4", "%5"}}
+	m.rebuildItems()
+
+	if panes, scope := m.selectedDeadPanes(false); len(panes) != 2 || scope != "api" {
+		t.Errorf("selectedDeadPanes() = %v in %q, want server's 2 panes in api", panes, scope)
+	}
+	if got := m.renderWindowPlain("api", server, 1, "", false, false); !strings.Contains(got, "[2 dead panes]") {
+		t.Errorf("renderWindowPlain() = %q, want the dead panes badge", got)
+	}
+	if got := m.renderWindowPlain("api", editor, 0, "", false, false); strings.Contains(got, "dead") {
+		t.Errorf("renderWindowPlain() = %q, want no badge without dead panes", got)
+	}
+
+	m.handleNormalMode(tea.KeyMsg{Type: tea.KeyDown})
+	m.paletteRespawn(nil)
+	if got := m.lastToast(); !strings.Contains(got, "No dead panes in web") {
+		t.Errorf("toast = %q, want nothing to respawn in web", got)
+	}
+	m.paletteReap([]string{"everything"})
+	if !m.hasError() {
+		t.Error(":reap everything succeeded, want the usage")
+	}
+}
+
+func TestPaletteDuplicate(t *testing.T) {
+	m := New("", config.Config{})
+	m.sessions = []tmux.Session{{Name: "api", Windows: []tmux.Window{{ID: "@1", Index: 1, Name: "editor"}}}}
+	m.rebuildItems()
+
+	m.paletteDuplicate(nil)
+	if got := m.lastToast(); !m.hasError() || !strings.Contains(got, "Select a window") {
+		t.Errorf("toast = %q, want a window asked for on a session row", got)
+	}
+	m.paletteDuplicate([]string{"nvim"})
+	if got := m.lastToast(); !strings.Contains(got, "Usage: :duplicate [cmd]") {
+		t.Errorf("toast = %q, want the usage", got)
+	}
+}
+
+func TestAgentsView(t *testing.T) {
+	m := New("home", config.Config{ClaudeStatusEnabled: true})
+	now := time.Now()
+	m.sessions = []tmux.Session{{Name: "api"}, {Name: "docs"}, {Name: "web"}, {Name: "ops"}, {Name: "db"}}
+	m.claudeStatuses = map[string]claude.Status{
+		"api": {State: "working", Timestamp: now.Add(-time.Minute)},
+		"web": {State: "waiting", Timestamp: now.Add(-30 * time.Second)},
+		"ops": {State: "waiting", Timestamp: now.Add(-100 * time.Second)},
+		"db":  {State: "new", Timestamp: now},
+	}
+	m.rebuildItems()
+
+	m.toggleAgentsView()
+	var names []string
+	for _, item := range m.items {
+		names = append(names, m.sessions[item.SessionIndex].Name)
+	}
+	if want := []string{"ops", "web", "api", "db"}; !slices.Equal(names, want) {
+		t.Errorf("agents view = %v, want %v", names, want)
+	}
+	if got := m.agentDuration("ops"); got != "waiting 1m" {
+		t.Errorf("agentDuration(ops) = %q, want \"waiting 1m\"", got)
+	}
+
+	// Number labels count the rows of the agents view
+	orig := switchClient
+	t.Cleanup(func() { switchClient = orig })
+	var switched string
+	switchClient = func(target string) error {
+		switched = target
+		return nil
+	}
+	m.handleJump(1)
+	if switched != "ops" {
+		t.Errorf("1 switched to %q, want the top agent ops", switched)
+	}
+	m.createdSession = "db"
+	m.announceCreated()
+	if got := m.lastToast(); !strings.Contains(got, "press 4") {
+		t.Errorf("toast = %q, want db's label 4 in the agents view", got)
+	}
+
+	m.toggleAgentsView()
+	if len(m.items) != 5 {
+		t.Errorf("items = %d, want every session back", len(m.items))
+	}
+}
+
+func TestPaletteFocus(t *testing.T) {
+	m := New("home", config.Config{})
+	m.sessions = []tmux.Session{
+		{Name: "api", Group: "work"}, {Name: "docs"}, {Name: "web", Group: "work"}, {Name: "ops", Group: "infra"},
+	}
+	m.recent = []state.HistoryEntry{{Name: "old"}}
+	m.rebuildItems()
+
+	m.cursor = 1
+	m.paletteFocus(nil)
+	if !m.hasError() || m.focusGroup != "" {
+		t.Error(":focus on an ungrouped session focused a group, want an error")
+	}
+	m.paletteFocus([]string{"nope"})
+	if !m.hasError() || m.focusGroup != "" {
+		t.Error(":focus nope focused a group, want an error")
+	}
+
+	m.cursor = 2
+	m.paletteFocus(nil)
+	var names []string
+	for _, item := range m.items {
+		names = append(names, m.sessions[item.SessionIndex].Name)
+	}
+	if want := []string{"api", "web"}; !slices.Equal(names, want) || m.focusGroup != "work" {
+		t.Errorf("focused items = %v, want %v", names, want)
+	}
+	if view := m.View(); !strings.Contains(view, "work") {
+		t.Error("header doesn't show the focused group")
+	}
+
+	// Number labels count the rows of the group
+	orig := switchClient
+	t.Cleanup(func() { switchClient = orig })
+	var switched string
+	switchClient = func(target string) error {
+		switched = target
+		return nil
+	}
+	m.handleJump(2)
+	if switched != "web" {
+		t.Errorf("2 switched to %q, want web, the group's second session", switched)
+	}
+
+	m.paletteFocus([]string{"infra"})
+	if len(m.items) != 1 || m.sessions[m.items[0].SessionIndex].Name != "ops" {
+		t.Errorf("items = %v, want only ops in infra", m.items)
+	}
+
+	m.handleKey(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.focusGroup != "" || len(m.items) != 5 {
+		t.Errorf("esc left focus %q with %d items, want every session and the recent one back", m.focusGroup, len(m.items))
+	}
+}
+
+func TestStartBreakOut(t *testing.T) {
+	m := New("home", config.Config{})
+	m.sessions = []tmux.Session{{Name: "api", Expanded: true, Windows: []tmux.Window{
+		{ID: "@1", Index: 1, Name: "editor"},
+		{ID: "@2", Index: 2, Name: "dev server"},
+	}}}
+	m.rebuildItems()
+
+	m.startBreakOut()
+	if !m.hasError() || m.mode != ModeNormal {
+		t.Error("startBreakOut() on a session opened the prompt, want an error")
+	}
+
+	m.cursor = 2
+	m.startBreakOut()
+	if m.mode != ModeCreate || m.breakOutWindow != "@2" || m.input.Value() != "dev-server" {
+		t.Errorf("breakOutWindow = %q, input = %q, want @2 named dev-server", m.breakOutWindow, m.input.Value())
+	}
+	m.input.SetValue("api")
+	m.handleCreateMode(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.hasError() || !strings.Contains(m.lastToast(), "already exists") {
+		t.Errorf("toast = %q, want the taken name refused", m.lastToast())
+	}
+
+	// A plain create afterwards doesn't move the window
+	m.handleCreateMode(tea.KeyMsg{Type: tea.KeyEsc})
+	m.startCreate("", "new")
+	if m.breakOutWindow != "" {
+		t.Errorf("breakOutWindow = %q after startCreate, want it cleared", m.breakOutWindow)
+	}
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/gitinfo"
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// maxNameSuffix bounds the numbered names tried for a conflicting name
+const maxNameSuffix = 99
+
+// resolveNameConflict returns the session to use for a project directory.
+// When the derived name belongs to a session in another directory, a
+// disambiguated name is picked instead (see config.NameConflict).
+func (m *Model) resolveNameConflict(name, dir string) string {
+	if m.config.NameConflict == config.NameConflictSwitch {
+		return name
+	}
+	sessions, err := tmux.ListSessions("")
+	if err != nil {
+		return name
+	}
+	paths := make(map[string]string, len(sessions))
+	for _, s := range sessions {
+		paths[s.Name] = s.Path
+	}
+
+	branch := ""
+	if m.config.NameConflict == config.NameConflictBranch {
+		if _, taken := paths[name]; taken {
+			branch = sanitizeSessionName(gitinfo.Branch(dir))
+		}
+	}
+	return disambiguateName(name, dir, branch, paths)
+}
+
+// disambiguateName returns the first of name, name@branch (with a branch)
+// and name~2, name~3, … that is free or already a session in dir. paths maps
+// the existing sessions to their directories.
+func disambiguateName(name, dir, branch string, paths map[string]string) string {
+	candidates := []string{name}
+	if branch != "" {
+		candidates = append(candidates, name+"@"+branch)
+	}
+	for i := 2; i <= maxNameSuffix; i++ {
+		candidates = append(candidates, fmt.Sprintf("%s~%d", name, i))
+	}
+
+	for _, candidate := range candidates {
+		path, taken := paths[candidate]
+		if !taken || filepath.Clean(path) == filepath.Clean(dir) {
+			return candidate
+		}
+	}
+	return name
+}
@@ -0,0 +1,240 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// cursorSessionName returns the name of the session under the cursor.
+// For window items this is the parent session.
+func (m *Model) cursorSessionName() string {
+	if !m.isCursorValid() || m.items[m.cursor].IsRecent {
+		return ""
+	}
+	return m.sessions[m.items[m.cursor].SessionIndex].Name
+}
+
+// openNoteInput switches to note input mode for the session under the cursor
+func (m *Model) openNoteInput() (tea.Model, tea.Cmd) {
+	name := m.cursorSessionName()
+	if name == "" {
+		return m, nil
+	}
+	return m.startNoteInput(name)
+}
+
+func (m *Model) startNoteInput(sessionName string) (tea.Model, tea.Cmd) {
+	m.noteTarget = sessionName
+	m.mode = ModeNoteInput
+	m.noteInput.Reset()
+	m.noteInput.SetWidth(m.contentWidth())
+	return m, m.noteInput.Focus()
+}
+
+func (m *Model) handleNoteInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+
+	switch {
+	case key.Matches(msg, keys.Cancel):
+		m.mode = ModeNormal
+		m.noteInput.Blur()
+		return m, nil
+
+	case key.Matches(msg, keys.SaveNote):
+		return m.saveNote()
+	}
+
+	var cmd tea.Cmd
+	m.noteInput, cmd = m.noteInput.Update(msg)
+	return m, cmd
+}
+
+// saveNote appends the note input to the target session's notes file
+func (m *Model) saveNote() (tea.Model, tea.Cmd) {
+	text := strings.TrimSpace(m.noteInput.Value())
+	if text == "" {
+		m.setError("Note cannot be empty")
+		return m, nil
+	}
+
+	m.mode = ModeNormal
+	m.noteInput.Blur()
+
+	if err := m.store.AppendNote(m.noteTarget, text, time.Now()); err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+
+	if m.notedSessions == nil {
+		m.notedSessions = make(map[string]bool)
+	}
+	m.notedSessions[m.noteTarget] = true
+	if m.notes == nil {
+		m.notes = make(map[string]string)
+	}
+	m.notes[m.noteTarget] += "\n" + text
+	m.setInfo("Note added to \"%s\"", m.noteTarget)
+	return m, nil
+}
+
+// openNotes switches to the notes view for the session under the cursor
+func (m *Model) openNotes() (tea.Model, tea.Cmd) {
+	name := m.cursorSessionName()
+	if name == "" {
+		return m, nil
+	}
+
+	content, err := m.store.ReadNotes(name)
+	if err != nil {
+		m.setError("Error reading notes: %v", err)
+		return m, nil
+	}
+	if content == "" {
+		m.setInfo("No notes for \"%s\". Press C-e to add one.", name)
+		return m, nil
+	}
+
+	m.noteTarget = name
+	m.notesLines = strings.Split(strings.TrimRight(content, "\n"), "\n")
+	m.notesScrollOffset = 0
+	m.mode = ModeNotes
+	return m, tea.WindowSize()
+}
+
+func (m *Model) handleNotesMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+
+	switch {
+	case key.Matches(msg, keys.Cancel):
+		m.mode = ModeNormal
+
+	case key.Matches(msg, keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, keys.Up):
+		if m.notesScrollOffset > 0 {
+			m.notesScrollOffset--
+		}
+
+	case key.Matches(msg, keys.Down):
+		if m.notesScrollOffset < len(m.notesLines)-m.notesMaxVisibleLines() {
+			m.notesScrollOffset++
+		}
+
+	case key.Matches(msg, keys.AddNote):
+		return m.startNoteInput(m.noteTarget)
+	}
+
+	return m, nil
+}
+
+// notesMaxVisibleLines returns the number of note lines that fit in the view
+func (m *Model) notesMaxVisibleLines() int {
+	contentH := m.contentHeight()
+	if contentH <= 0 {
+		return m.config.MaxVisibleItems
+	}
+	// Reserve: header(1) + header border(1) + footer border(1) + statusline(1) + help(1) = 5 lines
+	if available := contentH - 5; available > 0 {
+		return available
+	}
+	return 1
+}
+
+// viewNoteInput renders the note capture view
+func (m Model) viewNoteInput() string {
+	var b strings.Builder
+
+	b.WriteString(ui.HeaderStyle.Render("New note"))
+	b.WriteString("  ")
+	b.WriteString(ui.FilterStyle.Render(m.noteTarget))
+	b.WriteString("\n")
+	b.WriteString(ui.RenderBorder(m.borderWidth()))
+	b.WriteString("\n")
+
+	b.WriteString(m.noteInput.View())
+	b.WriteString("\n")
+	usedLines := 2 + m.noteInput.Height()
+
+	// Footer: border (1) + message (1) + help (1)
+	footerLines := 3
+	if contentH := m.contentHeight(); contentH > 0 {
+		for i := 0; i < contentH-usedLines-footerLines; i++ {
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(ui.RenderBorder(m.borderWidth()))
+	b.WriteString("\n")
+	if m.hasError() {
+		b.WriteString(ui.ErrorMessageStyle.Render(m.lastError()))
+	}
+	b.WriteString("\n")
+	b.WriteString(ui.FooterStyle.Render(ui.HelpNoteInput()))
+
+	return ui.AppStyle.Render(b.String())
+}
+
+// viewNotes renders the notes read view
+func (m Model) viewNotes() string {
+	var b strings.Builder
+	usedLines := 0
+
+	b.WriteString(ui.HeaderStyle.Render("Notes"))
+	b.WriteString("  ")
+	b.WriteString(ui.FilterStyle.Render(m.noteTarget))
+	b.WriteString("\n")
+	usedLines++
+
+	b.WriteString(ui.RenderBorder(m.borderWidth()))
+	b.WriteString("\n")
+	usedLines++
+
+	maxLines := m.notesMaxVisibleLines()
+	endIdx := m.notesScrollOffset + maxLines
+	if endIdx > len(m.notesLines) {
+		endIdx = len(m.notesLines)
+	}
+	visibleCount := endIdx - m.notesScrollOffset
+
+	scrollbar := ui.ScrollbarChars(len(m.notesLines), maxLines, m.notesScrollOffset, visibleCount)
+
+	for i := m.notesScrollOffset; i < endIdx; i++ {
+		lineIdx := i - m.notesScrollOffset
+		if lineIdx < len(scrollbar) {
+			b.WriteString(scrollbar[lineIdx])
+			b.WriteString(" ")
+		}
+
+		line := m.notesLines[i]
+		if strings.HasPrefix(line, "## ") {
+			b.WriteString(ui.NoteHeadingStyle.Render(strings.TrimPrefix(line, "## ")))
+		} else {
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+		usedLines++
+	}
+
+	// Footer: border (1) + statusline (1) + help (1)
+	footerLines := 3
+	if contentH := m.contentHeight(); contentH > 0 {
+		for i := 0; i < contentH-usedLines-footerLines; i++ {
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(ui.RenderBorder(m.borderWidth()))
+	b.WriteString("\n")
+	b.WriteString(ui.StatuslineStyle.Render(fmt.Sprintf("%d lines", len(m.notesLines))))
+	b.WriteString("\n")
+	b.WriteString(ui.FooterStyle.Render(ui.HelpNotes()))
+
+	return ui.AppStyle.Render(b.String())
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// toggleNotesPane switches the split view's window pane between the
+// highlighted session's windows and its rendered notes
+func (m *Model) toggleNotesPane() (tea.Model, tea.Cmd) {
+	if !m.config.SplitView {
+		m.setError("The notes pane is part of the split view (split_view = true)")
+		return m, nil
+	}
+
+	m.splitNotes = !m.splitNotes
+	m.splitPreview = false
+	m.splitFocus = false
+	if m.splitNotes && m.notes[m.splitSession] == "" && m.splitSession != "" {
+		m.setInfo("No notes for \"%s\". Press C-e to add one.", m.splitSession)
+		return m, nil
+	}
+	return m, nil
+}
+
+// showsNotesPane reports whether the window pane shows notes: the notes tab
+// is open and the highlighted session has some
+func (m Model) showsNotesPane() bool {
+	return m.config.SplitView && m.splitNotes && m.notes[m.splitSession] != ""
+}
+
+// notesPaneRows renders the highlighted session's notes as markdown, cut to
+// the pane's width and height
+func (m Model) notesPaneRows(width, maxLines int) []string {
+	rows := []string{ui.NoteHeadingStyle.Render("Notes") + " " + ui.TimeStyle.Render("M-v windows")}
+	if ui.Plain {
+		rows[0] = "Notes (M-v windows)"
+	}
+
+	for _, line := range m.renderNotes(m.notes[m.splitSession], width) {
+		if len(rows) == maxLines {
+			break
+		}
+		rows = append(rows, ui.TruncateMiddle(line, width))
+	}
+	return rows
+}
+
+// renderNotes renders notes with glamour, wrapped to width. Results are
+// cached per text and width since the view redraws on every tick.
+func (m Model) renderNotes(text string, width int) []string {
+	key := fmt.Sprintf("%d:%s", width, text)
+	if lines, ok := m.renderedNotes[key]; ok {
+		return lines
+	}
+
+	style := styles.DarkStyle
+	if ui.Plain {
+		style = styles.NoTTYStyle
+	}
+	var lines []string
+	renderer, err := glamour.NewTermRenderer(glamour.WithStandardStyle(style), glamour.WithWordWrap(width))
+	if err == nil {
+		var out string
+		if out, err = renderer.Render(text); err == nil {
+			lines = strings.Split(strings.Trim(out, "\n"), "\n")
+		}
+	}
+	// Fall back to the raw markdown
+	if err != nil {
+		lines = strings.Split(strings.TrimRight(text, "\n"), "\n")
+	}
+
+	// Drop renders for old texts and widths
+	if len(m.renderedNotes) >= 16 {
+		clear(m.renderedNotes)
+	}
+	m.renderedNotes[key] = lines
+	return lines
+}
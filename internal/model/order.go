@@ -0,0 +1,180 @@
+package model
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/profile"
+	"github.com/nikbrunner/tsm/internal/ranking"
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// sortCommandTimeout is how long sort_command may take before the sessions
+// keep their last order
+const sortCommandTimeout = 2 * time.Second
+
+// stabilizeOrder keeps sessions in the order of the first load for
+// sort_stability after the picker opens, so number labels don't shift while
+// background sessions produce output. Sessions missing from that first load
+// (e.g. just created) are listed last, in activity order.
+func (m *Model) stabilizeOrder(sessions []tmux.Session) {
+	if m.sessionOrder == nil {
+		m.sessionOrder = make(map[string]int, len(sessions))
+		for i, s := range sessions {
+			m.sessionOrder[s.Name] = i
+		}
+		return
+	}
+	if time.Since(m.openedAt) >= m.config.SortStability {
+		return
+	}
+
+	position := func(name string) int {
+		if i, ok := m.sessionOrder[name]; ok {
+			return i
+		}
+		return len(m.sessionOrder)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return position(sessions[i].Name) < position(sessions[j].Name)
+	})
+}
+
+// resetOrder drops the order snapshot, starting a new stability window
+func (m *Model) resetOrder() {
+	m.sessionOrder = nil
+	m.openedAt = time.Now()
+}
+
+// sortSessions orders the sessions as chosen with :sort or the sort option.
+// tmux lists them by activity, so only an explicit :sort activity resorts
+// them that way, e.g. after sort_stability held them in place.
+func (m *Model) sortSessions(sessions []tmux.Session) {
+	switch m.sortBy {
+	case config.SortName:
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return sessions[i].Name < sessions[j].Name
+		})
+	case config.SortActivity:
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return sessions[i].LastActivity.After(sessions[j].LastActivity)
+		})
+	case config.SortManual:
+		// Sessions not arranged yet go last, in activity order
+		sortByNames(sessions, m.manualOrder)
+	case config.SortExternal:
+		sortByNames(sessions, m.commandOrder)
+	}
+}
+
+// sortByNames orders the sessions as the names list them. Sessions not
+// listed go last, in the order they came in.
+func sortByNames(sessions []tmux.Session, names []string) {
+	position := func(name string) int {
+		if i := slices.Index(names, name); i >= 0 {
+			return i
+		}
+		return len(names)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return position(sessions[i].Name) < position(sessions[j].Name)
+	})
+}
+
+// rankSessions runs sort_command on the sessions as tmux lists them, by
+// activity, with their tags. It runs with each session load, so edits to whatever it ranks by
+// show up on the next refresh.
+func (m Model) rankSessions(sessions []tmux.Session, tags state.Tags) ([]string, error) {
+	defer profile.Start("sort command")()
+
+	input := make([]ranking.Session, len(sessions))
+	for i, s := range sessions {
+		input[i] = ranking.Session{
+			Name:         s.Name,
+			Path:         s.Path,
+			Group:        s.Group,
+			LastActivity: s.LastActivity.Unix(),
+			Windows:      len(s.Windows),
+			Tags:         append([]string{}, tags[s.Name]...),
+		}
+	}
+	return ranking.Rank(m.config.SortCommand, input, sortCommandTimeout)
+}
+
+// isLastUsed reports whether the session labelled num is the one used last,
+// the first in activity order
+func (m *Model) isLastUsed(num int) bool {
+	return num == 1 && (m.sortBy == "" || m.sortBy == config.SortActivity)
+}
+
+// moveSession moves the session under the cursor past its neighbour (M-k up,
+// M-j down), switching to the manual order. The arrangement is remembered
+// for the next time the picker opens.
+func (m *Model) moveSession(delta int) (tea.Model, tea.Cmd) {
+	if m.filter != "" {
+		m.setError("Clear the filter to move sessions")
+		return m, nil
+	}
+	item, ok := m.selectedItem()
+	if !ok || !item.IsSession {
+		m.setError("Select a session to move")
+		return m, nil
+	}
+	next, ok := m.neighbourSession(delta)
+	if !ok {
+		return m, nil
+	}
+	from, to := item.SessionIndex, next.SessionIndex
+
+	name := m.sessions[from].Name
+	m.sessions[from], m.sessions[to] = m.sessions[to], m.sessions[from]
+	m.sortBy = config.SortManual
+
+	m.manualOrder = mergeOrder(m.manualOrder, m.sessionNames())
+	_ = state.UpdatePrefs(m.config.StateDir, func(prefs *state.Prefs) error {
+		prefs.Order = m.manualOrder
+		return nil
+	})
+
+	m.rebuildItems()
+	for i, it := range m.items {
+		if it.IsSession && m.sessions[it.SessionIndex].Name == name {
+			m.cursor = i
+		}
+	}
+	m.updateScrollOffset()
+	return m, nil
+}
+
+// neighbourSession returns the listed session item before (delta < 0) or
+// after the cursor, skipping window, pane and recent rows. Sessions the
+// list hides, like those outside a focused group, are never neighbours.
+func (m *Model) neighbourSession(delta int) (Item, bool) {
+	for i := m.cursor + delta; i >= 0 && i < len(m.items); i += delta {
+		if m.items[i].IsSession {
+			return m.items[i], true
+		}
+	}
+	return Item{}, false
+}
+
+// mergeOrder returns the saved order with the listed sessions rearranged as
+// listed. Sessions that aren't listed, like the current one, keep their
+// place; listed sessions new to the order go last.
+func mergeOrder(saved, listed []string) []string {
+	order := make([]string, 0, len(saved)+len(listed))
+	next := 0
+	for _, name := range saved {
+		if slices.Contains(listed, name) {
+			name = listed[next]
+			next++
+		}
+		order = append(order, name)
+	}
+	return append(order, listed[next:]...)
+}
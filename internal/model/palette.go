@@ -0,0 +1,396 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/tmux"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// paletteCommand is a command typed after ":" in the command line
+type paletteCommand struct {
+	name        string
+	args        string // Argument synopsis shown while typing
+	description string
+	mutates     bool // Refused by --read-only
+	raw         bool // Gets the text after the name as a single argument, spacing kept
+
+	// complete returns the candidates for the command's arguments
+	complete func(m *Model) []string
+	run      func(m *Model, args []string) (tea.Model, tea.Cmd)
+}
+
+// paletteCommands lists the command line's commands in the order they are
+// offered. New actions that don't deserve a key go here.
+var paletteCommands = []paletteCommand{
+	{name: "rename", args: "<name>", description: "Rename the selected session", mutates: true, run: (*Model).paletteRename},
+	{name: "kill", args: "[session]", description: "Kill a session (the selected item by default)", mutates: true, complete: (*Model).sessionNames, run: (*Model).paletteKill},
+	{name: "sort", args: "activity|name|manual|command", description: "Order the sessions", complete: func(*Model) []string { return config.Sorts }, run: (*Model).paletteSort},
+	{name: "group", args: "<name>", description: "Create a session grouped with the selected one", mutates: true, run: (*Model).paletteGroup},
+	{name: "send", args: "<command>", description: "Run a command in the active pane of every marked session", mutates: true, raw: true, run: (*Model).confirmBroadcast},
+	{name: "respawn", args: "[all]", description: "Restart the dead panes of the selected item", mutates: true, complete: func(*Model) []string { return []string{"all"} }, run: (*Model).paletteRespawn},
+	{name: "reap", args: "[all]", description: "Close the dead panes of the selected item", mutates: true, complete: func(*Model) []string { return []string{"all"} }, run: (*Model).paletteReap},
+	{name: "duplicate", args: "[cmd]", description: "Open a window next to the selected one in its directory, cmd runs its command too", mutates: true, complete: func(*Model) []string { return []string{"cmd"} }, run: (*Model).paletteDuplicate},
+	{name: "worktree", description: "Kill the selected session, then remove its git worktree", mutates: true, run: (*Model).paletteWorktree},
+	{name: "focus", args: "[group]", description: "List only a session group, numbered within it (again to leave)", complete: (*Model).groupNames, run: (*Model).paletteFocus},
+	{name: "tag", args: "[tag...]", description: "Set the tags of the selected session (none clears)", mutates: true, complete: (*Model).tagNames, run: (*Model).paletteTag},
+	{name: "quit", description: "Close the picker", run: func(m *Model, _ []string) (tea.Model, tea.Cmd) { return m, tea.Quit }},
+}
+
+// findPaletteCommand looks up a command by name
+func findPaletteCommand(name string) (paletteCommand, bool) {
+	for _, c := range paletteCommands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return paletteCommand{}, false
+}
+
+// openPalette starts the command line (":") with the text typed after the
+// colon, which arrives along with it when pasted
+func (m *Model) openPalette(text string) (tea.Model, tea.Cmd) {
+	m.mode = ModeCommand
+	m.input.Reset()
+	m.input.SetValue(text)
+	m.input.CursorEnd()
+	m.input.Focus()
+	return m, textinput.Blink
+}
+
+// closePalette leaves the command line
+func (m *Model) closePalette() {
+	m.mode = ModeNormal
+	m.input.Blur()
+}
+
+func (m *Model) handleCommandMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+
+	switch {
+	case key.Matches(msg, keys.Cancel):
+		m.closePalette()
+		return m, nil
+
+	case msg.Type == tea.KeyBackspace && m.input.Value() == "":
+		// Like vim, backspace on an empty command line leaves it
+		m.closePalette()
+		return m, nil
+
+	case msg.Type == tea.KeyTab:
+		m.completePalette()
+		return m, nil
+
+	case msg.Type == tea.KeyEnter:
+		return m.runPalette()
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// paletteWord splits the command line into the finished words and the word
+// being typed
+func paletteWord(line string) (done []string, word string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.HasSuffix(line, " ") {
+		return fields, ""
+	}
+	return fields[:len(fields)-1], fields[len(fields)-1]
+}
+
+// paletteCandidates returns what the word being typed can complete to: a
+// command name, or an argument of the command typed before it
+func (m *Model) paletteCandidates() []string {
+	done, word := paletteWord(m.input.Value())
+
+	var all []string
+	if len(done) == 0 {
+		for _, c := range paletteCommands {
+			all = append(all, c.name)
+		}
+	} else if c, ok := findPaletteCommand(done[0]); ok && c.complete != nil {
+		all = c.complete(m)
+	}
+
+	var candidates []string
+	for _, s := range all {
+		if strings.HasPrefix(s, word) && !slices.Contains(done[min(len(done), 1):], s) {
+			candidates = append(candidates, s)
+		}
+	}
+	return candidates
+}
+
+// completePalette completes the word being typed (tab): fully when one
+// candidate is left, otherwise as far as the candidates agree
+func (m *Model) completePalette() {
+	candidates := m.paletteCandidates()
+	if len(candidates) == 0 {
+		return
+	}
+	done, word := paletteWord(m.input.Value())
+
+	completed := candidates[0]
+	for _, c := range candidates[1:] {
+		completed = commonPrefix(completed, c)
+	}
+	if len(candidates) == 1 {
+		completed += " "
+	} else if completed == word {
+		return
+	}
+
+	m.input.SetValue(strings.Join(append(done, completed), " "))
+	m.input.CursorEnd()
+}
+
+// commonPrefix returns the longest prefix shared by a and b
+func commonPrefix(a, b string) string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return a[:n]
+}
+
+// palettePreview lists the candidates for the word being typed, with the
+// synopsis of each command while the command is typed
+func (m *Model) palettePreview() []string {
+	done, _ := paletteWord(m.input.Value())
+	candidates := m.paletteCandidates()
+
+	if len(done) > 0 {
+		c, ok := findPaletteCommand(done[0])
+		if !ok {
+			return []string{fmt.Sprintf("✗ unknown command \"%s\"", done[0])}
+		}
+		return append([]string{fmt.Sprintf("%s %s  %s", c.name, c.args, c.description)}, candidates...)
+	}
+
+	lines := make([]string, 0, len(candidates))
+	for _, name := range candidates {
+		c, _ := findPaletteCommand(name)
+		lines = append(lines, fmt.Sprintf("%-8s %-16s %s", c.name, c.args, c.description))
+	}
+	return lines
+}
+
+// runPalette runs the typed command
+func (m *Model) runPalette() (tea.Model, tea.Cmd) {
+	name, rest, _ := strings.Cut(strings.TrimSpace(m.input.Value()), " ")
+	m.closePalette()
+	if name == "" {
+		return m, nil
+	}
+
+	c, ok := findPaletteCommand(name)
+	if !ok {
+		m.setError("Unknown command \"%s\"", name)
+		return m, nil
+	}
+	if c.mutates && m.config.ReadOnly {
+		return m.refuseReadOnly()
+	}
+	args := strings.Fields(rest)
+	if rest = strings.TrimSpace(rest); c.raw && rest != "" {
+		args = []string{rest}
+	}
+	return c.run(m, args)
+}
+
+// sessionNames returns the listed sessions' names, completing :kill
+func (m *Model) sessionNames() []string {
+	names := make([]string, len(m.sessions))
+	for i, s := range m.sessions {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// tagNames returns every tag in use, completing :tag
+func (m *Model) tagNames() []string {
+	var tags []string
+	for _, t := range m.tags {
+		for _, tag := range t {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	slices.Sort(tags)
+	return tags
+}
+
+// paletteRename renames the selected session (:rename <name>), moving its
+// tags and notes along
+func (m *Model) paletteRename(args []string) (tea.Model, tea.Cmd) {
+	from := m.cursorSessionName()
+	if from == "" {
+		m.setError("Select a session to rename")
+		return m, nil
+	}
+	if len(args) != 1 {
+		m.setError("Usage: :rename <name>")
+		return m, nil
+	}
+
+	to := sanitizeSessionName(args[0])
+	if to == from {
+		return m, nil
+	}
+	if to == m.currentSession || slices.Contains(m.sessionNames(), to) {
+		m.setError("\"%s\" already exists", to)
+		return m, nil
+	}
+	if !m.renameSession(from, to) {
+		return m, m.loadSessions
+	}
+	m.setInfo("Renamed \"%s\" to \"%s\"", from, to)
+	return m, m.loadSessions
+}
+
+// paletteKill asks to kill a session by name (:kill api), moving the cursor
+// onto it so the list shows what goes, or the selected item like C-x
+func (m *Model) paletteKill(args []string) (tea.Model, tea.Cmd) {
+	if len(args) == 0 {
+		return m.confirmKill()
+	}
+	if len(m.marked) > 0 {
+		m.setError("Clear the marks (esc) to kill by name")
+		return m, nil
+	}
+	if !slices.ContainsFunc(m.sessions, func(s tmux.Session) bool { return s.Name == args[0] }) {
+		m.setError("No session \"%s\"", args[0])
+		return m, nil
+	}
+
+	// A session the agents view or a group focus hides is listed again, so
+	// the list shows what goes
+	for range 2 {
+		for i, item := range m.items {
+			if item.IsSession && m.sessions[item.SessionIndex].Name == args[0] {
+				m.cursor = i
+				m.splitFocus = false
+				m.updateScrollOffset()
+				return m.confirmKill()
+			}
+		}
+		m.agentsView = false
+		m.focusGroup = ""
+		m.rebuildItems()
+	}
+	return m, nil
+}
+
+// paletteSort orders the sessions (:sort name) until the picker closes
+func (m *Model) paletteSort(args []string) (tea.Model, tea.Cmd) {
+	if len(args) != 1 || !slices.Contains(config.Sorts, args[0]) {
+		m.setError("Usage: :sort %s", strings.Join(config.Sorts, "|"))
+		return m, nil
+	}
+	if args[0] == config.SortExternal {
+		if m.config.SortCommand == "" {
+			m.setError("Set sort_command in the config to sort by command")
+			return m, nil
+		}
+		// The ranking comes with the reloaded sessions
+		m.sortBy = args[0]
+		m.setInfo("Sorted by %s", m.sortBy)
+		return m, m.loadSessions
+	}
+	m.sortBy = args[0]
+	m.sortSessions(m.sessions)
+	m.rebuildItems()
+	m.setInfo("Sorted by %s", m.sortBy)
+	return m, nil
+}
+
+// paletteGroup creates a session grouped with the selected one (:group
+// name), like M-g without the name prompt
+func (m *Model) paletteGroup(args []string) (tea.Model, tea.Cmd) {
+	if len(args) != 1 {
+		m.setError("Usage: :group <name>")
+		return m, nil
+	}
+	item, ok := m.selectedItem()
+	if !ok || item.IsRecent {
+		m.setError("Select a session to group with")
+		return m, nil
+	}
+	m.createDir = ""
+	m.breakOutWindow = ""
+	m.createGroup = m.sessions[item.SessionIndex].Name
+	return m.createSession(args[0], m.config.CreateInBackground)
+}
+
+// paletteDuplicate opens another window in the directory of the selected
+// window's active pane (or of the selected pane in the tree), right after it
+// (:duplicate), and switches to it unless sessions are created in the
+// background. ":duplicate cmd" starts the command the pane runs too, with its
+// arguments.
+func (m *Model) paletteDuplicate(args []string) (tea.Model, tea.Cmd) {
+	if len(args) > 1 || len(args) == 1 && args[0] != "cmd" {
+		m.setError("Usage: :duplicate [cmd]")
+		return m, nil
+	}
+	item, ok := m.selectedItem()
+	if !ok || item.IsRecent || item.IsSession {
+		m.setError("Select a window to duplicate (expand a session with C-l)")
+		return m, nil
+	}
+	session := m.sessions[item.SessionIndex]
+	window := session.Windows[item.WindowIndex]
+	target := window.Target(session.Name)
+	if item.IsPane {
+		target = m.itemPane(item).ID
+	}
+
+	dir, err := tmux.PanePath(target)
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, m.loadSessions
+	}
+	command := ""
+	if len(args) == 1 {
+		if command, err = tmux.PaneCommandLine(target); err != nil {
+			m.setError("Error: %v", err)
+			return m, m.loadSessions
+		}
+	}
+	id, err := tmux.DuplicateWindow(target, dir, command)
+	if err != nil {
+		m.setError("Error duplicating %s: %v", m.displayName(item), err)
+		return m, m.loadSessions
+	}
+
+	if m.config.CreateInBackground {
+		m.setInfo("Opened a window next to %s in %s", m.displayName(item), tildePath(dir))
+		return m, m.loadSessions
+	}
+	if err := switchClient(id); err != nil {
+		m.setError("Opened but failed to switch: %v", err)
+		return m, m.loadSessions
+	}
+	m.recordSwitch(session.Name)
+	return m, tea.Quit
+}
+
+// paletteTag replaces the tags of the selected session (:tag work client-x)
+func (m *Model) paletteTag(args []string) (tea.Model, tea.Cmd) {
+	name := m.cursorSessionName()
+	if name == "" {
+		m.setError("Select a session to tag")
+		return m, nil
+	}
+	return m.setTags(name, state.ParseTags(strings.Join(args, " ")))
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// plainCursor returns the textual cursor prefix used in plain mode
+func plainCursor(selected bool) string {
+	if selected {
+		return "> "
+	}
+	return "  "
+}
+
+// renderSessionPlain renders a session row as plain text with textual state,
+// for screen readers and NO_COLOR terminals
+func (m Model) renderSessionPlain(session tmux.Session, num int, isFirst, marked, selected bool) string {
+	parts := []string{fmt.Sprintf("%s%d. %s", plainCursor(selected), num, session.Name)}
+	if m.showsColumn(config.ColumnTime) {
+		parts = append(parts, formatTimeAgo(session.LastActivity))
+	}
+	if m.showsColumn(config.ColumnWindows) && len(session.Windows) > 0 {
+		parts = append(parts, fmt.Sprintf("%d windows", len(session.Windows)))
+	}
+
+	if isFirst {
+		parts = append(parts, "[last]")
+	}
+	if session.Expanded {
+		parts = append(parts, "[expanded]")
+	}
+	if status, ok := m.claudeStatuses[session.Name]; ok && m.showsColumn(config.ColumnClaude) && (status.State == "working" || status.State == "waiting") {
+		parts = append(parts, "["+status.State+"]")
+	}
+	if d := m.agentDuration(session.Name); m.agentsView && d != "" {
+		parts = append(parts, "["+d+"]")
+	}
+	if m.showsColumn(config.ColumnIndicators) {
+		if healthy, ok := m.health[session.Name]; ok {
+			if healthy {
+				parts = append(parts, "[healthy]")
+			} else {
+				parts = append(parts, "[unhealthy]")
+			}
+		}
+		if m.suspended[session.Name] {
+			parts = append(parts, "[suspended]")
+		}
+		if m.notedSessions[session.Name] {
+			parts = append(parts, "[notes]")
+		}
+		if session.Group != "" {
+			parts = append(parts, "[group: "+session.Group+"]")
+		}
+		if clients := m.smallClients[session.Name]; len(clients) > 0 {
+			parts = append(parts, "[small client: "+clients[0].Size+"]")
+		}
+		if n := m.sessionDeadPanes(session); n > 0 {
+			parts = append(parts, "["+pluralize(n, "dead pane")+"]")
+		}
+	}
+	if tags := m.tags[session.Name]; len(tags) > 0 && m.showsColumn(config.ColumnTags) {
+		parts = append(parts, "[tags: "+strings.Join(tags, " ")+"]")
+	}
+	if branch := m.branches[session.Name]; branch != "" && m.showsColumn(config.ColumnGit) {
+		parts = append(parts, "[branch: "+branch+"]")
+	}
+	if m.showsColumn(config.ColumnPath) && session.Path != "" {
+		parts = append(parts, "[path: "+tildePath(session.Path)+"]")
+	}
+	if marked {
+		parts = append(parts, "[marked]")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// renderWindowPlain renders a window row as plain text
+func (m Model) renderWindowPlain(session string, window tmux.Window, pos int, prefix string, marked, selected bool) string {
+	label := "  "
+	if pos < 9 {
+		label = fmt.Sprintf("%d.", pos+1)
+	}
+	row := fmt.Sprintf("%s    %s %swindow %d: %s", plainCursor(selected), label, prefix, window.Index, window.Name)
+	if m.showsColumn(config.ColumnTime) && !window.LastActivity.IsZero() {
+		row += ", " + formatTimeAgo(window.LastActivity)
+	}
+	if m.filterMatcher != nil && matches(m.filterMatcher, window.Name) {
+		row += " [match]"
+	}
+	if len(m.filterCommands) > 0 && m.windowRunsCommands(window, m.filterCommands) {
+		row += " [runs " + strings.Join(m.filterCommands, ", ") + "]"
+	}
+	if m.holdsMarkedPane(window) {
+		row += " [marked pane]"
+	}
+	if n := m.deadPaneCount(window); n > 0 {
+		row += " [" + pluralize(n, "dead pane") + "]"
+	}
+	if marked {
+		row += " [marked]"
+	}
+	if summary := m.windowSummary(session, window, len(row)+14); summary != "" {
+		row += " [last line: " + summary + "]"
+	}
+	return row
+}
+
+// renderRecentPlain renders a recent (dead) session row as plain text
+func (m Model) renderRecentPlain(entry state.HistoryEntry, selected bool) string {
+	return fmt.Sprintf("%srecent: %s, seen %s", plainCursor(selected), entry.Name, formatTimeAgo(entry.LastSeen))
+}
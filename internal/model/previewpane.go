@@ -0,0 +1,140 @@
+package model
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// previewIdleMsg loads the preview once the cursor rested on a row for
+// preview_delay
+type previewIdleMsg struct {
+	seq int
+}
+
+// previewMsg carries the captured content of a previewed target
+type previewMsg struct {
+	target string
+	lines  []string
+	err    error
+}
+
+// togglePreviewPane shows a preview of the highlighted row below the split
+// view's window pane: the window focused in the pane (C-l), or the session's
+// active window
+func (m *Model) togglePreviewPane() (tea.Model, tea.Cmd) {
+	if !m.config.SplitView {
+		m.setError("The preview pane is part of the split view (split_view = true)")
+		return m, nil
+	}
+
+	m.splitPreview = !m.splitPreview
+	m.splitNotes = false
+	m.splitFocus = false
+	return m, m.schedulePreview(true)
+}
+
+// showsPreviewPane reports whether the window pane shows the preview
+func (m Model) showsPreviewPane() bool {
+	return m.config.SplitView && m.splitPreview
+}
+
+// hoveredTarget returns the tmux target the preview shows for the row under
+// the cursor ("" for closed sessions)
+func (m *Model) hoveredTarget() string {
+	item, ok := m.selectedItem()
+	if !ok || item.IsRecent {
+		return ""
+	}
+	return m.getTargetName(item)
+}
+
+// schedulePreview starts the preview_delay wait for the row under the cursor.
+// Moving on before it passes bumps previewSeq, which cancels the wait, so only
+// the row the cursor rests on is captured. reload captures the same row again.
+func (m *Model) schedulePreview(reload bool) tea.Cmd {
+	if !m.showsPreviewPane() {
+		return nil
+	}
+	target := m.hoveredTarget()
+	if target == m.previewTarget && !reload {
+		return nil
+	}
+	if target != m.previewTarget {
+		m.previewLines = nil
+	}
+	m.previewTarget = target
+	m.previewSeq++
+	if target == "" {
+		return nil
+	}
+
+	seq := m.previewSeq
+	if m.config.PreviewDelay <= 0 {
+		return func() tea.Msg { return previewIdleMsg{seq: seq} }
+	}
+	return tea.Tick(m.config.PreviewDelay, func(time.Time) tea.Msg {
+		return previewIdleMsg{seq: seq}
+	})
+}
+
+// handlePreviewIdle captures the row the cursor still rests on
+func (m *Model) handlePreviewIdle(msg previewIdleMsg) tea.Cmd {
+	if msg.seq != m.previewSeq || !m.showsPreviewPane() {
+		return nil
+	}
+	target := m.previewTarget
+	return func() tea.Msg {
+		lines, err := tmux.CapturePane(target)
+		return previewMsg{target: target, lines: lines, err: err}
+	}
+}
+
+// handlePreview stores a capture unless the cursor moved on meanwhile
+func (m *Model) handlePreview(msg previewMsg) {
+	if msg.target != m.previewTarget {
+		return
+	}
+	if msg.err != nil {
+		m.previewLines = []string{"✗ " + msg.err.Error()}
+		return
+	}
+	m.previewLines = msg.lines
+	if len(msg.lines) == 0 {
+		m.previewLines = []string{"(empty)"}
+	}
+}
+
+// previewPaneRows renders the bottom of the previewed pane's screen, where
+// the latest output is, cut to the pane's width and height
+func (m Model) previewPaneRows(width, maxLines int) []string {
+	if maxLines <= 0 {
+		return nil
+	}
+	name := ""
+	if item, ok := m.selectedItem(); ok && m.previewTarget != "" {
+		name = m.displayName(item)
+	}
+	heading := ui.NoteHeadingStyle.Render("Preview") + " " + ui.TimeStyle.Render(truncate(name, width-lipgloss.Width("Preview  · C-v close"))+" · C-v close")
+	if ui.Plain {
+		heading = "Preview " + name + " (C-v close)"
+	}
+	rows := []string{heading}
+
+	switch {
+	case m.previewTarget == "":
+		rows = append(rows, ui.TimeStyle.Render("nothing to preview"))
+	case m.previewLines == nil:
+		rows = append(rows, ui.TimeStyle.Render("loading…"))
+	default:
+		lines := m.previewLines[max(len(m.previewLines)-(maxLines-1), 0):]
+		for _, line := range lines {
+			rows = append(rows, truncate(line, width))
+		}
+	}
+	return rows
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/projects"
+	"github.com/nikbrunner/tsm/internal/state"
+)
+
+// projectsScannedMsg carries a fresh scan of the project directories
+type projectsScannedMsg struct {
+	dirs []string
+}
+
+// loadProjectDirs fills the directory picker from the last scan, cached in
+// the state directory, and returns a command rescanning in the background,
+// so large repo trees aren't walked before the picker shows. Without a scan
+// of the configured project_dirs and project_depth, they are scanned right
+// away.
+func (m *Model) loadProjectDirs() tea.Cmd {
+	cache, _ := state.LoadProjectCache(m.config.StateDir)
+	if cache.Matches(m.config.ProjectDirs, m.config.ProjectDepth) {
+		m.projectDirs = cache.Dirs
+		m.projectScanning = true
+		return m.scanProjects
+	}
+
+	m.projectDirs = scanProjects(m.config.StateDir, m.config.ProjectDirs, m.config.ProjectDepth)
+	return nil
+}
+
+// scanProjects rescans the project directories for the directory picker
+func (m Model) scanProjects() tea.Msg {
+	return projectsScannedMsg{dirs: scanProjects(m.config.StateDir, m.config.ProjectDirs, m.config.ProjectDepth)}
+}
+
+// scanProjects scans the project directories and caches the result
+func scanProjects(stateDir string, roots []string, depth int) []string {
+	dirs := projects.Scan(roots, depth)
+	_ = state.SaveProjectCache(stateDir, state.ProjectCache{Roots: roots, Depth: depth, Dirs: dirs, ScannedAt: time.Now()})
+	return dirs
+}
+
+// handleProjectsScanned replaces the cached directories with a fresh scan,
+// keeping the filter and the selected directory
+func (m *Model) handleProjectsScanned(msg projectsScannedMsg) {
+	m.projectScanning = false
+	var selected string
+	if m.projectCursor < len(m.projectFiltered) {
+		selected = m.projectFiltered[m.projectCursor]
+	}
+
+	m.projectDirs = msg.dirs
+	m.filterProjectDirs()
+	for i, dir := range m.projectFiltered {
+		if dir == selected {
+			m.projectCursor = i
+			m.updateProjectScrollOffset()
+			break
+		}
+	}
+}
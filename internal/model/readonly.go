@@ -0,0 +1,39 @@
+package model
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// readOnlyBlocked reports whether a normal-mode key would kill, move, create
+// or otherwise change sessions, which read-only mode refuses
+func (m *Model) readOnlyBlocked(msg tea.KeyMsg) bool {
+	if !m.config.ReadOnly {
+		return false
+	}
+	keys := ui.DefaultKeyMap
+
+	// While filtering, C-p/C-n cycle the filter history instead
+	if m.filter != "" && (key.Matches(msg, keys.HistoryPrev) || key.Matches(msg, keys.HistoryNext)) {
+		return false
+	}
+
+	for _, binding := range []key.Binding{
+		keys.Kill, keys.KillNumber, keys.Mark, keys.MarkAll, keys.Merge, keys.BreakOut, keys.Rename, keys.Suspend,
+		keys.Create, keys.CreateHere, keys.CreateGrouped, keys.PickDirectory, keys.Restore, keys.EditConfig,
+		keys.JoinMarked, keys.SwapMarked, keys.Archive, keys.DetachSmall,
+	} {
+		if key.Matches(msg, binding) {
+			return true
+		}
+	}
+	return false
+}
+
+// refuseReadOnly shows that an action is disabled in read-only mode
+func (m *Model) refuseReadOnly() (tea.Model, tea.Cmd) {
+	m.setError("Read-only mode")
+	return m, nil
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// resurrectSession recreates a recent session at its remembered path with its
+// remembered layout, then switches to it
+func (m *Model) resurrectSession(entry state.HistoryEntry) (tea.Model, tea.Cmd) {
+	dir := entry.Path
+	if dir == "" {
+		dir = m.config.DefaultSessionDir
+	} else if _, err := os.Stat(dir); err != nil {
+		// Remembered directory is gone; fall back rather than failing
+		dir = m.config.DefaultSessionDir
+	}
+
+	if m.sessionLimitReached() {
+		return m.confirmEvict(func(m *Model) (tea.Model, tea.Cmd) { return m.resurrectSession(entry) })
+	}
+
+	layout := entry.Layout
+	if layout == "" {
+		layout = m.config.Layout
+	}
+	if m.needsLayoutVars(layout) {
+		return m.promptLayoutVars(layout, func(m *Model) (tea.Model, tea.Cmd) { return m.resurrectSession(entry) })
+	}
+
+	if err := tmux.CreateSession(entry.Name, dir, m.config.SessionCommand(layout)); err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+
+	return m.openCreated(entry.Name, dir, layout, false)
+}
+
+// forgetRecent removes a recent session from the history
+func (m *Model) forgetRecent(name string) (tea.Model, tea.Cmd) {
+	err := state.UpdateHistory(m.config.StateDir, func(h *state.History) error {
+		h.Remove(name)
+		return nil
+	})
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+
+	m.setInfo("Forgot \"%s\"", name)
+	return m, m.loadSessions
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// sessionWindowsMsg carries the reloaded windows of a single session
+type sessionWindowsMsg struct {
+	server  int // serverIdx the reload started on
+	session string
+	windows []tmux.Window
+	ended   bool // tmux destroyed the session, e.g. with its last window
+	err     error
+}
+
+// refreshSession reloads only the windows of one session, for window-level
+// changes that leave the other sessions untouched
+func refreshSession(server int, name string) tea.Cmd {
+	return func() tea.Msg {
+		windows, err := tmux.ListWindows(name)
+		if err != nil && !tmux.SessionExists(name) {
+			return sessionWindowsMsg{server: server, session: name, ended: true}
+		}
+		return sessionWindowsMsg{server: server, session: name, windows: windows, err: err}
+	}
+}
+
+// refreshSessions reloads the windows of each named session once
+func refreshSessions(server int, names []string) tea.Cmd {
+	seen := make(map[string]bool, len(names))
+	var cmds []tea.Cmd
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			cmds = append(cmds, refreshSession(server, name))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleSessionWindows swaps in a session's reloaded windows, keeping the
+// list's expansion state and cursor. When the session is gone, e.g. its last
+// window was killed, it says so and falls back to reloading everything.
+func (m *Model) handleSessionWindows(msg sessionWindowsMsg) tea.Cmd {
+	if msg.server != m.serverIdx {
+		return nil
+	}
+	idx := -1
+	for i := range m.sessions {
+		if m.sessions[i].Name == msg.session {
+			idx = i
+			break
+		}
+	}
+	if msg.ended || len(msg.windows) == 0 && msg.err == nil {
+		if idx < 0 {
+			return m.loadSessions
+		}
+		// Drop it right away so nothing acts on the stale rows until the reload
+		m.sessions = slices.Delete(m.sessions, idx, idx+1)
+		m.rebuildItems()
+		m.setInfo("Session %s ended", msg.session)
+		return m.loadSessions
+	}
+	if msg.err != nil {
+		return m.loadSessions
+	}
+	// Not listed (anymore), nothing to update
+	if idx < 0 {
+		return nil
+	}
+
+	m.sessions[idx].Windows = msg.windows
+	if msg.session == m.splitSession && m.splitCursor >= len(msg.windows) {
+		m.splitCursor = len(msg.windows) - 1
+	}
+	m.calculateColumnWidths()
+	m.rebuildItems()
+	return nil
+}
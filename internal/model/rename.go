@@ -0,0 +1,233 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/tmux"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// renameStep renames one session
+type renameStep struct {
+	from, to string
+}
+
+// applyRenamePattern returns the new name of a session for a batch rename
+// pattern: "+pre" adds a prefix, "-pre" removes it, "old/new" replaces text.
+// The result is sanitized like any new session name.
+func applyRenamePattern(pattern, name string) (string, error) {
+	switch {
+	case pattern == "":
+		return name, nil
+	case strings.HasPrefix(pattern, "+"):
+		name = pattern[1:] + name
+	case strings.HasPrefix(pattern, "-"):
+		name = strings.TrimPrefix(name, pattern[1:])
+	case strings.Contains(pattern, "/"):
+		find, replace, _ := strings.Cut(pattern, "/")
+		if find == "" {
+			return "", errors.New("nothing to find before /")
+		}
+		name = strings.ReplaceAll(name, find, replace)
+	default:
+		return "", errors.New("use +prefix, -prefix or find/replace")
+	}
+	return sanitizeSessionName(name), nil
+}
+
+// openRename prompts for a rename pattern for the marked sessions, or the
+// session under the cursor when nothing is marked
+func (m *Model) openRename() (tea.Model, tea.Cmd) {
+	targets, _ := m.markedTargets()
+	if len(m.marked) == 0 {
+		if name := m.cursorSessionName(); name != "" {
+			targets = []string{name}
+		}
+	}
+	if len(targets) == 0 {
+		m.setError("No sessions marked to rename")
+		return m, nil
+	}
+
+	m.renameTargets = targets
+	m.mode = ModeRename
+	m.input.Reset()
+	m.input.Placeholder = "+prefix, -prefix or find/replace"
+	m.input.Focus()
+	return m, textinput.Blink
+}
+
+// renamePlan returns the renames for the current pattern, refusing names that
+// are empty, taken by another session or produced twice
+func (m *Model) renamePlan() ([]renameStep, error) {
+	taken := m.takenNames()
+	for _, name := range m.renameTargets {
+		delete(taken, name)
+	}
+
+	steps := make([]renameStep, 0, len(m.renameTargets))
+	for _, name := range m.renameTargets {
+		to, err := applyRenamePattern(m.input.Value(), name)
+		if err != nil {
+			return nil, err
+		}
+		if to == "" {
+			return nil, fmt.Errorf("\"%s\" would get an empty name", name)
+		}
+		if taken[to] {
+			return nil, fmt.Errorf("\"%s\" already exists", to)
+		}
+		taken[to] = true
+		steps = append(steps, renameStep{from: name, to: to})
+	}
+	return steps, nil
+}
+
+// orderRenames returns the steps in an order tmux can apply them: a session
+// is renamed to a name another one in the batch gives up only after that one
+// is renamed. Steps swapping names go through a temporary name.
+func orderRenames(steps []renameStep, taken map[string]bool) []renameStep {
+	pending := make([]renameStep, 0, len(steps))
+	for _, step := range steps {
+		if step.from != step.to {
+			pending = append(pending, step)
+		}
+	}
+
+	ordered := make([]renameStep, 0, len(pending))
+	for len(pending) > 0 {
+		// A step can run once no other pending session still has its name
+		next := slices.IndexFunc(pending, func(step renameStep) bool {
+			return !slices.ContainsFunc(pending, func(other renameStep) bool { return other.from == step.to })
+		})
+		if next < 0 {
+			// Only cycles are left: move one out of the way first
+			step := pending[0]
+			tmp := step.from + "~"
+			for taken[tmp] || slices.ContainsFunc(pending, func(other renameStep) bool { return other.from == tmp || other.to == tmp }) {
+				tmp += "~"
+			}
+			taken[tmp] = true
+			ordered = append(ordered, renameStep{from: step.from, to: tmp})
+			pending[0].from = tmp
+			continue
+		}
+		ordered = append(ordered, pending[next])
+		pending = slices.Delete(pending, next, next+1)
+	}
+	return ordered
+}
+
+// renameSession renames a session and moves what tsm keeps about it along:
+// tags and notes, its place in the manual order and the switch history.
+// Shows the error and returns false when it fails.
+func (m *Model) renameSession(from, to string) bool {
+	if err := tmux.RenameSession(from, to); err != nil {
+		m.setError("Error renaming \"%s\": %v", from, err)
+		return false
+	}
+	if err := m.store.RenameSession(from, to); err != nil {
+		m.setError("Renamed \"%s\" but lost its tags or notes: %v", from, err)
+		return false
+	}
+
+	if i := slices.Index(m.manualOrder, from); i >= 0 {
+		m.manualOrder[i] = to
+	}
+	_ = state.UpdatePrefs(m.config.StateDir, func(prefs *state.Prefs) error {
+		prefs.RenameSession(from, to)
+		return nil
+	})
+	// Switch history only tracks the default server
+	if m.serverIdx == 0 {
+		_ = state.UpdateSwitchHistory(m.config.StateDir, func(h *state.SwitchHistory) error {
+			h.RenameSession(from, to)
+			return nil
+		})
+	}
+	return true
+}
+
+// takenNames returns the names of the sessions, the current one included
+func (m *Model) takenNames() map[string]bool {
+	taken := make(map[string]bool, len(m.sessions)+1)
+	if m.currentSession != "" {
+		taken[m.currentSession] = true
+	}
+	for _, s := range m.sessions {
+		taken[s.Name] = true
+	}
+	return taken
+}
+
+// renamePreview describes the pending renames, or why they can't be applied
+func (m *Model) renamePreview() []string {
+	steps, err := m.renamePlan()
+	if err != nil {
+		return []string{"✗ " + err.Error()}
+	}
+	lines := make([]string, 0, len(steps))
+	for _, step := range steps {
+		if step.from == step.to {
+			lines = append(lines, step.from+" (unchanged)")
+		} else {
+			lines = append(lines, step.from+" → "+step.to)
+		}
+	}
+	return lines
+}
+
+func (m *Model) handleRenameMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+
+	switch {
+	case key.Matches(msg, keys.Cancel):
+		m.resetRename()
+		return m, nil
+
+	case msg.Type == tea.KeyEnter:
+		return m.applyRename()
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// applyRename renames the sessions one after another, moving their tags and
+// notes along
+func (m *Model) applyRename() (tea.Model, tea.Cmd) {
+	steps, err := m.renamePlan()
+	if err != nil {
+		m.setError("%v", err)
+		return m, nil
+	}
+	m.resetRename()
+
+	for _, step := range orderRenames(steps, m.takenNames()) {
+		if !m.renameSession(step.from, step.to) {
+			return m, m.loadSessions
+		}
+	}
+	renamed := len(slices.DeleteFunc(steps, func(step renameStep) bool { return step.from == step.to }))
+
+	m.clearMarks()
+	m.setInfo("Renamed %s", pluralize(renamed, "session"))
+	return m, m.loadSessions
+}
+
+// resetRename leaves the rename prompt
+func (m *Model) resetRename() {
+	m.mode = ModeNormal
+	m.renameTargets = nil
+	m.input.Placeholder = ""
+	m.input.Blur()
+}
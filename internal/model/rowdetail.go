@@ -0,0 +1,68 @@
+package model
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/gitinfo"
+	"github.com/nikbrunner/tsm/internal/state"
+)
+
+type prefsMsg struct {
+	prefs state.Prefs
+}
+
+type branchesMsg struct {
+	branches map[string]string
+}
+
+// loadPrefs reads the preferences changed from within the picker
+func (m Model) loadPrefs() tea.Msg {
+	prefs, err := state.LoadPrefs(m.config.StateDir)
+	if err != nil {
+		return nil
+	}
+	return prefsMsg{prefs: prefs}
+}
+
+// toggleRowDetail switches between compact rows (number and name) and
+// detailed rows (every column), overriding the size profile's columns. The
+// choice is remembered for the next time the picker opens.
+func (m *Model) toggleRowDetail() (tea.Model, tea.Cmd) {
+	if m.rowDetail == state.RowDetailed {
+		m.rowDetail = state.RowCompact
+	} else {
+		m.rowDetail = state.RowDetailed
+	}
+
+	_ = state.UpdatePrefs(m.config.StateDir, func(prefs *state.Prefs) error {
+		prefs.RowDetail = m.rowDetail
+		return nil
+	})
+
+	m.setInfo("Rows: %s", m.rowDetail)
+	return m, m.loadBranches()
+}
+
+// loadBranches returns a command reading the branch checked out in each
+// session directory, when the git column is shown
+func (m *Model) loadBranches() tea.Cmd {
+	if !m.showsColumn(config.ColumnGit) {
+		return nil
+	}
+	paths := make(map[string]string, len(m.sessions))
+	for _, s := range m.sessions {
+		if s.Path != "" {
+			paths[s.Name] = s.Path
+		}
+	}
+	return func() tea.Msg {
+		branches := make(map[string]string, len(paths))
+		for name, path := range paths {
+			if branch := gitinfo.Branch(path); branch != "" {
+				branches[name] = branch
+			}
+		}
+		return branchesMsg{branches: branches}
+	}
+}
@@ -0,0 +1,126 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// keySequence is a vim-style two-key sequence (see config.KeySequences)
+type keySequence struct {
+	keys string // The two keys, e.g. "g g"
+	run  func(m *Model) (tea.Model, tea.Cmd)
+}
+
+// keySequences are the sequences typed with an empty filter
+var keySequences = []keySequence{
+	{keys: "g g", run: (*Model).cursorToTop},
+	{keys: "d d", run: (*Model).killSequence},
+	{keys: "z a", run: (*Model).toggleFold},
+}
+
+// sequenceTimeoutMsg gives up on the key held since at
+type sequenceTimeoutMsg struct {
+	at time.Time
+}
+
+// startsSequence reports whether a key is the first of a sequence
+func startsSequence(k string) bool {
+	for _, s := range keySequences {
+		if strings.HasPrefix(s.keys, k+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// handleSequence holds the first key of a sequence typed with an empty filter
+// and runs the sequence when the second one follows within sequence_timeout.
+// Keys that turn out not to be a sequence go to the filter as usual; ok is
+// false when the key still has to be handled.
+func (m *Model) handleSequence(msg tea.KeyMsg) (model tea.Model, cmd tea.Cmd, ok bool) {
+	if !m.config.KeySequences {
+		return m, nil, false
+	}
+	k := msg.String()
+
+	if m.lastKey != "" {
+		held := m.lastKey
+		m.lastKey = ""
+		if key.Matches(msg, ui.DefaultKeyMap.Cancel) {
+			return m, nil, true
+		}
+		if !m.sequenceExpired(time.Now()) {
+			for _, s := range keySequences {
+				if s.keys == held+" "+k {
+					model, cmd := s.run(m)
+					return model, cmd, true
+				}
+			}
+		}
+		// Not a sequence after all: the held key was typed into the filter
+		m.typeFilter(held)
+		return m, nil, false
+	}
+
+	if m.filter != "" || msg.Type != tea.KeyRunes || !startsSequence(k) {
+		return m, nil, false
+	}
+	m.lastKey = k
+	m.lastKeyTime = time.Now()
+	if m.config.SequenceTimeout <= 0 {
+		return m, nil, true
+	}
+	at := m.lastKeyTime
+	return m, tea.Tick(m.config.SequenceTimeout, func(time.Time) tea.Msg { return sequenceTimeoutMsg{at} }), true
+}
+
+// sequenceExpired reports whether the held key waited longer than
+// sequence_timeout (0 waits forever)
+func (m *Model) sequenceExpired(now time.Time) bool {
+	return m.config.SequenceTimeout > 0 && now.Sub(m.lastKeyTime) > m.config.SequenceTimeout
+}
+
+// handleSequenceTimeout types the held key into the filter once no second key
+// followed it in time
+func (m *Model) handleSequenceTimeout(msg sequenceTimeoutMsg) {
+	if m.lastKey == "" || !m.lastKeyTime.Equal(msg.at) {
+		return
+	}
+	m.typeFilter(m.lastKey)
+	m.lastKey = ""
+}
+
+// cursorToTop moves the cursor to the first item (g g)
+func (m *Model) cursorToTop() (tea.Model, tea.Cmd) {
+	m.cursor = 0
+	m.splitFocus = false
+	m.updateScrollOffset()
+	return m, nil
+}
+
+// killSequence asks to kill the item under the cursor like C-x (d d)
+func (m *Model) killSequence() (tea.Model, tea.Cmd) {
+	if m.config.ReadOnly {
+		return m.refuseReadOnly()
+	}
+	return m.confirmKill()
+}
+
+// toggleFold expands the session under the cursor, or collapses it when it
+// (or the window under the cursor) is expanded (z a)
+func (m *Model) toggleFold() (tea.Model, tea.Cmd) {
+	item, ok := m.selectedItem()
+	if !ok || item.IsRecent {
+		return m, nil
+	}
+	if !item.IsSession || m.sessions[item.SessionIndex].Expanded {
+		m.collapseCurrent()
+		return m, nil
+	}
+	return m, m.expandCurrent()
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/state"
+)
+
+// sizeProfile returns the active size profile
+func (m *Model) sizeProfile() config.SizeProfile {
+	return m.config.Profile(m.profile)
+}
+
+// showsColumn reports whether a session column is shown: by the row detail
+// toggled with M-d, otherwise by the active size profile
+func (m *Model) showsColumn(column string) bool {
+	switch m.rowDetail {
+	case state.RowCompact:
+		return false
+	case state.RowDetailed:
+		return true
+	}
+	return m.sizeProfile().ShowsColumn(column)
+}
+
+// helpLines returns the number of lines the help line takes (0 when hidden)
+func (m *Model) helpLines() int {
+	if m.sizeProfile().Help {
+		return 1
+	}
+	return 0
+}
+
+// cycleSizeProfile switches to the next size profile
+func (m *Model) cycleSizeProfile() (tea.Model, tea.Cmd) {
+	names := m.config.ProfileNames()
+	current := m.profile
+	if current == "" {
+		current = config.ProfileDefault
+	}
+	m.profile = names[(slices.Index(names, current)+1)%len(names)]
+
+	m.updateScrollOffset()
+	m.setInfo("Size: %s", m.profile)
+	return m, m.loadBranches()
+}
+
+// tildePath shortens a path below $HOME to start with ~
+func tildePath(path string) string {
+	home := os.Getenv("HOME")
+	if home != "" && (path == home || strings.HasPrefix(path, home+"/")) {
+		return "~" + strings.TrimPrefix(path, home)
+	}
+	return path
+}
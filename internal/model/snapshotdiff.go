@@ -0,0 +1,59 @@
+package model
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// openSnapshotDiff shows how the live sessions differ from the snapshot
+// saved with tsm save, i.e. what tsm restore would change
+func (m *Model) openSnapshotDiff() (tea.Model, tea.Cmd) {
+	if m.serverIdx != 0 {
+		m.setError("Snapshots cover the default server only")
+		return m, nil
+	}
+
+	snapshot, err := state.LoadSnapshot(m.config.StateDir)
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+	if snapshot.SavedAt.IsZero() {
+		m.setError("No snapshot saved yet (tsm save)")
+		return m, nil
+	}
+	live, err := state.CaptureSessions()
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+
+	m.snapshotDiff = snapshotDiffLines(snapshot, snapshot.Diff(live))
+	m.mode = ModeSnapshotDiff
+	return m, nil
+}
+
+// snapshotDiffLines describes the differences, headed by the snapshot's age
+func snapshotDiffLines(snapshot state.Snapshot, diffs []state.SessionDiff) []string {
+	lines := []string{"Snapshot saved " + formatTimeAgo(snapshot.SavedAt)}
+	if len(diffs) == 0 {
+		return append(lines, "Live sessions match the snapshot")
+	}
+	for _, d := range diffs {
+		lines = append(lines, d.String())
+	}
+	return lines
+}
+
+func (m *Model) handleSnapshotDiffMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+
+	if key.Matches(msg, keys.Cancel) || key.Matches(msg, keys.SnapshotDiff) {
+		m.mode = ModeNormal
+		m.snapshotDiff = nil
+	}
+	return m, nil
+}
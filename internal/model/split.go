@@ -0,0 +1,216 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+type splitWindowsMsg struct {
+	session string
+	windows []tmux.Window
+	err     error
+}
+
+// loadSplitWindows fetches the windows shown in the split view's window pane
+func loadSplitWindows(sessionName string) tea.Cmd {
+	return func() tea.Msg {
+		windows, err := tmux.ListWindows(sessionName)
+		return splitWindowsMsg{session: sessionName, windows: windows, err: err}
+	}
+}
+
+// syncSplitPane loads the windows of the highlighted session when it changed,
+// or always when reload is set. Returns nil outside the split view.
+func (m *Model) syncSplitPane(reload bool) tea.Cmd {
+	if !m.config.SplitView {
+		return nil
+	}
+
+	name := m.cursorSessionName()
+	if name != m.splitSession {
+		m.splitSession = name
+		m.splitCursor = 0
+		m.splitFocus = false
+	} else if !reload {
+		return nil
+	}
+
+	if name == "" {
+		return nil
+	}
+	return loadSplitWindows(name)
+}
+
+// handleSplitWindows stores windows loaded for the split view's window pane
+func (m *Model) handleSplitWindows(msg splitWindowsMsg) {
+	// The cursor moved on while loading
+	if msg.session != m.splitSession {
+		return
+	}
+	if msg.err != nil {
+		m.setError("Error loading windows: %v", msg.err)
+		return
+	}
+
+	for i := range m.sessions {
+		if m.sessions[i].Name == msg.session {
+			m.sessions[i].Windows = msg.windows
+			break
+		}
+	}
+	if m.splitCursor >= len(msg.windows) {
+		m.splitCursor = max(len(msg.windows)-1, 0)
+	}
+	if len(msg.windows) == 0 {
+		m.splitFocus = false
+	}
+}
+
+// splitWindows returns the windows shown in the window pane
+func (m *Model) splitWindows() []tmux.Window {
+	if !m.isCursorValid() || m.items[m.cursor].IsRecent {
+		return nil
+	}
+	return m.sessions[m.items[m.cursor].SessionIndex].Windows
+}
+
+// focusWindowPane moves focus to the window pane if it has windows
+func (m *Model) focusWindowPane() {
+	if len(m.splitWindows()) > 0 && !m.showsNotesPane() {
+		m.splitFocus = true
+	}
+}
+
+// moveSplitCursor moves the window pane cursor by delta, staying in bounds
+func (m *Model) moveSplitCursor(delta int) {
+	m.splitCursor = min(max(m.splitCursor+delta, 0), max(len(m.splitWindows())-1, 0))
+}
+
+// selectedItem returns the item that actions apply to: the focused window in
+// the split view's window pane, otherwise the item under the cursor
+func (m *Model) selectedItem() (Item, bool) {
+	if !m.isCursorValid() {
+		return Item{}, false
+	}
+
+	item := m.items[m.cursor]
+	if m.splitFocus {
+		// Never fall back to the session while its windows are (re)loading
+		if m.splitCursor >= len(m.splitWindows()) {
+			return Item{}, false
+		}
+		return Item{SessionIndex: item.SessionIndex, WindowIndex: m.splitCursor}, true
+	}
+	return item, true
+}
+
+// splitPaneRows renders the window pane for the highlighted session,
+// scrolled so the window cursor stays visible, the session's notes or the
+// preview of the highlighted row
+func (m Model) splitPaneRows(width, maxLines int) []string {
+	if m.showsNotesPane() {
+		return m.notesPaneRows(width, maxLines)
+	}
+	if m.showsPreviewPane() {
+		// The windows stay listed above the preview, to move between them
+		rows := m.windowPaneRows(width, min(len(m.splitWindows()), maxLines/2))
+		return append(rows, m.previewPaneRows(width, maxLines-len(rows))...)
+	}
+	return m.windowPaneRows(width, maxLines)
+}
+
+// windowPaneRows renders the highlighted session's windows, scrolled so the
+// window cursor stays visible
+func (m Model) windowPaneRows(width, maxLines int) []string {
+	windows := m.splitWindows()
+	if len(windows) == 0 {
+		if m.splitSession == "" {
+			return nil
+		}
+		return []string{ui.SplitWindowStyle.Render(ui.TimeStyle.Render("loading…"))}
+	}
+
+	offset := max(m.splitCursor-maxLines+1, 0)
+	end := min(offset+maxLines, len(windows))
+	session := m.sessions[m.items[m.cursor].SessionIndex]
+
+	rows := make([]string, 0, end-offset)
+	for i := offset; i < end; i++ {
+		window := windows[i]
+		selected := m.splitFocus && i == m.splitCursor
+		marked := m.marked[window.Target(session.Name)]
+
+		if ui.Plain {
+			label := "  "
+			if i < 9 {
+				label = fmt.Sprintf("%d.", i+1)
+			}
+			row := fmt.Sprintf("%s%s window %d: %s", plainCursor(selected), label, window.Index, window.Name)
+			if m.holdsMarkedPane(window) {
+				row += " [marked pane]"
+			}
+			if n := m.deadPaneCount(window); n > 0 {
+				row += " [" + pluralize(n, "dead pane") + "]"
+			}
+			if marked {
+				row += " [marked]"
+			}
+			rows = append(rows, row)
+			continue
+		}
+
+		mark := " "
+		if marked {
+			mark = ui.MarkIcon
+		}
+		index := len(fmt.Sprintf("%d: ", window.Index))
+		cols := []ui.Column{
+			{Text: mark},
+			{Text: windowPickLabel(i, selected)},
+			{Text: m.windowLabel(window, 0, selected), Gap: 1, Flex: true, Fit: func(width int) string {
+				return m.windowLabel(window, width-index, selected)
+			}},
+		}
+		if m.holdsMarkedPane(window) {
+			cols = append(cols, ui.Column{Text: ui.MarkedPaneIcon, Gap: 1})
+		}
+		if m.deadPaneCount(window) > 0 {
+			cols = append(cols, ui.Column{Text: ui.DeadPaneIcon, Gap: 1})
+		}
+		// The command goes once the name can't be cut any shorter
+		if window.Command != "" {
+			cols = append(cols, ui.Column{Text: ui.TimeStyle.Render(window.Command), Gap: 1, Drop: true, Priority: 1})
+		}
+		row := ui.Row(cols, width-ui.SplitWindowStyle.GetHorizontalPadding())
+		rows = append(rows, ui.SplitWindowStyle.Render(row))
+	}
+	return rows
+}
+
+// joinSplitPane places the window pane to the right of the session rows
+func (m Model) joinSplitPane(left []string, maxLines int) []string {
+	leftWidth := 0
+	for _, row := range left {
+		leftWidth = max(leftWidth, lipgloss.Width(row))
+	}
+	right := m.splitPaneRows(m.contentWidth()-leftWidth-lipgloss.Width(ui.RenderSplitSeparator()), maxLines)
+
+	rows := make([]string, max(len(left), len(right)))
+	for i := range rows {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		rows[i] = l + strings.Repeat(" ", leftWidth-lipgloss.Width(l)) + ui.RenderSplitSeparator() + r
+	}
+	return rows
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// windowSummariesMsg carries the last pane line per window target
+type windowSummariesMsg struct {
+	summaries map[string]string
+}
+
+// loadWindowSummaries captures the last non-empty line of each window's
+// active pane, shown next to the window when its session is expanded
+func loadWindowSummaries(session string, windows []tmux.Window) tea.Cmd {
+	if len(windows) == 0 {
+		return nil
+	}
+	targets := make([]string, len(windows))
+	for i, w := range windows {
+		targets[i] = w.Target(session)
+	}
+
+	return func() tea.Msg {
+		summaries := make(map[string]string, len(targets))
+		for _, target := range targets {
+			if line, err := tmux.PaneLastLine(target); err == nil {
+				summaries[target] = line
+			}
+		}
+		return windowSummariesMsg{summaries: summaries}
+	}
+}
+
+// handleWindowSummaries stores loaded summaries, replacing older ones
+func (m *Model) handleWindowSummaries(msg windowSummariesMsg) {
+	if m.windowSummaries == nil {
+		m.windowSummaries = make(map[string]string, len(msg.summaries))
+	}
+	for target, line := range msg.summaries {
+		m.windowSummaries[target] = line
+	}
+}
+
+// windowSummary returns the summary of a window truncated to fit next to a
+// row of the given width. Empty when there is none or no room.
+func (m Model) windowSummary(session string, window tmux.Window, rowWidth int) string {
+	line := m.windowSummaries[window.Target(session)]
+	room := m.contentWidth() - rowWidth - 1
+	if line == "" || room < 8 {
+		return ""
+	}
+	return truncate(line, room)
+}
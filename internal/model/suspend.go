@@ -0,0 +1,66 @@
+package model
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/suspend"
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// suspendedSessions returns the sessions whose pane processes are all
+// stopped, by tsm or by an external tool. Errors mean nothing is shown.
+func suspendedSessions() map[string]bool {
+	pids, err := tmux.ListPanePIDs()
+	if err != nil {
+		return nil
+	}
+	procs, err := suspend.Snapshot()
+	if err != nil {
+		return nil
+	}
+
+	suspended := make(map[string]bool)
+	for name, panePIDs := range pids {
+		if procs.Stopped(panePIDs) {
+			suspended[name] = true
+		}
+	}
+	return suspended
+}
+
+// toggleSuspend stops all processes of the session under the cursor, or
+// continues them if the session is suspended
+func (m *Model) toggleSuspend() (tea.Model, tea.Cmd) {
+	name := m.cursorSessionName()
+	if name == "" {
+		return m, nil
+	}
+	if name == m.currentSession {
+		m.setError("Can't suspend the current session")
+		return m, nil
+	}
+
+	pids, err := tmux.ListPanePIDs()
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+	procs, err := suspend.Snapshot()
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+
+	if m.suspended[name] {
+		err = suspend.Resume(procs, pids[name])
+		m.setInfo("Resumed \"%s\"", name)
+	} else {
+		err = suspend.Suspend(procs, pids[name])
+		m.setInfo("Suspended \"%s\"", name)
+	}
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, m.loadSessions
+	}
+	return m, m.loadSessions
+}
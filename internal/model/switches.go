@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/state"
+)
+
+// recordSwitch adds a switch from the current session to the switch history
+func (m *Model) recordSwitch(to string) {
+	// Switch history only tracks the default server
+	if m.serverIdx != 0 {
+		return
+	}
+	_ = state.UpdateSwitchHistory(m.config.StateDir, func(h *state.SwitchHistory) error {
+		h.Visit(m.currentSession, to)
+		return nil
+	})
+	m.logEvent(state.EventSwitch, to)
+}
+
+// logEvent adds a switch, create or kill to the metrics log (metrics_log),
+// which like the switch history only tracks the default server
+func (m *Model) logEvent(action, session string) {
+	if !m.config.MetricsLog || m.serverIdx != 0 {
+		return
+	}
+	_ = state.AppendEvent(m.config.StateDir, state.Event{Time: time.Now(), Action: action, Session: session})
+}
+
+// stepSwitchHistory switches to the previous (delta -1) or next (delta 1)
+// session in the switch history
+func (m *Model) stepSwitchHistory(delta int) (tea.Model, tea.Cmd) {
+	if m.serverIdx != 0 {
+		m.setError("Switch history only tracks the default server")
+		return m, nil
+	}
+
+	alive := make(map[string]bool, len(m.sessions))
+	for _, s := range m.sessions {
+		alive[s.Name] = true
+	}
+	name, ok := "", false
+	prev, pos := 0, 0
+	err := state.UpdateSwitchHistory(m.config.StateDir, func(h *state.SwitchHistory) error {
+		prev = h.Pos
+		name, ok = h.Step(delta, m.currentSession, func(name string) bool { return alive[name] })
+		pos = h.Pos
+		return nil
+	})
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+	if !ok {
+		if delta < 0 {
+			m.setInfo("No older session in switch history")
+		} else {
+			m.setInfo("No newer session in switch history")
+		}
+		return m, nil
+	}
+
+	// Switching happens outside the lock; a failed switch steps back, unless
+	// another instance has moved on since
+	if err := switchClient(name); err != nil {
+		_ = state.UpdateSwitchHistory(m.config.StateDir, func(h *state.SwitchHistory) error {
+			if h.Pos == pos {
+				h.Pos = prev
+			}
+			return nil
+		})
+		m.setError("Failed to switch: %v", err)
+		return m, nil
+	}
+	return m, tea.Quit
+}
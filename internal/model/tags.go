@@ -0,0 +1,120 @@
+package model
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/state"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// parseFilter splits the filter into required tags ("#work"), commands
+// running in a pane (">psql", lowercased) and the text that is fuzzy matched
+// against session names
+func parseFilter(filter string) (tags, commands []string, text string) {
+	var words []string
+	for _, field := range strings.Fields(filter) {
+		if strings.HasPrefix(field, "#") {
+			if tag := strings.ToLower(strings.TrimPrefix(field, "#")); tag != "" {
+				tags = append(tags, tag)
+			}
+			continue
+		}
+		if strings.HasPrefix(field, ">") {
+			if command := strings.ToLower(strings.TrimPrefix(field, ">")); command != "" {
+				commands = append(commands, command)
+			}
+			continue
+		}
+		words = append(words, field)
+	}
+	return tags, commands, strings.Join(words, " ")
+}
+
+// matchesFilter reports whether a session carries all filter tags and
+// matches the filter text
+func (m *Model) matchesFilter(name string, tags []string, tm textMatcher) bool {
+	return m.matchesTags(name, tags) && matches(tm, name)
+}
+
+// matchesTags reports whether a session carries all filter tags.
+// Tags match by prefix so the list narrows while a tag is being typed.
+func (m *Model) matchesTags(name string, tags []string) bool {
+	for _, tag := range tags {
+		if !slices.ContainsFunc(m.tags[name], func(t string) bool { return strings.HasPrefix(t, tag) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// openTagInput prompts for the tags of the session under the cursor
+func (m *Model) openTagInput() (tea.Model, tea.Cmd) {
+	name := m.cursorSessionName()
+	if name == "" {
+		return m, nil
+	}
+
+	m.tagTarget = name
+	m.mode = ModeTagInput
+	m.input.Reset()
+	m.input.SetValue(strings.Join(m.tags[name], " "))
+	m.input.CursorEnd()
+	m.input.Focus()
+	return m, textinput.Blink
+}
+
+func (m *Model) handleTagInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+
+	switch {
+	case key.Matches(msg, keys.Cancel):
+		m.mode = ModeNormal
+		m.input.Blur()
+		return m, nil
+
+	case msg.Type == tea.KeyEnter:
+		return m.saveTags()
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// saveTags replaces the target session's tags with the input
+func (m *Model) saveTags() (tea.Model, tea.Cmd) {
+	m.mode = ModeNormal
+	m.input.Blur()
+	return m.setTags(m.tagTarget, state.ParseTags(m.input.Value()))
+}
+
+// setTags replaces a session's tags
+func (m *Model) setTags(name string, tags []string) (tea.Model, tea.Cmd) {
+	if err := m.store.SetTags(name, tags); err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+
+	if m.tags == nil {
+		m.tags = state.Tags{}
+	}
+	m.tags.Set(name, tags)
+	m.rebuildItems()
+
+	if len(tags) == 0 {
+		m.setInfo("Cleared tags of \"%s\"", name)
+	} else {
+		m.setInfo("Tagged \"%s\": %s", name, formatTags(tags))
+	}
+	return m, nil
+}
+
+// formatTags renders tags in filter syntax, e.g. "#client-x #work"
+func formatTags(tags []string) string {
+	return "#" + strings.Join(tags, " #")
+}
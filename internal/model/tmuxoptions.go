@@ -0,0 +1,78 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// detachWarning warns that killing the sessions detaches the clients
+// attached to them, as tmux's detach-on-destroy decides. Empty when no client
+// would be detached or the option can't be read.
+func (m Model) detachWarning(killed []string) string {
+	option, err := tmux.GetOption("detach-on-destroy")
+	if err != nil {
+		return ""
+	}
+	clients, err := tmux.ListClients()
+	if err != nil {
+		return ""
+	}
+
+	remaining := make([]string, 0, len(m.sessions)+1)
+	if m.currentSession != "" && !slices.Contains(killed, m.currentSession) {
+		remaining = append(remaining, m.currentSession)
+	}
+	for _, s := range m.sessions {
+		if !slices.Contains(killed, s.Name) {
+			remaining = append(remaining, s.Name)
+		}
+	}
+
+	n := detachedClients(option, clients, killed, remaining)
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("detaches %s (detach-on-destroy %s)", pluralize(n, "client"), option)
+}
+
+// detachedClients counts the clients tmux detaches when the killed sessions
+// are destroyed, rather than switching them to one of the remaining sessions
+func detachedClients(option string, clients []tmux.Client, killed, remaining []string) int {
+	attached := 0
+	attachedTo := make(map[string]bool)
+	for _, c := range clients {
+		attachedTo[c.Session] = true
+		if slices.Contains(killed, c.Session) {
+			attached++
+		}
+	}
+	if attached == 0 {
+		return 0
+	}
+
+	switch option {
+	case "off", "previous", "next":
+		// Clients switch to another session, if one is left
+		if len(remaining) > 0 {
+			return 0
+		}
+	case "no-detached":
+		// Clients switch to a session nobody is attached to, if one is left
+		for _, name := range remaining {
+			if !attachedTo[name] {
+				return 0
+			}
+		}
+	}
+	return attached
+}
+
+// destroysUnattached reports whether tmux destroys sessions without an
+// attached client (destroy-unattached), which takes sessions created in the
+// background right with them
+func destroysUnattached() bool {
+	option, err := tmux.GetOption("destroy-unattached")
+	return err == nil && option != "" && option != "off"
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// toastLevel is how serious a toast is, picking its style and lifetime
+type toastLevel int
+
+const (
+	toastInfo toastLevel = iota
+	toastWarning
+	toastError
+)
+
+// toastLifetimes are how long toasts stay per level; errors outlast infos
+var toastLifetimes = map[toastLevel]time.Duration{
+	toastInfo:    3 * time.Second,
+	toastWarning: 5 * time.Second,
+	toastError:   8 * time.Second,
+}
+
+// maxToasts is the number of toasts stacked at once, the oldest go first
+const maxToasts = 3
+
+// toast is a message stacked above the statusline until it expires
+type toast struct {
+	level   toastLevel
+	text    string
+	expires time.Time
+}
+
+// pushToast stacks a toast with its own expiry. Repeating a toast moves it
+// to the top and restarts its time rather than stacking it twice.
+func (m *Model) pushToast(level toastLevel, text string) {
+	m.toasts = slices.DeleteFunc(m.toasts, func(t toast) bool { return t.text == text })
+	m.toasts = append(m.toasts, toast{level: level, text: text, expires: time.Now().Add(toastLifetimes[level])})
+	if len(m.toasts) > maxToasts {
+		m.toasts = m.toasts[len(m.toasts)-maxToasts:]
+	}
+}
+
+// setInfo shows an info toast
+func (m *Model) setInfo(format string, args ...any) {
+	m.pushToast(toastInfo, fmt.Sprintf(format, args...))
+}
+
+// setWarning shows a warning toast
+func (m *Model) setWarning(format string, args ...any) {
+	m.pushToast(toastWarning, fmt.Sprintf(format, args...))
+}
+
+// expireToasts drops the toasts whose time is up. Runs on the animation
+// tick, so every toast expires on its own schedule.
+func (m *Model) expireToasts(now time.Time) {
+	m.toasts = slices.DeleteFunc(m.toasts, func(t toast) bool { return !now.Before(t.expires) })
+}
+
+// lastToast returns the text of the newest toast, empty without toasts
+func (m Model) lastToast() string {
+	if len(m.toasts) == 0 {
+		return ""
+	}
+	return m.toasts[len(m.toasts)-1].text
+}
+
+// hasError reports whether an error toast is showing
+func (m Model) hasError() bool {
+	return m.lastError() != ""
+}
+
+// lastError returns the text of the newest error toast
+func (m Model) lastError() string {
+	for _, t := range slices.Backward(m.toasts) {
+		if t.level == toastError {
+			return t.text
+		}
+	}
+	return ""
+}
+
+// extraMessageLines returns the lines stacked toasts add to the footer's
+// single message line
+func (m Model) extraMessageLines() int {
+	lines := len(m.toasts)
+	switch {
+	case m.prompt != "", m.mode == ModeTagInput, m.mode == ModeRename, m.mode == ModeLayoutVars, m.mode == ModeCreate:
+		lines++
+	}
+	return max(lines-1, 0)
+}
+
+// toastLines renders the stacked toasts, oldest first, cut to the width
+func (m Model) toastLines() []string {
+	lines := make([]string, len(m.toasts))
+	for i, t := range m.toasts {
+		text := truncate(t.text, m.contentWidth()-2)
+		switch t.level {
+		case toastError:
+			lines[i] = ui.ErrorMessageStyle.Render(text)
+		case toastWarning:
+			lines[i] = ui.WarningMessageStyle.Render(text)
+		default:
+			lines[i] = ui.MessageStyle.Render(text)
+		}
+	}
+	return lines
+}
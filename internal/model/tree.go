@@ -0,0 +1,87 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// TreeView opens the picker as a replacement for tmux's choose-tree: every
+// session expanded, the current one included, and windows and panes drawn
+// below their session with tree connectors
+func (m *Model) TreeView() {
+	m.tree = true
+	m.startExpandAll = true
+	m.listCurrent = true
+}
+
+// treePanes returns the panes listed below a window in the tree view. A
+// window with a single pane shows none, like choose-tree.
+func (m Model) treePanes(window tmux.Window) []tmux.Pane {
+	if !m.tree {
+		return nil
+	}
+	if panes := m.panes[window.ID]; len(panes) > 1 {
+		return panes
+	}
+	return nil
+}
+
+// treePrefix returns the connectors drawn before a window or pane row in the
+// tree view, empty outside it. Plain mode draws them in ASCII.
+func (m Model) treePrefix(item Item) string {
+	if !m.tree || item.IsSession || item.IsRecent {
+		return ""
+	}
+	branch, last, through, gap := "├─ ", "└─ ", "│  ", "   "
+	if ui.Plain {
+		branch, last, through = "|- ", "`- ", "|  "
+	}
+
+	session := m.sessions[item.SessionIndex]
+	windowLast := item.WindowIndex == len(session.Windows)-1
+	if !item.IsPane {
+		if windowLast {
+			return last
+		}
+		return branch
+	}
+
+	prefix := through
+	if windowLast {
+		prefix = gap
+	}
+	if item.PaneIndex == len(m.treePanes(session.Windows[item.WindowIndex]))-1 {
+		return prefix + last
+	}
+	return prefix + branch
+}
+
+// itemPane returns the pane of a pane item
+func (m Model) itemPane(item Item) tmux.Pane {
+	window := m.sessions[item.SessionIndex].Windows[item.WindowIndex]
+	return m.treePanes(window)[item.PaneIndex]
+}
+
+func (m Model) renderPane(pane tmux.Pane, prefix string, selected bool) string {
+	label := fmt.Sprintf("%d: %s", pane.Index, pane.Command)
+	if selected {
+		label = ui.WindowNameSelectedStyle.Render(label)
+	} else {
+		label = ui.DimStyle.Render(label)
+	}
+	if m.isDeadPane(pane) {
+		label += " " + ui.DeadPaneIcon
+	}
+	return ui.WindowStyle.Render(prefix + label)
+}
+
+// renderPanePlain renders a pane row of the tree view as plain text
+func (m Model) renderPanePlain(pane tmux.Pane, prefix string, selected bool) string {
+	row := fmt.Sprintf("%s    %spane %d: %s", plainCursor(selected), prefix, pane.Index, pane.Command)
+	if m.isDeadPane(pane) {
+		row += " [dead]"
+	}
+	return row
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// waitingTarget finds the session whose Claude has waited longest for input.
+// Returns the session's name and the tmux target to switch to: the window
+// Claude runs in when the hook recorded one that still exists, otherwise the
+// session.
+func (m Model) waitingTarget() (name, target string, ok bool) {
+	var since time.Time
+	for _, session := range m.sessions {
+		status, found := m.claudeStatuses[session.Name]
+		if !found || status.State != "waiting" {
+			continue
+		}
+		if ok && !status.Timestamp.Before(since) {
+			continue
+		}
+		name, target, since, ok = session.Name, session.Name, status.Timestamp, true
+		for _, window := range session.Windows {
+			if status.Window != "" && window.ID == status.Window {
+				target = window.ID
+				break
+			}
+		}
+	}
+	return name, target, ok
+}
+
+// jumpToWaiting switches straight to the Claude session waiting longest for
+// input, landing on its window
+func (m *Model) jumpToWaiting() (tea.Model, tea.Cmd) {
+	if !m.config.ClaudeStatusEnabled {
+		m.setError("Claude status is off (claude_status_enabled = true)")
+		return m, nil
+	}
+	name, target, ok := m.waitingTarget()
+	if !ok {
+		m.setInfo("No Claude session is waiting")
+		return m, nil
+	}
+
+	if err := switchClient(target); err != nil {
+		m.setError("Failed to switch to %s: %v", name, err)
+		return m, m.loadSessions
+	}
+	m.recordSwitch(name)
+	return m, tea.Quit
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/gitinfo"
+	"github.com/nikbrunner/tsm/internal/ui"
+)
+
+// worktreeRemoval is a session to kill along with the linked worktree it
+// works in (:worktree)
+type worktreeRemoval struct {
+	session  string
+	worktree gitinfo.Worktree
+	changes  []string // Uncommitted changes shown when asking, lost with --force
+}
+
+// paletteWorktree kills the selected session and then removes its git
+// worktree (:worktree), e.g. once its branch is merged. Both steps ask first:
+// the kill like C-x, the removal with C-y.
+func (m *Model) paletteWorktree(_ []string) (tea.Model, tea.Cmd) {
+	if len(m.marked) > 0 {
+		m.setError("Clear the marks (esc) to remove a worktree")
+		return m, nil
+	}
+	item, ok := m.selectedItem()
+	if !ok || !item.IsSession {
+		m.setError("Select a session to remove with its worktree")
+		return m, nil
+	}
+
+	session := m.sessions[item.SessionIndex]
+	worktree, ok := gitinfo.LinkedWorktree(session.Path)
+	if !ok {
+		m.setError("\"%s\" doesn't work in a linked git worktree", session.Name)
+		return m, nil
+	}
+	if m.groupShared(item.SessionIndex) {
+		m.setError("\"%s\" shares its windows with group %s, kill the others first", session.Name, session.Group)
+		return m, nil
+	}
+	// Removing the directory would pull it out from under their shells
+	if others := m.sessionsInDir(worktree.Path, session.Name); len(others) > 0 {
+		verb, them := "work", "them"
+		if len(others) == 1 {
+			verb, them = "works", "it"
+		}
+		m.setError("\"%s\" also %s in %s, kill %s first", strings.Join(others, "\", \""), verb, tildePath(worktree.Path), them)
+		return m, nil
+	}
+
+	model, cmd := m.confirmKill()
+	if m.mode == ModeConfirmKill {
+		m.worktreeRemoval = &worktreeRemoval{session: session.Name, worktree: worktree}
+	}
+	return model, cmd
+}
+
+// sessionsInDir returns the sessions other than except whose directory is
+// dir or inside it
+func (m *Model) sessionsInDir(dir, except string) []string {
+	var names []string
+	for _, s := range m.sessions {
+		if s.Name != except && s.Path != "" && (s.Path == dir || strings.HasPrefix(s.Path, dir+string(filepath.Separator))) {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+// confirmWorktreeRemoval asks to remove the worktree once its session is
+// killed, listing the changes that would be lost
+func (m *Model) confirmWorktreeRemoval(removal *worktreeRemoval) {
+	removal.changes = gitinfo.Changes(removal.worktree.Path)
+
+	lost := ""
+	if len(removal.changes) > 0 {
+		lost = ", losing " + pluralize(len(removal.changes), "uncommitted change")
+	}
+	branch := ""
+	if removal.worktree.Branch != "" {
+		branch = fmt.Sprintf(" (branch %s is kept)", removal.worktree.Branch)
+	}
+	m.prompt = fmt.Sprintf("Remove worktree %s%s?%s", tildePath(removal.worktree.Path), lost, branch)
+	m.killPreview = removal.changes
+	m.worktreeRemoval = removal
+	m.mode = ModeConfirmWorktree
+}
+
+func (m *Model) handleConfirmWorktreeMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.DefaultKeyMap
+
+	switch {
+	case key.Matches(msg, keys.Confirm):
+		m.removeWorktree()
+	case key.Matches(msg, keys.Cancel):
+		m.setInfo("Kept worktree %s", tildePath(m.worktreeRemoval.worktree.Path))
+		m.resetWorktreeRemoval()
+	}
+	return m, nil
+}
+
+// removeWorktree removes the confirmed worktree, forcing past the changes
+// the confirmation listed
+func (m *Model) removeWorktree() {
+	removal := m.worktreeRemoval
+	m.resetWorktreeRemoval()
+
+	if err := gitinfo.RemoveWorktree(removal.worktree.Path, len(removal.changes) > 0); err != nil {
+		m.setError("Error removing worktree: %v", err)
+		return
+	}
+	m.setInfo("Removed worktree %s", tildePath(removal.worktree.Path))
+}
+
+// resetWorktreeRemoval leaves the worktree confirmation
+func (m *Model) resetWorktreeRemoval() {
+	m.mode = ModeNormal
+	m.prompt = ""
+	m.killPreview = nil
+	m.worktreeRemoval = nil
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nikbrunner/tsm/internal/clipboard"
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// yankText returns what the yank keys copy for an item: its tmux target
+// ("session" or "session:index"), or its working directory when path is set
+func (m *Model) yankText(item Item, path bool) (string, error) {
+	session := m.sessions[item.SessionIndex]
+	if item.IsSession {
+		if path {
+			return session.Path, nil
+		}
+		return session.Name, nil
+	}
+
+	window := session.Windows[item.WindowIndex]
+	if path {
+		return tmux.PanePath(window.Target(session.Name))
+	}
+	return fmt.Sprintf("%s:%d", session.Name, window.Index), nil
+}
+
+// yank copies the selected session or window's target, or its working
+// directory, to the clipboard
+func (m *Model) yank(path bool) (tea.Model, tea.Cmd) {
+	item, ok := m.selectedItem()
+	if !ok || item.IsRecent {
+		return m, nil
+	}
+
+	text, err := m.yankText(item, path)
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+	if text == "" {
+		m.setError("Nothing to copy")
+		return m, nil
+	}
+
+	via, err := clipboard.Copy(text)
+	if err != nil {
+		m.setError("Error: %v", err)
+		return m, nil
+	}
+	m.setInfo("Copied %s (%s)", truncate(text, m.contentWidth()-20), via)
+	return m, nil
+}
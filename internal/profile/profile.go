@@ -0,0 +1,127 @@
+// Package profile records timing spans (tmux calls, status loads, first render)
+// so latency can be diagnosed with real numbers. Recording is a no-op until
+// Enable is called.
+package profile
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Span is a single timed operation
+type Span struct {
+	Name     string
+	Start    time.Duration // Offset from Enable
+	Duration time.Duration
+}
+
+var (
+	mu      sync.Mutex
+	enabled bool
+	epoch   time.Time
+	spans   []Span
+	marked  map[string]bool
+)
+
+// Enable starts recording spans; offsets are relative to this call
+func Enable() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = true
+	epoch = time.Now()
+	spans = nil
+	marked = make(map[string]bool)
+}
+
+// Enabled reports whether spans are being recorded
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enabled
+}
+
+// Start begins a span and returns the function that ends it:
+//
+//	defer profile.Start("tmux list-sessions")()
+func Start(name string) func() {
+	if !Enabled() {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		record(name, start, time.Since(start))
+	}
+}
+
+// Mark records a span from Enable until now, once per name (e.g. "first render")
+func Mark(name string) {
+	mu.Lock()
+	if !enabled || marked[name] {
+		mu.Unlock()
+		return
+	}
+	marked[name] = true
+	start := epoch
+	mu.Unlock()
+
+	record(name, start, time.Since(start))
+}
+
+func record(name string, start time.Time, d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	if enabled {
+		spans = append(spans, Span{Name: name, Start: start.Sub(epoch), Duration: d})
+	}
+}
+
+// Spans returns a copy of the recorded spans in completion order
+func Spans() []Span {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Span(nil), spans...)
+}
+
+// summary aggregates the spans sharing a name
+type summary struct {
+	name  string
+	count int
+	total time.Duration
+	max   time.Duration
+}
+
+// Report writes the recorded spans aggregated by name, slowest total first
+func Report(w io.Writer) {
+	recorded := Spans()
+
+	byName := make(map[string]*summary)
+	var summaries []*summary
+	for _, s := range recorded {
+		sum, ok := byName[s.Name]
+		if !ok {
+			sum = &summary{name: s.Name}
+			byName[s.Name] = sum
+			summaries = append(summaries, sum)
+		}
+		sum.count++
+		sum.total += s.Duration
+		sum.max = max(sum.max, s.Duration)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].total > summaries[j].total
+	})
+
+	_, _ = fmt.Fprintf(w, "tsm profile (%d spans)\n", len(recorded))
+	_, _ = fmt.Fprintf(w, "  %-28s %6s %10s %10s\n", "span", "count", "total", "max")
+	for _, sum := range summaries {
+		_, _ = fmt.Fprintf(w, "  %-28s %6d %10s %10s\n", sum.name, sum.count, round(sum.total), round(sum.max))
+	}
+}
+
+// round trims durations to a readable precision
+func round(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
@@ -0,0 +1,48 @@
+package profile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDisabledRecordsNothing(t *testing.T) {
+	mu.Lock()
+	enabled = false
+	spans = nil
+	mu.Unlock()
+
+	Start("tmux list-sessions")()
+	Mark("first render")
+
+	if got := Spans(); len(got) != 0 {
+		t.Errorf("Spans() = %v, want none while disabled", got)
+	}
+}
+
+func TestReport(t *testing.T) {
+	Enable()
+
+	Start("tmux list-sessions")()
+	Start("tmux list-sessions")()
+	Start("claude statuses")()
+	Mark("first render")
+	Mark("first render")
+
+	if got := len(Spans()); got != 4 {
+		t.Fatalf("len(Spans()) = %d, want 4 (marks record once)", got)
+	}
+
+	var buf bytes.Buffer
+	Report(&buf)
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "tsm profile (4 spans)") {
+		t.Errorf("report header = %q", strings.SplitN(out, "\n", 2)[0])
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "tmux list-sessions") && !strings.Contains(line, " 2 ") {
+			t.Errorf("list-sessions line %q should count 2 spans", line)
+		}
+	}
+}
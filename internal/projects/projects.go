@@ -0,0 +1,57 @@
+// Package projects finds the project directories offered by the picker's
+// project mode (C-p) and `tsm projects`.
+package projects
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Scan returns the full paths of the directories depth levels below each of
+// the base directories, skipping hidden ones
+func Scan(baseDirs []string, depth int) []string {
+	var dirs []string
+	for _, baseDir := range baseDirs {
+		walkAtDepth(baseDir, "", depth, &dirs)
+	}
+	return dirs
+}
+
+// walkAtDepth recursively walks directories and collects full paths at the target depth
+func walkAtDepth(baseDir, currentPath string, remainingDepth int, dirs *[]string) {
+	if remainingDepth == 0 {
+		// We've reached the target depth - add the full path
+		if currentPath != "" {
+			fullPath := filepath.Join(baseDir, currentPath)
+			*dirs = append(*dirs, fullPath)
+		}
+		return
+	}
+
+	// Read the current directory
+	scanPath := filepath.Join(baseDir, currentPath)
+	entries, err := os.ReadDir(scanPath)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		// Skip hidden directories
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		var nextPath string
+		if currentPath == "" {
+			nextPath = entry.Name()
+		} else {
+			nextPath = filepath.Join(currentPath, entry.Name())
+		}
+
+		walkAtDepth(baseDir, nextPath, remainingDepth-1, dirs)
+	}
+}
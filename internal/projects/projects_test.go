@@ -0,0 +1,28 @@
+package projects
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	base := t.TempDir()
+	for _, dir := range []string{"acme/api", "acme/web", "acme/.cache", "solo", ".hidden/repo"} {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(base, "acme", "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{filepath.Join(base, "acme", "api"), filepath.Join(base, "acme", "web")}
+	if got := Scan([]string{base, filepath.Join(base, "missing")}, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan() = %v, want %v", got, want)
+	}
+	if got := Scan([]string{base}, 1); len(got) != 2 {
+		t.Errorf("Scan(depth 1) = %v, want acme and solo", got)
+	}
+}
@@ -0,0 +1,72 @@
+// Package ranking runs the sort_command that orders the sessions for
+// sort = "command".
+package ranking
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Session is what the command learns about a session
+type Session struct {
+	Name         string   `json:"name"`
+	Path         string   `json:"path"`
+	Group        string   `json:"group,omitempty"`
+	LastActivity int64    `json:"last_activity"` // Unix seconds
+	Windows      int      `json:"windows"`
+	Tags         []string `json:"tags"`
+}
+
+// Rank runs command with sh -c, the sessions as a JSON array on stdin (in
+// tmux's activity order), and returns the session names it prints, one per
+// line, in the order printed. The command has until the timeout.
+func Rank(command string, sessions []Session, timeout time.Duration) ([]string, error) {
+	input, err := json.Marshal(sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(input)
+	// Don't wait for background processes holding on to stdout/stderr
+	cmd.WaitDelay = time.Second
+	out, err := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(bytes.TrimSpace(exitErr.Stderr)) > 0 {
+			return nil, fmt.Errorf("%w: %s", err, lastLine(exitErr.Stderr))
+		}
+		return nil, err
+	}
+	return parseNames(out), nil
+}
+
+// parseNames reads the printed session names, skipping blank lines
+func parseNames(out []byte) []string {
+	var names []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// lastLine returns the last non-blank line of the command's stderr, usually
+// the one saying what went wrong
+func lastLine(stderr []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(stderr)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,36 @@
+package ranking
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRank(t *testing.T) {
+	sessions := []Session{
+		{Name: "api", Path: "/work/api", Windows: 2, Tags: []string{"work"}},
+		{Name: "dotfiles", Path: "/home/dotfiles", Windows: 1},
+	}
+
+	// Reverses the input order, proving the JSON arrived
+	command := `grep -o '"name":"[^"]*"' | cut -d'"' -f4 | sort -r; echo`
+	names, err := Rank(command, sessions, time.Second)
+	if err != nil {
+		t.Fatalf("Rank() error = %v", err)
+	}
+	if len(names) != 2 || names[0] != "dotfiles" || names[1] != "api" {
+		t.Errorf("Rank() = %q, want dotfiles before api", names)
+	}
+
+	if _, err := Rank("echo 'no priorities file' >&2; exit 1", sessions, time.Second); err == nil || !strings.Contains(err.Error(), "no priorities file") {
+		t.Errorf("Rank() error = %v, want the command's stderr", err)
+	}
+
+	start := time.Now()
+	if _, err := Rank("sleep 5", sessions, 50*time.Millisecond); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Rank() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Rank() took %s, want it killed at the timeout", elapsed)
+	}
+}
@@ -0,0 +1,173 @@
+// Package selfupdate replaces the running binary with the latest GitHub release.
+//
+// Releases carry one binary per platform named by AssetName plus a
+// checksums.txt in sha256sum format (see `make release`).
+package selfupdate
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the GitHub API endpoint of the latest tsm release
+const DefaultAPIURL = "https://api.github.com/repos/nikbrunner/tsm/releases/latest"
+
+// checksumsAsset is the release asset listing the SHA-256 of each binary
+const checksumsAsset = "checksums.txt"
+
+// maxDownloadSize bounds downloads so a bad response can't exhaust memory
+const maxDownloadSize = 200 << 20
+
+// AssetName returns the release asset name of the binary for a platform
+func AssetName(goos, goarch string) string {
+	return fmt.Sprintf("tsm_%s_%s", goos, goarch)
+}
+
+// Release is a published tsm release
+type Release struct {
+	Tag    string  `json:"tag_name"`
+	Assets []Asset `json:"assets"`
+}
+
+// Asset is a downloadable file of a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// asset returns the release asset with the given name
+func (r Release) asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Updater fetches releases from the GitHub API
+type Updater struct {
+	APIURL string
+	Client *http.Client
+}
+
+// New returns an updater for the official tsm releases
+func New() *Updater {
+	return &Updater{
+		APIURL: DefaultAPIURL,
+		Client: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Latest returns the latest release
+func (u *Updater) Latest() (Release, error) {
+	data, err := u.get(u.APIURL)
+	if err != nil {
+		return Release{}, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	var r Release
+	if err := json.Unmarshal(data, &r); err != nil {
+		return Release{}, fmt.Errorf("failed to parse release: %w", err)
+	}
+	if r.Tag == "" {
+		return Release{}, fmt.Errorf("release has no tag")
+	}
+	return r, nil
+}
+
+// Update downloads the release binary for this platform, verifies its
+// checksum and replaces the binary at path with it
+func (u *Updater) Update(r Release, path string) error {
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	bin, ok := r.asset(name)
+	if !ok {
+		return fmt.Errorf("release %s has no binary for %s/%s", r.Tag, runtime.GOOS, runtime.GOARCH)
+	}
+	sums, ok := r.asset(checksumsAsset)
+	if !ok {
+		return fmt.Errorf("release %s has no %s, refusing to install unverified binary", r.Tag, checksumsAsset)
+	}
+
+	sumsData, err := u.get(sums.URL)
+	if err != nil {
+		return fmt.Errorf("failed to download checksums: %w", err)
+	}
+	want, err := parseChecksum(sumsData, name)
+	if err != nil {
+		return err
+	}
+
+	data, err := u.get(bin.URL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, want)
+	}
+
+	return replace(path, data)
+}
+
+// get downloads a URL
+func (u *Updater) get(url string) ([]byte, error) {
+	resp, err := u.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxDownloadSize))
+}
+
+// parseChecksum finds the SHA-256 of a file in sha256sum output
+func parseChecksum(data []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Binary mode marks the name with a leading '*'
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s", name)
+}
+
+// replace atomically swaps the file at path for an executable with data.
+// The temp file lives next to it so the rename stays on one filesystem.
+func replace(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tsm-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to write update: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write update: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write update: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
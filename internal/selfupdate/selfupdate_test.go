@@ -0,0 +1,92 @@
+package selfupdate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// releaseServer serves a release with the given binary and checksums file
+func releaseServer(t *testing.T, binary, checksums string) *httptest.Server {
+	t.Helper()
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			fmt.Fprintf(w, `{"tag_name": "v9.9.9", "assets": [
+				{"name": %q, "browser_download_url": "%s/bin"},
+				{"name": "checksums.txt", "browser_download_url": "%s/sums"}]}`, name, srv.URL, srv.URL)
+		case "/bin":
+			fmt.Fprint(w, binary)
+		case "/sums":
+			fmt.Fprint(w, checksums)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestUpdate(t *testing.T) {
+	binary := "#!/bin/sh\necho new tsm\n"
+	sum := sha256.Sum256([]byte(binary))
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	checksums := fmt.Sprintf("%s  tsm_other_arch\n%s *%s\n", strings.Repeat("0", 64), hex.EncodeToString(sum[:]), name)
+
+	srv := releaseServer(t, binary, checksums)
+	u := &Updater{APIURL: srv.URL + "/latest", Client: srv.Client()}
+
+	release, err := u.Latest()
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if release.Tag != "v9.9.9" {
+		t.Errorf("Latest() tag = %q, want v9.9.9", release.Tag)
+	}
+
+	path := filepath.Join(t.TempDir(), "tsm")
+	if err := os.WriteFile(path, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Update(release, path); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != binary {
+		t.Errorf("binary = %q, want the downloaded one", got)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0755 {
+		t.Errorf("mode = %v, want 0755", info.Mode().Perm())
+	}
+}
+
+func TestUpdateChecksumMismatch(t *testing.T) {
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	srv := releaseServer(t, "tampered", strings.Repeat("a", 64)+"  "+name+"\n")
+	u := &Updater{APIURL: srv.URL + "/latest", Client: srv.Client()}
+
+	release, err := u.Latest()
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "tsm")
+	if err := os.WriteFile(path, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Update(release, path); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Update() error = %v, want checksum mismatch", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "old" {
+		t.Errorf("binary = %q, want it left untouched", got)
+	}
+}
@@ -0,0 +1,71 @@
+package state
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// archiveFile is the name of the archived sessions file in the state directory
+const archiveFile = "archive.json"
+
+// ArchivedSession is a snapshot of a session killed with the archive action,
+// enough to recreate its windows and panes later
+type ArchivedSession struct {
+	Name       string                `json:"name"`
+	Path       string                `json:"path"`
+	Layout     string                `json:"layout,omitempty"` // tsm layout it was created with
+	Windows    []tmux.WindowSnapshot `json:"windows"`
+	ArchivedAt time.Time             `json:"archived_at"`
+}
+
+// Archive is the list of archived sessions, most recently archived first
+type Archive struct {
+	Sessions []ArchivedSession `json:"sessions"`
+}
+
+// LoadArchive reads the archived sessions from the state directory.
+// Returns an empty archive if the file doesn't exist.
+func LoadArchive(stateDir string) (Archive, error) {
+	var a Archive
+	if err := readJSON(filepath.Join(stateDir, archiveFile), &a); err != nil {
+		return Archive{}, err
+	}
+	return a, nil
+}
+
+// SaveArchive writes the archived sessions to the state directory
+func SaveArchive(stateDir string, a Archive) error {
+	return writeJSON(filepath.Join(stateDir, archiveFile), a)
+}
+
+// UpdateArchive applies fn to the archived sessions and saves them (see update)
+func UpdateArchive(stateDir string, fn func(*Archive) error) error {
+	return update(stateDir, LoadArchive, SaveArchive, fn)
+}
+
+// Add archives a session, replacing an older snapshot of the same name
+func (a *Archive) Add(s ArchivedSession) {
+	a.Remove(s.Name)
+	a.Sessions = append([]ArchivedSession{s}, a.Sessions...)
+}
+
+// Remove drops a session from the archive
+func (a *Archive) Remove(name string) {
+	for i, s := range a.Sessions {
+		if s.Name == name {
+			a.Sessions = append(a.Sessions[:i], a.Sessions[i+1:]...)
+			return
+		}
+	}
+}
+
+// Names returns the names of the archived sessions
+func (a Archive) Names() []string {
+	names := make([]string, len(a.Sessions))
+	for i, s := range a.Sessions {
+		names[i] = s.Name
+	}
+	return names
+}
@@ -0,0 +1,51 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	stateDir := t.TempDir()
+	now := time.Now().Truncate(time.Second)
+
+	a, err := LoadArchive(stateDir)
+	if err != nil {
+		t.Fatalf("LoadArchive() on empty dir error = %v", err)
+	}
+	if len(a.Sessions) != 0 {
+		t.Fatalf("LoadArchive() on empty dir = %v, want empty", a.Sessions)
+	}
+
+	windows := []tmux.WindowSnapshot{
+		{Layout: "41b5,80x24,0,0[80x12,0,0,7,80x11,0,13,8]", Panes: []string{"/work/api", "/work/api/web"}},
+		{Name: "logs", Layout: "b266,80x24,0,0,9", Panes: []string{"/var/log"}},
+	}
+	a.Add(ArchivedSession{Name: "api", Path: "/work/api", Windows: windows, ArchivedAt: now.Add(-time.Hour)})
+	a.Add(ArchivedSession{Name: "web", Path: "/work/web", ArchivedAt: now})
+	// Archiving again replaces the old snapshot and moves it to the front
+	a.Add(ArchivedSession{Name: "api", Path: "/work/api", Windows: windows, ArchivedAt: now})
+
+	if err := SaveArchive(stateDir, a); err != nil {
+		t.Fatalf("SaveArchive() error = %v", err)
+	}
+	loaded, err := LoadArchive(stateDir)
+	if err != nil {
+		t.Fatalf("LoadArchive() error = %v", err)
+	}
+
+	if got := loaded.Names(); !reflect.DeepEqual(got, []string{"api", "web"}) {
+		t.Errorf("Names() = %v, want [api web]", got)
+	}
+	if got := loaded.Sessions[0].Windows; !reflect.DeepEqual(got, windows) {
+		t.Errorf("api windows = %+v, want %+v", got, windows)
+	}
+
+	loaded.Remove("api")
+	if got := loaded.Names(); !reflect.DeepEqual(got, []string{"web"}) {
+		t.Errorf("Names() after Remove = %v, want [web]", got)
+	}
+}
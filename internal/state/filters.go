@@ -0,0 +1,49 @@
+package state
+
+import "path/filepath"
+
+// filtersFile is the name of the filter history file in the state directory
+const filtersFile = "filters.json"
+
+// maxFilterHistory is the number of filter strings remembered
+const maxFilterHistory = 20
+
+// filterHistory is the on-disk format of the filter history
+type filterHistory struct {
+	Filters []string `json:"filters"`
+}
+
+// LoadFilterHistory returns remembered filter strings, most recent first
+func LoadFilterHistory(stateDir string) ([]string, error) {
+	var h filterHistory
+	if err := readJSON(filepath.Join(stateDir, filtersFile), &h); err != nil {
+		return nil, err
+	}
+	return h.Filters, nil
+}
+
+// RecordFilter moves a filter string to the front of the filter history
+func RecordFilter(stateDir, filter string) error {
+	if filter == "" {
+		return nil
+	}
+
+	unlock, err := lock(stateDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	filters, err := LoadFilterHistory(stateDir)
+	if err != nil {
+		return err
+	}
+
+	h := filterHistory{Filters: []string{filter}}
+	for _, f := range filters {
+		if f != filter && len(h.Filters) < maxFilterHistory {
+			h.Filters = append(h.Filters, f)
+		}
+	}
+	return writeJSON(filepath.Join(stateDir, filtersFile), h)
+}
@@ -0,0 +1,44 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRecordFilter(t *testing.T) {
+	stateDir := t.TempDir()
+
+	for _, f := range []string{"api", "web", "", "api"} {
+		if err := RecordFilter(stateDir, f); err != nil {
+			t.Fatalf("RecordFilter(%q) error = %v", f, err)
+		}
+	}
+
+	filters, err := LoadFilterHistory(stateDir)
+	if err != nil {
+		t.Fatalf("LoadFilterHistory() error = %v", err)
+	}
+
+	want := []string{"api", "web"}
+	if fmt.Sprint(filters) != fmt.Sprint(want) {
+		t.Errorf("LoadFilterHistory() = %v, want %v", filters, want)
+	}
+}
+
+func TestRecordFilterLimit(t *testing.T) {
+	stateDir := t.TempDir()
+
+	for i := 0; i < maxFilterHistory+5; i++ {
+		if err := RecordFilter(stateDir, fmt.Sprintf("f%d", i)); err != nil {
+			t.Fatalf("RecordFilter() error = %v", err)
+		}
+	}
+
+	filters, _ := LoadFilterHistory(stateDir)
+	if len(filters) != maxFilterHistory {
+		t.Errorf("len(filters) = %d, want %d", len(filters), maxFilterHistory)
+	}
+	if filters[0] != fmt.Sprintf("f%d", maxFilterHistory+4) {
+		t.Errorf("filters[0] = %q, want most recent", filters[0])
+	}
+}
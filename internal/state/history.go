@@ -0,0 +1,169 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// historyFile is the name of the session history file in the state directory
+const historyFile = "history.json"
+
+// maxHistoryEntries bounds the history file size; the oldest entries are dropped
+const maxHistoryEntries = 100
+
+// HistoryEntry remembers a session seen by tsm so it can be recreated later
+type HistoryEntry struct {
+	Name     string    `json:"name"`
+	Path     string    `json:"path"`
+	Layout   string    `json:"layout,omitempty"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// History is the list of sessions tsm has seen, most recently seen first
+type History struct {
+	Sessions []HistoryEntry `json:"sessions"`
+}
+
+// LoadHistory reads the session history from the state directory.
+// Returns an empty history if the file doesn't exist.
+func LoadHistory(stateDir string) (History, error) {
+	var h History
+	if err := readJSON(filepath.Join(stateDir, historyFile), &h); err != nil {
+		return History{}, err
+	}
+	return h, nil
+}
+
+// SaveHistory writes the session history to the state directory
+func SaveHistory(stateDir string, h History) error {
+	sort.SliceStable(h.Sessions, func(i, j int) bool {
+		return h.Sessions[i].LastSeen.After(h.Sessions[j].LastSeen)
+	})
+	if len(h.Sessions) > maxHistoryEntries {
+		h.Sessions = h.Sessions[:maxHistoryEntries]
+	}
+	return writeJSON(filepath.Join(stateDir, historyFile), h)
+}
+
+// UpdateHistory applies fn to the session history and saves it (see update)
+func UpdateHistory(stateDir string, fn func(*History) error) error {
+	return update(stateDir, LoadHistory, SaveHistory, fn)
+}
+
+// Touch records that a session was seen alive at the given path.
+// An empty path keeps the previously remembered one.
+func (h *History) Touch(name, path string, at time.Time) {
+	if e := h.find(name); e != nil {
+		if path != "" {
+			e.Path = path
+		}
+		e.LastSeen = at
+		return
+	}
+	h.Sessions = append(h.Sessions, HistoryEntry{Name: name, Path: path, LastSeen: at})
+}
+
+// SetLayout remembers the layout a session was created with
+func (h *History) SetLayout(name, layout string) {
+	if e := h.find(name); e != nil {
+		e.Layout = layout
+	}
+}
+
+// Remove forgets a session
+func (h *History) Remove(name string) {
+	for i, e := range h.Sessions {
+		if e.Name == name {
+			h.Sessions = append(h.Sessions[:i], h.Sessions[i+1:]...)
+			return
+		}
+	}
+}
+
+// Get returns the entry for a session, if remembered
+func (h *History) Get(name string) (HistoryEntry, bool) {
+	if e := h.find(name); e != nil {
+		return *e, true
+	}
+	return HistoryEntry{}, false
+}
+
+// Recent returns up to limit remembered sessions that are not in live,
+// most recently seen first
+func (h History) Recent(live []string, limit int) []HistoryEntry {
+	liveSet := make(map[string]bool, len(live))
+	for _, name := range live {
+		liveSet[name] = true
+	}
+
+	var recent []HistoryEntry
+	for _, e := range h.Sessions {
+		if !liveSet[e.Name] {
+			recent = append(recent, e)
+		}
+	}
+
+	sort.SliceStable(recent, func(i, j int) bool {
+		return recent[i].LastSeen.After(recent[j].LastSeen)
+	})
+	if len(recent) > limit {
+		recent = recent[:limit]
+	}
+	return recent
+}
+
+func (h *History) find(name string) *HistoryEntry {
+	for i := range h.Sessions {
+		if h.Sessions[i].Name == name {
+			return &h.Sessions[i]
+		}
+	}
+	return nil
+}
+
+// readJSON decodes a JSON state file into v. A missing file leaves v untouched.
+func readJSON(path string, v any) error {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// writeJSON atomically writes v as JSON to a state file
+func writeJSON(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file and rename so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
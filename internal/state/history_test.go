@@ -0,0 +1,86 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryRoundTrip(t *testing.T) {
+	stateDir := t.TempDir()
+	now := time.Now().Truncate(time.Second)
+
+	h, err := LoadHistory(stateDir)
+	if err != nil {
+		t.Fatalf("LoadHistory() on empty dir error = %v", err)
+	}
+	if len(h.Sessions) != 0 {
+		t.Fatalf("LoadHistory() on empty dir = %v, want empty", h.Sessions)
+	}
+
+	h.Touch("api", "/work/api", now)
+	h.SetLayout("api", "ide")
+	h.Touch("web", "/work/web", now.Add(-time.Hour))
+
+	if err := SaveHistory(stateDir, h); err != nil {
+		t.Fatalf("SaveHistory() error = %v", err)
+	}
+
+	loaded, err := LoadHistory(stateDir)
+	if err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+
+	entry, ok := loaded.Get("api")
+	if !ok {
+		t.Fatal("api should be remembered")
+	}
+	if entry.Path != "/work/api" || entry.Layout != "ide" || !entry.LastSeen.Equal(now) {
+		t.Errorf("api entry = %+v, want path /work/api, layout ide, last seen %v", entry, now)
+	}
+}
+
+func TestHistoryTouchKeepsPathAndLayout(t *testing.T) {
+	var h History
+	now := time.Now()
+
+	h.Touch("api", "/work/api", now.Add(-time.Hour))
+	h.SetLayout("api", "ide")
+	h.Touch("api", "", now)
+
+	entry, _ := h.Get("api")
+	if entry.Path != "/work/api" {
+		t.Errorf("Path = %q, want /work/api", entry.Path)
+	}
+	if entry.Layout != "ide" {
+		t.Errorf("Layout = %q, want ide", entry.Layout)
+	}
+	if !entry.LastSeen.Equal(now) {
+		t.Errorf("LastSeen = %v, want %v", entry.LastSeen, now)
+	}
+	if len(h.Sessions) != 1 {
+		t.Errorf("len(Sessions) = %d, want 1", len(h.Sessions))
+	}
+}
+
+func TestHistoryRecent(t *testing.T) {
+	var h History
+	now := time.Now()
+
+	h.Touch("old", "/old", now.Add(-3*time.Hour))
+	h.Touch("live", "/live", now)
+	h.Touch("newer", "/newer", now.Add(-time.Hour))
+	h.Touch("oldest", "/oldest", now.Add(-5*time.Hour))
+
+	recent := h.Recent([]string{"live"}, 2)
+	if len(recent) != 2 {
+		t.Fatalf("len(Recent()) = %d, want 2", len(recent))
+	}
+	if recent[0].Name != "newer" || recent[1].Name != "old" {
+		t.Errorf("Recent() = [%s %s], want [newer old]", recent[0].Name, recent[1].Name)
+	}
+
+	h.Remove("newer")
+	if _, ok := h.Get("newer"); ok {
+		t.Error("newer should be removed")
+	}
+}
@@ -0,0 +1,64 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lockFile is the name of the lock file in the state directory
+const lockFile = ".lock"
+
+// lock takes an exclusive lock on the state directory and returns the
+// function releasing it. With the picker bound in several clients, tsm
+// instances would otherwise interleave their read-modify-write cycles and
+// drop each other's changes. The kernel releases the lock if tsm dies.
+func lock(stateDir string) (func(), error) {
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(stateDir, lockFile), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to lock state directory: %w", err)
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		_ = f.Close()
+	}, nil
+}
+
+// update loads a state file, applies fn and saves the result, holding the
+// state directory lock throughout. Nothing is saved when fn fails. The other
+// instances wait while fn runs, so it should only change the value; slow
+// work like tmux calls goes before or after.
+func update[T any](stateDir string, load func(string) (T, error), save func(string, T) error, fn func(*T) error) error {
+	unlock, err := lock(stateDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	v, err := load(stateDir)
+	if err != nil {
+		return err
+	}
+	if err := fn(&v); err != nil {
+		return err
+	}
+	return save(stateDir, v)
+}
+
+// writeLocked writes a whole state file under the state directory lock
+func writeLocked(stateDir, name string, v any) error {
+	unlock, err := lock(stateDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeJSON(filepath.Join(stateDir, name), v)
+}
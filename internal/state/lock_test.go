@@ -0,0 +1,80 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUpdateHistoryConcurrent(t *testing.T) {
+	stateDir := t.TempDir()
+	now := time.Now()
+
+	// Unlocked, concurrent load-modify-save cycles would drop sessions
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := UpdateHistory(stateDir, func(h *History) error {
+				h.Touch(fmt.Sprintf("s%d", i), "/work", now)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("UpdateHistory() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	h, err := LoadHistory(stateDir)
+	if err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	if len(h.Sessions) != 20 {
+		t.Errorf("history has %d sessions, want 20", len(h.Sessions))
+	}
+}
+
+func TestUpdateFailureSavesNothing(t *testing.T) {
+	stateDir := t.TempDir()
+	failed := errors.New("failed")
+
+	err := UpdatePrefs(stateDir, func(p *Prefs) error {
+		p.RowDetail = RowDetailed
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("UpdatePrefs() error = %v, want %v", err, failed)
+	}
+
+	p, err := LoadPrefs(stateDir)
+	if err != nil {
+		t.Fatalf("LoadPrefs() error = %v", err)
+	}
+	if p.RowDetail != "" {
+		t.Errorf("RowDetail = %q, want nothing saved", p.RowDetail)
+	}
+}
+
+func TestSaveSnapshotWaitsForLock(t *testing.T) {
+	stateDir := t.TempDir()
+	unlock, err := lock(stateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := make(chan error)
+	go func() { saved <- SaveSnapshot(stateDir, Snapshot{SavedAt: time.Now()}) }()
+	select {
+	case <-saved:
+		t.Fatal("SaveSnapshot() wrote while another instance held the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	if err := <-saved; err != nil {
+		t.Errorf("SaveSnapshot() error = %v", err)
+	}
+}
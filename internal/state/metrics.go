@@ -0,0 +1,157 @@
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// metricsFile is the name of the event log in the state directory
+const metricsFile = "metrics.jsonl"
+
+// maxFocusGap caps the time credited to a session between two events, so
+// the night after the last switch of the day isn't counted as work
+const maxFocusGap = time.Hour
+
+// Logged actions (see Event.Action)
+const (
+	EventSwitch = "switch"
+	EventCreate = "create"
+	EventKill   = "kill"
+)
+
+// Event is a switch to, creation or kill of a session, logged with
+// metrics_log for `tsm stats`
+type Event struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Session string    `json:"session"`
+}
+
+// MetricsPath returns the path of the event log
+func MetricsPath(stateDir string) string {
+	return filepath.Join(stateDir, metricsFile)
+}
+
+// AppendEvent adds an event to the log. Appending keeps each write a single
+// line, so concurrent tsm instances need no lock.
+func AppendEvent(stateDir string, e Event) error {
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(MetricsPath(stateDir), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open metrics log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// LoadEvents reads the event log, oldest first. Lines that don't parse (e.g.
+// cut short by a full disk) are skipped. Returns no events if the log doesn't
+// exist.
+func LoadEvents(stateDir string) ([]Event, error) {
+	f, err := os.Open(MetricsPath(stateDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if json.Unmarshal(scanner.Bytes(), &e) == nil && e.Session != "" {
+			events = append(events, e)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events, scanner.Err()
+}
+
+// Usage is the time spent in a session and how often it was switched to
+type Usage struct {
+	Session  string
+	Time     time.Duration
+	Switches int
+}
+
+// Usages sums up the events between from and to, most time spent first. The
+// time after a switch counts towards the session switched to until the next
+// switch or its kill, but no longer than maxFocusGap past the latest event.
+func Usages(events []Event, from, to time.Time) []Usage {
+	bySession := make(map[string]*Usage)
+	get := func(name string) *Usage {
+		if bySession[name] == nil {
+			bySession[name] = &Usage{Session: name}
+		}
+		return bySession[name]
+	}
+
+	current := ""
+	var since time.Time
+	credit := func(until time.Time) {
+		if current == "" {
+			return
+		}
+		start, end := since, until
+		if start.Before(from) {
+			start = from
+		}
+		if capped := since.Add(maxFocusGap); capped.Before(end) {
+			end = capped
+		}
+		if to.Before(end) {
+			end = to
+		}
+		if end.After(start) {
+			get(current).Time += end.Sub(start)
+		}
+	}
+
+	for _, e := range events {
+		if e.Time.After(to) {
+			break
+		}
+		credit(e.Time)
+		since = e.Time
+		switch {
+		case e.Action == EventSwitch:
+			current = e.Session
+			if !e.Time.Before(from) {
+				get(e.Session).Switches++
+			}
+		case e.Action == EventKill && e.Session == current:
+			current = ""
+		}
+	}
+	credit(to)
+
+	usages := make([]Usage, 0, len(bySession))
+	for _, u := range bySession {
+		usages = append(usages, *u)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Time != usages[j].Time {
+			return usages[i].Time > usages[j].Time
+		}
+		if usages[i].Switches != usages[j].Switches {
+			return usages[i].Switches > usages[j].Switches
+		}
+		return usages[i].Session < usages[j].Session
+	})
+	return usages
+}
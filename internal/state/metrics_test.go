@@ -0,0 +1,65 @@
+package state
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEvents(t *testing.T) {
+	dir := t.TempDir()
+	if events, err := LoadEvents(dir); err != nil || events != nil {
+		t.Fatalf("LoadEvents() = %v, %v, want no events", events, err)
+	}
+
+	now := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	for _, e := range []Event{
+		{Time: now.Add(time.Minute), Action: EventSwitch, Session: "web"},
+		{Time: now, Action: EventCreate, Session: "web"},
+	} {
+		if err := AppendEvent(dir, e); err != nil {
+			t.Fatalf("AppendEvent() error = %v", err)
+		}
+	}
+	// A line cut short doesn't lose the others
+	f, _ := os.OpenFile(MetricsPath(dir), os.O_APPEND|os.O_WRONLY, 0644)
+	_, _ = f.WriteString(`{"time":"2026-10`)
+	_ = f.Close()
+
+	events, err := LoadEvents(dir)
+	if err != nil {
+		t.Fatalf("LoadEvents() error = %v", err)
+	}
+	if len(events) != 2 || events[0].Action != EventCreate || !events[0].Time.Equal(now) {
+		t.Errorf("LoadEvents() = %+v, want both events oldest first", events)
+	}
+}
+
+func TestUsages(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 10, 12, h, m, 0, 0, time.UTC) }
+	events := []Event{
+		{Time: at(8, 0), Action: EventSwitch, Session: "old"},
+		{Time: at(9, 0), Action: EventSwitch, Session: "api"},
+		{Time: at(9, 30), Action: EventCreate, Session: "web"},
+		{Time: at(10, 0), Action: EventSwitch, Session: "web"},
+		{Time: at(10, 20), Action: EventKill, Session: "web"},
+		{Time: at(11, 0), Action: EventSwitch, Session: "api"},
+	}
+
+	// api: 9:00-10:00 and 11:00 on, capped an hour past the last event;
+	// web: until its kill; old: only the part after from
+	got := Usages(events, at(8, 45), at(18, 0))
+	want := []Usage{
+		{Session: "api", Time: 2 * time.Hour, Switches: 2},
+		{Session: "web", Time: 20 * time.Minute, Switches: 1},
+		{Session: "old", Time: 15 * time.Minute},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Usages() = %+v, want %+v", got, want)
+	}
+
+	if got := Usages(events, at(10, 10), at(10, 15)); len(got) != 1 || got[0].Time != 5*time.Minute || got[0].Switches != 0 {
+		t.Errorf("Usages() = %+v, want 5m of web without its switch", got)
+	}
+}
@@ -0,0 +1,88 @@
+package state
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// notesExt is the file extension used for session notes files
+const notesExt = ".md"
+
+// NotesDir returns the directory holding per-session notes files
+func NotesDir(stateDir string) string {
+	return filepath.Join(stateDir, "notes")
+}
+
+// NotesPath returns the notes file path for a session.
+// Session names are escaped so any valid tmux name maps to a safe filename.
+func NotesPath(stateDir, sessionName string) string {
+	return filepath.Join(NotesDir(stateDir), url.PathEscape(sessionName)+notesExt)
+}
+
+// AppendNote appends a timestamped note to the session's notes file
+func AppendNote(stateDir, sessionName, text string, at time.Time) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("note is empty")
+	}
+
+	if err := os.MkdirAll(NotesDir(stateDir), 0755); err != nil {
+		return fmt.Errorf("failed to create notes directory: %w", err)
+	}
+
+	f, err := os.OpenFile(NotesPath(stateDir, sessionName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open notes file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.WriteString(formatNote(text, at)); err != nil {
+		return fmt.Errorf("failed to write note: %w", err)
+	}
+	return nil
+}
+
+// formatNote renders a note as a timestamped markdown section
+func formatNote(text string, at time.Time) string {
+	return fmt.Sprintf("## %s\n\n%s\n\n", at.Format("2006-01-02 15:04"), text)
+}
+
+// ReadNotes returns the notes for a session.
+// Returns an empty string if the session has no notes.
+func ReadNotes(stateDir, sessionName string) (string, error) {
+	content, err := os.ReadFile(NotesPath(stateDir, sessionName))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// NotedSessions returns the set of session names that have a notes file
+func NotedSessions(stateDir string) map[string]bool {
+	noted := make(map[string]bool)
+
+	entries, err := os.ReadDir(NotesDir(stateDir))
+	if err != nil {
+		return noted
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), notesExt) {
+			continue
+		}
+		name, err := url.PathUnescape(strings.TrimSuffix(entry.Name(), notesExt))
+		if err != nil {
+			continue
+		}
+		noted[name] = true
+	}
+
+	return noted
+}
@@ -0,0 +1,66 @@
+package state
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendAndReadNotes(t *testing.T) {
+	stateDir := t.TempDir()
+	at := time.Date(2025, 1, 6, 14, 30, 0, 0, time.Local)
+
+	if err := AppendNote(stateDir, "api", "first note", at); err != nil {
+		t.Fatalf("AppendNote() error = %v", err)
+	}
+	if err := AppendNote(stateDir, "api", "  second\nline  ", at.Add(time.Hour)); err != nil {
+		t.Fatalf("AppendNote() error = %v", err)
+	}
+
+	notes, err := ReadNotes(stateDir, "api")
+	if err != nil {
+		t.Fatalf("ReadNotes() error = %v", err)
+	}
+
+	want := "## 2025-01-06 14:30\n\nfirst note\n\n## 2025-01-06 15:30\n\nsecond\nline\n\n"
+	if notes != want {
+		t.Errorf("ReadNotes() = %q, want %q", notes, want)
+	}
+}
+
+func TestAppendNoteEmpty(t *testing.T) {
+	stateDir := t.TempDir()
+
+	if err := AppendNote(stateDir, "api", "   \n", time.Now()); err == nil {
+		t.Error("AppendNote() with blank text should return an error")
+	}
+}
+
+func TestReadNotesMissing(t *testing.T) {
+	notes, err := ReadNotes(t.TempDir(), "missing")
+	if err != nil {
+		t.Fatalf("ReadNotes() error = %v", err)
+	}
+	if notes != "" {
+		t.Errorf("ReadNotes() = %q, want empty", notes)
+	}
+}
+
+func TestNotedSessions(t *testing.T) {
+	stateDir := t.TempDir()
+
+	for _, name := range []string{"api", "owner/repo"} {
+		if err := AppendNote(stateDir, name, "note", time.Now()); err != nil {
+			t.Fatalf("AppendNote(%q) error = %v", name, err)
+		}
+	}
+
+	noted := NotedSessions(stateDir)
+	if len(noted) != 2 || !noted["api"] || !noted["owner/repo"] {
+		t.Errorf("NotedSessions() = %v, want api and owner/repo", noted)
+	}
+
+	if !strings.HasSuffix(NotesPath(stateDir, "owner/repo"), "owner%2Frepo.md") {
+		t.Errorf("NotesPath() = %q, should escape slashes", NotesPath(stateDir, "owner/repo"))
+	}
+}
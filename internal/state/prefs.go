@@ -0,0 +1,50 @@
+package state
+
+import "path/filepath"
+
+// prefsFile is the name of the preferences file in the state directory
+const prefsFile = "prefs.json"
+
+// Row detail levels (see Prefs.RowDetail)
+const (
+	RowCompact  = "compact"  // Number and name only
+	RowDetailed = "detailed" // Every column
+)
+
+// Prefs are settings changed from within the picker that outlive it
+type Prefs struct {
+	// Session row detail overriding the size profile's columns ("" = the profile decides)
+	RowDetail string `json:"row_detail,omitempty"`
+
+	// Session names in the order arranged with M-j/M-k, used by sort = "manual"
+	Order []string `json:"order,omitempty"`
+}
+
+// LoadPrefs reads the preferences from the state directory.
+// Returns empty preferences if the file doesn't exist.
+func LoadPrefs(stateDir string) (Prefs, error) {
+	var p Prefs
+	if err := readJSON(filepath.Join(stateDir, prefsFile), &p); err != nil {
+		return Prefs{}, err
+	}
+	return p, nil
+}
+
+// SavePrefs writes the preferences to the state directory
+func SavePrefs(stateDir string, p Prefs) error {
+	return writeJSON(filepath.Join(stateDir, prefsFile), p)
+}
+
+// UpdatePrefs applies fn to the preferences and saves them (see update)
+func UpdatePrefs(stateDir string, fn func(*Prefs) error) error {
+	return update(stateDir, LoadPrefs, SavePrefs, fn)
+}
+
+// RenameSession keeps a renamed session's place in the manual order
+func (p *Prefs) RenameSession(from, to string) {
+	for i, name := range p.Order {
+		if name == from {
+			p.Order[i] = to
+		}
+	}
+}
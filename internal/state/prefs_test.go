@@ -0,0 +1,39 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrefs(t *testing.T) {
+	dir := t.TempDir()
+
+	p, err := LoadPrefs(dir)
+	if err != nil || !reflect.DeepEqual(p, Prefs{}) {
+		t.Fatalf("LoadPrefs() = %+v, %v, want empty preferences", p, err)
+	}
+
+	if err := SavePrefs(dir, Prefs{RowDetail: RowCompact}); err != nil {
+		t.Fatalf("SavePrefs() error = %v", err)
+	}
+	if p, _ := LoadPrefs(dir); p.RowDetail != RowCompact {
+		t.Errorf("RowDetail = %q, want %q", p.RowDetail, RowCompact)
+	}
+
+	err = UpdatePrefs(dir, func(p *Prefs) error {
+		p.Order = []string{"web", "api"}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdatePrefs() error = %v", err)
+	}
+	p, _ = LoadPrefs(dir)
+	if p.RowDetail != RowCompact || !reflect.DeepEqual(p.Order, []string{"web", "api"}) {
+		t.Errorf("LoadPrefs() = %+v, want the row detail kept and the order saved", p)
+	}
+
+	p.RenameSession("api", "backend")
+	if !reflect.DeepEqual(p.Order, []string{"web", "backend"}) {
+		t.Errorf("Order = %v, want api renamed in place", p.Order)
+	}
+}
@@ -0,0 +1,40 @@
+package state
+
+import (
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// projectsFile is the name of the project directory cache in the state directory
+const projectsFile = "projects.json"
+
+// ProjectCache is the last scan of the project directories, shown right away
+// when project mode opens while a fresh scan runs in the background
+type ProjectCache struct {
+	Roots     []string  `json:"roots"` // project_dirs the scan walked
+	Depth     int       `json:"depth"` // project_depth the scan walked to
+	Dirs      []string  `json:"dirs"`
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+// Matches reports whether the cache was scanned with the given project_dirs
+// and project_depth, so a config change isn't answered with stale results
+func (c ProjectCache) Matches(roots []string, depth int) bool {
+	return !c.ScannedAt.IsZero() && c.Depth == depth && slices.Equal(c.Roots, roots)
+}
+
+// LoadProjectCache reads the project directory cache from the state
+// directory. Returns an empty cache if the file doesn't exist.
+func LoadProjectCache(stateDir string) (ProjectCache, error) {
+	var c ProjectCache
+	if err := readJSON(filepath.Join(stateDir, projectsFile), &c); err != nil {
+		return ProjectCache{}, err
+	}
+	return c, nil
+}
+
+// SaveProjectCache writes the project directory cache to the state directory
+func SaveProjectCache(stateDir string, c ProjectCache) error {
+	return writeLocked(stateDir, projectsFile, c)
+}
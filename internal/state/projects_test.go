@@ -0,0 +1,27 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProjectCache(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := LoadProjectCache(dir)
+	if err != nil || c.Matches(nil, 0) {
+		t.Fatalf("LoadProjectCache() = %+v, %v, want an empty cache matching nothing", c, err)
+	}
+
+	saved := ProjectCache{Roots: []string{"/repos"}, Depth: 2, Dirs: []string{"/repos/acme/api"}, ScannedAt: time.Now()}
+	if err := SaveProjectCache(dir, saved); err != nil {
+		t.Fatalf("SaveProjectCache() error = %v", err)
+	}
+	c, _ = LoadProjectCache(dir)
+	if len(c.Dirs) != 1 || !c.Matches([]string{"/repos"}, 2) {
+		t.Errorf("LoadProjectCache() = %+v, want the saved scan", c)
+	}
+	if c.Matches([]string{"/repos"}, 3) || c.Matches([]string{"/repos", "/work"}, 2) {
+		t.Error("cache should not match another project_depth or project_dirs")
+	}
+}
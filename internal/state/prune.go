@@ -0,0 +1,63 @@
+package state
+
+import (
+	"os"
+	"slices"
+	"time"
+)
+
+// PruneClosed removes the tags and notes of sessions closed for longer than
+// ttl, returning the sessions it cleaned up. A session counts as closed when
+// the history saw it but it isn't live; archived sessions keep theirs, they
+// are meant to come back. Since the history only tracks the default server,
+// sessions on other servers are never pruned.
+func PruneClosed(stateDir string, live []string, ttl time.Duration, now time.Time) ([]string, error) {
+	history, err := LoadHistory(stateDir)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := LoadArchive(stateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	archived := archive.Names()
+	closed := make(map[string]bool)
+	for _, e := range history.Sessions {
+		if now.Sub(e.LastSeen) > ttl && !slices.Contains(live, e.Name) && !slices.Contains(archived, e.Name) {
+			closed[e.Name] = true
+		}
+	}
+	if len(closed) == 0 {
+		return nil, nil
+	}
+
+	pruned := make(map[string]bool)
+	err = UpdateTags(stateDir, func(tags *Tags) error {
+		for name := range *tags {
+			if closed[name] {
+				delete(*tags, name)
+				pruned[name] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for name := range NotedSessions(stateDir) {
+		if closed[name] {
+			if err := os.Remove(NotesPath(stateDir, name)); err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			pruned[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(pruned))
+	for name := range pruned {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
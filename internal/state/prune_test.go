@@ -0,0 +1,56 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPruneClosed(t *testing.T) {
+	stateDir := t.TempDir()
+	now := time.Now()
+	ttl := 24 * time.Hour
+
+	h := History{}
+	h.Touch("live", "/work/live", now.Add(-48*time.Hour))
+	h.Touch("old", "/work/old", now.Add(-48*time.Hour))
+	h.Touch("recent", "/work/recent", now.Add(-time.Hour))
+	h.Touch("archived", "/work/archived", now.Add(-48*time.Hour))
+	if err := SaveHistory(stateDir, h); err != nil {
+		t.Fatalf("SaveHistory() error = %v", err)
+	}
+	a := Archive{}
+	a.Add(ArchivedSession{Name: "archived", ArchivedAt: now})
+	if err := SaveArchive(stateDir, a); err != nil {
+		t.Fatalf("SaveArchive() error = %v", err)
+	}
+
+	tags := Tags{}
+	for _, name := range []string{"live", "old", "recent", "archived", "other-server"} {
+		tags.Set(name, []string{"work"})
+		if err := AppendNote(stateDir, name, "note", now); err != nil {
+			t.Fatalf("AppendNote() error = %v", err)
+		}
+	}
+	if err := SaveTags(stateDir, tags); err != nil {
+		t.Fatalf("SaveTags() error = %v", err)
+	}
+
+	pruned, err := PruneClosed(stateDir, []string{"live"}, ttl, now)
+	if err != nil {
+		t.Fatalf("PruneClosed() error = %v", err)
+	}
+	if !reflect.DeepEqual(pruned, []string{"old"}) {
+		t.Errorf("PruneClosed() = %v, want [old]", pruned)
+	}
+
+	// Live, recently closed, archived and never seen sessions keep theirs
+	want := map[string]bool{"live": true, "recent": true, "archived": true, "other-server": true}
+	if got := NotedSessions(stateDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("NotedSessions() = %v, want %v", got, want)
+	}
+	loaded, _ := LoadTags(stateDir)
+	if _, ok := loaded["old"]; ok || len(loaded) != len(want) {
+		t.Errorf("tags = %v, want only old's removed", loaded)
+	}
+}
@@ -0,0 +1,94 @@
+package state
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Searched fields, in the order a session's matches are listed
+const (
+	FieldName = "name"
+	FieldTag  = "tag"
+	FieldPath = "path"
+	FieldNote = "note"
+)
+
+// fieldScores rank the fields a match was found in: a name says more about a
+// session than a word somewhere in its notes
+var fieldScores = map[string]int{
+	FieldName: 8,
+	FieldTag:  4,
+	FieldPath: 2,
+	FieldNote: 1,
+}
+
+// SearchEntry is what tsm grep searches of a session
+type SearchEntry struct {
+	Session string
+	Path    string
+	Tags    []string
+	Notes   string
+}
+
+// SearchMatch is a field of a session containing the searched text
+type SearchMatch struct {
+	Session string
+	Field   string
+	Text    string // The matching tag, path or note line
+}
+
+// SearchResult lists a session's matches, best session first
+type SearchResult struct {
+	Session string
+	Score   int
+	Matches []SearchMatch
+}
+
+// Search finds the text in the sessions' names, tags, paths and notes. Like
+// the picker's smart-case filter it ignores case unless the text has an
+// uppercase letter. A session scores each field it matches in, an exact name
+// twice, so the best match is the first result.
+func Search(entries []SearchEntry, text string) []SearchResult {
+	contains := func(s string) bool { return strings.Contains(strings.ToLower(s), strings.ToLower(text)) }
+	if strings.IndexFunc(text, unicode.IsUpper) >= 0 {
+		contains = func(s string) bool { return strings.Contains(s, text) }
+	}
+
+	var results []SearchResult
+	for _, e := range entries {
+		r := SearchResult{Session: e.Session}
+		add := func(field, s string) {
+			r.Matches = append(r.Matches, SearchMatch{Session: e.Session, Field: field, Text: s})
+			r.Score += fieldScores[field]
+		}
+
+		if contains(e.Session) {
+			add(FieldName, e.Session)
+			if e.Session == text {
+				r.Score += fieldScores[FieldName]
+			}
+		}
+		for _, tag := range e.Tags {
+			if contains(tag) {
+				add(FieldTag, tag)
+			}
+		}
+		if e.Path != "" && contains(e.Path) {
+			add(FieldPath, e.Path)
+		}
+		for _, line := range strings.Split(e.Notes, "\n") {
+			// Skip the timestamp headings formatNote writes
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "## ") && contains(line) {
+				add(FieldNote, line)
+			}
+		}
+
+		if len(r.Matches) > 0 {
+			results = append(results, r)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results
+}
@@ -0,0 +1,31 @@
+package state
+
+import "testing"
+
+func TestSearch(t *testing.T) {
+	entries := []SearchEntry{
+		{Session: "web", Path: "/code/web", Notes: "## 2026-10-01 09:00\n\nDeploy the api proxy\n\n"},
+		{Session: "api", Path: "/code/api", Tags: []string{"work"}},
+		{Session: "apidocs", Path: "/code/docs"},
+		{Session: "home", Path: "/home/me"},
+	}
+
+	results := Search(entries, "api")
+	if len(results) != 3 {
+		t.Fatalf("Search() = %+v, want api, apidocs and web", results)
+	}
+	// Exact name and path beat a name prefix, which beats a note
+	if results[0].Session != "api" || results[1].Session != "apidocs" || results[2].Session != "web" {
+		t.Errorf("Search() order = %s, %s, %s, want api, apidocs, web", results[0].Session, results[1].Session, results[2].Session)
+	}
+	if m := results[2].Matches; len(m) != 1 || m[0].Field != FieldNote || m[0].Text != "Deploy the api proxy" {
+		t.Errorf("web matches = %+v, want the note line", m)
+	}
+
+	if got := Search(entries, "WORK"); len(got) != 0 {
+		t.Errorf("Search(\"WORK\") = %+v, want none, uppercase matches case", got)
+	}
+	if got := Search(entries, "2026"); len(got) != 0 {
+		t.Errorf("Search(\"2026\") = %+v, want the note headings skipped", got)
+	}
+}
@@ -0,0 +1,190 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// snapshotFile is the name of the saved server snapshot in the state directory
+const snapshotFile = "snapshot.json"
+
+// SessionSnapshot records what it takes to recreate a session
+type SessionSnapshot struct {
+	Name    string                `json:"name"`
+	Path    string                `json:"path"`
+	Windows []tmux.WindowSnapshot `json:"windows"`
+}
+
+// Snapshot is the saved state of every session (tsm save), which tsm restore
+// recreates
+type Snapshot struct {
+	SavedAt  time.Time         `json:"saved_at"`
+	Sessions []SessionSnapshot `json:"sessions"`
+}
+
+// LoadSnapshot reads the saved snapshot from the state directory. Returns an
+// empty snapshot (zero SavedAt) if none was saved.
+func LoadSnapshot(stateDir string) (Snapshot, error) {
+	var s Snapshot
+	if err := readJSON(filepath.Join(stateDir, snapshotFile), &s); err != nil {
+		return Snapshot{}, err
+	}
+	return s, nil
+}
+
+// SaveSnapshot writes the snapshot to the state directory
+func SaveSnapshot(stateDir string, s Snapshot) error {
+	return writeLocked(stateDir, snapshotFile, s)
+}
+
+// CaptureSessions snapshots the live sessions, most recently active first
+func CaptureSessions() ([]SessionSnapshot, error) {
+	sessions, err := tmux.ListSessions("")
+	if err != nil {
+		return nil, err
+	}
+	windows, err := tmux.SnapshotAll()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]SessionSnapshot, len(sessions))
+	for i, session := range sessions {
+		snapshots[i] = SessionSnapshot{Name: session.Name, Path: session.Path, Windows: windows[session.Name]}
+	}
+	return snapshots, nil
+}
+
+// Session change kinds between a snapshot and the live sessions
+const (
+	DiffMissing = "missing" // Saved but not running: restore recreates it
+	DiffExtra   = "extra"   // Running but not saved: restore leaves it alone
+	DiffChanged = "changed" // Both, with different windows
+)
+
+// SessionDiff is how a live session differs from its saved snapshot
+type SessionDiff struct {
+	Name string
+	Kind string // See the Diff constants
+
+	// Windows only saved / only live (see WindowLabel), for changed sessions
+	RemovedWindows []string
+	AddedWindows   []string
+
+	// Saved windows, for missing sessions
+	Windows int
+}
+
+// Diff compares the snapshot to the live sessions: saved sessions first in
+// their saved order, then the live-only ones
+func (s Snapshot) Diff(live []SessionSnapshot) []SessionDiff {
+	liveByName := make(map[string]SessionSnapshot, len(live))
+	for _, l := range live {
+		liveByName[l.Name] = l
+	}
+	saved := make(map[string]bool, len(s.Sessions))
+
+	var diffs []SessionDiff
+	for _, session := range s.Sessions {
+		saved[session.Name] = true
+		l, ok := liveByName[session.Name]
+		if !ok {
+			diffs = append(diffs, SessionDiff{Name: session.Name, Kind: DiffMissing, Windows: len(session.Windows)})
+			continue
+		}
+		removed, added := windowChanges(session.Windows, l.Windows)
+		if len(removed) > 0 || len(added) > 0 {
+			diffs = append(diffs, SessionDiff{Name: session.Name, Kind: DiffChanged, RemovedWindows: removed, AddedWindows: added})
+		}
+	}
+	for _, l := range live {
+		if !saved[l.Name] {
+			diffs = append(diffs, SessionDiff{Name: l.Name, Kind: DiffExtra, Windows: len(l.Windows)})
+		}
+	}
+	return diffs
+}
+
+// WindowLabel identifies a window across snapshots: its name, or the
+// directory of its first pane when tmux names it automatically
+func WindowLabel(w tmux.WindowSnapshot) string {
+	if w.Name != "" || len(w.Panes) == 0 {
+		return w.Name
+	}
+	return w.Panes[0]
+}
+
+// windowChanges returns the labels of the windows only saved and only live,
+// counting duplicates
+func windowChanges(saved, live []tmux.WindowSnapshot) (removed, added []string) {
+	counts := make(map[string]int)
+	for _, w := range live {
+		counts[WindowLabel(w)]++
+	}
+	for _, w := range saved {
+		label := WindowLabel(w)
+		if counts[label] > 0 {
+			counts[label]--
+		} else {
+			removed = append(removed, label)
+		}
+	}
+	for _, w := range live {
+		label := WindowLabel(w)
+		if counts[label] > 0 {
+			counts[label]--
+			added = append(added, label)
+		}
+	}
+	return removed, added
+}
+
+// String describes the difference as a line of tsm diff
+func (d SessionDiff) String() string {
+	switch d.Kind {
+	case DiffMissing:
+		return fmt.Sprintf("+ %s: not running, restore recreates it (%s)", d.Name, countWindows(d.Windows))
+	case DiffExtra:
+		return fmt.Sprintf("  %s: not saved, restore leaves it (%s)", d.Name, countWindows(d.Windows))
+	}
+	changes := make([]string, 0, len(d.RemovedWindows)+len(d.AddedWindows))
+	for _, w := range d.RemovedWindows {
+		changes = append(changes, "-"+w)
+	}
+	for _, w := range d.AddedWindows {
+		changes = append(changes, "+"+w)
+	}
+	return fmt.Sprintf("~ %s: windows %s", d.Name, strings.Join(changes, " "))
+}
+
+func countWindows(n int) string {
+	if n == 1 {
+		return "1 window"
+	}
+	return fmt.Sprintf("%d windows", n)
+}
+
+// RestorableWindows prepares saved windows for tmux.RestoreSession: pane
+// directories that are gone fall back to fallbackDir, and a session without
+// windows gets a single one there
+func RestorableWindows(windows []tmux.WindowSnapshot, fallbackDir string) []tmux.WindowSnapshot {
+	if len(windows) == 0 {
+		return []tmux.WindowSnapshot{{Panes: []string{fallbackDir}}}
+	}
+	restorable := make([]tmux.WindowSnapshot, len(windows))
+	for i, w := range windows {
+		w.Panes = append([]string(nil), w.Panes...)
+		for j, dir := range w.Panes {
+			if _, err := os.Stat(dir); dir == "" || err != nil {
+				w.Panes[j] = fallbackDir
+			}
+		}
+		restorable[i] = w
+	}
+	return restorable
+}
@@ -0,0 +1,75 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	stateDir := t.TempDir()
+
+	s, err := LoadSnapshot(stateDir)
+	if err != nil || !s.SavedAt.IsZero() {
+		t.Fatalf("LoadSnapshot() on empty dir = %+v, %v, want an empty snapshot", s, err)
+	}
+
+	s = Snapshot{
+		SavedAt:  time.Now().Truncate(time.Second),
+		Sessions: []SessionSnapshot{{Name: "api", Path: "/work/api", Windows: []tmux.WindowSnapshot{{Name: "logs", Panes: []string{"/var/log"}}}}},
+	}
+	if err := SaveSnapshot(stateDir, s); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+	loaded, err := LoadSnapshot(stateDir)
+	if err != nil || !loaded.SavedAt.Equal(s.SavedAt) || !reflect.DeepEqual(loaded.Sessions, s.Sessions) {
+		t.Errorf("LoadSnapshot() = %+v, %v, want %+v", loaded, err, s)
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	shell := tmux.WindowSnapshot{Panes: []string{"/work/api"}}
+	logs := tmux.WindowSnapshot{Name: "logs", Panes: []string{"/var/log"}}
+	server := tmux.WindowSnapshot{Name: "server", Panes: []string{"/work/api"}}
+
+	saved := Snapshot{Sessions: []SessionSnapshot{
+		{Name: "api", Windows: []tmux.WindowSnapshot{shell, logs, logs}},
+		{Name: "docs", Windows: []tmux.WindowSnapshot{shell, shell}},
+		{Name: "web", Windows: []tmux.WindowSnapshot{server}},
+	}}
+	live := []SessionSnapshot{
+		{Name: "scratch", Windows: []tmux.WindowSnapshot{shell}},
+		{Name: "api", Windows: []tmux.WindowSnapshot{logs, server, shell}},
+		{Name: "web", Windows: []tmux.WindowSnapshot{server}},
+	}
+
+	want := []SessionDiff{
+		{Name: "api", Kind: DiffChanged, RemovedWindows: []string{"logs"}, AddedWindows: []string{"server"}},
+		{Name: "docs", Kind: DiffMissing, Windows: 2},
+		{Name: "scratch", Kind: DiffExtra, Windows: 1},
+	}
+	if got := saved.Diff(live); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v, want %+v", got, want)
+	}
+	if got := want[0].String(); got != "~ api: windows -logs +server" {
+		t.Errorf("String() = %q, want the window changes", got)
+	}
+}
+
+func TestRestorableWindows(t *testing.T) {
+	existing := t.TempDir()
+	windows := []tmux.WindowSnapshot{{Name: "logs", Panes: []string{existing, "/gone/for/good"}}}
+
+	got := RestorableWindows(windows, "/home")
+	if want := []string{existing, "/home"}; !reflect.DeepEqual(got[0].Panes, want) {
+		t.Errorf("panes = %v, want %v", got[0].Panes, want)
+	}
+	if windows[0].Panes[1] != "/gone/for/good" {
+		t.Error("RestorableWindows() should not modify the saved windows")
+	}
+	if got := RestorableWindows(nil, "/home"); len(got) != 1 || got[0].Panes[0] != "/home" {
+		t.Errorf("RestorableWindows(nil) = %+v, want one window in /home", got)
+	}
+}
@@ -0,0 +1,137 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nikbrunner/tsm/internal/config"
+	"github.com/nikbrunner/tsm/internal/tmux"
+)
+
+// Session user options used by the tmux backend
+const (
+	tagsOption  = "@tsm_tags"
+	notesOption = "@tsm_notes"
+)
+
+// Store persists per-session metadata (notes and tags). The history of
+// closed sessions always stays in the state directory since it has to
+// outlive the sessions.
+type Store interface {
+	LoadTags() (Tags, error)
+	SetTags(session string, tags []string) error
+	AppendNote(session, text string, at time.Time) error
+	ReadNotes(session string) (string, error)
+	NotedSessions() map[string]bool
+	RenameSession(from, to string) error
+}
+
+// NewStore returns the store for a backend (see config.Backends)
+func NewStore(backend, stateDir string) Store {
+	if backend == config.BackendTmux {
+		return TmuxStore{}
+	}
+	return FileStore{Dir: stateDir}
+}
+
+// FileStore keeps metadata in the state directory
+type FileStore struct {
+	Dir string
+}
+
+func (s FileStore) LoadTags() (Tags, error) {
+	return LoadTags(s.Dir)
+}
+
+func (s FileStore) SetTags(session string, tags []string) error {
+	return UpdateTags(s.Dir, func(all *Tags) error {
+		all.Set(session, tags)
+		return nil
+	})
+}
+
+func (s FileStore) AppendNote(session, text string, at time.Time) error {
+	return AppendNote(s.Dir, session, text, at)
+}
+
+func (s FileStore) ReadNotes(session string) (string, error) {
+	return ReadNotes(s.Dir, session)
+}
+
+func (s FileStore) NotedSessions() map[string]bool {
+	return NotedSessions(s.Dir)
+}
+
+// RenameSession moves the tags and notes of a renamed session to its new name
+func (s FileStore) RenameSession(from, to string) error {
+	err := UpdateTags(s.Dir, func(all *Tags) error {
+		if tags, ok := (*all)[from]; ok {
+			delete(*all, from)
+			all.Set(to, tags)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(NotesPath(s.Dir, from), NotesPath(s.Dir, to))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to move notes: %w", err)
+	}
+	return nil
+}
+
+// TmuxStore keeps metadata in user options on each session, so it is shared
+// by every tsm client of the server and removed along with the session
+type TmuxStore struct{}
+
+func (TmuxStore) LoadTags() (Tags, error) {
+	values, err := tmux.ListSessionOption(tagsOption)
+	if err != nil {
+		return Tags{}, err
+	}
+	tags := Tags{}
+	for session, value := range values {
+		tags.Set(session, ParseTags(value))
+	}
+	return tags, nil
+}
+
+func (TmuxStore) SetTags(session string, tags []string) error {
+	return tmux.SetSessionOption(session, tagsOption, strings.Join(tags, " "))
+}
+
+func (TmuxStore) AppendNote(session, text string, at time.Time) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("note is empty")
+	}
+	notes, err := tmux.SessionOption(session, notesOption)
+	if err != nil {
+		return fmt.Errorf("failed to read notes: %w", err)
+	}
+	if notes != "" {
+		notes = strings.TrimRight(notes, "\n") + "\n\n"
+	}
+	return tmux.SetSessionOption(session, notesOption, notes+formatNote(text, at))
+}
+
+func (TmuxStore) ReadNotes(session string) (string, error) {
+	return tmux.SessionOption(session, notesOption)
+}
+
+// RenameSession does nothing: the options stay on the renamed session
+func (TmuxStore) RenameSession(from, to string) error {
+	return nil
+}
+
+func (TmuxStore) NotedSessions() map[string]bool {
+	noted, err := tmux.ListSessionsWithOption(notesOption)
+	if err != nil {
+		return map[string]bool{}
+	}
+	return noted
+}
@@ -0,0 +1,44 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nikbrunner/tsm/internal/config"
+)
+
+func TestFileStore(t *testing.T) {
+	s := NewStore(config.BackendFile, t.TempDir())
+
+	if err := s.SetTags("api", []string{"work"}); err != nil {
+		t.Fatalf("SetTags() error = %v", err)
+	}
+	if err := s.SetTags("web", []string{"exp"}); err != nil {
+		t.Fatalf("SetTags() error = %v", err)
+	}
+	tags, err := s.LoadTags()
+	if err != nil || !tags.Has("api", "work") || !tags.Has("web", "exp") {
+		t.Errorf("LoadTags() = %v, %v, want both sessions tagged", tags, err)
+	}
+
+	if err := s.AppendNote("api", "check logs", time.Now()); err != nil {
+		t.Fatalf("AppendNote() error = %v", err)
+	}
+	if noted := s.NotedSessions(); !noted["api"] || noted["web"] {
+		t.Errorf("NotedSessions() = %v, want only api", noted)
+	}
+
+	if err := s.RenameSession("api", "work-api"); err != nil {
+		t.Fatalf("RenameSession() error = %v", err)
+	}
+	tags, _ = s.LoadTags()
+	if !tags.Has("work-api", "work") || len(tags["api"]) > 0 {
+		t.Errorf("tags after rename = %v, want api's tags on work-api", tags)
+	}
+	if noted := s.NotedSessions(); !noted["work-api"] || noted["api"] {
+		t.Errorf("NotedSessions() after rename = %v, want only work-api", noted)
+	}
+	if err := s.RenameSession("web", "web2"); err != nil {
+		t.Errorf("RenameSession() without notes error = %v", err)
+	}
+}
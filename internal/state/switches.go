@@ -0,0 +1,79 @@
+package state
+
+import "path/filepath"
+
+// switchesFile is the name of the switch history file in the state directory
+const switchesFile = "switches.json"
+
+// maxSwitchHistory is the number of visited sessions remembered
+const maxSwitchHistory = 50
+
+// SwitchHistory is the stack of sessions visited through tsm, oldest first.
+// Pos is the entry last switched to; going back and forward moves it like a
+// browser history, and a new switch drops the entries after it.
+type SwitchHistory struct {
+	Sessions []string `json:"sessions"`
+	Pos      int      `json:"pos"`
+}
+
+// LoadSwitchHistory reads the switch history from the state directory.
+// Returns an empty history if the file doesn't exist.
+func LoadSwitchHistory(stateDir string) (SwitchHistory, error) {
+	var h SwitchHistory
+	if err := readJSON(filepath.Join(stateDir, switchesFile), &h); err != nil {
+		return SwitchHistory{}, err
+	}
+	if h.Pos < 0 || h.Pos >= len(h.Sessions) {
+		h.Pos = len(h.Sessions) - 1
+	}
+	return h, nil
+}
+
+// SaveSwitchHistory writes the switch history to the state directory
+func SaveSwitchHistory(stateDir string, h SwitchHistory) error {
+	return writeJSON(filepath.Join(stateDir, switchesFile), h)
+}
+
+// UpdateSwitchHistory applies fn to the switch history and saves it (see update)
+func UpdateSwitchHistory(stateDir string, fn func(*SwitchHistory) error) error {
+	return update(stateDir, LoadSwitchHistory, SaveSwitchHistory, fn)
+}
+
+// Visit records a switch from one session to another
+func (h *SwitchHistory) Visit(from, to string) {
+	if len(h.Sessions) > 0 {
+		h.Sessions = h.Sessions[:h.Pos+1]
+	}
+	// The switch may start from a session reached outside of tsm
+	if from != "" && from != to && (len(h.Sessions) == 0 || h.Sessions[len(h.Sessions)-1] != from) {
+		h.Sessions = append(h.Sessions, from)
+	}
+	if len(h.Sessions) == 0 || h.Sessions[len(h.Sessions)-1] != to {
+		h.Sessions = append(h.Sessions, to)
+	}
+	if len(h.Sessions) > maxSwitchHistory {
+		h.Sessions = h.Sessions[len(h.Sessions)-maxSwitchHistory:]
+	}
+	h.Pos = len(h.Sessions) - 1
+}
+
+// RenameSession replaces the entries of a renamed session
+func (h *SwitchHistory) RenameSession(from, to string) {
+	for i, name := range h.Sessions {
+		if name == from {
+			h.Sessions[i] = to
+		}
+	}
+}
+
+// Step moves back (delta -1) or forward (delta 1) to the nearest session
+// that still exists and isn't the current one
+func (h *SwitchHistory) Step(delta int, current string, alive func(string) bool) (string, bool) {
+	for i := h.Pos + delta; i >= 0 && i < len(h.Sessions); i += delta {
+		if name := h.Sessions[i]; name != current && alive(name) {
+			h.Pos = i
+			return name, true
+		}
+	}
+	return "", false
+}
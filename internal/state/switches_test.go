@@ -0,0 +1,57 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSwitchHistory(t *testing.T) {
+	var h SwitchHistory
+	h.Visit("home", "api")
+	h.Visit("api", "web")
+	if fmt.Sprint(h.Sessions) != "[home api web]" || h.Pos != 2 {
+		t.Fatalf("history = %v at %d, want [home api web] at 2", h.Sessions, h.Pos)
+	}
+
+	alive := func(name string) bool { return name != "api" }
+
+	// Back skips the dead session, forward returns
+	if name, ok := h.Step(-1, "web", alive); !ok || name != "home" {
+		t.Errorf("Step(-1) = %q, %v, want home", name, ok)
+	}
+	if name, ok := h.Step(1, "home", alive); !ok || name != "web" {
+		t.Errorf("Step(1) = %q, %v, want web", name, ok)
+	}
+	if _, ok := h.Step(1, "web", alive); ok {
+		t.Error("Step(1) at the newest entry should fail")
+	}
+
+	// A new switch after going back drops the forward entries
+	h.Step(-1, "web", alive)
+	h.Visit("home", "docs")
+	if fmt.Sprint(h.Sessions) != "[home docs]" || h.Pos != 1 {
+		t.Errorf("history = %v at %d, want [home docs] at 1", h.Sessions, h.Pos)
+	}
+
+	h.RenameSession("docs", "wiki")
+	if fmt.Sprint(h.Sessions) != "[home wiki]" {
+		t.Errorf("history = %v, want docs renamed to wiki", h.Sessions)
+	}
+}
+
+func TestSwitchHistoryRoundTrip(t *testing.T) {
+	stateDir := t.TempDir()
+
+	h, err := LoadSwitchHistory(stateDir)
+	if err != nil || len(h.Sessions) != 0 {
+		t.Fatalf("LoadSwitchHistory() on empty dir = %v, %v", h, err)
+	}
+
+	h.Visit("home", "api")
+	if err := SaveSwitchHistory(stateDir, h); err != nil {
+		t.Fatalf("SaveSwitchHistory() error = %v", err)
+	}
+	if got, _ := LoadSwitchHistory(stateDir); fmt.Sprint(got) != fmt.Sprint(h) {
+		t.Errorf("LoadSwitchHistory() = %v, want %v", got, h)
+	}
+}
@@ -0,0 +1,72 @@
+package state
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// tagsFile is the name of the session tags file in the state directory
+const tagsFile = "tags.json"
+
+// Tags maps session names to their tags
+type Tags map[string][]string
+
+// tagsFileFormat is the on-disk format of the session tags
+type tagsFileFormat struct {
+	Sessions Tags `json:"sessions"`
+}
+
+// LoadTags reads session tags from the state directory.
+// Returns empty tags if the file doesn't exist.
+func LoadTags(stateDir string) (Tags, error) {
+	var f tagsFileFormat
+	if err := readJSON(filepath.Join(stateDir, tagsFile), &f); err != nil {
+		return Tags{}, err
+	}
+	if f.Sessions == nil {
+		return Tags{}, nil
+	}
+	return f.Sessions, nil
+}
+
+// SaveTags writes session tags to the state directory
+func SaveTags(stateDir string, tags Tags) error {
+	return writeJSON(filepath.Join(stateDir, tagsFile), tagsFileFormat{Sessions: tags})
+}
+
+// UpdateTags applies fn to the session tags and saves them (see update)
+func UpdateTags(stateDir string, fn func(*Tags) error) error {
+	return update(stateDir, LoadTags, SaveTags, fn)
+}
+
+// Set replaces a session's tags. No tags removes the session's entry.
+func (t Tags) Set(session string, tags []string) {
+	if len(tags) == 0 {
+		delete(t, session)
+		return
+	}
+	t[session] = tags
+}
+
+// Has reports whether a session carries the tag
+func (t Tags) Has(session, tag string) bool {
+	return slices.Contains(t[session], tag)
+}
+
+// ParseTags splits user input like "#work, client-x exp" into normalized tags:
+// lowercase, without the leading '#', deduplicated and sorted
+func ParseTags(input string) []string {
+	var tags []string
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	for _, field := range fields {
+		tag := strings.ToLower(strings.TrimLeft(field, "#"))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	return tags
+}
@@ -0,0 +1,51 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"work", []string{"work"}},
+		{"#Work, client-x  exp", []string{"client-x", "exp", "work"}},
+		{"work work #work", []string{"work"}},
+		{" # , ", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseTags(tt.input); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("ParseTags(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTagsRoundTrip(t *testing.T) {
+	stateDir := t.TempDir()
+
+	tags, err := LoadTags(stateDir)
+	if err != nil {
+		t.Fatalf("LoadTags() on empty dir error = %v", err)
+	}
+
+	tags.Set("api", []string{"client-x", "work"})
+	tags.Set("web", []string{"exp"})
+	tags.Set("web", nil)
+
+	if err := SaveTags(stateDir, tags); err != nil {
+		t.Fatalf("SaveTags() error = %v", err)
+	}
+
+	loaded, err := LoadTags(stateDir)
+	if err != nil {
+		t.Fatalf("LoadTags() error = %v", err)
+	}
+	if !loaded.Has("api", "work") || loaded.Has("api", "exp") {
+		t.Errorf("api tags = %v, want [client-x work]", loaded["api"])
+	}
+	if _, ok := loaded["web"]; ok {
+		t.Error("web should have no tags entry after clearing")
+	}
+}
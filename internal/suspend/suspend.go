@@ -0,0 +1,116 @@
+// Package suspend stops and resumes the processes running in tmux panes and
+// detects panes stopped by external tools (e.g. tmux-suspend)
+package suspend
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// process is a single entry of the process table
+type process struct {
+	ppid    int
+	stopped bool
+}
+
+// Table is a snapshot of the process table, keyed by PID
+type Table map[int]process
+
+// Snapshot lists all processes with their parent and stopped state
+func Snapshot() (Table, error) {
+	out, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,stat=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	return parsePS(string(out)), nil
+}
+
+// parsePS parses "pid ppid stat" lines as printed by ps
+func parsePS(out string) Table {
+	t := make(Table)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		t[pid] = process{ppid: ppid, stopped: strings.HasPrefix(fields[2], "T")}
+	}
+	return t
+}
+
+// The pane processes themselves are never stopped: tmux continues any of its
+// direct children that stop. Suspending stops everything started from them.
+
+// Stopped reports whether the panes run processes and all of them are stopped
+func (t Table) Stopped(panePIDs []int) bool {
+	procs := t.descendants(panePIDs)
+	if len(procs) == 0 {
+		return false
+	}
+	for _, pid := range procs {
+		if !t[pid].stopped {
+			return false
+		}
+	}
+	return true
+}
+
+// descendants returns all processes started from the given ones, parents first
+func (t Table) descendants(pids []int) []int {
+	children := make(map[int][]int)
+	for pid, p := range t {
+		children[p.ppid] = append(children[p.ppid], pid)
+	}
+
+	var all []int
+	seen := make(map[int]bool)
+	queue := append([]int(nil), pids...)
+	for _, pid := range pids {
+		seen[pid] = true
+	}
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		for _, child := range children[pid] {
+			if !seen[child] {
+				seen[child] = true
+				all = append(all, child)
+				queue = append(queue, child)
+			}
+		}
+	}
+	return all
+}
+
+// Suspend stops all processes started from the panes
+func Suspend(t Table, panePIDs []int) error {
+	procs := t.descendants(panePIDs)
+	if len(procs) == 0 {
+		return fmt.Errorf("nothing is running")
+	}
+	// Children first, so no parent notices a stopped child while still running
+	for i := len(procs) - 1; i >= 0; i-- {
+		if err := syscall.Kill(procs[i], syscall.SIGSTOP); err != nil && err != syscall.ESRCH {
+			return fmt.Errorf("failed to stop %d: %w", procs[i], err)
+		}
+	}
+	return nil
+}
+
+// Resume continues all processes started from the panes
+func Resume(t Table, panePIDs []int) error {
+	for _, pid := range t.descendants(panePIDs) {
+		if err := syscall.Kill(pid, syscall.SIGCONT); err != nil && err != syscall.ESRCH {
+			return fmt.Errorf("failed to continue %d: %w", pid, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package suspend
+
+import (
+	"fmt"
+	"testing"
+)
+
+const psOutput = `    1     0 Ss
+  100     1 Ss
+  101   100 T
+  102   101 S+
+  200     1 T
+  201   200 T+
+garbage
+`
+
+func TestParsePS(t *testing.T) {
+	table := parsePS(psOutput)
+	if len(table) != 6 {
+		t.Fatalf("parsePS() = %d processes, want 6", len(table))
+	}
+	if p := table[101]; p.ppid != 100 || !p.stopped {
+		t.Errorf("process 101 = %+v, want stopped child of 100", p)
+	}
+}
+
+func TestStopped(t *testing.T) {
+	table := parsePS(psOutput)
+	tests := []struct {
+		pids []int
+		want bool
+	}{
+		{[]int{200}, true},       // Only child stopped
+		{[]int{100}, false},      // 102 still runs
+		{[]int{200, 101}, false}, // 102 still runs
+		{[]int{201}, false},      // Nothing started from it
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := table.Stopped(tt.pids); got != tt.want {
+			t.Errorf("Stopped(%v) = %v, want %v", tt.pids, got, tt.want)
+		}
+	}
+}
+
+func TestDescendants(t *testing.T) {
+	table := parsePS(psOutput)
+	if got := table.descendants([]int{100}); fmt.Sprint(got) != "[101 102]" {
+		t.Errorf("descendants(100) = %v, want [101 102]", got)
+	}
+	if got := table.descendants([]int{200, 201}); len(got) != 0 {
+		t.Errorf("descendants(200, 201) = %v, want none besides the given processes", got)
+	}
+}
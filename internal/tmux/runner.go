@@ -0,0 +1,190 @@
+package tmux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nikbrunner/tsm/internal/profile"
+)
+
+// DefaultTimeout is how long a single tmux command may run before it is killed
+const DefaultTimeout = 5 * time.Second
+
+// TimeoutError is returned when a tmux command exceeds the command timeout,
+// usually because the tmux server is hung
+type TimeoutError struct {
+	Args    []string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("tmux %s timed out after %s", e.Args[0], e.Timeout)
+}
+
+// CommandError is returned when a tmux command exits with an error. It keeps
+// what tmux printed to stderr, which "exit status 1" alone hides.
+type CommandError struct {
+	Args     []string
+	Socket   string // Server socket the command targeted ("" = default)
+	ExitCode int
+	Stderr   string
+	Err      error
+}
+
+func (e *CommandError) Error() string {
+	if msg, _, _ := strings.Cut(e.Stderr, "\n"); msg != "" {
+		return fmt.Sprintf("tmux %s: %s", e.Args[0], msg)
+	}
+	return fmt.Sprintf("tmux %s: %v", e.Args[0], e.Err)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// Command returns the failed command line, quoting arguments where a shell
+// would need it
+func (e *CommandError) Command() string {
+	args := e.Args
+	if e.Socket != "" {
+		args = append([]string{"-S", e.Socket}, args...)
+	}
+	parts := []string{"tmux"}
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'#$;&|<>") {
+			arg = strconv.Quote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// runner serializes tmux invocations, dedupes identical in-flight list calls
+// and applies a timeout to every command
+type runner struct {
+	timeout time.Duration
+	execFn  func(ctx context.Context, socket string, args ...string) ([]byte, error)
+
+	execMu sync.Mutex // Held while a tmux command executes
+
+	inflightMu sync.Mutex
+	inflight   map[string]*call
+}
+
+// call is an in-flight command whose result is shared with duplicate callers
+type call struct {
+	done chan struct{}
+	out  []byte
+	err  error
+}
+
+var defaultRunner = newRunner(DefaultTimeout, func(ctx context.Context, socket string, args ...string) ([]byte, error) {
+	return command(ctx, socket, args...).Output()
+})
+
+func newRunner(timeout time.Duration, execFn func(ctx context.Context, socket string, args ...string) ([]byte, error)) *runner {
+	return &runner{
+		timeout:  timeout,
+		execFn:   execFn,
+		inflight: make(map[string]*call),
+	}
+}
+
+// SetTimeout sets the per-command timeout (values <= 0 restore DefaultTimeout)
+func SetTimeout(d time.Duration) {
+	if d <= 0 {
+		d = DefaultTimeout
+	}
+	defaultRunner.execMu.Lock()
+	defaultRunner.timeout = d
+	defaultRunner.execMu.Unlock()
+}
+
+// output runs a tmux command and returns its stdout.
+// Identical concurrent list-* calls share a single invocation.
+func output(args ...string) ([]byte, error) {
+	return defaultRunner.output(args...)
+}
+
+// run runs a tmux command, discarding its output
+func run(args ...string) error {
+	_, err := defaultRunner.exec(Socket(), args...)
+	return err
+}
+
+// output runs a command against the server targeted when it starts
+func (r *runner) output(args ...string) ([]byte, error) {
+	socket := Socket()
+	if !isReadOnly(args) {
+		return r.exec(socket, args...)
+	}
+
+	// Socket is part of the key: the same list call against another server differs
+	key := socket + "\x00" + strings.Join(args, "\x00")
+
+	r.inflightMu.Lock()
+	if c, ok := r.inflight[key]; ok {
+		r.inflightMu.Unlock()
+		<-c.done
+		return c.out, c.err
+	}
+	c := &call{done: make(chan struct{})}
+	r.inflight[key] = c
+	r.inflightMu.Unlock()
+
+	c.out, c.err = r.exec(socket, args...)
+
+	r.inflightMu.Lock()
+	delete(r.inflight, key)
+	r.inflightMu.Unlock()
+	close(c.done)
+
+	return c.out, c.err
+}
+
+// exec runs a single tmux command against a server socket with the timeout
+// applied, one at a time
+func (r *runner) exec(socket string, args ...string) ([]byte, error) {
+	r.execMu.Lock()
+	defer r.execMu.Unlock()
+	defer profile.Start("tmux " + args[0])()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	out, err := r.execFn(ctx, socket, args...)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, &TimeoutError{Args: args, Timeout: r.timeout}
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return out, &CommandError{
+			Args:     args,
+			Socket:   socket,
+			ExitCode: exitErr.ExitCode(),
+			Stderr:   strings.TrimSpace(string(exitErr.Stderr)),
+			Err:      err,
+		}
+	}
+	return out, err
+}
+
+// isReadOnly reports whether a tmux command only lists state and can be deduped
+func isReadOnly(args []string) bool {
+	return len(args) > 0 && strings.HasPrefix(args[0], "list-")
+}
+
+// command builds a tmux command targeting a server socket ("" = the one tmux
+// resolves itself)
+func command(ctx context.Context, socket string, args ...string) *exec.Cmd {
+	if socket != "" {
+		args = append([]string{"-S", socket}, args...)
+	}
+	return exec.CommandContext(ctx, "tmux", args...)
+}
@@ -0,0 +1,155 @@
+package tmux
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunnerDedupesInflightListCalls(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	r := newRunner(time.Second, func(ctx context.Context, _ string, args ...string) ([]byte, error) {
+		calls.Add(1)
+		<-release
+		return []byte("out"), nil
+	})
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			out, err := r.output("list-sessions", "-F", "#{session_name}")
+			if err != nil {
+				t.Errorf("output() error = %v", err)
+			}
+			results[i] = string(out)
+		}(i)
+	}
+
+	// Give all callers time to join the in-flight call
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("tmux invoked %d times, want 1", got)
+	}
+	for i, out := range results {
+		if out != "out" {
+			t.Errorf("results[%d] = %q, want %q", i, out, "out")
+		}
+	}
+}
+
+func TestRunnerDoesNotDedupeMutations(t *testing.T) {
+	var calls atomic.Int32
+	r := newRunner(time.Second, func(ctx context.Context, _ string, args ...string) ([]byte, error) {
+		calls.Add(1)
+		return nil, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.output("kill-session", "-t", "api"); err != nil {
+			t.Fatalf("output() error = %v", err)
+		}
+	}
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("tmux invoked %d times, want 3", got)
+	}
+}
+
+func TestRunnerKeepsSocketPerCommand(t *testing.T) {
+	t.Cleanup(func() { SetSocket("") })
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r := newRunner(time.Second, func(ctx context.Context, socket string, args ...string) ([]byte, error) {
+		close(started)
+		<-release
+		return []byte(socket), nil
+	})
+
+	SetSocket("/tmp/inner")
+	done := make(chan string)
+	go func() {
+		out, _ := r.output("list-sessions")
+		done <- string(out)
+	}()
+	<-started
+	// Switching servers mid-command doesn't redirect it
+	SetSocket("/tmp/outer")
+	close(release)
+	if got := <-done; got != "/tmp/inner" {
+		t.Errorf("command ran against %q, want the socket targeted when it started", got)
+	}
+}
+
+func TestRunnerTimeout(t *testing.T) {
+	r := newRunner(20*time.Millisecond, func(ctx context.Context, _ string, args ...string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	_, err := r.exec("", "list-sessions")
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("exec() error = %v, want *TimeoutError", err)
+	}
+	if want := "tmux list-sessions timed out after 20ms"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestRunnerCommandError(t *testing.T) {
+	r := newRunner(time.Second, func(ctx context.Context, _ string, args ...string) ([]byte, error) {
+		return exec.CommandContext(ctx, "sh", "-c", "echo \"can't find session: api\" >&2; exit 3").Output()
+	})
+
+	_, err := r.exec("", "kill-session", "-t", "my api")
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("exec() error = %v, want *CommandError", err)
+	}
+	if cmdErr.ExitCode != 3 || cmdErr.Stderr != "can't find session: api" {
+		t.Errorf("exit code = %d, stderr = %q", cmdErr.ExitCode, cmdErr.Stderr)
+	}
+	if want := "tmux kill-session: can't find session: api"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if want := `tmux kill-session -t "my api"`; cmdErr.Command() != want {
+		t.Errorf("Command() = %q, want %q", cmdErr.Command(), want)
+	}
+}
+
+func TestSessionNames(t *testing.T) {
+	orig := defaultRunner
+	t.Cleanup(func() { defaultRunner = orig })
+	defaultRunner = newRunner(time.Second, func(ctx context.Context, socket string, args ...string) ([]byte, error) {
+		switch socket {
+		case "/tmp/work":
+			return []byte("api\nmy notes\n"), nil
+		case "/tmp/stopped":
+			return exec.CommandContext(ctx, "sh", "-c", "echo 'no server running on /tmp/stopped' >&2; exit 1").Output()
+		}
+		return exec.CommandContext(ctx, "sh", "-c", "echo 'server exited unexpectedly' >&2; exit 1").Output()
+	})
+
+	names, err := SessionNames("/tmp/work", "/tmp/stopped")
+	if err != nil || !slices.Equal(names, []string{"api", "my notes"}) {
+		t.Errorf("SessionNames() = %v, %v, want the running server's sessions", names, err)
+	}
+	if _, err := SessionNames("/tmp/work", "/tmp/broken"); err == nil {
+		t.Error("SessionNames() with a failing server returned no error")
+	}
+}
@@ -1,17 +1,22 @@
 package tmux
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
 // Session represents a tmux session
 type Session struct {
 	Name         string
+	Path         string // Session working directory
+	Group        string // Session group sharing its windows (new-session -t), empty when ungrouped
 	LastActivity time.Time
 	Windows      []Window
 	Expanded     bool
@@ -19,13 +24,125 @@ type Session struct {
 
 // Window represents a tmux window
 type Window struct {
-	Index int
-	Name  string
+	ID           string // Stable window ID (e.g. "@12"), survives renumbering
+	Index        int
+	Name         string
+	Command      string    // Command running in the window's active pane
+	LastActivity time.Time // Last output or input in the window
+}
+
+// Target returns the tmux target for the window. Prefers the stable window ID,
+// since indices can shift (renumber-windows) between loading and acting.
+func (w Window) Target(sessionName string) string {
+	if w.ID != "" {
+		return w.ID
+	}
+	return fmt.Sprintf("%s:%d", sessionName, w.Index)
+}
+
+// Pane represents a tmux pane
+type Pane struct {
+	ID      string // Stable pane ID (e.g. "%7"), only set by ListAllPanes
+	Index   int
+	Command string
+}
+
+// socketPath is the tmux server socket targeted by all commands, a string.
+// Empty means the server tmux itself resolves (from $TMUX or the default
+// socket). The picker switches servers while commands run in the background,
+// so it is read atomically, once per command.
+var socketPath atomic.Value
+
+// SetSocket selects the tmux server socket used by subsequent commands
+func SetSocket(path string) {
+	socketPath.Store(path)
+}
+
+// Socket returns the currently targeted tmux server socket
+func Socket() string {
+	path, _ := socketPath.Load().(string)
+	return path
+}
+
+// Installed reports whether the tmux binary is on $PATH
+func Installed() bool {
+	_, err := exec.LookPath("tmux")
+	return err == nil
+}
+
+// ServerRunning reports whether the targeted tmux server answers
+func ServerRunning() bool {
+	return run("list-sessions") == nil
+}
+
+// IsNested reports whether the current client runs inside another tmux,
+// i.e. its terminal is itself a tmux (or screen) pane
+func IsNested() bool {
+	out, err := output("display-message", "-p", "#{client_termname}")
+	if err != nil {
+		return false
+	}
+	term := strings.TrimSpace(string(out))
+	return strings.HasPrefix(term, "tmux") || strings.HasPrefix(term, "screen")
 }
 
 // CurrentSession returns the name of the current tmux session
 func CurrentSession() (string, error) {
-	out, err := exec.Command("tmux", "display-message", "-p", "#S").Output()
+	out, err := output("display-message", "-p", "#S")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// CurrentPanePath returns the working directory of the current pane
+func CurrentPanePath() (string, error) {
+	out, err := output("display-message", "-p", "#{pane_current_path}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Client is a terminal attached to the tmux server
+type Client struct {
+	Name    string // Client tty, e.g. "/dev/pts/3"
+	Session string // Attached session
+	Size    string // Terminal size, e.g. "212x58"
+	Width   int
+	Height  int
+}
+
+// ListClients returns the clients attached to the server
+func ListClients() ([]Client, error) {
+	out, err := output("list-clients", "-F", "#{client_name}\t#{client_session}\t#{client_width}x#{client_height}")
+	if err != nil {
+		return nil, err
+	}
+	return parseClients(string(out)), nil
+}
+
+// parseClients parses "name<TAB>session<TAB>size" lines
+func parseClients(out string) []Client {
+	var clients []Client
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) != 3 {
+			continue
+		}
+		c := Client{Name: parts[0], Session: parts[1], Size: parts[2]}
+		if w, h, ok := strings.Cut(c.Size, "x"); ok {
+			c.Width, _ = strconv.Atoi(w)
+			c.Height, _ = strconv.Atoi(h)
+		}
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+// CurrentClient returns the name of the client tsm runs in
+func CurrentClient() (string, error) {
+	out, err := output("display-message", "-p", "#{client_name}")
 	if err != nil {
 		return "", err
 	}
@@ -35,7 +152,7 @@ func CurrentSession() (string, error) {
 // ListSessions returns all tmux sessions sorted by activity (most recent first)
 // Excludes the current session and popup sessions
 func ListSessions(excludeCurrent string) ([]Session, error) {
-	out, err := exec.Command("tmux", "list-sessions", "-F", "#{session_activity} #{session_name}").Output()
+	out, err := output("list-sessions", "-F", "#{session_activity}\t#{session_name}\t#{session_group}\t#{session_group_size}\t#{session_path}")
 	if err != nil {
 		return nil, err
 	}
@@ -48,8 +165,8 @@ func ListSessions(excludeCurrent string) ([]Session, error) {
 	var sessions []Session
 
 	for _, line := range lines {
-		parts := strings.SplitN(line, " ", 2)
-		if len(parts) != 2 {
+		parts := strings.SplitN(line, "\t", 5)
+		if len(parts) != 5 {
 			continue
 		}
 
@@ -64,9 +181,15 @@ func ListSessions(excludeCurrent string) ([]Session, error) {
 		if err != nil {
 			continue
 		}
+		// tmux keeps the group of the last session left in it
+		if size, err := strconv.Atoi(parts[3]); err == nil && size < 2 {
+			parts[2] = ""
+		}
 
 		sessions = append(sessions, Session{
 			Name:         name,
+			Group:        parts[2],
+			Path:         parts[4],
 			LastActivity: time.Unix(activityUnix, 0),
 		})
 	}
@@ -79,9 +202,30 @@ func ListSessions(excludeCurrent string) ([]Session, error) {
 	return sessions, nil
 }
 
+// SessionNames lists the sessions of the servers at sockets ("" for the one
+// targeted otherwise). Sockets without a server running have none.
+func SessionNames(sockets ...string) ([]string, error) {
+	var names []string
+	for _, socket := range sockets {
+		out, err := defaultRunner.exec(socket, "list-sessions", "-F", "#{session_name}")
+		var cmdErr *CommandError
+		if errors.As(err, &cmdErr) && (strings.Contains(cmdErr.Stderr, "no server running") || strings.Contains(cmdErr.Stderr, "error connecting to")) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if lines := strings.TrimSpace(string(out)); lines != "" {
+			names = append(names, strings.Split(lines, "\n")...)
+		}
+	}
+	return names, nil
+}
+
 // ListWindows returns all windows for a given session
 func ListWindows(sessionName string) ([]Window, error) {
-	out, err := exec.Command("tmux", "list-windows", "-t", sessionName, "-F", "#{window_index}:#{window_name}").Output()
+	out, err := output("list-windows", "-t", sessionName, "-F",
+		"#{window_index}:#{window_id}:#{window_activity}:#{pane_current_command}:#{window_name}")
 	if err != nil {
 		return nil, err
 	}
@@ -93,8 +237,8 @@ func ListWindows(sessionName string) ([]Window, error) {
 
 	var windows []Window
 	for _, line := range lines {
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) != 2 {
+		parts := strings.SplitN(line, ":", 5)
+		if len(parts) != 5 {
 			continue
 		}
 
@@ -102,44 +246,656 @@ func ListWindows(sessionName string) ([]Window, error) {
 		if err != nil {
 			continue
 		}
+		activity, _ := strconv.ParseInt(parts[2], 10, 64)
 
 		windows = append(windows, Window{
-			Index: index,
-			Name:  parts[1],
+			ID:           parts[1],
+			Index:        index,
+			Command:      parts[3],
+			Name:         parts[4],
+			LastActivity: time.Unix(activity, 0),
 		})
 	}
 
 	return windows, nil
 }
 
+// ListAllWindows returns the windows of every session in a single call,
+// keyed by session name
+func ListAllWindows() (map[string][]Window, error) {
+	out, err := output("list-windows", "-a", "-F",
+		"#{session_name}\t#{window_index}\t#{window_id}\t#{window_activity}\t#{pane_current_command}\t#{window_name}")
+	if err != nil {
+		return nil, err
+	}
+	return parseAllWindows(string(out)), nil
+}
+
+// parseAllWindows parses list-windows -a output (see ListAllWindows)
+func parseAllWindows(out string) map[string][]Window {
+	windows := make(map[string][]Window)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		parts := strings.SplitN(line, "\t", 6)
+		if len(parts) != 6 {
+			continue
+		}
+
+		index, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		activity, _ := strconv.ParseInt(parts[3], 10, 64)
+
+		windows[parts[0]] = append(windows[parts[0]], Window{
+			ID:           parts[2],
+			Index:        index,
+			Command:      parts[4],
+			Name:         parts[5],
+			LastActivity: time.Unix(activity, 0),
+		})
+	}
+	return windows
+}
+
+// ListAllPanes returns the panes of every window in a single call, keyed by
+// window ID
+func ListAllPanes() (map[string][]Pane, error) {
+	out, err := output("list-panes", "-a", "-F", "#{window_id}\t#{pane_id}\t#{pane_index}\t#{pane_current_command}")
+	if err != nil {
+		return nil, err
+	}
+	return parseAllPanes(string(out)), nil
+}
+
+// parseAllPanes parses list-panes -a output (see ListAllPanes)
+func parseAllPanes(out string) map[string][]Pane {
+	panes := make(map[string][]Pane)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		parts := strings.SplitN(line, "\t", 4)
+		if len(parts) != 4 {
+			continue
+		}
+		index, err := strconv.Atoi(parts[2])
+		if err != nil {
+			continue
+		}
+		panes[parts[0]] = append(panes[parts[0]], Pane{ID: parts[1], Index: index, Command: parts[3]})
+	}
+	return panes
+}
+
+// ListPanePIDs returns the PIDs of the processes running in each pane of
+// every session, keyed by session name
+func ListPanePIDs() (map[string][]int, error) {
+	out, err := output("list-panes", "-a", "-F", "#{session_name}\t#{pane_pid}")
+	if err != nil {
+		return nil, err
+	}
+	pids := make(map[string][]int)
+	for name, value := range parseSessionValues(string(out)) {
+		for _, v := range value {
+			if pid, err := strconv.Atoi(v); err == nil {
+				pids[name] = append(pids[name], pid)
+			}
+		}
+	}
+	return pids, nil
+}
+
+// ListPanes returns all panes for a given window target (session:index)
+func ListPanes(target string) ([]Pane, error) {
+	out, err := output("list-panes", "-t", target, "-F", "#{pane_index}:#{pane_current_command}")
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) == 0 || (len(lines) == 1 && lines[0] == "") {
+		return []Pane{}, nil
+	}
+
+	var panes []Pane
+	for _, line := range lines {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		index, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+
+		panes = append(panes, Pane{
+			Index:   index,
+			Command: parts[1],
+		})
+	}
+
+	return panes, nil
+}
+
 // KillSession kills a tmux session by name
 func KillSession(name string) error {
-	return exec.Command("tmux", "kill-session", "-t", name).Run()
+	return run("kill-session", "-t", name)
+}
+
+// KillSessionSafe kills a session like KillSession, but first switches the
+// clients attached to it to the most recently active other session, so tmux
+// doesn't detach them (and a picker running in their popup) with the session
+func KillSessionSafe(name string) error {
+	clients, err := ListClients()
+	if err != nil {
+		return err
+	}
+
+	var others []Session
+	for _, client := range clients {
+		if client.Session != name {
+			continue
+		}
+		if others == nil {
+			if others, err = ListSessions(name); err != nil {
+				return err
+			}
+			if len(others) == 0 {
+				return fmt.Errorf("no other session to move the clients of %q to", name)
+			}
+		}
+		if err := SwitchClientFor(client.Name, others[0].Name); err != nil {
+			return fmt.Errorf("failed to switch client %s: %w", client.Name, err)
+		}
+	}
+
+	return KillSession(name)
+}
+
+// RenameSession renames a tmux session
+func RenameSession(from, to string) error {
+	return run("rename-session", "-t", from, to)
+}
+
+// PaneLastLine returns the last non-empty line shown in the active pane of
+// a window (see Window.Target)
+func PaneLastLine(target string) (string, error) {
+	out, err := output("capture-pane", "-p", "-J", "-t", target)
+	if err != nil {
+		return "", err
+	}
+	return lastLine(string(out)), nil
+}
+
+// CapturePane returns the lines shown in a pane, or the active pane of a
+// window or session, without the empty lines below the last output
+func CapturePane(target string) ([]string, error) {
+	out, err := output("capture-pane", "-p", "-J", "-t", target)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimRight(string(out), " \n")
+	if text == "" {
+		return nil, nil
+	}
+	return strings.Split(text, "\n"), nil
+}
+
+// lastLine returns the last line of text with non-space content, trimmed
+func lastLine(text string) string {
+	lines := strings.Split(text, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// SetBuffer stores text in a new paste buffer and, depending on the
+// set-clipboard option, in the outer terminal's clipboard (OSC 52)
+func SetBuffer(text string) error {
+	return run("set-buffer", "-w", "--", text)
+}
+
+// PanePath returns the working directory of a window's active pane
+// (see Window.Target), or of a pane by its ID
+func PanePath(target string) (string, error) {
+	out, err := output("display-message", "-p", "-t", target, "#{pane_current_path}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// PaneCommandLine returns what runs in a window's active pane (see
+// Window.Target), or in a pane by its ID, like PaneCommand but with its
+// arguments as ps shows them. Without ps only the command's name is known.
+func PaneCommandLine(target string) (string, error) {
+	out, err := output("display-message", "-p", "-t", target, "#{pane_pid}\t#{pane_current_command}")
+	if err != nil {
+		return "", err
+	}
+	pidText, name, _ := strings.Cut(strings.TrimSpace(string(out)), "\t")
+	pid, err := strconv.Atoi(pidText)
+	if err != nil {
+		return name, nil
+	}
+	ps, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,args=").Output()
+	if err != nil {
+		return name, nil
+	}
+	return commandLine(string(ps), pid, name), nil
 }
 
-// KillWindow kills a tmux window
-func KillWindow(sessionName string, windowIndex int) error {
-	target := fmt.Sprintf("%s:%d", sessionName, windowIndex)
-	return exec.Command("tmux", "kill-window", "-t", target).Run()
+// commandLine finds the arguments of the process called name among pid and
+// the processes started from it, in "pid ppid args" lines as printed by ps.
+// Falls back to name.
+func commandLine(ps string, pid int, name string) string {
+	args := make(map[int]string)
+	children := make(map[int][]int)
+	for _, line := range strings.Split(ps, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		p, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		args[p] = strings.Join(fields[2:], " ")
+		children[ppid] = append(children[ppid], p)
+	}
+
+	queue := []int{pid}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if fields := strings.Fields(args[p]); len(fields) > 0 && filepath.Base(fields[0]) == name {
+			return args[p]
+		}
+		queue = append(queue, children[p]...)
+	}
+	return name
+}
+
+// DuplicateWindow opens a window right after another (see Window.Target, or
+// the window of a pane ID), started in dir and running command, or the
+// default shell when empty. Returns the new window's ID.
+func DuplicateWindow(target, dir, command string) (string, error) {
+	args := []string{"new-window", "-d", "-a", "-t", target, "-c", dir, "-P", "-F", "#{window_id}"}
+	if command != "" {
+		args = append(args, command)
+	}
+	out, err := output(args...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// KillWindow kills a tmux window by target (see Window.Target)
+func KillWindow(target string) error {
+	return run("kill-window", "-t", target)
+}
+
+// KillPane kills a pane; tmux closes its window along with the last pane
+func KillPane(target string) error {
+	return run("kill-pane", "-t", target)
+}
+
+// DeadPanes returns the IDs of the panes whose command exited but stay
+// open (remain-on-exit), keyed by window ID
+func DeadPanes() (map[string][]string, error) {
+	out, err := output("list-panes", "-a", "-F", "#{?pane_dead,#{window_id}	#{pane_id},}")
+	if err != nil {
+		return nil, err
+	}
+	return parseDeadPanes(string(out)), nil
+}
+
+// parseDeadPanes parses list-panes output that is empty for every pane but
+// the dead ones (see DeadPanes)
+func parseDeadPanes(out string) map[string][]string {
+	dead := make(map[string][]string)
+	for _, line := range strings.Split(out, "\n") {
+		window, pane, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if ok && window != "" && pane != "" {
+			dead[window] = append(dead[window], pane)
+		}
+	}
+	return dead
+}
+
+// RespawnPane restarts the command of a dead pane
+func RespawnPane(target string) error {
+	return run("respawn-pane", "-t", target)
+}
+
+// MarkedWindow returns the ID of the window holding the marked pane
+// (select-pane -m), empty when no pane is marked
+func MarkedWindow() (string, error) {
+	out, err := output("list-panes", "-a", "-F", "#{?pane_marked,#{window_id},}")
+	if err != nil {
+		return "", err
+	}
+	return markedWindow(string(out)), nil
+}
+
+// markedWindow picks the window ID out of list-panes output that is empty
+// for every pane but the marked one
+func markedWindow(out string) string {
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// JoinMarkedPane moves the marked pane into a window (see Window.Target),
+// splitting its active pane
+func JoinMarkedPane(target string) error {
+	return run("join-pane", "-d", "-s", "{marked}", "-t", target)
+}
+
+// SwapMarkedPane swaps the marked pane with the active pane of a window
+// (see Window.Target)
+func SwapMarkedPane(target string) error {
+	return run("swap-pane", "-d", "-s", "{marked}", "-t", target)
+}
+
+// MergeSession moves all windows of a session into another session, appending
+// them after the target's windows, then kills the (now empty) source session
+func MergeSession(source, target string) error {
+	windows, err := ListWindows(source)
+	if err != nil {
+		return err
+	}
+
+	// Move by window ID: indexes shift as windows leave the source
+	for _, w := range windows {
+		if err := run("move-window", "-d", "-s", w.Target(source), "-t", target+":"); err != nil {
+			return fmt.Errorf("failed to move window %d: %w", w.Index, err)
+		}
+	}
+
+	// tmux destroys a session when its last window leaves
+	if SessionExists(source) {
+		return KillSession(source)
+	}
+	return nil
+}
+
+// BreakWindow moves a window (see Window.Target) out into a new session of
+// its own started in dir, the reverse of MergeSession. The window replaces
+// the new session's first window, so it keeps the session's base-index.
+func BreakWindow(window, session, dir string) error {
+	out, err := output("new-session", "-d", "-s", session, "-c", dir, "-P", "-F", "#{window_id}")
+	if err != nil {
+		return err
+	}
+	placeholder := strings.TrimSpace(string(out))
+	if err := run("move-window", "-k", "-s", window, "-t", placeholder); err != nil {
+		_ = KillSession(session)
+		return fmt.Errorf("failed to move the window: %w", err)
+	}
+	return nil
 }
 
 // SessionExists checks if a tmux session with the given name exists
 func SessionExists(name string) bool {
-	return exec.Command("tmux", "has-session", "-t", name).Run() == nil
+	return run("has-session", "-t", name) == nil
+}
+
+// SetSessionOption sets a user option (e.g. "@tsm_tags") on a session.
+// An empty value unsets the option.
+func SetSessionOption(session, option, value string) error {
+	if value == "" {
+		return run("set-option", "-u", "-t", session, option)
+	}
+	return run("set-option", "-t", session, option, value)
+}
+
+// SessionOption returns a session's user option, or "" if it is unset
+func SessionOption(session, option string) (string, error) {
+	out, err := output("show-options", "-qv", "-t", session, option)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// GetOption returns the global value of a tmux option, e.g.
+// "detach-on-destroy"
+func GetOption(option string) (string, error) {
+	out, err := output("show-options", "-gv", option)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ListSessionOption returns a single-line user option for every session
+// that has it set, keyed by session name
+func ListSessionOption(option string) (map[string]string, error) {
+	out, err := output("list-sessions", "-F", "#{session_name}\t#{"+option+"}")
+	if err != nil {
+		return nil, err
+	}
+	return parseSessionOption(string(out)), nil
+}
+
+// ListSessionsWithOption returns the sessions that have a user option set.
+// Unlike ListSessionOption it works for multi-line values.
+func ListSessionsWithOption(option string) (map[string]bool, error) {
+	out, err := output("list-sessions", "-F", "#{session_name}\t#{?"+option+",1,}")
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[string]bool)
+	for name := range parseSessionOption(string(out)) {
+		set[name] = true
+	}
+	return set, nil
+}
+
+// parseSessionOption parses "name\tvalue" lines, skipping empty values
+func parseSessionOption(out string) map[string]string {
+	values := make(map[string]string)
+	for name, v := range parseSessionValues(out) {
+		values[name] = v[len(v)-1]
+	}
+	return values
 }
 
-// CreateSession creates a new tmux session
-func CreateSession(name, dir string) error {
-	return exec.Command("tmux", "new-session", "-d", "-s", name, "-c", dir).Run()
+// parseSessionValues parses "name\tvalue" lines into all values per session,
+// skipping empty values
+func parseSessionValues(out string) map[string][]string {
+	values := make(map[string][]string)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		name, value, ok := strings.Cut(line, "\t")
+		if ok && value != "" {
+			values[name] = append(values[name], value)
+		}
+	}
+	return values
+}
+
+// CreateSession creates a new tmux session. Its first window runs command,
+// or tmux's default-command (default-shell) when command is empty.
+func CreateSession(name, dir, command string) error {
+	args := []string{"new-session", "-d", "-s", name, "-c", dir}
+	if command != "" {
+		args = append(args, command)
+	}
+	return run(args...)
 }
 
-// SwitchClient switches the tmux client to a session or window
+// CreateGroupedSession creates a detached session in the group of target,
+// sharing its windows while keeping its own current window and size
+func CreateGroupedSession(name, target string) error {
+	return run("new-session", "-d", "-s", name, "-t", target)
+}
+
+// WindowSnapshot records what it takes to recreate a window (see
+// SnapshotSession)
+type WindowSnapshot struct {
+	Name   string   `json:"name,omitempty"` // Empty when tmux names it automatically
+	Layout string   `json:"layout"`         // window_layout, for select-layout
+	Panes  []string `json:"panes"`          // Working directory of each pane
+}
+
+// SnapshotSession records a session's windows with their pane layouts and
+// working directories
+func SnapshotSession(name string) ([]WindowSnapshot, error) {
+	out, err := output("list-panes", "-s", "-t", name, "-F",
+		"#{window_id}\t#{?automatic-rename,,#{window_name}}\t#{window_layout}\t#{pane_current_path}")
+	if err != nil {
+		return nil, err
+	}
+	return parseSnapshot(string(out)), nil
+}
+
+// SnapshotAll records the windows of every session (see SnapshotSession) in a
+// single call, keyed by session name
+func SnapshotAll() (map[string][]WindowSnapshot, error) {
+	out, err := output("list-panes", "-a", "-F",
+		"#{session_name}\t#{window_id}\t#{?automatic-rename,,#{window_name}}\t#{window_layout}\t#{pane_current_path}")
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string][]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if name, rest, ok := strings.Cut(line, "\t"); ok {
+			lines[name] = append(lines[name], rest)
+		}
+	}
+	snapshots := make(map[string][]WindowSnapshot, len(lines))
+	for name, panes := range lines {
+		snapshots[name] = parseSnapshot(strings.Join(panes, "\n"))
+	}
+	return snapshots, nil
+}
+
+// parseSnapshot parses "window_id\tname\tlayout\tpath" pane lines, which
+// tmux lists window by window
+func parseSnapshot(out string) []WindowSnapshot {
+	var windows []WindowSnapshot
+	lastID := ""
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		parts := strings.SplitN(line, "\t", 4)
+		if len(parts) < 4 {
+			continue
+		}
+		if parts[0] != lastID {
+			lastID = parts[0]
+			windows = append(windows, WindowSnapshot{Name: parts[1], Layout: parts[2]})
+		}
+		w := &windows[len(windows)-1]
+		w.Panes = append(w.Panes, parts[3])
+	}
+	return windows
+}
+
+// RestoreSession recreates a session from a snapshot in the background. The
+// snapshot's directories must exist.
+func RestoreSession(name string, windows []WindowSnapshot) error {
+	for i, w := range windows {
+		if len(w.Panes) == 0 {
+			continue
+		}
+		args := []string{"new-window", "-d", "-t", name + ":"}
+		if i == 0 {
+			args = []string{"new-session", "-d", "-s", name}
+		}
+		args = append(args, "-P", "-F", "#{pane_id}", "-c", w.Panes[0])
+		if w.Name != "" {
+			args = append(args, "-n", w.Name)
+		}
+		out, err := output(args...)
+		if err != nil {
+			return err
+		}
+
+		// Splitting the newest pane keeps the panes in order
+		pane := strings.TrimSpace(string(out))
+		for _, dir := range w.Panes[1:] {
+			out, err := output("split-window", "-d", "-P", "-F", "#{pane_id}", "-t", pane, "-c", dir)
+			if err != nil {
+				return err
+			}
+			pane = strings.TrimSpace(string(out))
+		}
+		if len(w.Panes) > 1 && w.Layout != "" {
+			if err := run("select-layout", "-t", pane, w.Layout); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DetachClient detaches a client (by its tty, see Client.Name)
+func DetachClient(client string) error {
+	return run("detach-client", "-t", client)
+}
+
+// SwitchClient switches the tmux client to a session or window. On another
+// server than the one tsm runs in there is no client of its own to switch;
+// the error says how to attach instead.
 func SwitchClient(target string) error {
-	return exec.Command("tmux", "switch-client", "-t", target).Run()
+	err := run("switch-client", "-t", target)
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Socket != "" && strings.Contains(cmdErr.Stderr, "no current client") {
+		return fmt.Errorf("not attached to the server at %s, attach with: tmux -S %s attach -t %s", cmdErr.Socket, cmdErr.Socket, target)
+	}
+	return err
+}
+
+// SwitchClientFor switches another client (by its tty, see Client.Name) to a
+// session or window
+func SwitchClientFor(client, target string) error {
+	return run("switch-client", "-c", client, "-t", target)
+}
+
+// SelectWindow selects a specific window (see Window.Target) in the current client
+func SelectWindow(target string) error {
+	return run("switch-client", "-t", target)
+}
+
+// ZoomPane zooms the active pane of the target window, unless already zoomed
+func ZoomPane(target string) error {
+	out, err := output("display-message", "-p", "-t", target, "#{window_zoomed_flag}")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(out)) == "1" {
+		return nil
+	}
+	return run("resize-pane", "-Z", "-t", target)
+}
+
+// PaneCommand returns the command running in the active pane of a session or
+// window (see Window.Target)
+func PaneCommand(target string) (string, error) {
+	out, err := output("display-message", "-p", "-t", target, "#{pane_current_command}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// SendCommand types a command line into the active pane of a session or
+// window (see Window.Target) and runs it
+func SendCommand(target, command string) error {
+	if err := run("send-keys", "-t", target, "-l", command); err != nil {
+		return err
+	}
+	return run("send-keys", "-t", target, "Enter")
 }
 
-// SelectWindow selects a specific window in the current client
-func SelectWindow(sessionName string, windowIndex int) error {
-	target := fmt.Sprintf("%s:%d", sessionName, windowIndex)
-	return exec.Command("tmux", "switch-client", "-t", target).Run()
+// BreakPane breaks the target pane out into its own window
+func BreakPane(target string) error {
+	return run("break-pane", "-s", target)
 }
@@ -0,0 +1,119 @@
+package tmux
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseAllWindows(t *testing.T) {
+	out := "api\t1\t@3\t1700000000\tnvim\teditor\n" +
+		"api\t2\t@4\t1700000060\tzsh\tshell: logs\n" +
+		"web\t1\t@7\t1700000120\tnode\tserver\n" +
+		"broken line\n"
+
+	windows := parseAllWindows(out)
+	if len(windows) != 2 {
+		t.Fatalf("sessions = %d, want 2", len(windows))
+	}
+	if got := windows["api"]; len(got) != 2 || got[1].Name != "shell: logs" || got[1].ID != "@4" {
+		t.Errorf("api windows = %+v, want editor and \"shell: logs\" (@4)", got)
+	}
+	if got := windows["api"][1].LastActivity; !got.Equal(time.Unix(1700000060, 0)) {
+		t.Errorf("api window 2 activity = %v, want 1700000060", got)
+	}
+	if got := windows["web"]; len(got) != 1 || got[0].Index != 1 || got[0].Command != "node" {
+		t.Errorf("web windows = %+v, want server running node", got)
+	}
+	if got := parseAllWindows(""); len(got) != 0 {
+		t.Errorf("parseAllWindows(\"\") = %v, want empty", got)
+	}
+}
+
+func TestParseAllPanes(t *testing.T) {
+	out := "@3\t%1\t0\tnvim\n" +
+		"@3\t%5\t1\tgo test ./...\n" +
+		"@4\t%2\t0\tzsh\n" +
+		"broken line\n"
+
+	panes := parseAllPanes(out)
+	if got := panes["@3"]; len(got) != 2 || got[1].ID != "%5" || got[1].Index != 1 || got[1].Command != "go test ./..." {
+		t.Errorf("@3 panes = %+v, want nvim and go test (%%5)", got)
+	}
+	if got := panes["@4"]; len(got) != 1 || got[0].Command != "zsh" {
+		t.Errorf("@4 panes = %+v, want zsh", got)
+	}
+}
+
+func TestParseSessionOption(t *testing.T) {
+	got := parseSessionOption("api\twork exp\nweb\t\nlegacy\tclient\n")
+	if len(got) != 2 || got["api"] != "work exp" || got["legacy"] != "client" {
+		t.Errorf("parseSessionOption() = %v, want api and legacy only", got)
+	}
+}
+
+func TestParseClients(t *testing.T) {
+	got := parseClients("/dev/pts/1\tapi\t212x58\n/dev/pts/2\tweb\t80x24\n")
+	if len(got) != 2 || got[1] != (Client{Name: "/dev/pts/2", Session: "web", Size: "80x24", Width: 80, Height: 24}) {
+		t.Errorf("parseClients() = %+v, want pts/1 and pts/2", got)
+	}
+	if got := parseClients(""); len(got) != 0 {
+		t.Errorf("parseClients() = %+v, want none without clients", got)
+	}
+}
+
+func TestLastLine(t *testing.T) {
+	if got := lastLine("$ go test\n  ok  tsm 0.2s  \n\n\n"); got != "ok  tsm 0.2s" {
+		t.Errorf("lastLine() = %q, want the trimmed last non-empty line", got)
+	}
+	if got := lastLine("\n  \n"); got != "" {
+		t.Errorf("lastLine() = %q, want empty for a blank pane", got)
+	}
+}
+
+func TestParseDeadPanes(t *testing.T) {
+	got := parseDeadPanes("\n@3\t%5\n\n@3\t%6\n@4\t%2\n\n")
+	if len(got) != 2 || len(got["@3"]) != 2 || got["@3"][1] != "%6" || got["@4"][0] != "%2" {
+		t.Errorf("parseDeadPanes() = %v, want %%5 and %%6 in @3, %%2 in @4", got)
+	}
+	if got := parseDeadPanes("\n\n"); len(got) != 0 {
+		t.Errorf("parseDeadPanes() = %v, want empty without dead panes", got)
+	}
+}
+
+func TestMarkedWindow(t *testing.T) {
+	if got := markedWindow("\n\n@7\n\n"); got != "@7" {
+		t.Errorf("markedWindow() = %q, want @7", got)
+	}
+	if got := markedWindow("\n\n"); got != "" {
+		t.Errorf("markedWindow() = %q, want empty without a marked pane", got)
+	}
+}
+
+func TestParseSnapshot(t *testing.T) {
+	out := "@6\t\t41b5,80x24,0,0[80x12,0,0,7,80x11,0,13,8]\t/tmp\n" +
+		"@6\t\t41b5,80x24,0,0[80x12,0,0,7,80x11,0,13,8]\t/root\n" +
+		"@7\tlogs\tb266,80x24,0,0,9\t/var/log\n"
+
+	want := []WindowSnapshot{
+		{Layout: "41b5,80x24,0,0[80x12,0,0,7,80x11,0,13,8]", Panes: []string{"/tmp", "/root"}},
+		{Name: "logs", Layout: "b266,80x24,0,0,9", Panes: []string{"/var/log"}},
+	}
+	if got := parseSnapshot(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSnapshot() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+	ps := `  100     1 -zsh
+  200   100 /usr/bin/nvim -O main.go main_test.go
+  300   200 gopls
+  400     1 sleep 60`
+
+	if got := commandLine(ps, 100, "nvim"); got != "/usr/bin/nvim -O main.go main_test.go" {
+		t.Errorf("commandLine(nvim) = %q, want its arguments", got)
+	}
+	if got := commandLine(ps, 100, "sleep"); got != "sleep" {
+		t.Errorf("commandLine(sleep) = %q, want the name for a process outside the pane", got)
+	}
+}
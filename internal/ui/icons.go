@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Icon sets, picked with icon_set in the config
+const (
+	IconsAuto  = "auto"  // ascii when the terminal or locale can't show nerd font glyphs, else nerd
+	IconsNerd  = "nerd"  // Nerd font glyphs
+	IconsASCII = "ascii" // Plain ASCII, for terminals without nerd fonts or Unicode
+)
+
+// IconSets lists the icon sets
+var IconSets = []string{IconsAuto, IconsNerd, IconsASCII}
+
+// Icons, rendered in their color by SetIcons
+var (
+	ExpandedIcon  string
+	CollapsedIcon string
+
+	LastIcon string
+
+	NoteIcon string
+
+	// Session whose pane processes are all stopped
+	SuspendedIcon string
+
+	BranchIcon string
+
+	// Health check passed / failed
+	HealthyIcon   string
+	UnhealthyIcon string
+
+	RecentIcon string
+
+	ArchiveIcon string
+
+	// Session sharing its windows with others (a session group)
+	GroupIcon string
+
+	// Session shrunk to a client much smaller than the picker's
+	SmallClientIcon string
+
+	MarkIcon string
+
+	// Window holding tmux's marked pane (select-pane -m)
+	MarkedPaneIcon string
+
+	// Pane whose command exited, kept open by remain-on-exit
+	DeadPaneIcon string
+
+	// Claude status badge: its label, the waiting mark and the working mark
+	// (empty animates dots); styled by FormatClaudeStatus
+	ClaudeLabel       string
+	ClaudeWaitingIcon string
+	ClaudeWorkingIcon string
+)
+
+// icon is an icon variable with the style it is rendered in
+type icon struct {
+	target *string
+	style  lipgloss.Style
+}
+
+// colored returns the style of an icon in a color
+func colored(color lipgloss.Color) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(color)
+}
+
+// icons maps the names the config's [icons] table uses to the icon variables
+var icons = map[string]icon{
+	"expanded":       {&ExpandedIcon, colored(ColorPrimary)},
+	"collapsed":      {&CollapsedIcon, colored(ColorDim)},
+	"last":           {&LastIcon, colored(ColorWarning)},
+	"note":           {&NoteIcon, colored(ColorPrimary)},
+	"suspended":      {&SuspendedIcon, colored(ColorDim)},
+	"branch":         {&BranchIcon, colored(ColorDim)},
+	"healthy":        {&HealthyIcon, colored(ColorSuccess)},
+	"unhealthy":      {&UnhealthyIcon, colored(ColorError)},
+	"recent":         {&RecentIcon, colored(ColorDim)},
+	"archive":        {&ArchiveIcon, colored(ColorDim)},
+	"group":          {&GroupIcon, colored(ColorDim)},
+	"small_client":   {&SmallClientIcon, colored(ColorWarning)},
+	"mark":           {&MarkIcon, colored(ColorSuccess).Bold(true)},
+	"marked_pane":    {&MarkedPaneIcon, colored(ColorWarning)},
+	"dead_pane":      {&DeadPaneIcon, colored(ColorError)},
+	"claude":         {&ClaudeLabel, lipgloss.NewStyle()},
+	"claude_waiting": {&ClaudeWaitingIcon, lipgloss.NewStyle()},
+	"claude_working": {&ClaudeWorkingIcon, lipgloss.NewStyle()},
+}
+
+// iconPresets holds the glyphs of each icon set. ASCII icons in the session
+// row's fixed columns are a single character, like the glyphs they replace.
+var iconPresets = map[string]map[string]string{
+	IconsNerd: {
+		"expanded": "▼", "collapsed": "▶", "last": "󰒮", "note": "󰎞", "suspended": "⏸", "branch": "",
+		"healthy": "●", "unhealthy": "●", "recent": "󰦛", "archive": "󰀼", "group": "⧉", "small_client": "󰍹",
+		"mark": "●", "marked_pane": "◆", "dead_pane": "✝", "claude": "CC:", "claude_waiting": "?",
+	},
+	IconsASCII: {
+		"expanded": "v", "collapsed": ">", "last": "^", "note": "n", "suspended": "z", "branch": "git",
+		"healthy": "+", "unhealthy": "!", "recent": "-", "archive": "=", "group": "&", "small_client": "<",
+		"mark": "*", "marked_pane": "m", "dead_pane": "x", "claude": "CC:", "claude_waiting": "?",
+	},
+}
+
+func init() {
+	_ = SetIcons(IconsNerd, nil)
+}
+
+// IconNames lists the icons the config's [icons] table can set
+func IconNames() []string {
+	names := make([]string, 0, len(icons))
+	for name := range icons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetIcons renders the icons of a set (nerd or ascii; auto has to be
+// resolved with DetectIconSet first), with single icons overridden by name
+func SetIcons(set string, overrides map[string]string) error {
+	preset, ok := iconPresets[set]
+	if !ok {
+		return fmt.Errorf("invalid icon set %q (valid: %s)", set, strings.Join(IconSets, ", "))
+	}
+	for name := range overrides {
+		if _, ok := icons[name]; !ok {
+			return fmt.Errorf("unknown icon %q (valid: %s)", name, strings.Join(IconNames(), ", "))
+		}
+	}
+
+	for name, i := range icons {
+		glyph, ok := overrides[name]
+		if !ok {
+			glyph = preset[name]
+		}
+		*i.target = ""
+		if glyph != "" {
+			*i.target = i.style.Render(glyph)
+		}
+	}
+	return nil
+}
+
+// DetectIconSet resolves the auto icon set from the environment: ascii on
+// the Linux console, dumb terminals and non-UTF-8 locales, nerd otherwise.
+// Whether a nerd font is installed can't be told, so nerd is the guess
+// wherever the glyphs could render at all.
+func DetectIconSet(getenv func(string) string) string {
+	if term := getenv("TERM"); term == "linux" || term == "dumb" {
+		return IconsASCII
+	}
+	locale := ""
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale = getenv(name); locale != "" {
+			break
+		}
+	}
+	locale = strings.ToLower(locale)
+	if !strings.Contains(locale, "utf-8") && !strings.Contains(locale, "utf8") {
+		return IconsASCII
+	}
+	return IconsNerd
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestSetIcons(t *testing.T) {
+	t.Cleanup(func() { _ = SetIcons(IconsNerd, nil) })
+
+	if err := SetIcons(IconsASCII, map[string]string{"last": "*", "claude_working": "~"}); err != nil {
+		t.Fatalf("SetIcons() error = %v", err)
+	}
+	if got := ansi.Strip(LastIcon); got != "*" {
+		t.Errorf("LastIcon = %q, want the override", got)
+	}
+	if got := ansi.Strip(CollapsedIcon); got != ">" {
+		t.Errorf("CollapsedIcon = %q, want the ascii preset", got)
+	}
+	if got := FormatClaudeStatus("working", 0); !strings.Contains(ansi.Strip(got), "CC: ~") {
+		t.Errorf("FormatClaudeStatus(working) = %q, want the fixed working icon", got)
+	}
+
+	// Every icon has a glyph in every preset
+	for set, preset := range iconPresets {
+		for _, name := range IconNames() {
+			if _, ok := preset[name]; !ok && name != "claude_working" {
+				t.Errorf("%s set has no %s icon", set, name)
+			}
+		}
+	}
+
+	if err := SetIcons("emoji", nil); err == nil {
+		t.Error("SetIcons() should refuse an unknown set")
+	}
+	if err := SetIcons(IconsNerd, map[string]string{"pin": "P"}); err == nil {
+		t.Error("SetIcons() should refuse an unknown icon")
+	}
+}
+
+func TestDetectIconSet(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{"TERM": "xterm-256color", "LANG": "en_US.UTF-8"}, IconsNerd},
+		{map[string]string{"TERM": "xterm-256color", "LC_ALL": "C", "LANG": "en_US.UTF-8"}, IconsASCII},
+		{map[string]string{"TERM": "tmux-256color", "LC_CTYPE": "de_DE.utf8"}, IconsNerd},
+		{map[string]string{"TERM": "linux", "LANG": "en_US.UTF-8"}, IconsASCII},
+		{map[string]string{"TERM": "xterm"}, IconsASCII},
+	}
+	for _, tt := range tests {
+		if got := DetectIconSet(func(name string) string { return tt.env[name] }); got != tt.want {
+			t.Errorf("DetectIconSet(%v) = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
@@ -9,10 +9,52 @@ type KeyMap struct {
 	Expand        key.Binding
 	Collapse      key.Binding
 	Select        key.Binding
+	SelectZoom    key.Binding
 	Kill          key.Binding
+	KillNumber    key.Binding
 	Create        key.Binding
+	CreateHere    key.Binding
+	CreateQuiet   key.Binding
+	CreateGrouped key.Binding
 	PickDirectory key.Binding
+	AddNote       key.Binding
+	ViewNotes     key.Binding
+	NotesPane     key.Binding
+	PreviewPane   key.Binding
+	Archive       key.Binding
+	JumpWaiting   key.Binding
+	AgentsView    key.Binding
+	Tag           key.Binding
+	Merge         key.Binding
+	BreakOut      key.Binding
+	Rename        key.Binding
+	Suspend       key.Binding
+	Back          key.Binding
+	Forward       key.Binding
+	SizeProfile   key.Binding
+	RowDetail     key.Binding
+	MoveUp        key.Binding
+	MoveDown      key.Binding
+	SwitchClient  key.Binding
+	DetachSmall   key.Binding
+	Yank          key.Binding
+	YankPath      key.Binding
+	ErrorDetail   key.Binding
+	SnapshotDiff  key.Binding
+	CycleMatcher  key.Binding
+	JoinMarked    key.Binding
+	SwapMarked    key.Binding
+	Restore       key.Binding
+	EditConfig    key.Binding
+	SaveNote      key.Binding
+	Save          key.Binding
+	ToggleServer  key.Binding
+	Mark          key.Binding
+	MarkAll       key.Binding
+	HistoryPrev   key.Binding
+	HistoryNext   key.Binding
 	Quit          key.Binding
+	SuspendPicker key.Binding
 	Cancel        key.Binding
 	Confirm       key.Binding
 	Jump1         key.Binding
@@ -49,22 +91,190 @@ var DefaultKeyMap = KeyMap{
 		key.WithKeys("enter"),
 		key.WithHelp("enter", "switch"),
 	),
+	SelectZoom: key.NewBinding(
+		key.WithKeys("alt+enter"),
+		key.WithHelp("M-enter", "switch and zoom"),
+	),
 	Kill: key.NewBinding(
 		key.WithKeys("ctrl+x"),
 		key.WithHelp("C-x", "kill"),
 	),
+	KillNumber: key.NewBinding(
+		key.WithKeys("alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9"),
+		key.WithHelp("M-1…9", "kill session N"),
+	),
 	Create: key.NewBinding(
 		key.WithKeys("ctrl+n"),
 		key.WithHelp("C-n", "new"),
 	),
+	CreateHere: key.NewBinding(
+		key.WithKeys("alt+n"),
+		key.WithHelp("M-n", "new here"),
+	),
+	CreateQuiet: key.NewBinding(
+		key.WithKeys("alt+enter"),
+		key.WithHelp("M-enter", "create in background"),
+	),
+	CreateGrouped: key.NewBinding(
+		key.WithKeys("alt+g"),
+		key.WithHelp("M-g", "new grouped"),
+	),
 	PickDirectory: key.NewBinding(
 		key.WithKeys("ctrl+p"),
 		key.WithHelp("C-p", "projects"),
 	),
+	AddNote: key.NewBinding(
+		key.WithKeys("ctrl+e"),
+		key.WithHelp("C-e", "note"),
+	),
+	ViewNotes: key.NewBinding(
+		key.WithKeys("ctrl+o"),
+		key.WithHelp("C-o", "notes"),
+	),
+	NotesPane: key.NewBinding(
+		key.WithKeys("alt+v"),
+		key.WithHelp("M-v", "notes/windows pane"),
+	),
+	PreviewPane: key.NewBinding(
+		key.WithKeys("ctrl+v"),
+		key.WithHelp("C-v", "preview/windows pane"),
+	),
+	Archive: key.NewBinding(
+		key.WithKeys("alt+a"),
+		key.WithHelp("M-a", "archive"),
+	),
+	JumpWaiting: key.NewBinding(
+		key.WithKeys("alt+w"),
+		key.WithHelp("M-w", "waiting Claude"),
+	),
+	AgentsView: key.NewBinding(
+		key.WithKeys("alt+W"),
+		key.WithHelp("M-W", "agents"),
+	),
+	Tag: key.NewBinding(
+		key.WithKeys("ctrl+g"),
+		key.WithHelp("C-g", "tags"),
+	),
+	Merge: key.NewBinding(
+		key.WithKeys("ctrl+w"),
+		key.WithHelp("C-w", "merge"),
+	),
+	BreakOut: key.NewBinding(
+		key.WithKeys("alt+b"),
+		key.WithHelp("M-b", "break out"),
+	),
+	Rename: key.NewBinding(
+		key.WithKeys("alt+r"),
+		key.WithHelp("M-r", "rename"),
+	),
+	Suspend: key.NewBinding(
+		key.WithKeys("alt+s"),
+		key.WithHelp("M-s", "suspend/resume"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys("alt+o", "alt+left"),
+		key.WithHelp("M-o", "back"),
+	),
+	Forward: key.NewBinding(
+		key.WithKeys("alt+i", "alt+right"),
+		key.WithHelp("M-i", "forward"),
+	),
+	SizeProfile: key.NewBinding(
+		key.WithKeys("alt+p"),
+		key.WithHelp("M-p", "size"),
+	),
+	RowDetail: key.NewBinding(
+		key.WithKeys("alt+d"),
+		key.WithHelp("M-d", "compact/detailed rows"),
+	),
+	MoveUp: key.NewBinding(
+		key.WithKeys("alt+k", "alt+up"),
+		key.WithHelp("M-k", "move session up"),
+	),
+	MoveDown: key.NewBinding(
+		key.WithKeys("alt+j", "alt+down"),
+		key.WithHelp("M-j", "move session down"),
+	),
+	SwitchClient: key.NewBinding(
+		key.WithKeys("alt+c"),
+		key.WithHelp("M-c", "switch other client"),
+	),
+	DetachSmall: key.NewBinding(
+		key.WithKeys("alt+x"),
+		key.WithHelp("M-x", "detach small clients"),
+	),
+	Yank: key.NewBinding(
+		key.WithKeys("alt+y"),
+		key.WithHelp("M-y", "copy target"),
+	),
+	YankPath: key.NewBinding(
+		key.WithKeys("alt+Y"),
+		key.WithHelp("M-Y", "copy path"),
+	),
+	ErrorDetail: key.NewBinding(
+		key.WithKeys("alt+e"),
+		key.WithHelp("M-e", "error details"),
+	),
+	SnapshotDiff: key.NewBinding(
+		key.WithKeys("ctrl+d"),
+		key.WithHelp("C-d", "snapshot diff"),
+	),
+	CycleMatcher: key.NewBinding(
+		key.WithKeys("alt+f"),
+		key.WithHelp("M-f", "cycle filter matcher"),
+	),
+	JoinMarked: key.NewBinding(
+		key.WithKeys("alt+m"),
+		key.WithHelp("M-m", "join marked pane here"),
+	),
+	SwapMarked: key.NewBinding(
+		key.WithKeys("alt+M"),
+		key.WithHelp("M-M", "swap with marked pane"),
+	),
+	Restore: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("C-r", "restore"),
+	),
+	EditConfig: key.NewBinding(
+		key.WithKeys("ctrl+f"),
+		key.WithHelp("C-f", "config"),
+	),
+	SaveNote: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("C-s", "save"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("C-s", "save"),
+	),
+	ToggleServer: key.NewBinding(
+		key.WithKeys("ctrl+t"),
+		key.WithHelp("C-t", "server"),
+	),
+	Mark: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "mark"),
+	),
+	MarkAll: key.NewBinding(
+		key.WithKeys("ctrl+a"),
+		key.WithHelp("C-a", "mark all"),
+	),
+	HistoryPrev: key.NewBinding(
+		key.WithKeys("ctrl+p"),
+		key.WithHelp("C-p", "older filter"),
+	),
+	HistoryNext: key.NewBinding(
+		key.WithKeys("ctrl+n"),
+		key.WithHelp("C-n", "newer filter"),
+	),
 	Quit: key.NewBinding(
 		key.WithKeys("ctrl+c"),
 		key.WithHelp("C-c", "quit"),
 	),
+	SuspendPicker: key.NewBinding(
+		key.WithKeys("ctrl+z"),
+		key.WithHelp("C-z", "suspend tsm"),
+	),
 	Cancel: key.NewBinding(
 		key.WithKeys("esc"),
 		key.WithHelp("esc", "cancel"),
@@ -97,35 +307,206 @@ func helpSep() string {
 // HelpNormal returns the help text for normal mode
 func HelpNormal() string {
 	return helpItem("type", "filter") + helpSep() +
+		helpItem(":", "commands") + helpSep() +
 		helpItem("C-j/k | ↑↓", "nav") + helpSep() +
 		helpItem("C-h/l | ←→", "expand") + helpSep() +
+		helpItem("M-enter", "zoom") + helpSep() +
 		helpItem("C-x", "kill") + helpSep() +
+		helpItem("M-1…9", "kill N") + helpSep() +
+		helpItem("M-a", "archive") + helpSep() +
+		helpItem("C-w", "merge") + helpSep() +
+		helpItem("M-b", "break out") + helpSep() +
+		helpItem("M-r", "rename") + helpSep() +
+		helpItem("M-s", "suspend") + helpSep() +
+		helpItem("M-o/i", "back/fwd") + helpSep() +
+		helpItem("M-w", "waiting Claude") + helpSep() +
+		helpItem("M-W", "agents") + helpSep() +
+		helpItem("M-p", "size") + helpSep() +
+		helpItem("M-d", "details") + helpSep() +
+		helpItem("M-j/k", "move") + helpSep() +
+		helpItem("M-y/Y", "copy target/path") + helpSep() +
+		helpItem("M-c", "other client") + helpSep() +
+		helpItem("M-x", "detach small clients") + helpSep() +
+		helpItem("M-m/M", "join/swap marked pane") + helpSep() +
+		helpItem("tab", "mark") + helpSep() +
 		helpItem("C-n", "new") + helpSep() +
-		helpItem("C-p", "projects")
+		helpItem("M-n", "new here") + helpSep() +
+		helpItem("M-g", "new grouped") + helpSep() +
+		helpItem("C-p", "projects") + helpSep() +
+		helpItem("C-e/o", "note") + helpSep() +
+		helpItem("M-v", "notes pane") + helpSep() +
+		helpItem("C-v", "preview pane") + helpSep() +
+		helpItem("C-g", "tags")
+}
+
+// HelpReadOnly returns the help text for normal mode with --read-only
+func HelpReadOnly() string {
+	return helpItem("type", "filter") + helpSep() +
+		helpItem(":", "commands") + helpSep() +
+		helpItem("C-j/k | ↑↓", "nav") + helpSep() +
+		helpItem("C-h/l | ←→", "expand") + helpSep() +
+		helpItem("M-enter", "zoom") + helpSep() +
+		helpItem("M-o/i", "back/fwd") + helpSep() +
+		helpItem("M-w", "waiting Claude") + helpSep() +
+		helpItem("M-W", "agents") + helpSep() +
+		helpItem("M-p", "size") + helpSep() +
+		helpItem("M-d", "details") + helpSep() +
+		helpItem("M-j/k", "move") + helpSep() +
+		helpItem("M-y/Y", "copy target/path") + helpSep() +
+		helpItem("M-c", "other client") + helpSep() +
+		helpItem("C-e/o", "note") + helpSep() +
+		helpItem("M-v", "notes pane") + helpSep() +
+		helpItem("C-v", "preview pane") + helpSep() +
+		helpItem("C-g", "tags")
 }
 
 // HelpFiltering returns the help text when filter is active
 func HelpFiltering() string {
 	return helpItem("esc", "clear") + helpSep() +
 		helpItem("enter", "select") + helpSep() +
+		helpItem("C-p/n", "history") + helpSep() +
+		helpItem("M-f", "matcher") + helpSep() +
+		helpItem("C-a", "mark all") + helpSep() +
 		helpItem("C-c", "quit")
 }
 
+// HelpMarked returns the help text when items are marked
+func HelpMarked() string {
+	return helpItem("tab", "mark") + helpSep() +
+		helpItem("C-a", "mark all") + helpSep() +
+		helpItem("C-x", "kill marked") + helpSep() +
+		helpItem("M-r", "rename marked") + helpSep() +
+		helpItem("esc", "clear marks")
+}
+
+// HelpWindowPane returns the help text when the split view's window pane has focus
+func HelpWindowPane() string {
+	return helpItem("↑↓", "nav") + helpSep() +
+		helpItem("enter", "switch") + helpSep() +
+		helpItem("M-enter", "zoom") + helpSep() +
+		helpItem("C-x", "kill") + helpSep() +
+		helpItem("M-y/Y", "copy") + helpSep() +
+		helpItem("M-m/M", "join/swap marked pane") + helpSep() +
+		helpItem("tab", "mark") + helpSep() +
+		helpItem("←", "sessions")
+}
+
 // HelpConfirmKill returns the help text for kill confirmation mode
 func HelpConfirmKill() string {
 	return helpItem("C-x", "confirm") + helpSep() +
 		helpItem("esc", "cancel")
 }
 
-// HelpCreate returns the help text for create mode
-func HelpCreate() string {
-	return helpItem("enter", "create") + helpSep() +
+// HelpKillTypeName returns the help text for a kill confirmed by typing the
+// session name
+func HelpKillTypeName() string {
+	return helpItem("enter", "confirm") + helpSep() +
+		helpItem("esc", "cancel")
+}
+
+// HelpConfirm returns the help text for confirmations taken with C-y
+func HelpConfirm() string {
+	return helpItem("C-y", "confirm") + helpSep() +
+		helpItem("esc", "cancel")
+}
+
+// HelpBroadcastReport returns the help text for the :send report
+func HelpBroadcastReport() string {
+	return helpItem("esc | enter", "close")
+}
+
+// HelpCreate returns the help text for create mode. With background set,
+// enter creates without switching and M-enter switches.
+func HelpCreate(background bool) string {
+	enter, alt := "create", "create in background"
+	if background {
+		enter, alt = alt, "create and switch"
+	}
+	return helpItem("enter", enter) + helpSep() +
+		helpItem("M-enter", alt) + helpSep() +
+		helpItem("esc", "cancel")
+}
+
+// HelpLayoutVars returns the help text while prompting for layout variables
+func HelpLayoutVars() string {
+	return helpItem("enter", "next") + helpSep() +
+		helpItem("esc", "cancel")
+}
+
+// HelpRename returns the help text for the batch rename prompt
+func HelpRename() string {
+	return helpItem("+pre", "add prefix") + helpSep() +
+		helpItem("-pre", "remove prefix") + helpSep() +
+		helpItem("old/new", "replace") + helpSep() +
+		helpItem("enter", "apply") + helpSep() +
+		helpItem("esc", "cancel")
+}
+
+// HelpCommand returns the help text for the command line
+func HelpCommand() string {
+	return helpItem("tab", "complete") + helpSep() +
+		helpItem("enter", "run") + helpSep() +
+		helpItem("esc", "cancel")
+}
+
+// HelpTagInput returns the help text for tag input mode
+func HelpTagInput() string {
+	return helpItem("enter", "save") + helpSep() +
+		helpItem("esc", "cancel") + helpSep() +
+		HelpDescStyle.Render("space-separated, empty clears")
+}
+
+// HelpMergeTarget returns the help text for the merge target picker
+func HelpMergeTarget() string {
+	return helpItem("type", "filter") + helpSep() +
+		helpItem("↑↓", "nav") + helpSep() +
+		helpItem("enter", "merge") + helpSep() +
+		helpItem("esc", "back/cancel")
+}
+
+// HelpClientTarget returns the help text for the client picker
+func HelpClientTarget() string {
+	return helpItem("↑↓", "nav") + helpSep() +
+		helpItem("enter", "switch client") + helpSep() +
 		helpItem("esc", "cancel")
 }
 
+// HelpErrorDetail returns the help text for the error detail view
+func HelpErrorDetail() string {
+	return helpItem("esc | M-e", "close")
+}
+
+// HelpSnapshotDiff returns the help text for the snapshot diff view
+func HelpSnapshotDiff() string {
+	return helpItem("esc | C-d", "close") + helpSep() +
+		helpItem("tsm restore", "recreate missing sessions")
+}
+
+// HelpConfigForm returns the help text for the config form
+func HelpConfigForm() string {
+	return helpItem("↑↓ | tab", "nav") + helpSep() +
+		helpItem("space", "toggle") + helpSep() +
+		helpItem("C-s", "save") + helpSep() +
+		helpItem("esc", "discard")
+}
+
 // HelpPickDirectory returns the help text for directory picker mode
 func HelpPickDirectory() string {
 	return helpItem("↑↓", "nav") + helpSep() +
 		helpItem("enter", "select") + helpSep() +
 		helpItem("esc", "back/cancel")
 }
+
+// HelpNoteInput returns the help text for note input mode
+func HelpNoteInput() string {
+	return helpItem("C-s", "save") + helpSep() +
+		helpItem("enter", "newline") + helpSep() +
+		helpItem("esc", "cancel")
+}
+
+// HelpNotes returns the help text for the notes view
+func HelpNotes() string {
+	return helpItem("↑↓", "scroll") + helpSep() +
+		helpItem("C-e", "add") + helpSep() +
+		helpItem("esc", "back")
+}
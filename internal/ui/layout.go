@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// MinFlexWidth is the fewest cells a flexible column is cut to; rows that
+// still don't fit overflow rather than cut names beyond recognition
+const MinFlexWidth = 8
+
+// Column is a cell of a row laid out by Row
+type Column struct {
+	Text  string // Content, may be styled
+	Width int    // Width the text is padded to, aligning the column across rows (0 = the text's own)
+	Gap   int    // Blank cells before the column
+	Flex  bool   // Cut in the middle, down to MinFlexWidth, when the row is too wide
+	Drop  bool   // Left out when the row is still too wide
+
+	// Priority orders the columns giving way: higher ones later, equal ones
+	// from the last
+	Priority int
+
+	// Fit renders a flexible column in at most the given cells, for content
+	// that is cut in part only (e.g. a name after its index). Without it the
+	// text is cut in the middle.
+	Fit func(width int) string
+}
+
+// width returns the cells the column takes, without its gap
+func (c Column) width() int {
+	return max(c.Width, lipgloss.Width(c.Text))
+}
+
+// Row lays out columns in at most width cells (0 = no limit), so render
+// functions only say what goes in a row and what may give way. Columns give
+// way from the last one (see Column.Priority): flexible columns are cut,
+// droppable ones left out, until the row fits. A row that can't give way
+// enough overflows.
+func Row(columns []Column, width int) string {
+	cols := slices.Clone(columns)
+	dropped := make([]bool, len(cols))
+
+	order := make([]int, len(cols))
+	for i := range order {
+		order[i] = len(cols) - 1 - i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(cols[a].Priority, cols[b].Priority)
+	})
+
+	over := -width
+	for _, c := range cols {
+		over += c.Gap + c.width()
+	}
+	for _, i := range order {
+		if width <= 0 || over <= 0 {
+			break
+		}
+		c := &cols[i]
+		if w := c.width(); c.Flex && w > MinFlexWidth {
+			fit := max(w-over, MinFlexWidth)
+			if c.Fit != nil {
+				c.Text = c.Fit(fit)
+			} else {
+				c.Text = TruncateMiddle(c.Text, fit)
+			}
+			c.Width = min(c.Width, fit)
+			over -= w - c.width()
+		}
+		if over > 0 && c.Drop {
+			dropped[i] = true
+			over -= c.Gap + c.width()
+		}
+	}
+
+	var b strings.Builder
+	for i, c := range cols {
+		if dropped[i] {
+			continue
+		}
+		b.WriteString(strings.Repeat(" ", c.Gap))
+		b.WriteString(c.Text)
+		b.WriteString(strings.Repeat(" ", c.width()-lipgloss.Width(c.Text)))
+	}
+	return b.String()
+}
+
+// TruncateMiddle cuts s to width cells by replacing its middle with an
+// ellipsis, keeping both ends ("feature/…-fix"), which tell names apart
+// better than their prefix alone. Styled text keeps its escape sequences.
+func TruncateMiddle(s string, width int) string {
+	if width <= 0 || ansi.StringWidth(s) <= width {
+		return s
+	}
+	keep := width - 1
+	head := (keep + 1) / 2
+	cut := ansi.StringWidth(s) - (keep - head)
+	tail := ansi.TruncateLeft(s, cut, "")
+	// TruncateLeft keeps a wide rune split by the cut
+	if ansi.StringWidth(tail) > keep-head {
+		tail = ansi.TruncateLeft(s, cut+1, "")
+	}
+	return ansi.Truncate(s, head, "") + "…" + tail
+}
+
+// RenderBorder returns a horizontal border line (empty in plain mode)
+func RenderBorder(width int) string {
+	if Plain {
+		return ""
+	}
+	return BorderStyle.Render(strings.Repeat("─", width))
+}
+
+// RenderSplitSeparator returns the separator between the split view panes
+func RenderSplitSeparator() string {
+	if Plain {
+		return " | "
+	}
+	return BorderStyle.Render(" │ ")
+}
+
+// ScrollbarChars returns scrollbar characters for each visible line
+// totalItems: total number of items in the list
+// visibleItems: number of items currently visible
+// scrollOffset: current scroll position (first visible item index)
+// height: number of lines to render scrollbar for
+func ScrollbarChars(totalItems, visibleItems, scrollOffset, height int) []string {
+	result := make([]string, height)
+
+	// No scrollbar needed if all items fit (never drawn in plain mode)
+	if totalItems <= visibleItems || height <= 0 || Plain {
+		for i := range result {
+			result[i] = " "
+		}
+		return result
+	}
+
+	// Calculate thumb size (minimum 1 line)
+	thumbSize := (visibleItems * height) / totalItems
+	if thumbSize < 1 {
+		thumbSize = 1
+	}
+
+	// Calculate thumb position
+	scrollRange := totalItems - visibleItems
+	trackRange := height - thumbSize
+	thumbPos := 0
+	if scrollRange > 0 && trackRange > 0 {
+		thumbPos = (scrollOffset * trackRange) / scrollRange
+	}
+
+	// Build scrollbar
+	trackChar := BorderStyle.Render("│")
+	thumbChar := lipgloss.NewStyle().Foreground(ColorSecondary).Render("┃")
+
+	for i := 0; i < height; i++ {
+		if i >= thumbPos && i < thumbPos+thumbSize {
+			result[i] = thumbChar
+		} else {
+			result[i] = trackChar
+		}
+	}
+
+	return result
+}
@@ -3,87 +3,86 @@ package ui
 import (
 	"strings"
 	"testing"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
-func TestFormatClaudeStatus(t *testing.T) {
+func TestRow(t *testing.T) {
+	cols := []Column{
+		{Text: "1"},
+		{Text: "feature/login-redirect-fix", Gap: 1, Width: 30, Flex: true},
+		{Text: "5m", Gap: 2},
+		{Text: "~/work/api", Gap: 2, Drop: true},
+	}
 	tests := []struct {
-		name           string
-		state          string
-		animationFrame int
-		wantEmpty      bool
-		contains       string
+		name  string
+		width int
+		want  string
 	}{
-		{
-			name:           "empty state returns empty",
-			state:          "",
-			animationFrame: 0,
-			wantEmpty:      true,
-		},
-		{
-			name:           "new state returns empty (no visual noise)",
-			state:          "new",
-			animationFrame: 0,
-			wantEmpty:      true,
-		},
-		{
-			name:           "working state frame 0",
-			state:          "working",
-			animationFrame: 0,
-			wantEmpty:      false,
-			contains:       ".",
-		},
-		{
-			name:           "working state frame 1",
-			state:          "working",
-			animationFrame: 1,
-			wantEmpty:      false,
-			contains:       "..",
-		},
-		{
-			name:           "working state frame 2",
-			state:          "working",
-			animationFrame: 2,
-			wantEmpty:      false,
-			contains:       "...",
-		},
-		{
-			name:           "waiting state",
-			state:          "waiting",
-			animationFrame: 0,
-			wantEmpty:      false,
-			contains:       "?",
-		},
-		{
-			name:           "unknown state returns empty",
-			state:          "unknown",
-			animationFrame: 0,
-			wantEmpty:      true,
-		},
+		{"no limit", 0, "1 feature/login-redirect-fix      5m  ~/work/api"},
+		{"fits", 48, "1 feature/login-redirect-fix      5m  ~/work/api"},
+		{"last column is dropped first", 44, "1 feature/login-redirect-fix      5m"},
+		{"padding gives way before the text", 35, "1 feature/login-redirect-fix     5m"},
+		{"flexible column is cut", 28, "1 feature/log…direct-fix  5m"},
+		{"cut down to the minimum", 14, "1 feat…fix  5m"},
+		{"overflows past the minimum", 5, "1 feat…fix  5m"},
 	}
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := FormatClaudeStatus(tt.state, tt.animationFrame)
-
-			if tt.wantEmpty && result != "" {
-				t.Errorf("FormatClaudeStatus(%q, %d) = %q, want empty", tt.state, tt.animationFrame, result)
-			}
-
-			if !tt.wantEmpty {
-				if result == "" {
-					t.Errorf("FormatClaudeStatus(%q, %d) returned empty, want non-empty", tt.state, tt.animationFrame)
-				}
-				if tt.contains != "" && !strings.Contains(result, tt.contains) {
-					t.Errorf("FormatClaudeStatus(%q, %d) = %q, should contain %q", tt.state, tt.animationFrame, result, tt.contains)
-				}
-				if !strings.Contains(result, "CC:") {
-					t.Errorf("FormatClaudeStatus(%q, %d) = %q, should contain 'CC:'", tt.state, tt.animationFrame, result)
-				}
+			if got := Row(cols, tt.width); got != tt.want {
+				t.Errorf("Row(%d) = %q, want %q", tt.width, got, tt.want)
 			}
 		})
 	}
 }
 
+func TestRowFit(t *testing.T) {
+	cols := []Column{
+		{Text: "3: a-very-long-window-name", Flex: true, Fit: func(width int) string {
+			return "3: " + TruncateMiddle("a-very-long-window-name", width-3)
+		}},
+		{Text: "1h", Gap: 1},
+	}
+	got := Row(cols, 20)
+	if lipgloss.Width(got) != 20 || !strings.HasPrefix(got, "3: a-very") || !strings.HasSuffix(got, "name 1h") {
+		t.Errorf("Row() = %q, want the index kept and the name cut to 20 cells", got)
+	}
+}
+
+func TestRowPriority(t *testing.T) {
+	cols := []Column{
+		{Text: "feature/login-redirect-fix", Flex: true},
+		{Text: "nvim", Gap: 1, Drop: true, Priority: 1},
+	}
+	// The name is cut before the command is left out
+	if got, want := Row(cols, 20), "feature…ect-fix nvim"; got != want {
+		t.Errorf("Row(20) = %q, want %q", got, want)
+	}
+	// and left out once the name can't be cut any shorter
+	if got, want := Row(cols, 10), "feat…fix"; got != want {
+		t.Errorf("Row(10) = %q, want %q", got, want)
+	}
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"feature/login-redirect-fix", 13, "featur…ct-fix"},
+		{"feature/login-redirect-fix", 12, "featur…t-fix"},
+		{"日本語のウィンドウ", 8, "日本…ウ"}, // A wide rune never straddles the cut
+		{"anything", 0, "anything"},
+	}
+	for _, tt := range tests {
+		if got := TruncateMiddle(tt.s, tt.width); got != tt.want {
+			t.Errorf("TruncateMiddle(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
 func TestScrollbarChars(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -1,11 +1,23 @@
 package ui
 
 import (
-	"strings"
-
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
+// Plain disables icons, colors and box drawing (screen-reader friendly output).
+// Enable with SetPlain before rendering.
+var Plain bool
+
+// SetPlain switches rendering to plain mode: no colors, no icons, no box drawing
+func SetPlain() {
+	Plain = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	// Hidden border keeps the layout overhead (AppBorderOverhead) unchanged
+	AppStyle = AppStyle.Border(lipgloss.HiddenBorder())
+}
+
 // ANSI 16 colors - adapts to terminal theme
 // 0-7: black, red, green, yellow, blue, magenta, cyan, white
 // 8-15: bright variants
@@ -39,6 +51,10 @@ var (
 			Foreground(ColorPrimary).
 			Padding(0, 1)
 
+	// Targeted server indicator shown next to the header
+	ServerStyle = lipgloss.NewStyle().
+			Foreground(ColorClaude)
+
 	FooterStyle = lipgloss.NewStyle().
 			Foreground(ColorSecondary).
 			Padding(0, 1)
@@ -51,6 +67,10 @@ var (
 				Foreground(ColorError).
 				Padding(0, 1)
 
+	WarningMessageStyle = lipgloss.NewStyle().
+				Foreground(ColorWarning).
+				Padding(0, 1)
+
 	// Session row styles
 	SessionStyle = lipgloss.NewStyle().
 			Padding(0, 1)
@@ -65,6 +85,10 @@ var (
 			Foreground(ColorSecondary).
 			Width(3)
 
+	// Number key label of a window row, counting the listed windows
+	WindowPickStyle = lipgloss.NewStyle().
+			Foreground(ColorSecondary)
+
 	IndexSelectedStyle = lipgloss.NewStyle().
 				Foreground(ColorWarning).
 				Bold(true).
@@ -78,13 +102,16 @@ var (
 				Foreground(ColorWarning).
 				Bold(true)
 
-	ExpandedIcon  = lipgloss.NewStyle().Foreground(ColorPrimary).Render("▼")
-	CollapsedIcon = lipgloss.NewStyle().Foreground(ColorDim).Render("▶")
-
 	TimeStyle = lipgloss.NewStyle().
 			Foreground(ColorDim)
 
-	LastIcon = lipgloss.NewStyle().Foreground(ColorWarning).Render("󰒮")
+	// Recent (dead) session name
+	RecentNameStyle = lipgloss.NewStyle().
+			Foreground(ColorDim)
+
+	// Marked count shown in the header
+	MarkedCountStyle = lipgloss.NewStyle().
+				Foreground(ColorSuccess)
 
 	// Claude status styles
 	ClaudeNewStyle = lipgloss.NewStyle().
@@ -99,10 +126,24 @@ var (
 	ClaudeLabelStyle = lipgloss.NewStyle().
 				Foreground(ColorClaude)
 
+	// Kill preview style (windows/panes lost by a kill)
+	KillPreviewStyle = lipgloss.NewStyle().
+				Foreground(ColorError).
+				Padding(0, 1)
+
 	// Input styles
 	InputPromptStyle = lipgloss.NewStyle().
 				Foreground(ColorPrimary)
 
+	// Input length counter at the limit, and hints on rejected input
+	InputWarningStyle = lipgloss.NewStyle().
+				Foreground(ColorWarning)
+
+	// Notes view styles
+	NoteHeadingStyle = lipgloss.NewStyle().
+				Foreground(ColorPrimary).
+				Bold(true)
+
 	// Help styles
 	HelpKeyStyle = lipgloss.NewStyle().
 			Foreground(ColorPrimary).
@@ -123,16 +164,34 @@ var (
 	BorderStyle = lipgloss.NewStyle().
 			Foreground(ColorDim)
 
+	// Matched part of a window name while filtering
+	MatchStyle = lipgloss.NewStyle().
+			Foreground(ColorWarning).
+			Underline(true)
+
+	// Windows that don't match the filter but are shown for context
+	DimStyle = lipgloss.NewStyle().
+			Foreground(ColorDim)
+
+	// Session tags shown after the name
+	TagStyle = lipgloss.NewStyle().
+			Foreground(ColorDim).
+			Italic(true)
+
+	// Window row style in the split view's window pane (not indented)
+	SplitWindowStyle = lipgloss.NewStyle().
+				Padding(0, 1)
+
 	// Statusline style
 	StatuslineStyle = lipgloss.NewStyle().
 			Foreground(ColorDim).
 			Padding(0, 1)
-)
 
-// RenderBorder returns a horizontal border line
-func RenderBorder(width int) string {
-	return BorderStyle.Render(strings.Repeat("─", width))
-}
+	// Statusline while the filter matches nothing, so a typo stands out
+	StatuslineWarningStyle = lipgloss.NewStyle().
+				Foreground(ColorWarning).
+				Padding(0, 1)
+)
 
 // FormatClaudeStatus formats the Claude status for display
 // animationFrame cycles 0-2 for animated states
@@ -141,65 +200,31 @@ func FormatClaudeStatus(state string, animationFrame int) string {
 		return ""
 	}
 
-	label := ClaudeLabelStyle.Render("CC:")
+	if Plain {
+		// Textual state, no animation
+		if state == "working" || state == "waiting" {
+			return "[" + state + "]"
+		}
+		return ""
+	}
+
+	label := ClaudeLabelStyle.Render(ClaudeLabel)
 
 	switch state {
 	case "new":
 		// Don't show badge for "new" - it's just noise
 		return ""
 	case "working":
-		// Animated ellipses: .  ..  ...
-		dots := []string{".  ", ".. ", "..."}
-		return "[" + label + " " + ClaudeWorkingStyle.Render(dots[animationFrame]) + "]"
+		// Animated ellipses: .  ..  ... (unless the icon set has a fixed one)
+		working := ClaudeWorkingIcon
+		if working == "" {
+			working = []string{".  ", ".. ", "..."}[animationFrame]
+		}
+		return "[" + label + " " + ClaudeWorkingStyle.Render(working) + "]"
 	case "waiting":
 		// Prominent - needs user attention
-		return "[" + label + " " + ClaudeWorkingStyle.Render("?") + "]"
+		return "[" + label + " " + ClaudeWorkingStyle.Render(ClaudeWaitingIcon) + "]"
 	default:
 		return ""
 	}
 }
-
-// ScrollbarChars returns scrollbar characters for each visible line
-// totalItems: total number of items in the list
-// visibleItems: number of items currently visible
-// scrollOffset: current scroll position (first visible item index)
-// height: number of lines to render scrollbar for
-func ScrollbarChars(totalItems, visibleItems, scrollOffset, height int) []string {
-	result := make([]string, height)
-
-	// No scrollbar needed if all items fit
-	if totalItems <= visibleItems || height <= 0 {
-		for i := range result {
-			result[i] = " "
-		}
-		return result
-	}
-
-	// Calculate thumb size (minimum 1 line)
-	thumbSize := (visibleItems * height) / totalItems
-	if thumbSize < 1 {
-		thumbSize = 1
-	}
-
-	// Calculate thumb position
-	scrollRange := totalItems - visibleItems
-	trackRange := height - thumbSize
-	thumbPos := 0
-	if scrollRange > 0 && trackRange > 0 {
-		thumbPos = (scrollOffset * trackRange) / scrollRange
-	}
-
-	// Build scrollbar
-	trackChar := BorderStyle.Render("│")
-	thumbChar := lipgloss.NewStyle().Foreground(ColorSecondary).Render("┃")
-
-	for i := 0; i < height; i++ {
-		if i >= thumbPos && i < thumbPos+thumbSize {
-			result[i] = thumbChar
-		} else {
-			result[i] = trackChar
-		}
-	}
-
-	return result
-}
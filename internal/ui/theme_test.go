@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatClaudeStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		state          string
+		animationFrame int
+		wantEmpty      bool
+		contains       string
+	}{
+		{
+			name:           "empty state returns empty",
+			state:          "",
+			animationFrame: 0,
+			wantEmpty:      true,
+		},
+		{
+			name:           "new state returns empty (no visual noise)",
+			state:          "new",
+			animationFrame: 0,
+			wantEmpty:      true,
+		},
+		{
+			name:           "working state frame 0",
+			state:          "working",
+			animationFrame: 0,
+			wantEmpty:      false,
+			contains:       ".",
+		},
+		{
+			name:           "working state frame 1",
+			state:          "working",
+			animationFrame: 1,
+			wantEmpty:      false,
+			contains:       "..",
+		},
+		{
+			name:           "working state frame 2",
+			state:          "working",
+			animationFrame: 2,
+			wantEmpty:      false,
+			contains:       "...",
+		},
+		{
+			name:           "waiting state",
+			state:          "waiting",
+			animationFrame: 0,
+			wantEmpty:      false,
+			contains:       "?",
+		},
+		{
+			name:           "unknown state returns empty",
+			state:          "unknown",
+			animationFrame: 0,
+			wantEmpty:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FormatClaudeStatus(tt.state, tt.animationFrame)
+
+			if tt.wantEmpty && result != "" {
+				t.Errorf("FormatClaudeStatus(%q, %d) = %q, want empty", tt.state, tt.animationFrame, result)
+			}
+
+			if !tt.wantEmpty {
+				if result == "" {
+					t.Errorf("FormatClaudeStatus(%q, %d) returned empty, want non-empty", tt.state, tt.animationFrame)
+				}
+				if tt.contains != "" && !strings.Contains(result, tt.contains) {
+					t.Errorf("FormatClaudeStatus(%q, %d) = %q, should contain %q", tt.state, tt.animationFrame, result, tt.contains)
+				}
+				if !strings.Contains(result, "CC:") {
+					t.Errorf("FormatClaudeStatus(%q, %d) = %q, should contain 'CC:'", tt.state, tt.animationFrame, result)
+				}
+			}
+		})
+	}
+}
+
+func TestPlainMode(t *testing.T) {
+	Plain = true
+	defer func() { Plain = false }()
+
+	if got := FormatClaudeStatus("waiting", 0); got != "[waiting]" {
+		t.Errorf("FormatClaudeStatus(waiting) in plain mode = %q, want %q", got, "[waiting]")
+	}
+	if got := FormatClaudeStatus("working", 2); got != "[working]" {
+		t.Errorf("FormatClaudeStatus(working) in plain mode = %q, want %q", got, "[working]")
+	}
+	if got := FormatClaudeStatus("new", 0); got != "" {
+		t.Errorf("FormatClaudeStatus(new) in plain mode = %q, want empty", got)
+	}
+	if got := RenderBorder(10); got != "" {
+		t.Errorf("RenderBorder() in plain mode = %q, want empty", got)
+	}
+	for i, ch := range ScrollbarChars(20, 5, 0, 5) {
+		if ch != " " {
+			t.Errorf("ScrollbarChars()[%d] in plain mode = %q, want space", i, ch)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Package version reports the tsm build version
+package version
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Set at build time with -ldflags "-X github.com/nikbrunner/tsm/internal/version.Version=..."
+// (see the Makefile). Builds without them fall back to the Go build info.
+var (
+	Version = ""
+	Commit  = ""
+	Date    = ""
+)
+
+// Info is the version of the running binary
+type Info struct {
+	Version string // Release tag (e.g. "v1.4.0"), "dev" if unknown
+	Commit  string // Git revision, may be empty
+	Date    string // Build or commit date, may be empty
+}
+
+// Get returns the version of the running binary. `go install ...@v1.4.0`
+// embeds the module version, local builds the VCS revision.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.Date == "":
+				info.Date = s.Value
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
+
+// String renders the version as "v1.4.0 (abc1234, 2026-01-02T03:04:05Z)"
+func (i Info) String() string {
+	commit := i.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	switch {
+	case commit != "" && i.Date != "":
+		return fmt.Sprintf("%s (%s, %s)", i.Version, commit, i.Date)
+	case commit != "":
+		return fmt.Sprintf("%s (%s)", i.Version, commit)
+	}
+	return i.Version
+}
@@ -0,0 +1,19 @@
+package version
+
+import "testing"
+
+func TestInfoString(t *testing.T) {
+	tests := []struct {
+		info Info
+		want string
+	}{
+		{Info{Version: "v1.4.0", Commit: "abc1234def", Date: "2026-01-02"}, "v1.4.0 (abc1234, 2026-01-02)"},
+		{Info{Version: "dev", Commit: "abc1234"}, "dev (abc1234)"},
+		{Info{Version: "v1.4.0"}, "v1.4.0"},
+	}
+	for _, tt := range tests {
+		if got := tt.info.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}